@@ -1,6 +1,8 @@
 package orm
 
 import (
+	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/deicod/erm/orm/runtime"
@@ -37,3 +39,39 @@ func BenchmarkBuildBulkDeleteSQL(b *testing.B) {
 		}
 	}
 }
+
+// BenchmarkBulkLoad compares preparing a large load as chunked INSERT statements with streaming
+// it through a COPY source. Neither touches a database; they measure client-side cost per load.
+func BenchmarkBulkLoad(b *testing.B) {
+	columns := []string{"id", "name", "email", "visits"}
+	for _, size := range []int{1000, 20000, 100000} {
+		rows := make([][]any, size)
+		for i := range rows {
+			rows[i] = []any{i, "alice", "alice@example.com", i % 7}
+		}
+		b.Run(fmt.Sprintf("insert/%d", size), func(b *testing.B) {
+			spec := runtime.BulkInsertSpec{Table: "users", Columns: columns, Returning: columns, Rows: rows}
+			for i := 0; i < b.N; i++ {
+				for _, chunk := range runtime.ChunkBulkInsert(spec) {
+					if _, _, err := runtime.BuildBulkInsertSQL(chunk); err != nil {
+						b.Fatalf("build bulk insert: %v", err)
+					}
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("copy/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				src, stop := runtime.CopySource(slices.Values(rows), func(row []any) ([]any, error) { return row, nil })
+				for src.Next() {
+					if _, err := src.Values(); err != nil {
+						b.Fatalf("copy values: %v", err)
+					}
+				}
+				stop()
+				if err := src.Err(); err != nil {
+					b.Fatalf("copy source: %v", err)
+				}
+			}
+		})
+	}
+}
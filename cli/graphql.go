@@ -96,7 +96,9 @@ type PageInfo {
   endCursor: String
 }
 
-directive @auth(roles: [String!]) on FIELD_DEFINITION
+directive @auth(roles: [String!], owner: String) on FIELD_DEFINITION
+
+directive @authClaim(claim: String!, field: String, value: String) repeatable on FIELD_DEFINITION
 
 type Query {
   node(id: ID!): Node
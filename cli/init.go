@@ -96,6 +96,8 @@ oidc:
 graphql:
   # 4. The HTTP path your API will be served on.
   path: "/graphql"
+  # Report invalid mutation input in payload userErrors, or as top-level errors with "top_level".
+  mutation_errors: payload
 extensions:
   postgis: false
   pgvector: false
@@ -190,6 +192,7 @@ import (
         "syscall"
         "time"
 
+        "{{.ModulePath}}/graphql/resolvers"
         "{{.ModulePath}}/graphql/server"
         "{{.ModulePath}}/observability/metrics"
         "{{.ModulePath}}/orm/gen"
@@ -226,8 +229,9 @@ func main() {
         ormClient := gen.NewClient(db)
 
         gqlOpts := server.Options{
-                ORM:       ormClient,
-                Collector: collector,
+                ORM:            ormClient,
+                Collector:      collector,
+                MutationErrors: resolvers.MutationErrorMode(cfg.GraphQL.MutationErrors),
                 Subscriptions: server.SubscriptionOptions{
                         Enabled: cfg.GraphQL.Subscriptions.Enabled,
                         Transports: server.SubscriptionTransports{
@@ -331,8 +335,9 @@ type replicaPolicyConfig struct {
 }
 
 type graphQLConfig struct {
-        Path          string {{.Backtick}}yaml:"path"{{.Backtick}}
-        Subscriptions struct {
+        Path           string {{.Backtick}}yaml:"path"{{.Backtick}}
+        MutationErrors string {{.Backtick}}yaml:"mutation_errors"{{.Backtick}}
+        Subscriptions  struct {
                 Enabled    bool {{.Backtick}}yaml:"enabled"{{.Backtick}}
                 Transports struct {
                         Websocket bool {{.Backtick}}yaml:"websocket"{{.Backtick}}
@@ -11,6 +11,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/deicod/erm/graphql/resolvers"
 	"github.com/deicod/erm/graphql/server"
 	"github.com/deicod/erm/observability/metrics"
 	"github.com/deicod/erm/orm/gen"
@@ -44,8 +45,9 @@ func main() {
 	ormClient := gen.NewClient(db)
 
 	gqlOpts := server.Options{
-		ORM:       ormClient,
-		Collector: collector,
+		ORM:            ormClient,
+		Collector:      collector,
+		MutationErrors: resolvers.MutationErrorMode(cfg.GraphQL.MutationErrors),
 		Subscriptions: server.SubscriptionOptions{
 			Enabled: cfg.GraphQL.Subscriptions.Enabled,
 			Transports: server.SubscriptionTransports{
@@ -149,8 +151,9 @@ type replicaPolicyConfig struct {
 }
 
 type graphQLConfig struct {
-	Path          string `yaml:"path"`
-	Subscriptions struct {
+	Path           string `yaml:"path"`
+	MutationErrors string `yaml:"mutation_errors"`
+	Subscriptions  struct {
 		Enabled    bool `yaml:"enabled"`
 		Transports struct {
 			Websocket bool `yaml:"websocket"`
@@ -2,6 +2,7 @@ package blog_test
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	pgxmock "github.com/pashagolub/pgxmock/v4"
 
 	"github.com/deicod/erm/graphql/relay"
+	"github.com/deicod/erm/orm/runtime"
 	ermtesting "github.com/deicod/erm/testing"
 )
 
@@ -43,8 +45,8 @@ func TestGraphQLUserResolvers(t *testing.T) {
 		t.Fatalf("unexpected global id: %s", createResp.CreateUser.User.ID)
 	}
 
-	mock.ExpectQuery("SELECT id, slug, created_at, updated_at FROM users WHERE id = $1").
-		WithArgs("user-graphql").
+	mock.ExpectQuery("SELECT id, slug, created_at, updated_at FROM users WHERE id = ANY($1) LIMIT $2").
+		WithArgs([]string{"user-graphql"}, 1).
 		WillReturnRows(mock.NewRows([]string{"id", "slug", "created_at", "updated_at"}).AddRow("user-graphql", "user-graphql", createdAt, updatedAt))
 
 	var userResp struct {
@@ -59,18 +61,24 @@ func TestGraphQLUserResolvers(t *testing.T) {
 		t.Fatalf("unexpected user payload: %+v", userResp.User)
 	}
 
-	mock.ExpectQuery("SELECT COUNT(*) FROM users").
-		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(1))
-	mock.ExpectQuery("SELECT id, slug, created_at, updated_at FROM users ORDER BY id LIMIT $1 OFFSET $2").
-		WithArgs(2, 0).
+	mock.ExpectQuery("SELECT id, slug, created_at, updated_at FROM users ORDER BY id ASC LIMIT $1").
+		WithArgs(3).
 		WillReturnRows(mock.NewRows([]string{"id", "slug", "created_at", "updated_at"}).
 			AddRow("user-graphql", "user-graphql", createdAt, updatedAt).
-			AddRow("user-other", "user-other", createdAt.Add(time.Minute), updatedAt.Add(time.Minute)))
+			AddRow("user-other", "user-other", createdAt.Add(time.Minute), updatedAt.Add(time.Minute)).
+			AddRow("user-third", "user-third", createdAt.Add(2*time.Minute), updatedAt.Add(2*time.Minute)))
+	mock.ExpectQuery("SELECT COUNT(*) FROM users").
+		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(3))
 
 	var listResp struct {
 		Users struct {
 			TotalCount int
-			Edges      []struct {
+			PageInfo   struct {
+				HasNextPage     bool
+				HasPreviousPage bool
+				EndCursor       string
+			}
+			Edges []struct {
 				Node struct {
 					ID string
 				}
@@ -80,15 +88,52 @@ func TestGraphQLUserResolvers(t *testing.T) {
 	harness.MustExec(t, ctx, `query($first: Int) {
                 users(first: $first) {
                         totalCount
+                        pageInfo { hasNextPage hasPreviousPage endCursor }
                         edges { node { id } }
                 }
         }`, &listResp, client.Var("first", 2))
-	if listResp.Users.TotalCount != 1 {
+	if listResp.Users.TotalCount != 3 {
 		t.Fatalf("unexpected total count: %d", listResp.Users.TotalCount)
 	}
 	if len(listResp.Users.Edges) != 2 {
 		t.Fatalf("expected 2 edges, got %d", len(listResp.Users.Edges))
 	}
+	if !listResp.Users.PageInfo.HasNextPage || listResp.Users.PageInfo.HasPreviousPage {
+		t.Fatalf("unexpected page info: %+v", listResp.Users.PageInfo)
+	}
+
+	// Paging backwards from the end cursor reads the closest rows in reverse and skips the count
+	// because totalCount is not selected.
+	mock.ExpectQuery("SELECT * FROM (SELECT id, slug, created_at, updated_at FROM users WHERE id < $1 ORDER BY id DESC LIMIT $2) AS erm_page ORDER BY id ASC").
+		WithArgs("user-other", 2).
+		WillReturnRows(mock.NewRows([]string{"id", "slug", "created_at", "updated_at"}).
+			AddRow("user-graphql", "user-graphql", createdAt, updatedAt))
+
+	var backResp struct {
+		Users struct {
+			PageInfo struct {
+				HasNextPage     bool
+				HasPreviousPage bool
+			}
+			Edges []struct {
+				Node struct {
+					ID string
+				}
+			}
+		}
+	}
+	harness.MustExec(t, ctx, `query($last: Int, $before: String) {
+                users(last: $last, before: $before) {
+                        pageInfo { hasNextPage hasPreviousPage }
+                        edges { node { id } }
+                }
+        }`, &backResp, client.Var("last", 1), client.Var("before", listResp.Users.PageInfo.EndCursor))
+	if len(backResp.Users.Edges) != 1 || backResp.Users.Edges[0].Node.ID != globalID {
+		t.Fatalf("unexpected backward page: %+v", backResp.Users.Edges)
+	}
+	if !backResp.Users.PageInfo.HasNextPage || backResp.Users.PageInfo.HasPreviousPage {
+		t.Fatalf("unexpected backward page info: %+v", backResp.Users.PageInfo)
+	}
 
 	mock.ExpectQuery("UPDATE users SET updated_at = $1 WHERE id = $2 RETURNING id, slug, created_at, updated_at").
 		WithArgs(pgxmock.AnyArg(), "user-graphql").
@@ -130,3 +175,70 @@ func TestGraphQLUserResolvers(t *testing.T) {
 
 	sandbox.ExpectationsWereMet(t)
 }
+
+type queryCounter struct {
+	mu      sync.Mutex
+	queries map[string]int
+	batches []int
+}
+
+func (c *queryCounter) RecordDataloaderBatch(_ string, size int, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batches = append(c.batches, size)
+}
+
+func (c *queryCounter) RecordQuery(table, _ string, _ time.Duration, _ error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.queries == nil {
+		c.queries = make(map[string]int)
+	}
+	c.queries[table]++
+}
+
+// TestGraphQLBatchesEntityLoads guards against N+1 queries: sibling fields loading users by ID
+// share one batched query per request.
+func TestGraphQLBatchesEntityLoads(t *testing.T) {
+	sandbox := ermtesting.NewPostgresSandbox(t)
+	ctx := context.Background()
+	counter := &queryCounter{}
+	sandbox.DB().UseObserver(runtime.QueryObserver{Collector: counter})
+	harness := ermtesting.NewGraphQLHarness(t, ermtesting.GraphQLHarnessOptions{ORM: sandbox.ORM(t), Collector: counter})
+	mock := sandbox.Mock()
+
+	createdAt := time.Date(2024, time.January, 2, 15, 30, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT id, slug, created_at, updated_at FROM users WHERE id = ANY($1) LIMIT $2").
+		WithArgs(pgxmock.AnyArg(), 3).
+		WillReturnRows(mock.NewRows([]string{"id", "slug", "created_at", "updated_at"}).
+			AddRow("user-a", "user-a", createdAt, createdAt).
+			AddRow("user-b", "user-b", createdAt, createdAt).
+			AddRow("user-c", "user-c", createdAt, createdAt))
+
+	var resp struct {
+		First  struct{ ID string }
+		Second struct{ ID string }
+		Third  struct{ ID string }
+		Again  struct{ ID string }
+	}
+	harness.MustExec(t, ctx, `query($a: ID!, $b: ID!, $c: ID!) {
+                first: user(id: $a) { id }
+                second: user(id: $b) { id }
+                third: node(id: $c) { id }
+                again: user(id: $a) { id }
+        }`, &resp,
+		client.Var("a", relay.ToGlobalID("User", "user-a")),
+		client.Var("b", relay.ToGlobalID("User", "user-b")),
+		client.Var("c", relay.ToGlobalID("User", "user-c")))
+	if resp.First.ID != relay.ToGlobalID("User", "user-a") || resp.Third.ID != relay.ToGlobalID("User", "user-c") || resp.Again.ID != resp.First.ID {
+		t.Fatalf("unexpected payload: %+v", resp)
+	}
+
+	if got := counter.queries["users"]; got != 1 {
+		t.Fatalf("expected 1 users query, got %d", got)
+	}
+	if len(counter.batches) != 1 || counter.batches[0] != 3 {
+		t.Fatalf("expected one dataloader batch of 3 keys, got %v", counter.batches)
+	}
+	sandbox.ExpectationsWereMet(t)
+}
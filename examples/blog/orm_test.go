@@ -2,12 +2,17 @@ package blog_test
 
 import (
 	"context"
+	"errors"
+	"regexp"
 	"testing"
 	"time"
 
 	pgxmock "github.com/pashagolub/pgxmock/v4"
 
 	"github.com/deicod/erm/orm/gen"
+	"github.com/deicod/erm/orm/pg"
+	"github.com/deicod/erm/orm/runtime"
+	"github.com/deicod/erm/orm/runtime/validation"
 	ermtesting "github.com/deicod/erm/testing"
 )
 
@@ -168,3 +173,267 @@ func TestUserORMCRUDFlow(t *testing.T) {
 
 	sandbox.ExpectationsWereMet(t)
 }
+
+type recordingCache map[string]any
+
+func (c recordingCache) Get(_ context.Context, key string) (any, bool, error) {
+	value, ok := c[key]
+	return value, ok, nil
+}
+
+func (c recordingCache) Set(_ context.Context, key string, value any) error {
+	c[key] = value
+	return nil
+}
+
+func (c recordingCache) Delete(_ context.Context, key string) error {
+	delete(c, key)
+	return nil
+}
+
+func TestUserORMTransaction(t *testing.T) {
+	sandbox := ermtesting.NewPostgresSandbox(t)
+	ctx := context.Background()
+	client := sandbox.ORM(t)
+	mock := sandbox.Mock()
+	store := recordingCache{}
+	client.UseCache(store)
+
+	createdAt := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "slug", "created_at", "updated_at"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO users (id, created_at, updated_at) VALUES ($1, $2, $3) RETURNING id, slug, created_at, updated_at").
+		WithArgs("user-1", pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(mock.NewRows(columns).AddRow("user-1", "user-1", createdAt, createdAt))
+	mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(pgxmock.NewResult("SAVEPOINT", 0))
+	mock.ExpectQuery("INSERT INTO users (id, created_at, updated_at) VALUES ($1, $2, $3) RETURNING id, slug, created_at, updated_at").
+		WithArgs("user-2", pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(mock.NewRows(columns).AddRow("user-2", "user-2", createdAt, createdAt))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_1").WillReturnResult(pgxmock.NewResult("ROLLBACK", 0))
+	mock.ExpectCommit()
+
+	errNested := errors.New("abort nested unit")
+	err := client.Tx(ctx, func(tx *gen.Tx) error {
+		if _, err := tx.Users().Create(ctx, &gen.User{ID: "user-1"}); err != nil {
+			return err
+		}
+		if len(store) != 0 {
+			t.Fatalf("expected cache writes to be deferred until commit, got %v", store)
+		}
+		nestedErr := tx.Tx(ctx, func(nested *gen.Tx) error {
+			if _, err := nested.Users().Create(ctx, &gen.User{ID: "user-2"}); err != nil {
+				return err
+			}
+			return errNested
+		})
+		if !errors.Is(nestedErr, errNested) {
+			t.Fatalf("expected nested error, got %v", nestedErr)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("tx: %v", err)
+	}
+	if _, ok := store["orm:User:user-1"]; !ok {
+		t.Fatalf("expected committed user to be cached, got %v", store)
+	}
+	if _, ok := store["orm:User:user-2"]; ok {
+		t.Fatalf("expected rolled back savepoint to leave cache untouched")
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM users WHERE id = $1").
+		WithArgs("user-1").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectRollback()
+
+	errAbort := errors.New("abort")
+	err = client.Tx(ctx, func(tx *gen.Tx) error {
+		if err := tx.Users().Delete(ctx, "user-1"); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("expected abort error, got %v", err)
+	}
+	if _, ok := store["orm:User:user-1"]; !ok {
+		t.Fatalf("expected rolled back delete to keep cache entry")
+	}
+
+	sandbox.ExpectationsWereMet(t)
+}
+
+func TestUserORMHooksAndInterceptors(t *testing.T) {
+	sandbox := ermtesting.NewPostgresSandbox(t)
+	ctx := context.Background()
+	client := sandbox.ORM(t)
+	mock := sandbox.Mock()
+
+	createdAt := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "slug", "created_at", "updated_at"}
+
+	var ops []runtime.MutationOp
+	client.Use(func(next runtime.Mutator) runtime.Mutator {
+		return runtime.MutateFunc(func(ctx context.Context, m runtime.Mutation) (any, error) {
+			ops = append(ops, m.Op())
+			return next.Mutate(ctx, m)
+		})
+	})
+	errProtected := errors.New("user-root cannot be deleted")
+	client.Users().Use(runtime.MutationHook(func(ctx context.Context, m *gen.UserMutation, next runtime.Mutator) (any, error) {
+		switch m.Op() {
+		case runtime.MutationCreate:
+			if m.Input != nil && m.Input.ID == "" {
+				m.Input.ID = "user-hooked"
+			}
+		case runtime.MutationDelete:
+			for _, id := range m.IDs {
+				if id == "user-root" {
+					return nil, errProtected
+				}
+			}
+		}
+		return next.Mutate(ctx, m)
+	}))
+	client.Users().Intercept(runtime.QueryInterceptor(func(ctx context.Context, q *gen.UserQuery, next runtime.Querier) (any, error) {
+		if q.Op() == runtime.QueryAll {
+			q.WhereIDEq("user-hooked")
+		}
+		return next.Query(ctx, q)
+	}))
+
+	mock.ExpectQuery("INSERT INTO users (id, created_at, updated_at) VALUES ($1, $2, $3) RETURNING id, slug, created_at, updated_at").
+		WithArgs("user-hooked", pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(mock.NewRows(columns).AddRow("user-hooked", "user-hooked", createdAt, createdAt))
+
+	created, err := client.Users().Create(ctx, &gen.User{})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.ID != "user-hooked" {
+		t.Fatalf("expected hook to assign id, got %s", created.ID)
+	}
+
+	if err := client.Users().Delete(ctx, "user-root"); !errors.Is(err, errProtected) {
+		t.Fatalf("expected hook to veto delete, got %v", err)
+	}
+
+	mock.ExpectQuery("SELECT id, slug, created_at, updated_at FROM users WHERE id = $1 LIMIT $2").
+		WithArgs("user-hooked", 20).
+		WillReturnRows(mock.NewRows(columns).AddRow("user-hooked", "user-hooked", createdAt, createdAt))
+
+	query := client.Users().Query()
+	users, err := query.All(ctx)
+	if err != nil {
+		t.Fatalf("all: %v", err)
+	}
+	if len(users) != 1 || users[0].ID != "user-hooked" {
+		t.Fatalf("unexpected users: %+v", users)
+	}
+
+	mock.ExpectQuery("SELECT COUNT(*) FROM users").
+		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(3))
+
+	total, err := query.Count(ctx)
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if total != 3 {
+		t.Fatalf("expected interceptor to leave the original builder untouched, got %d", total)
+	}
+
+	if want := []runtime.MutationOp{runtime.MutationCreate, runtime.MutationDelete}; len(ops) != len(want) || ops[0] != want[0] || ops[1] != want[1] {
+		t.Fatalf("unexpected global hook calls: %v", ops)
+	}
+
+	sandbox.ExpectationsWereMet(t)
+}
+
+type replicaPool struct{ pgxmock.PgxConnIface }
+
+func (p replicaPool) Close() { _ = p.PgxConnIface.Close(context.Background()) }
+
+func TestUserORMReadsAfterWriteStayOnPrimary(t *testing.T) {
+	sandbox := ermtesting.NewPostgresSandbox(t)
+	ctx := context.Background()
+	mock := sandbox.Mock()
+	replica, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	db := sandbox.DB()
+	db.AddReplica(pg.ReplicaConfig{Name: "replica-a"}, replicaPool{PgxConnIface: replica})
+	db.UseReplicaHealthCheck(func(context.Context, pg.Pool) (pg.ReplicaHealthReport, error) {
+		return pg.ReplicaHealthReport{ReadOnly: true}, nil
+	})
+	db.UseReplicaPolicies("replica", map[string]pg.ReplicaReadOptions{"replica": {DisableFallback: true}})
+	client := gen.NewClient(db)
+
+	createdAt := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "slug", "created_at", "updated_at"}
+	mock.ExpectQuery("INSERT INTO users (id, created_at, updated_at) VALUES ($1, $2, $3) RETURNING id, slug, created_at, updated_at").
+		WithArgs("user-1", pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(mock.NewRows(columns).AddRow("user-1", "user-1", createdAt, createdAt))
+	mock.ExpectQuery("SELECT id, slug, created_at, updated_at FROM users WHERE id = $1").
+		WithArgs("user-1").
+		WillReturnRows(mock.NewRows(columns).AddRow("user-1", "user-1", createdAt, createdAt))
+	replica.ExpectQuery("SELECT id, slug, created_at, updated_at FROM users WHERE id = $1 LIMIT $2").
+		WithArgs("user-1", 1).
+		WillReturnRows(replica.NewRows(columns))
+
+	if _, err := client.Users().Create(ctx, &gen.User{ID: "user-1"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := client.Users().ByID(ctx, "user-1"); err != nil {
+		t.Fatalf("expected ByID to read the created user from the primary: %v", err)
+	}
+	lagging, err := client.Users().Query().WhereIDEq("user-1").Limit(1).All(ctx)
+	if err != nil || len(lagging) != 0 {
+		t.Fatalf("expected query builders to keep following the replica policy, got %v (err %v)", lagging, err)
+	}
+
+	sandbox.ExpectationsWereMet(t)
+	if err := replica.ExpectationsWereMet(); err != nil {
+		t.Fatalf("replica expectations: %v", err)
+	}
+}
+
+func TestUserORMTransactionRollsBackOnValidationError(t *testing.T) {
+	sandbox := ermtesting.NewPostgresSandbox(t)
+	ctx := context.Background()
+	client := sandbox.ORM(t)
+	mock := sandbox.Mock()
+	store := recordingCache{}
+	client.UseCache(store)
+
+	gen.ValidationRegistry = validation.NewRegistry()
+	t.Cleanup(func() { gen.ValidationRegistry = validation.NewRegistry() })
+	gen.ValidationRegistry.Entity("User").
+		OnCreate(validation.String("ID").Required().Matches(regexp.MustCompile(`^user-[0-9]+$`)).Rule())
+
+	createdAt := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO users (id, created_at, updated_at) VALUES ($1, $2, $3) RETURNING id, slug, created_at, updated_at").
+		WithArgs("user-1", pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(mock.NewRows([]string{"id", "slug", "created_at", "updated_at"}).AddRow("user-1", "user-1", createdAt, createdAt))
+	mock.ExpectRollback()
+
+	err := client.Tx(ctx, func(tx *gen.Tx) error {
+		if _, err := tx.Users().Create(ctx, &gen.User{ID: "user-1"}); err != nil {
+			return err
+		}
+		_, err := tx.Users().Create(ctx, &gen.User{ID: "invalid"})
+		return err
+	})
+	var fieldErrs validation.Errors
+	if !errors.As(err, &fieldErrs) {
+		t.Fatalf("expected validation errors to abort the transaction, got %v", err)
+	}
+	if len(store) != 0 {
+		t.Fatalf("expected the rolled back create to leave the cache untouched, got %v", store)
+	}
+
+	sandbox.ExpectationsWereMet(t)
+}
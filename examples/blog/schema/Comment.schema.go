@@ -4,15 +4,16 @@ import "github.com/deicod/erm/orm/dsl"
 
 type Comment struct{ dsl.Schema }
 
+func (Comment) Mixin() []dsl.Mixin {
+	return []dsl.Mixin{dsl.UUIDv7IDMixin(), dsl.TimeMixin()}
+}
+
 func (Comment) Fields() []dsl.Field {
 	return []dsl.Field{
-		dsl.UUIDv7("id").Primary(),
 		dsl.UUIDv7("post_id"),
 		dsl.UUIDv7("author_id"),
 		dsl.UUIDv7("parent_id").Optional(),
 		dsl.String("body").NotEmpty().Length(2000),
-		dsl.TimestampTZ("created_at").DefaultNow(),
-		dsl.TimestampTZ("updated_at").UpdateNow(),
 	}
 }
 
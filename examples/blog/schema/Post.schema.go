@@ -4,16 +4,17 @@ import "github.com/deicod/erm/orm/dsl"
 
 type Post struct{ dsl.Schema }
 
+func (Post) Mixin() []dsl.Mixin {
+	return []dsl.Mixin{dsl.UUIDv7IDMixin(), dsl.TimeMixin()}
+}
+
 func (Post) Fields() []dsl.Field {
 	return []dsl.Field{
-		dsl.UUIDv7("id").Primary(),
 		dsl.UUIDv7("author_id"),
 		dsl.UUIDv7("workspace_id"),
 		dsl.String("title").NotEmpty(),
 		dsl.String("body").Optional(),
 		dsl.TimestampTZ("published_at").Optional(),
-		dsl.TimestampTZ("created_at").DefaultNow(),
-		dsl.TimestampTZ("updated_at").UpdateNow(),
 	}
 }
 
@@ -4,14 +4,15 @@ import "github.com/deicod/erm/orm/dsl"
 
 type User struct{ dsl.Schema }
 
+func (User) Mixin() []dsl.Mixin {
+	return []dsl.Mixin{dsl.UUIDv7IDMixin(), dsl.TimeMixin()}
+}
+
 func (User) Fields() []dsl.Field {
 	return []dsl.Field{
-		dsl.UUIDv7("id").Primary(),
 		dsl.String("email").Unique().NotEmpty(),
 		dsl.String("name").Optional(),
 		dsl.Text("display_name").Computed(dsl.Computed(dsl.Expression("COALESCE(name, email)", "name", "email"))),
-		dsl.TimestampTZ("created_at").DefaultNow(),
-		dsl.TimestampTZ("updated_at").UpdateNow(),
 	}
 }
 
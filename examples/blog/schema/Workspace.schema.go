@@ -4,14 +4,15 @@ import "github.com/deicod/erm/orm/dsl"
 
 type Workspace struct{ dsl.Schema }
 
+func (Workspace) Mixin() []dsl.Mixin {
+	return []dsl.Mixin{dsl.UUIDv7IDMixin(), dsl.TimeMixin()}
+}
+
 func (Workspace) Fields() []dsl.Field {
 	return []dsl.Field{
-		dsl.UUIDv7("id").Primary(),
 		dsl.String("slug").NotEmpty().Unique(),
 		dsl.String("name").NotEmpty(),
 		dsl.String("description").Optional().Length(512),
-		dsl.TimestampTZ("created_at").DefaultNow(),
-		dsl.TimestampTZ("updated_at").UpdateNow(),
 	}
 }
 
@@ -1,11 +1,23 @@
 package generator
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
+// jsonScalarGoTypes lists the mangled gqlgen names and Go spellings of the
+// raw JSON type. Toolchains where json.RawMessage aliases jsontext.Value make
+// gqlgen emit the latter.
+var jsonScalarGoTypes = []struct {
+	mangled string
+	goType  string
+}{
+	{mangled: "encodingᚋjsonᚐRawMessage", goType: "json.RawMessage"},
+	{mangled: "encodingᚋjsonᚋjsontextᚐValue", goType: "jsontext.Value"},
+}
+
 func patchJSONScalarWrappers(root string) error {
 	path := filepath.Join(root, "graphql", "generated.go")
 	data, err := os.ReadFile(path)
@@ -15,11 +27,14 @@ func patchJSONScalarWrappers(root string) error {
 	original := string(data)
 	updated := original
 
-	replacements := map[string]string{
-		"func (ec *executionContext) unmarshalNJSONB2encodingᚋjsonᚐRawMessage(ctx context.Context, v any) (json.RawMessage, error) {\n\tres, err := ec.unmarshalInputJSONB(ctx, v)\n\treturn &res, graphql.ErrorOnPath(ctx, err)\n}\n":                                            "func (ec *executionContext) unmarshalNJSONB2encodingᚋjsonᚐRawMessage(ctx context.Context, v any) (json.RawMessage, error) {\n\tres, err := ec.unmarshalInputJSONB(ctx, v)\n\treturn res, graphql.ErrorOnPath(ctx, err)\n}\n",
-		"func (ec *executionContext) unmarshalOJSONB2encodingᚋjsonᚐRawMessage(ctx context.Context, v any) (json.RawMessage, error) {\n\tif v == nil {\n\t\treturn nil, nil\n\t}\n\tres, err := ec.unmarshalInputJSONB(ctx, v)\n\treturn &res, graphql.ErrorOnPath(ctx, err)\n}\n": "func (ec *executionContext) unmarshalOJSONB2encodingᚋjsonᚐRawMessage(ctx context.Context, v any) (json.RawMessage, error) {\n\tif v == nil {\n\t\treturn nil, nil\n\t}\n\tres, err := ec.unmarshalInputJSONB(ctx, v)\n\treturn res, graphql.ErrorOnPath(ctx, err)\n}\n",
-		"func (ec *executionContext) unmarshalNJSON2encodingᚋjsonᚐRawMessage(ctx context.Context, v any) (json.RawMessage, error) {\n\tres, err := ec.unmarshalInputJSON(ctx, v)\n\treturn &res, graphql.ErrorOnPath(ctx, err)\n}\n":                                              "func (ec *executionContext) unmarshalNJSON2encodingᚋjsonᚐRawMessage(ctx context.Context, v any) (json.RawMessage, error) {\n\tres, err := ec.unmarshalInputJSON(ctx, v)\n\treturn res, graphql.ErrorOnPath(ctx, err)\n}\n",
-		"func (ec *executionContext) unmarshalOJSON2encodingᚋjsonᚐRawMessage(ctx context.Context, v any) (json.RawMessage, error) {\n\tif v == nil {\n\t\treturn nil, nil\n\t}\n\tres, err := ec.unmarshalInputJSON(ctx, v)\n\treturn &res, graphql.ErrorOnPath(ctx, err)\n}\n":   "func (ec *executionContext) unmarshalOJSON2encodingᚋjsonᚐRawMessage(ctx context.Context, v any) (json.RawMessage, error) {\n\tif v == nil {\n\t\treturn nil, nil\n\t}\n\tres, err := ec.unmarshalInputJSON(ctx, v)\n\treturn res, graphql.ErrorOnPath(ctx, err)\n}\n",
+	replacements := make(map[string]string)
+	for _, scalar := range []string{"JSONB", "JSON"} {
+		for _, typ := range jsonScalarGoTypes {
+			required := fmt.Sprintf("func (ec *executionContext) unmarshalN%s2%s(ctx context.Context, v any) (%s, error) {\n\tres, err := ec.unmarshalInput%s(ctx, v)\n\treturn &res, graphql.ErrorOnPath(ctx, err)\n}\n", scalar, typ.mangled, typ.goType, scalar)
+			replacements[required] = strings.Replace(required, "return &res,", "return res,", 1)
+			optional := fmt.Sprintf("func (ec *executionContext) unmarshalO%s2%s(ctx context.Context, v any) (%s, error) {\n\tif v == nil {\n\t\treturn nil, nil\n\t}\n\tres, err := ec.unmarshalInput%s(ctx, v)\n\treturn &res, graphql.ErrorOnPath(ctx, err)\n}\n", scalar, typ.mangled, typ.goType, scalar)
+			replacements[optional] = strings.Replace(optional, "return &res,", "return res,", 1)
+		}
 	}
 
 	for old, replacement := range replacements {
@@ -89,6 +89,10 @@ func buildGraphQLGeneratedSection(entities []Entity) string {
 		needsSeparator = true
 	}
 
+	entityIndex := make(map[string]Entity, len(entities))
+	for _, ent := range entities {
+		entityIndex[ent.Name] = ent
+	}
 	queryEntityFields := make([]string, 0, len(entities)*2)
 	mutationEntityFields := make([]string, 0, len(entities)*3)
 	subscriptionEntityFields := make([]string, 0, len(entities)*3)
@@ -96,11 +100,23 @@ func buildGraphQLGeneratedSection(entities []Entity) string {
 		if needsSeparator {
 			builder.WriteString("\n")
 		}
-		builder.WriteString(renderEntityType(ent))
+		builder.WriteString(renderEntityType(ent, entityIndex))
 		builder.WriteString("\n")
+		if unions := renderEdgeUnions(ent, entityIndex); unions != "" {
+			builder.WriteString(unions)
+			builder.WriteString("\n")
+		}
 		builder.WriteString(renderConnectionTypes(ent))
 		builder.WriteString("\n")
-		builder.WriteString(renderEntityInputTypes(ent))
+		builder.WriteString(renderEntityWhereInput(ent, entityIndex))
+		builder.WriteString("\n")
+		builder.WriteString(renderEntityOrderTypes(ent))
+		builder.WriteString("\n")
+		if graphqlAggregate(ent) {
+			builder.WriteString(renderEntityAggregateTypes(ent))
+			builder.WriteString("\n")
+		}
+		builder.WriteString(renderEntityInputTypes(ent, entityIndex))
 		builder.WriteString("\n")
 		queryEntityFields = append(queryEntityFields, renderEntityQueryFields(ent)...)
 		mutationEntityFields = append(mutationEntityFields, renderEntityMutationFields(ent)...)
@@ -161,7 +177,7 @@ func renderRootTypeDefinition(name string, baseFields, entityFields []string) st
 	return builder.String()
 }
 
-func renderEntityType(ent Entity) string {
+func renderEntityType(ent Entity, entityIndex map[string]Entity) string {
 	builder := &strings.Builder{}
 	builder.WriteString(fmt.Sprintf("type %s implements Node {\n", ent.Name))
 	seenID := false
@@ -170,11 +186,18 @@ func renderEntityType(ent Entity) string {
 		if field.Name == "id" {
 			seenID = true
 		}
+		// Viewers failing the rule of a guarded field read null for it.
+		if rule := fieldAuthRule(field); rule != nil {
+			gqlType = trimNonNull(gqlType) + " " + buildFieldAuthDirective(ent, rule)
+		}
 		builder.WriteString(fmt.Sprintf("  %s: %s\n", lowerCamel(field.Name), gqlType))
 	}
 	if !seenID {
 		builder.WriteString("  id: ID!\n")
 	}
+	for _, gqlEdge := range graphqlEdges(ent, entityIndex) {
+		builder.WriteString(fmt.Sprintf("  %s\n", renderEdgeFieldDefinition(ent, gqlEdge)))
+	}
 	builder.WriteString("}\n")
 	return builder.String()
 }
@@ -186,8 +209,9 @@ func renderConnectionTypes(ent Entity) string {
 	return builder.String()
 }
 
-func renderEntityInputTypes(ent Entity) string {
+func renderEntityInputTypes(ent Entity, entityIndex map[string]Entity) string {
 	builder := &strings.Builder{}
+	refs := refInputs(ent, entityIndex)
 	builder.WriteString(fmt.Sprintf("input Create%sInput {\n", ent.Name))
 	builder.WriteString("  clientMutationId: String\n")
 	for _, field := range ent.Fields {
@@ -195,6 +219,10 @@ func renderEntityInputTypes(ent Entity) string {
 		if fieldName == "" {
 			continue
 		}
+		if ref, ok := refs[field.Name]; ok {
+			builder.WriteString(fmt.Sprintf("  %s: ID\n", ref.fieldName()))
+			continue
+		}
 		if field.Name == "id" {
 			builder.WriteString(fmt.Sprintf("  %s: ID\n", fieldName))
 			continue
@@ -202,32 +230,43 @@ func renderEntityInputTypes(ent Entity) string {
 		gqlType, _ := graphqlNamedType(field)
 		builder.WriteString(fmt.Sprintf("  %s: %s\n", fieldName, trimNonNull(gqlType)))
 	}
+	builder.WriteString(renderEdgeInputFields(ent, entityIndex, false))
 	builder.WriteString("}\n\n")
 
 	builder.WriteString(fmt.Sprintf("type Create%sPayload {\n", ent.Name))
 	builder.WriteString("  clientMutationId: String\n")
 	builder.WriteString(fmt.Sprintf("  %s: %s\n", lowerCamel(ent.Name), ent.Name))
+	builder.WriteString("  userErrors: [UserError!]!\n")
 	builder.WriteString("}\n\n")
 
 	builder.WriteString(fmt.Sprintf("input Update%sInput {\n", ent.Name))
 	builder.WriteString("  clientMutationId: String\n")
 	builder.WriteString("  id: ID!\n")
 	for _, field := range ent.Fields {
-		if field.Name == "id" {
+		if field.Name == "id" || isVersionField(field) {
 			continue
 		}
 		fieldName := lowerCamel(field.Name)
 		if fieldName == "" {
 			continue
 		}
+		if ref, ok := refs[field.Name]; ok {
+			builder.WriteString(fmt.Sprintf("  %s: ID\n", ref.fieldName()))
+			continue
+		}
 		gqlType, _ := graphqlNamedType(field)
 		builder.WriteString(fmt.Sprintf("  %s: %s\n", fieldName, trimNonNull(gqlType)))
 	}
+	if _, ok := versionField(ent); ok {
+		builder.WriteString("  expectedVersion: Int\n")
+	}
+	builder.WriteString(renderEdgeInputFields(ent, entityIndex, true))
 	builder.WriteString("}\n\n")
 
 	builder.WriteString(fmt.Sprintf("type Update%sPayload {\n", ent.Name))
 	builder.WriteString("  clientMutationId: String\n")
 	builder.WriteString(fmt.Sprintf("  %s: %s\n", lowerCamel(ent.Name), ent.Name))
+	builder.WriteString("  userErrors: [UserError!]!\n")
 	builder.WriteString("}\n\n")
 
 	builder.WriteString(fmt.Sprintf("input Delete%sInput {\n", ent.Name))
@@ -249,7 +288,10 @@ func renderEntityQueryFields(ent Entity) []string {
 	rule := ent.Authorization.Read
 	fields := []string{
 		appendAuthDirective(fmt.Sprintf("%s(id: ID!): %s", singular, ent.Name), rule),
-		appendAuthDirective(fmt.Sprintf("%s(first: Int, after: String, last: Int, before: String): %sConnection!", plural, ent.Name), rule),
+		appendAuthDirective(fmt.Sprintf("%s(first: Int, after: String, last: Int, before: String, where: %[2]sWhereInput, orderBy: [%[2]sOrder!]): %[2]sConnection!", plural, ent.Name), rule),
+	}
+	if graphqlAggregate(ent) {
+		fields = append(fields, renderEntityAggregateQueryField(ent))
 	}
 	return fields
 }
@@ -290,28 +332,23 @@ func appendAuthDirective(def string, rule *dsl.AuthRule) string {
 	return def + " " + directive
 }
 
+// buildAuthDirective renders the directives guarding a query, mutation or subscription field.
+// Rules depending on the record only require a viewer here; the resolvers enforce the rest.
 func buildAuthDirective(rule *dsl.AuthRule) string {
-	if rule == nil {
-		return ""
-	}
-	requirement := rule.Requirement
-	if requirement == "" || requirement == dsl.AuthRequirementPublic {
+	if !authRuleRestricted(rule) {
 		return ""
 	}
-	if len(rule.Roles) == 0 {
+	if authRuleScoped(rule) {
 		return "@auth"
 	}
-	roles := make([]string, 0, len(rule.Roles))
-	for _, role := range rule.Roles {
-		if strings.TrimSpace(role) == "" {
-			continue
-		}
-		roles = append(roles, fmt.Sprintf("\"%s\"", role))
+	directive := "@auth"
+	if roles := quotedRoles(rule.Roles); len(roles) > 0 {
+		directive = fmt.Sprintf("@auth(roles: [%s])", strings.Join(roles, ", "))
 	}
-	if len(roles) == 0 {
-		return "@auth"
+	for _, cond := range rule.Conditions {
+		directive += fmt.Sprintf(" @authClaim(claim: %q, value: %q)", cond.Claim, cond.Value)
 	}
-	return fmt.Sprintf("@auth(roles: [%s])", strings.Join(roles, ", "))
+	return directive
 }
 
 func entitySubscriptionEvents(ent Entity) []dsl.SubscriptionEvent {
@@ -659,16 +696,25 @@ func writeGraphQLResolvers(root string, entities []Entity, modulePath string) er
 	if len(entities) > 0 {
 		imports[fmt.Sprintf("%s/graphql/dataloaders", modulePath)] = struct{}{}
 		imports[fmt.Sprintf("%s/orm/gen", modulePath)] = struct{}{}
+		imports["reflect"] = struct{}{}
+		imports["encoding/json"] = struct{}{}
+		imports[`gql "github.com/99designs/gqlgen/graphql"`] = struct{}{}
+		for _, ent := range entities {
+			imports[fmt.Sprintf("%s/orm/gen/%s", modulePath, predicatePackageName(ent))] = struct{}{}
+		}
 	}
 	if helperUsage.HasSQLNull {
 		imports["database/sql"] = struct{}{}
 	}
-	if helperUsage.NeedsTime() {
+	if helperUsage.NeedsTime() || whereNeedsTime(entities) {
 		imports["time"] = struct{}{}
 	}
 	if needsGraphQLIntRangeCheck(entities) {
 		imports["math"] = struct{}{}
 	}
+	if hasResolverAuthRules(entities) {
+		imports[fmt.Sprintf("%s/graphql/directives", modulePath)] = struct{}{}
+	}
 	if len(imports) > 0 {
 		fmt.Fprintf(buf, "import (\n")
 		keys := make([]string, 0, len(imports))
@@ -677,6 +723,10 @@ func writeGraphQLResolvers(root string, entities []Entity, modulePath string) er
 		}
 		sort.Strings(keys)
 		for _, key := range keys {
+			if strings.Contains(key, " ") {
+				fmt.Fprintf(buf, "    %s\n", key)
+				continue
+			}
 			fmt.Fprintf(buf, "    \"%s\"\n", key)
 		}
 		fmt.Fprintf(buf, ")\n\n")
@@ -685,16 +735,28 @@ func writeGraphQLResolvers(root string, entities []Entity, modulePath string) er
 	buf.WriteString(renderEntityHooksSupport(entities))
 	buf.WriteString(renderNodeResolver(entities))
 	if len(entities) > 0 {
+		entityIndex := make(map[string]Entity, len(entities))
+		for _, ent := range entities {
+			entityIndex[ent.Name] = ent
+		}
+		buf.WriteString(renderConnectionHelpers())
 		for _, ent := range entities {
 			buf.WriteString(renderEntityHelpers(ent))
+			buf.WriteString(renderEntityAuthRules(ent, entityIndex))
 			buf.WriteString("\n")
+			buf.WriteString(renderEntityWherePredicates(ent, entityIndex))
+			buf.WriteString(renderEntityOrders(ent))
 			buf.WriteString(renderEntityQueryResolvers(ent))
 			buf.WriteString("\n")
-			buf.WriteString(renderEntityMutationResolvers(ent))
+			buf.WriteString(renderEntityEdgeResolvers(ent, entityIndex))
+			buf.WriteString(renderEntityMutationResolvers(ent, entityIndex))
 			buf.WriteString("\n")
 			buf.WriteString(renderEntitySubscriptionResolvers(ent))
 			buf.WriteString("\n")
 		}
+		if hasEdgeInputs(entities, entityIndex) {
+			buf.WriteString(renderDecodeIDsHelper())
+		}
 	}
 
 	if hasEnums {
@@ -719,6 +781,9 @@ func writeGraphQLResolvers(root string, entities []Entity, modulePath string) er
 	if needsIntPtrHelper {
 		buf.WriteString(renderGraphQLIntPointerHelper())
 	}
+	if needsGraphQLPointerHelper(entities) {
+		buf.WriteString(renderGraphQLPointerHelper())
+	}
 
 	path := filepath.Join(root, "graphql", "resolvers", "entities_gen.go")
 	return writeGoFile(path, buf.Bytes())
@@ -850,6 +915,9 @@ func renderNodeResolver(entities []Entity) string {
 		fmt.Fprintf(builder, "        record, err := r.load%[1]s(ctx, nativeID)\n", ent.Name)
 		fmt.Fprintf(builder, "        if err != nil {\n            return nil, err\n        }\n")
 		fmt.Fprintf(builder, "        if record == nil {\n            return nil, nil\n        }\n")
+		if authRuleScoped(ent.Authorization.Read) {
+			fmt.Fprintf(builder, "        if err := directives.Authorize(ctx, %s, record); err != nil {\n            return nil, err\n        }\n", authRuleVar(ent, "Read"))
+		}
 		fmt.Fprintf(builder, "        if err := r.applyBeforeReturn%[1]s(ctx, record); err != nil {\n            return nil, err\n        }\n", ent.Name)
 		fmt.Fprintf(builder, "        return toGraphQL%[1]s(record), nil\n", ent.Name)
 	}
@@ -864,7 +932,7 @@ func renderEntityHelpers(ent Entity) string {
 	fmt.Fprintf(builder, "    if loaders := dataloaders.FromContext(ctx); loaders != nil {\n")
 	fmt.Fprintf(builder, "        if loader := loaders.%[1]s(); loader != nil {\n", ent.Name)
 	fmt.Fprintf(builder, "            return loader.Load(ctx, id)\n        }\n    }\n")
-	fmt.Fprintf(builder, "    return r.ORM.%[1]s().ByID(ctx, id)\n}\n\n", exportName(pluralize(ent.Name)))
+	fmt.Fprintf(builder, "    return nilIfNotFound(r.ORM.%[1]s().ByID(ctx, id))\n}\n\n", exportName(pluralize(ent.Name)))
 
 	fmt.Fprintf(builder, "func (r *Resolver) prime%[1]s(ctx context.Context, record *gen.%[1]s) {\n", ent.Name)
 	fmt.Fprintf(builder, "    if record == nil {\n        return\n    }\n")
@@ -872,6 +940,11 @@ func renderEntityHelpers(ent Entity) string {
 	fmt.Fprintf(builder, "        if loader := loaders.%[1]s(); loader != nil {\n", ent.Name)
 	fmt.Fprintf(builder, "            loader.Prime(record.ID, record)\n        }\n    }\n}\n\n")
 
+	fmt.Fprintf(builder, "func (r *Resolver) clear%[1]s(ctx context.Context, id string) {\n", ent.Name)
+	fmt.Fprintf(builder, "    if loaders := dataloaders.FromContext(ctx); loaders != nil {\n")
+	fmt.Fprintf(builder, "        if loader := loaders.%[1]s(); loader != nil {\n", ent.Name)
+	fmt.Fprintf(builder, "            loader.Clear(id)\n        }\n    }\n}\n\n")
+
 	fmt.Fprintf(builder, "func toGraphQL%[1]s(record *gen.%[1]s) *graphql.%[1]s {\n", ent.Name)
 	fmt.Fprintf(builder, "    if record == nil {\n        return nil\n    }\n")
 	fmt.Fprintf(builder, "    return &graphql.%[1]s{\n", ent.Name)
@@ -881,7 +954,7 @@ func renderEntityHelpers(ent Entity) string {
 			continue
 		}
 		fieldName := exportName(field.Name)
-		fmt.Fprintf(builder, "        %s: %s,\n", fieldName, graphqlFieldValue(field))
+		fmt.Fprintf(builder, "        %s: %s,\n", fieldName, graphqlGuardedFieldValue(field))
 	}
 	fmt.Fprintf(builder, "    }\n}\n\n")
 
@@ -933,73 +1006,71 @@ func isGraphQLIntType(ft dsl.FieldType) bool {
 
 func renderEntityQueryResolvers(ent Entity) string {
 	builder := &strings.Builder{}
-	pluralName := exportName(pluralize(ent.Name))
-	pluralField := lowerCamel(pluralize(ent.Name))
 
 	fmt.Fprintf(builder, "func (r *queryResolver) %[1]s(ctx context.Context, id string) (*graphql.%[2]s, error) {\n", exportName(ent.Name), ent.Name)
 	fmt.Fprintf(builder, "    nativeID, err := decode%sID(id)\n", ent.Name)
 	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
 	fmt.Fprintf(builder, "    record, err := r.load%s(ctx, nativeID)\n", ent.Name)
 	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+	renderReadAuthCheck(builder, ent, "record", "    ")
 	fmt.Fprintf(builder, "    if err := r.applyBeforeReturn%[1]s(ctx, record); err != nil {\n        return nil, err\n    }\n", ent.Name)
 	fmt.Fprintf(builder, "    return toGraphQL%s(record), nil\n}\n\n", ent.Name)
 
-	fmt.Fprintf(builder, "func (r *queryResolver) %[1]s(ctx context.Context, first *int, after *string, last *int, before *string) (*graphql.%[2]sConnection, error) {\n", exportName(pluralField), ent.Name)
-	fmt.Fprintf(builder, "    if r.ORM == nil {\n        return nil, fmt.Errorf(\"orm client is not configured\")\n    }\n")
-	fmt.Fprintf(builder, "    if last != nil || before != nil {\n        return nil, fmt.Errorf(\"backward pagination is not supported\")\n    }\n")
-	fmt.Fprintf(builder, "    limit := defaultPageSize\n")
-	fmt.Fprintf(builder, "    if first != nil && *first > 0 {\n        limit = *first\n    }\n")
-	fmt.Fprintf(builder, "    offset := 0\n")
-	fmt.Fprintf(builder, "    if after != nil && *after != \"\" {\n")
-	fmt.Fprintf(builder, "        if decoded, err := decodeCursor(*after); err == nil {\n            offset = decoded + 1\n        }\n    }\n")
-	fmt.Fprintf(builder, "    total, err := r.ORM.%[1]s().Count(ctx)\n", pluralName)
-	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
-	fmt.Fprintf(builder, "    records, err := r.ORM.%[1]s().List(ctx, limit, offset)\n", pluralName)
-	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
-	fmt.Fprintf(builder, "    edges := make([]*graphql.%sEdge, len(records))\n", ent.Name)
-	fmt.Fprintf(builder, "    for idx, record := range records {\n")
-	fmt.Fprintf(builder, "        cursor := encodeCursor(offset + idx)\n")
-	fmt.Fprintf(builder, "        if err := r.applyBeforeReturn%[1]s(ctx, record); err != nil {\n            return nil, err\n        }\n", ent.Name)
-	fmt.Fprintf(builder, "        r.prime%s(ctx, record)\n", ent.Name)
-	fmt.Fprintf(builder, "        edges[idx] = &graphql.%sEdge{\n", ent.Name)
-	fmt.Fprintf(builder, "            Cursor: cursor,\n")
-	fmt.Fprintf(builder, "            Node:   toGraphQL%s(record),\n", ent.Name)
-	fmt.Fprintf(builder, "        }\n    }\n")
-	fmt.Fprintf(builder, "    var startCursor, endCursor *string\n")
-	fmt.Fprintf(builder, "    if len(edges) > 0 {\n")
-	fmt.Fprintf(builder, "        sc := edges[0].Cursor\n")
-	fmt.Fprintf(builder, "        ec := edges[len(edges)-1].Cursor\n")
-	fmt.Fprintf(builder, "        startCursor = &sc\n")
-	fmt.Fprintf(builder, "        endCursor = &ec\n    }\n")
-	fmt.Fprintf(builder, "    pageInfo := &graphql.PageInfo{\n")
-	fmt.Fprintf(builder, "        HasNextPage:     offset+len(edges) < total,\n")
-	fmt.Fprintf(builder, "        HasPreviousPage: offset > 0,\n")
-	fmt.Fprintf(builder, "        StartCursor:     startCursor,\n")
-	fmt.Fprintf(builder, "        EndCursor:       endCursor,\n")
-	fmt.Fprintf(builder, "    }\n")
-	fmt.Fprintf(builder, "    return &graphql.%sConnection{\n", ent.Name)
-	fmt.Fprintf(builder, "        Edges:      edges,\n")
-	fmt.Fprintf(builder, "        PageInfo:   pageInfo,\n")
-	fmt.Fprintf(builder, "        TotalCount: total,\n")
-	fmt.Fprintf(builder, "    }, nil\n}\n\n")
-
+	builder.WriteString(renderEntityConnectionResolver(ent))
+	if graphqlAggregate(ent) {
+		builder.WriteString(renderEntityAggregateResolver(ent))
+	}
 	return builder.String()
 }
 
-func renderEntityMutationResolvers(ent Entity) string {
+func renderEntityMutationResolvers(ent Entity, entityIndex map[string]Entity) string {
 	builder := &strings.Builder{}
 	pluralName := exportName(pluralize(ent.Name))
+	refs := refInputs(ent, entityIndex)
+	fieldsVar := lowerCamel(ent.Name) + "InputFields"
+	// Edge inputs write several statements, which run in one transaction. Errors caused by the
+	// input are reported through the payload's userErrors.
+	call := func(helper, payload string) {
+		if len(edgeInputs(ent, entityIndex)) == 0 {
+			fmt.Fprintf(builder, "    record, err := r.%s%s(ctx, r.ORM, input)\n", helper, ent.Name)
+			fmt.Fprintf(builder, "    if err != nil {\n")
+		} else {
+			fmt.Fprintf(builder, "    var record *gen.%s\n", ent.Name)
+			fmt.Fprintf(builder, "    if err := r.ORM.Tx(ctx, func(tx *gen.Tx) error {\n")
+			fmt.Fprintf(builder, "        var err error\n")
+			fmt.Fprintf(builder, "        record, err = r.%s%s(ctx, tx.Client, input)\n", helper, ent.Name)
+			fmt.Fprintf(builder, "        return err\n")
+			fmt.Fprintf(builder, "    }); err != nil {\n")
+		}
+		fmt.Fprintf(builder, "        userErrors, err := r.mutationFailure(err, %s)\n", fieldsVar)
+		fmt.Fprintf(builder, "        if err != nil {\n            return nil, err\n        }\n")
+		fmt.Fprintf(builder, "        return &graphql.%s{ClientMutationID: input.ClientMutationID, UserErrors: userErrors}, nil\n    }\n", payload)
+	}
 
-	fmt.Fprintf(builder, "func (r *mutationResolver) Create%[1]s(ctx context.Context, input graphql.Create%[1]sInput) (*graphql.Create%[1]sPayload, error) {\n", ent.Name)
-	fmt.Fprintf(builder, "    if r.ORM == nil {\n        return nil, fmt.Errorf(\"orm client is not configured\")\n    }\n")
-	fmt.Fprintf(builder, "    model := new(gen.%[1]s)\n", ent.Name)
+	// The input field of each Go field name and column, naming the fields of user errors.
+	fmt.Fprintf(builder, "var %s = map[string]string{\n", fieldsVar)
+	seen := map[string]struct{}{}
 	for _, field := range ent.Fields {
-		builder.WriteString(renderInputAssignment("input", "model", field, true))
+		inputName := lowerCamel(field.Name)
+		if ref, ok := refs[field.Name]; ok {
+			inputName = ref.fieldName()
+		}
+		if inputName == "" {
+			continue
+		}
+		for _, key := range []string{exportName(field.Name), fieldColumn(field)} {
+			if _, dup := seen[key]; dup {
+				continue
+			}
+			seen[key] = struct{}{}
+			fmt.Fprintf(builder, "    %q: %q,\n", key, inputName)
+		}
 	}
-	fmt.Fprintf(builder, "    if err := r.applyBeforeCreate%[1]s(ctx, input, model); err != nil {\n        return nil, err\n    }\n", ent.Name)
-	fmt.Fprintf(builder, "    record, err := r.ORM.%s().Create(ctx, model)\n", pluralName)
-	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
-	fmt.Fprintf(builder, "    if err := r.applyAfterCreate%[1]s(ctx, record); err != nil {\n        return nil, err\n    }\n", ent.Name)
+	fmt.Fprintf(builder, "}\n\n")
+
+	fmt.Fprintf(builder, "func (r *mutationResolver) Create%[1]s(ctx context.Context, input graphql.Create%[1]sInput) (*graphql.Create%[1]sPayload, error) {\n", ent.Name)
+	fmt.Fprintf(builder, "    if r.ORM == nil {\n        return nil, fmt.Errorf(\"orm client is not configured\")\n    }\n")
+	call("create", "Create"+ent.Name+"Payload")
 	fmt.Fprintf(builder, "    if err := r.applyBeforeReturn%[1]s(ctx, record); err != nil {\n        return nil, err\n    }\n", ent.Name)
 	fmt.Fprintf(builder, "    gqlRecord := toGraphQL%[1]s(record)\n", ent.Name)
 	fmt.Fprintf(builder, "    r.prime%[1]s(ctx, record)\n", ent.Name)
@@ -1009,23 +1080,30 @@ func renderEntityMutationResolvers(ent Entity) string {
 	fmt.Fprintf(builder, "    return &graphql.Create%[1]sPayload{\n", ent.Name)
 	fmt.Fprintf(builder, "        ClientMutationID: input.ClientMutationID,\n")
 	fmt.Fprintf(builder, "        %s: gqlRecord,\n", exportName(ent.Name))
+	fmt.Fprintf(builder, "        UserErrors: []*graphql.UserError{},\n")
 	fmt.Fprintf(builder, "    }, nil\n}\n\n")
 
-	fmt.Fprintf(builder, "func (r *mutationResolver) Update%[1]s(ctx context.Context, input graphql.Update%[1]sInput) (*graphql.Update%[1]sPayload, error) {\n", ent.Name)
-	fmt.Fprintf(builder, "    if r.ORM == nil {\n        return nil, fmt.Errorf(\"orm client is not configured\")\n    }\n")
-	fmt.Fprintf(builder, "    nativeID, err := decode%[1]sID(input.ID)\n", ent.Name)
-	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
-	fmt.Fprintf(builder, "    model := &gen.%[1]s{ID: nativeID}\n", ent.Name)
+	fmt.Fprintf(builder, "// create%[1]s creates the %[1]s described by input through client, followed by the edges the\n", ent.Name)
+	fmt.Fprintf(builder, "// input links and creates.\n")
+	fmt.Fprintf(builder, "func (r *Resolver) create%[1]s(ctx context.Context, client *gen.Client, input graphql.Create%[1]sInput) (*gen.%[1]s, error) {\n", ent.Name)
+	fmt.Fprintf(builder, "    model := new(gen.%[1]s)\n", ent.Name)
 	for _, field := range ent.Fields {
-		if field.Name == "id" {
+		if ref, ok := refs[field.Name]; ok {
+			builder.WriteString(renderRefAssignment(ref, field))
 			continue
 		}
-		builder.WriteString(renderInputAssignment("input", "model", field, false))
+		builder.WriteString(renderInputAssignment("input", "model", field, true))
 	}
-	fmt.Fprintf(builder, "    if err := r.applyBeforeUpdate%[1]s(ctx, input, model); err != nil {\n        return nil, err\n    }\n", ent.Name)
-	fmt.Fprintf(builder, "    record, err := r.ORM.%s().Update(ctx, model)\n", pluralName)
+	fmt.Fprintf(builder, "    if err := r.applyBeforeCreate%[1]s(ctx, input, model); err != nil {\n        return nil, err\n    }\n", ent.Name)
+	fmt.Fprintf(builder, "    record, err := client.%s().Create(ctx, model)\n", pluralName)
 	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
-	fmt.Fprintf(builder, "    if err := r.applyAfterUpdate%[1]s(ctx, record); err != nil {\n        return nil, err\n    }\n", ent.Name)
+	builder.WriteString(renderEdgeInputWrites(ent, entityIndex, false))
+	fmt.Fprintf(builder, "    if err := r.applyAfterCreate%[1]s(ctx, record); err != nil {\n        return nil, err\n    }\n", ent.Name)
+	fmt.Fprintf(builder, "    return record, nil\n}\n\n")
+
+	fmt.Fprintf(builder, "func (r *mutationResolver) Update%[1]s(ctx context.Context, input graphql.Update%[1]sInput) (*graphql.Update%[1]sPayload, error) {\n", ent.Name)
+	fmt.Fprintf(builder, "    if r.ORM == nil {\n        return nil, fmt.Errorf(\"orm client is not configured\")\n    }\n")
+	call("update", "Update"+ent.Name+"Payload")
 	fmt.Fprintf(builder, "    if err := r.applyBeforeReturn%[1]s(ctx, record); err != nil {\n        return nil, err\n    }\n", ent.Name)
 	fmt.Fprintf(builder, "    gqlRecord := toGraphQL%[1]s(record)\n", ent.Name)
 	fmt.Fprintf(builder, "    r.prime%[1]s(ctx, record)\n", ent.Name)
@@ -1035,14 +1113,71 @@ func renderEntityMutationResolvers(ent Entity) string {
 	fmt.Fprintf(builder, "    return &graphql.Update%[1]sPayload{\n", ent.Name)
 	fmt.Fprintf(builder, "        ClientMutationID: input.ClientMutationID,\n")
 	fmt.Fprintf(builder, "        %s: gqlRecord,\n", exportName(ent.Name))
+	fmt.Fprintf(builder, "        UserErrors: []*graphql.UserError{},\n")
 	fmt.Fprintf(builder, "    }, nil\n}\n\n")
 
+	fmt.Fprintf(builder, "// update%[1]s updates the %[1]s identified by input through client, followed by the edges the\n", ent.Name)
+	fmt.Fprintf(builder, "// input changes.\n")
+	fmt.Fprintf(builder, "func (r *Resolver) update%[1]s(ctx context.Context, client *gen.Client, input graphql.Update%[1]sInput) (*gen.%[1]s, error) {\n", ent.Name)
+	fmt.Fprintf(builder, "    nativeID, err := decode%[1]sID(input.ID)\n", ent.Name)
+	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+	if authRuleScoped(ent.Authorization.Update) {
+		fmt.Fprintf(builder, "    if err := r.authorize%s(ctx, client, nativeID, %s); err != nil {\n        return nil, err\n    }\n", ent.Name, authRuleVar(ent, "Update"))
+	}
+	fmt.Fprintf(builder, "    model := &gen.%[1]s{ID: nativeID}\n", ent.Name)
+	for _, field := range ent.Fields {
+		if field.Name == "id" || isVersionField(field) {
+			continue
+		}
+		if ref, ok := refs[field.Name]; ok {
+			builder.WriteString(renderRefAssignment(ref, field))
+			continue
+		}
+		builder.WriteString(renderInputAssignment("input", "model", field, false))
+	}
+	fmt.Fprintf(builder, "    before := *model\n")
+	fmt.Fprintf(builder, "    if err := r.applyBeforeUpdate%[1]s(ctx, input, model); err != nil {\n        return nil, err\n    }\n", ent.Name)
+	// Only fields present in the input or changed by the hook are written, so omitted fields keep
+	// their stored values.
+	updatable := partialUpdateFields(ent)
+	fmt.Fprintf(builder, "    fields := make([]string, 0, %d)\n", len(updatable))
+	for _, field := range updatable {
+		fieldName := exportName(field.Name)
+		inputName := fieldName
+		if ref, ok := refs[field.Name]; ok {
+			inputName = ref.goName()
+		}
+		if lowerCamel(field.Name) == "" {
+			fmt.Fprintf(builder, "    if !reflect.DeepEqual(before.%s, model.%s) {\n", fieldName, fieldName)
+		} else {
+			fmt.Fprintf(builder, "    if input.%s != nil || !reflect.DeepEqual(before.%s, model.%s) {\n", inputName, fieldName, fieldName)
+		}
+		fmt.Fprintf(builder, "        fields = append(fields, %q)\n    }\n", field.Name)
+	}
+	if version, ok := versionField(ent); ok {
+		// A stale expectedVersion surfaces as runtime.ErrStaleObject instead of overwriting a
+		// concurrent edit.
+		fmt.Fprintf(builder, "    update := client.%s().UpdateOneID(nativeID).SetFrom(model, fields...)\n", pluralName)
+		fmt.Fprintf(builder, "    if input.ExpectedVersion != nil {\n        update.ExpectVersion(%s(*input.ExpectedVersion))\n    }\n", baseGoType(version))
+		fmt.Fprintf(builder, "    record, err := update.Save(ctx)\n")
+	} else {
+		fmt.Fprintf(builder, "    record, err := client.%s().UpdateOneID(nativeID).SetFrom(model, fields...).Save(ctx)\n", pluralName)
+	}
+	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+	builder.WriteString(renderEdgeInputWrites(ent, entityIndex, true))
+	fmt.Fprintf(builder, "    if err := r.applyAfterUpdate%[1]s(ctx, record); err != nil {\n        return nil, err\n    }\n", ent.Name)
+	fmt.Fprintf(builder, "    return record, nil\n}\n\n")
+
 	fmt.Fprintf(builder, "func (r *mutationResolver) Delete%[1]s(ctx context.Context, input graphql.Delete%[1]sInput) (*graphql.Delete%[1]sPayload, error) {\n", ent.Name)
 	fmt.Fprintf(builder, "    if r.ORM == nil {\n        return nil, fmt.Errorf(\"orm client is not configured\")\n    }\n")
 	fmt.Fprintf(builder, "    nativeID, err := decode%[1]sID(input.ID)\n", ent.Name)
 	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+	if authRuleScoped(ent.Authorization.Delete) {
+		fmt.Fprintf(builder, "    if err := r.authorize%s(ctx, r.ORM, nativeID, %s); err != nil {\n        return nil, err\n    }\n", ent.Name, authRuleVar(ent, "Delete"))
+	}
 	fmt.Fprintf(builder, "    if err := r.applyBeforeDelete%[1]s(ctx, input, nativeID); err != nil {\n        return nil, err\n    }\n", ent.Name)
 	fmt.Fprintf(builder, "    if err := r.ORM.%s().Delete(ctx, nativeID); err != nil {\n        return nil, err\n    }\n", pluralName)
+	fmt.Fprintf(builder, "    r.clear%[1]s(ctx, nativeID)\n", ent.Name)
 	fmt.Fprintf(builder, "    if err := r.applyAfterDelete%[1]s(ctx, input, nativeID); err != nil {\n        return nil, err\n    }\n", ent.Name)
 	fmt.Fprintf(builder, "    deletedID := relay.ToGlobalID(\"%[1]s\", nativeID)\n", ent.Name)
 	if hasSubscriptionEvent(ent, dsl.SubscriptionEventDelete) {
@@ -1096,6 +1231,15 @@ func writeEntitySubscriptionResolver(builder *strings.Builder, ent Entity, event
 		fmt.Fprintf(builder, "                obj, ok := payload.(%s)\n", channelType)
 		fmt.Fprintf(builder, "                if !ok || obj == nil {\n                    continue\n                }\n")
 	}
+	if op, rule := subscriptionAuthRule(ent, event); authRuleScoped(rule) {
+		// Events are only delivered to viewers passing the rule for the record; deleted records
+		// are gone, so their events reach only viewers the rule does not limit to some records.
+		record := "obj"
+		if event == dsl.SubscriptionEventDelete {
+			record = "nil"
+		}
+		fmt.Fprintf(builder, "                if directives.Authorize(ctx, %s, %s) != nil {\n                    continue\n                }\n", authRuleVar(ent, op), record)
+	}
 	fmt.Fprintf(builder, "                select {\n")
 	switch event {
 	case dsl.SubscriptionEventDelete:
@@ -1301,6 +1445,10 @@ func hasCustomGoType(field dsl.Field) bool {
 	clone.GoType = ""
 	return baseGoType(clone) != field.GoType
 }
+
+// defaultLoaderMaxBatch mirrors dataloaders.DefaultMaxBatch.
+const defaultLoaderMaxBatch = 100
+
 func writeGraphQLDataloaders(root string, entities []Entity, modulePath string) error {
 	sort.Slice(entities, func(i, j int) bool { return entities[i].Name < entities[j].Name })
 	buf := &bytes.Buffer{}
@@ -1314,6 +1462,9 @@ func writeGraphQLDataloaders(root string, entities []Entity, modulePath string)
 	if len(entities) > 0 {
 		imports["context"] = struct{}{}
 	}
+	for _, ent := range entities {
+		imports[fmt.Sprintf("%s/orm/gen/%s", modulePath, predicatePackageName(ent))] = struct{}{}
+	}
 	if len(imports) > 0 {
 		fmt.Fprintf(buf, "import (\n")
 		keys := make([]string, 0, len(imports))
@@ -1331,13 +1482,19 @@ func writeGraphQLDataloaders(root string, entities []Entity, modulePath string)
 	fmt.Fprintf(buf, "    if loaders == nil || orm == nil {\n        return\n    }\n")
 	for _, ent := range entities {
 		plural := pluralize(ent.Name)
+		pk := exportName(primaryField(ent).Name)
 		fmt.Fprintf(buf, "    loaders.register(\"%[1]s\", newEntityLoader[string, *gen.%[1]s](\"%[2]s\", collector, func(ctx context.Context, keys []string) (map[string]*gen.%[1]s, error) {\n", ent.Name, plural)
-		fmt.Fprintf(buf, "        results := make(map[string]*gen.%[1]s, len(keys))\n", ent.Name)
-		fmt.Fprintf(buf, "        for _, key := range keys {\n")
-		fmt.Fprintf(buf, "            record, err := orm.%[1]s().ByID(ctx, key)\n", exportName(plural))
-		fmt.Fprintf(buf, "            if err != nil {\n                return nil, err\n            }\n")
-		fmt.Fprintf(buf, "            if record != nil {\n                results[key] = record\n            }\n")
-		fmt.Fprintf(buf, "        }\n        return results, nil\n    }))\n")
+		fmt.Fprintf(buf, "        records, err := orm.%s().Query().Where(%s.%sIn(keys...)).Limit(len(keys)).All(ctx)\n", exportName(plural), predicatePackageName(ent), pk)
+		fmt.Fprintf(buf, "        if err != nil {\n            return nil, err\n        }\n")
+		fmt.Fprintf(buf, "        results := make(map[string]*gen.%[1]s, len(records))\n", ent.Name)
+		fmt.Fprintf(buf, "        for _, record := range records {\n            results[record.%s] = record\n        }\n", pk)
+		fmt.Fprintf(buf, "        return results, nil\n")
+		// Batches must fit in one page of the query, which caps its limit at MaxLimit.
+		if ent.Query.MaxLimit > 0 && ent.Query.MaxLimit < defaultLoaderMaxBatch {
+			fmt.Fprintf(buf, "    }, WithMaxBatch(%d)))\n", ent.Query.MaxLimit)
+		} else {
+			fmt.Fprintf(buf, "    }))\n")
+		}
 	}
 	fmt.Fprintf(buf, "}\n\n")
 
@@ -1349,10 +1506,54 @@ func writeGraphQLDataloaders(root string, entities []Entity, modulePath string)
 		fmt.Fprintf(buf, "    return nil\n}\n\n")
 	}
 
+	entityIndex := make(map[string]Entity, len(entities))
+	for _, ent := range entities {
+		entityIndex[ent.Name] = ent
+	}
+	for _, ent := range entities {
+		for _, gqlEdge := range graphqlEdges(ent, entityIndex) {
+			if gqlEdge.connection() {
+				writeEdgeDataloader(buf, ent, gqlEdge)
+			}
+		}
+	}
+
 	path := filepath.Join(root, "graphql", "dataloaders", "entities_gen.go")
 	return writeGoFile(path, buf.Bytes())
 }
 
+// writeEdgeDataloader renders the loader of a connection edge. Resolvers key it by the connection
+// arguments, so every parent paged alike is loaded by one ranked query, plus one grouped count when
+// totalCount is selected.
+func writeEdgeDataloader(buf *bytes.Buffer, ent Entity, gqlEdge graphqlEdge) {
+	name := edgeLoaderName(ent, gqlEdge.edge)
+	target := gqlEdge.join.target.Name
+	edgeName := exportName(gqlEdge.edge.Name)
+	plural := exportName(pluralize(ent.Name))
+	key := exportName(primaryField(ent).Name)
+	loaderType := fmt.Sprintf("*EntityLoader[string, *EdgePage[*gen.%s]]", target)
+
+	fmt.Fprintf(buf, "// %s returns the loader of %s.%s pages read by query, shared by the parents whose\n", name, ent.Name, gqlEdge.edge.Name)
+	fmt.Fprintf(buf, "// connection arguments produce key. count adds the total number of rows per parent.\n")
+	fmt.Fprintf(buf, "func (l *Loaders) %s(key string, query *gen.%sQuery, count bool) %s {\n", name, target, loaderType)
+	fmt.Fprintf(buf, "    if l == nil || l.orm == nil {\n        return nil\n    }\n")
+	fmt.Fprintf(buf, "    loader, _ := l.getOrRegister(\"%s.%s:\"+key, func() any {\n", ent.Name, gqlEdge.edge.Name)
+	fmt.Fprintf(buf, "        return newEntityLoader[string, *EdgePage[*gen.%[1]s]](\"%[2]s.%[3]s\", l.collector, func(ctx context.Context, keys []string) (map[string]*EdgePage[*gen.%[1]s], error) {\n", target, pluralize(ent.Name), gqlEdge.edge.Name)
+	fmt.Fprintf(buf, "            parents := make([]*gen.%s, len(keys))\n", ent.Name)
+	fmt.Fprintf(buf, "            for idx, key := range keys {\n                parents[idx] = &gen.%s{%s: key}\n            }\n", ent.Name, key)
+	fmt.Fprintf(buf, "            if err := l.orm.%s().Load%sPage(ctx, query, parents...); err != nil {\n                return nil, err\n            }\n", plural, edgeName)
+	fmt.Fprintf(buf, "            var totals map[string]int\n")
+	fmt.Fprintf(buf, "            if count {\n")
+	fmt.Fprintf(buf, "                var err error\n")
+	fmt.Fprintf(buf, "                if totals, err = l.orm.%s().Count%s(ctx, query, parents...); err != nil {\n                    return nil, err\n                }\n            }\n", plural, edgeName)
+	fmt.Fprintf(buf, "            results := make(map[string]*EdgePage[*gen.%s], len(parents))\n", target)
+	fmt.Fprintf(buf, "            for _, parent := range parents {\n")
+	fmt.Fprintf(buf, "                results[parent.%s] = &EdgePage[*gen.%s]{Records: parent.Edges.%s, TotalCount: totals[parent.%s]}\n", key, target, edgeName, key)
+	fmt.Fprintf(buf, "            }\n            return results, nil\n        })\n")
+	fmt.Fprintf(buf, "    }).(%s)\n", loaderType)
+	fmt.Fprintf(buf, "    return loader\n}\n\n")
+}
+
 var predeclaredScalars = func() map[string]struct{} {
 	scalars := map[string]struct{}{
 		"Boolean": {},
@@ -1432,4 +1633,19 @@ type PageInfo {
   endCursor: String
 }
 
-directive @auth(roles: [String!]) on FIELD_DEFINITION`
+type UserError {
+  field: [String!]
+  code: String!
+  message: String!
+}
+
+enum OrderDirection {
+  ASC
+  DESC
+}
+
+directive @auth(roles: [String!], owner: String) on FIELD_DEFINITION
+
+directive @authClaim(claim: String!, field: String, value: String) repeatable on FIELD_DEFINITION
+
+directive @goField(forceResolver: Boolean, name: String, omittable: Boolean) on INPUT_FIELD_DEFINITION | FIELD_DEFINITION`
@@ -0,0 +1,182 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+// aggregateField is a field of ent exposed by the xxxAggregate query field.
+type aggregateField struct {
+	field   dsl.Field
+	gqlType string
+	// group reports whether buckets can be grouped by the field, sum whether it is summed and
+	// averaged, and extremes whether its minimum and maximum are reported.
+	group, sum, extremes bool
+}
+
+// graphqlAggregate reports whether ent opted into the xxxAggregate query field with
+// dsl.GraphQLAggregate.
+func graphqlAggregate(ent Entity) bool {
+	_, ok := graphqlNameSet(ent, "aggregate")
+	return ok
+}
+
+// aggregateFields lists the fields of ent that xxxAggregate groups by or aggregates, restricted to
+// those named by dsl.GraphQLAggregate when it names any. Buckets are scanned by column name, so
+// fields stored under a column not matching their name are left out, as is the primary key.
+func aggregateFields(ent Entity) []aggregateField {
+	allowed, _ := graphqlNameSet(ent, "aggregate")
+	var fields []aggregateField
+	for _, field := range ent.Fields {
+		if field.IsPrimary {
+			continue
+		}
+		if _, ok := allowed[field.Name]; len(allowed) > 0 && !ok {
+			continue
+		}
+		if !strings.EqualFold(strings.ReplaceAll(fieldColumn(field), "_", ""), exportName(field.Name)) {
+			continue
+		}
+		gqlType, _ := graphqlNamedType(field)
+		af := aggregateField{field: field, gqlType: gqlType}
+		switch {
+		case len(field.EnumValues) > 0 && field.EnumName != "":
+			af.group = true
+		case gqlType == "ID", gqlType == "Boolean":
+			af.group = true
+		case gqlType == "String", gqlType == "Date", gqlType == "Timestamp", gqlType == "Timestamptz":
+			af.group, af.extremes = true, true
+		case gqlType == "Int", gqlType == "BigInt", gqlType == "Float":
+			af.group, af.sum, af.extremes = true, true, true
+		default:
+			continue
+		}
+		// A field named count would clash with the count of the bucket.
+		af.group = af.group && lowerCamel(field.Name) != "count"
+		fields = append(fields, af)
+	}
+	return fields
+}
+
+// aggregateGroups reports whether any of fields can be grouped by, in which case xxxAggregate takes
+// a groupBy argument listing XxxAggregateField values.
+func aggregateGroups(fields []aggregateField) bool {
+	for _, af := range fields {
+		if af.group {
+			return true
+		}
+	}
+	return false
+}
+
+func renderEntityAggregateTypes(ent Entity) string {
+	fields := aggregateFields(ent)
+	builder := &strings.Builder{}
+	if aggregateGroups(fields) {
+		fmt.Fprintf(builder, "enum %sAggregateField {\n", ent.Name)
+		for _, af := range fields {
+			if af.group {
+				fmt.Fprintf(builder, "  %s\n", orderFieldValue(af.field))
+			}
+		}
+		builder.WriteString("}\n\n")
+	}
+	fmt.Fprintf(builder, "type %sAggregateBucket {\n", ent.Name)
+	for _, af := range fields {
+		if af.group {
+			fmt.Fprintf(builder, "  %s: %s\n", lowerCamel(af.field.Name), af.gqlType)
+		}
+	}
+	builder.WriteString("  count: Int!\n")
+	for _, af := range fields {
+		name := exportName(af.field.Name)
+		if af.sum {
+			fmt.Fprintf(builder, "  sum%s: Float\n", name)
+			fmt.Fprintf(builder, "  avg%s: Float\n", name)
+		}
+		if af.extremes {
+			fmt.Fprintf(builder, "  min%s: %s\n", name, af.gqlType)
+			fmt.Fprintf(builder, "  max%s: %s\n", name, af.gqlType)
+		}
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+func renderEntityAggregateQueryField(ent Entity) string {
+	args := fmt.Sprintf("where: %sWhereInput", ent.Name)
+	if aggregateGroups(aggregateFields(ent)) {
+		args += fmt.Sprintf(", groupBy: [%sAggregateField!]", ent.Name)
+	}
+	return appendAuthDirective(fmt.Sprintf("%sAggregate(%s): [%sAggregateBucket!]!", lowerCamel(ent.Name), args, ent.Name), ent.Authorization.Read)
+}
+
+// renderEntityAggregateResolver renders the xxxAggregate field of ent. Buckets are sorted by the
+// grouped fields, and only the aggregates selected by the query are computed.
+func renderEntityAggregateResolver(ent Entity) string {
+	builder := &strings.Builder{}
+	pkg := predicatePackageName(ent)
+	fields := aggregateFields(ent)
+
+	params := fmt.Sprintf("where *graphql.%sWhereInput", ent.Name)
+	grouped := aggregateGroups(fields)
+	if grouped {
+		params += fmt.Sprintf(", groupBy []graphql.%sAggregateField", ent.Name)
+	}
+	fmt.Fprintf(builder, "func (r *queryResolver) %[1]sAggregate(ctx context.Context, %[2]s) ([]*graphql.%[1]sAggregateBucket, error) {\n", ent.Name, params)
+	fmt.Fprintf(builder, "    if r.ORM == nil {\n        return nil, fmt.Errorf(\"orm client is not configured\")\n    }\n")
+	fmt.Fprintf(builder, "    preds, err := %s(ctx, where)\n", wherePredicatesFunc(ent))
+	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+	if authRuleScoped(ent.Authorization.Read) {
+		fmt.Fprintf(builder, "    scope, err := %sReadScope(ctx)\n", lowerCamel(ent.Name))
+		fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+		fmt.Fprintf(builder, "    preds = append(preds, scope...)\n")
+	}
+	if grouped {
+		fmt.Fprintf(builder, "    columns := make([]string, len(groupBy))\n")
+		fmt.Fprintf(builder, "    orders := make([]%s.Order, len(groupBy))\n", pkg)
+		fmt.Fprintf(builder, "    for idx, field := range groupBy {\n")
+		fmt.Fprintf(builder, "        switch field {\n")
+		for _, af := range fields {
+			if af.group {
+				fmt.Fprintf(builder, "        case %q:\n            columns[idx] = %s.Field%s\n", orderFieldValue(af.field), pkg, exportName(af.field.Name))
+			}
+		}
+		fmt.Fprintf(builder, "        default:\n            return nil, fmt.Errorf(\"unsupported %sAggregateField %%q\", field)\n        }\n", ent.Name)
+		fmt.Fprintf(builder, "        orders[idx] = %s.Asc(columns[idx])\n    }\n", pkg)
+	}
+	fmt.Fprintf(builder, "    var aggregates []%s.Aggregate\n", pkg)
+	for _, af := range fields {
+		name := exportName(af.field.Name)
+		column := fmt.Sprintf("%s.Field%s", pkg, name)
+		var fns []string
+		if af.sum {
+			fns = append(fns, "Sum", "Avg")
+		}
+		if af.extremes {
+			fns = append(fns, "Min", "Max")
+		}
+		for _, fn := range fns {
+			fmt.Fprintf(builder, "    if fieldSelected(ctx, %q) {\n", strings.ToLower(fn)+name)
+			fmt.Fprintf(builder, "        aggregates = append(aggregates, %s.%s(%s))\n    }\n", pkg, fn, column)
+		}
+	}
+	fmt.Fprintf(builder, "    if len(aggregates) == 0 || fieldSelected(ctx, \"count\") {\n")
+	fmt.Fprintf(builder, "        aggregates = append(aggregates, %s.Count())\n    }\n", pkg)
+	fmt.Fprintf(builder, "    buckets := []*graphql.%sAggregateBucket{}\n", ent.Name)
+	fmt.Fprintf(builder, "    err = r.ORM.%s().Query().\n", exportName(pluralize(ent.Name)))
+	fmt.Fprintf(builder, "        Where(preds...).\n")
+	if grouped {
+		fmt.Fprintf(builder, "        GroupBy(columns...).\n")
+		fmt.Fprintf(builder, "        Order(orders...).\n")
+	} else {
+		fmt.Fprintf(builder, "        GroupBy().\n")
+	}
+	fmt.Fprintf(builder, "        Aggregate(aggregates...).\n")
+	fmt.Fprintf(builder, "        Scan(ctx, &buckets)\n")
+	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+	fmt.Fprintf(builder, "    return buckets, nil\n}\n\n")
+	return builder.String()
+}
@@ -0,0 +1,275 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+// fieldAuthRule returns the rule guarding reads of field, declared with dsl.Field.Authorize.
+// Public rules guard nothing and are reported as nil.
+func fieldAuthRule(field dsl.Field) *dsl.AuthRule {
+	rule, ok := extractAuthRule(field.Annotations["auth"])
+	if !ok || !authRuleRestricted(rule) {
+		return nil
+	}
+	return rule
+}
+
+func authRuleRestricted(rule *dsl.AuthRule) bool {
+	return rule != nil && rule.Requirement != "" && rule.Requirement != dsl.AuthRequirementPublic
+}
+
+// authRuleScoped reports whether rule depends on the record it guards, through an owner or a claim
+// compared with a field. Query and mutation fields cannot check such rules in their directive, so
+// the generated resolvers enforce them.
+func authRuleScoped(rule *dsl.AuthRule) bool {
+	return authRuleRestricted(rule) && len(authRuleFields(rule)) > 0
+}
+
+// authRuleFields lists the record fields rule compares with claims of the viewer.
+func authRuleFields(rule *dsl.AuthRule) []string {
+	if rule == nil {
+		return nil
+	}
+	var fields []string
+	if rule.Owner != "" {
+		fields = append(fields, rule.Owner)
+	}
+	for _, cond := range rule.Conditions {
+		if cond.Field != "" {
+			fields = append(fields, cond.Field)
+		}
+	}
+	return fields
+}
+
+// authFieldName resolves name, a field or column of ent, to the name of the field through rename,
+// e.g. lowerCamel for GraphQL fields or exportName for Go struct fields.
+func authFieldName(ent Entity, name string, rename func(string) string) string {
+	if field, ok := policyOwnerField(ent, name); ok {
+		return rename(field.Name)
+	}
+	return rename(name)
+}
+
+// buildFieldAuthDirective renders the directives guarding a field of the GraphQL type of ent.
+// Owner and claim fields refer to the fields of the object holding the guarded field.
+func buildFieldAuthDirective(ent Entity, rule *dsl.AuthRule) string {
+	if !authRuleRestricted(rule) {
+		return ""
+	}
+	var args []string
+	if roles := quotedRoles(rule.Roles); len(roles) > 0 {
+		args = append(args, fmt.Sprintf("roles: [%s]", strings.Join(roles, ", ")))
+	}
+	if rule.Owner != "" {
+		args = append(args, fmt.Sprintf("owner: %q", authFieldName(ent, rule.Owner, lowerCamel)))
+	}
+	directives := []string{"@auth"}
+	if len(args) > 0 {
+		directives[0] = fmt.Sprintf("@auth(%s)", strings.Join(args, ", "))
+	}
+	for _, cond := range rule.Conditions {
+		if cond.Field != "" {
+			directives = append(directives, fmt.Sprintf("@authClaim(claim: %q, field: %q)", cond.Claim, authFieldName(ent, cond.Field, lowerCamel)))
+			continue
+		}
+		directives = append(directives, fmt.Sprintf("@authClaim(claim: %q, value: %q)", cond.Claim, cond.Value))
+	}
+	return strings.Join(directives, " ")
+}
+
+func quotedRoles(roles []string) []string {
+	quoted := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if strings.TrimSpace(role) == "" {
+			continue
+		}
+		quoted = append(quoted, fmt.Sprintf("%q", role))
+	}
+	return quoted
+}
+
+// authRuleVar names the variable holding the directives.Rule of ent for op.
+func authRuleVar(ent Entity, op string) string {
+	return lowerCamel(ent.Name) + op + "Rule"
+}
+
+// entityScopedRules lists the read, update and delete rules of ent that depend on the record,
+// keyed by operation.
+func entityScopedRules(ent Entity) ([]string, map[string]*dsl.AuthRule) {
+	candidates := []struct {
+		op   string
+		rule *dsl.AuthRule
+	}{
+		{"Read", ent.Authorization.Read},
+		{"Update", ent.Authorization.Update},
+		{"Delete", ent.Authorization.Delete},
+	}
+	var ops []string
+	rules := map[string]*dsl.AuthRule{}
+	for _, candidate := range candidates {
+		if authRuleScoped(candidate.rule) {
+			ops = append(ops, candidate.op)
+			rules[candidate.op] = candidate.rule
+		}
+	}
+	return ops, rules
+}
+
+// subscriptionAuthRule returns the operation and rule guarding the subscription field of ent for
+// event.
+func subscriptionAuthRule(ent Entity, event dsl.SubscriptionEvent) (string, *dsl.AuthRule) {
+	switch event {
+	case dsl.SubscriptionEventUpdate:
+		return "Update", ent.Authorization.Update
+	case dsl.SubscriptionEventDelete:
+		return "Delete", ent.Authorization.Delete
+	default:
+		return "Create", ent.Authorization.Create
+	}
+}
+
+// hasResolverAuthRules reports whether the resolvers of entities check rules themselves, either
+// rules depending on the record or the create rules of children created inline.
+func hasResolverAuthRules(entities []Entity) bool {
+	entityIndex := make(map[string]Entity, len(entities))
+	for _, ent := range entities {
+		entityIndex[ent.Name] = ent
+	}
+	for _, ent := range entities {
+		if ops, _ := entityScopedRules(ent); len(ops) > 0 {
+			return true
+		}
+		if inlineCreateRule(ent, entityIndex) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// renderEntityAuthRules renders the directives.Rule of each operation of ent whose rule depends on
+// the record, the read scope applied to queries, and the check of stored records before updates
+// and deletes. The create rule is rendered too when ent can be created inline through edge inputs.
+func renderEntityAuthRules(ent Entity, entityIndex map[string]Entity) string {
+	ops, rules := entityScopedRules(ent)
+	createRule := inlineCreateRule(ent, entityIndex)
+	if len(ops) == 0 && createRule == nil {
+		return ""
+	}
+	builder := &strings.Builder{}
+	if createRule != nil {
+		fmt.Fprintf(builder, "// %s is the create rule of %s, enforced by the resolvers creating it inline through edge\n", authRuleVar(ent, "Create"), ent.Name)
+		fmt.Fprintf(builder, "// inputs.\n")
+		renderAuthRuleVar(builder, ent, "Create", createRule)
+	}
+	for _, op := range ops {
+		fmt.Fprintf(builder, "// %s is the %s rule of %s, enforced by its resolvers as it depends on the record.\n", authRuleVar(ent, op), strings.ToLower(op), ent.Name)
+		renderAuthRuleVar(builder, ent, op, rules[op])
+	}
+
+	if rule, ok := rules["Read"]; ok {
+		pkg := predicatePackageName(ent)
+		fmt.Fprintf(builder, "// %sReadScope returns the predicates limiting queries of %s to the records the viewer may\n", lowerCamel(ent.Name), ent.Name)
+		fmt.Fprintf(builder, "// read.\n")
+		fmt.Fprintf(builder, "func %sReadScope(ctx context.Context) ([]%s.Predicate, error) {\n", lowerCamel(ent.Name), pkg)
+		fmt.Fprintf(builder, "    matches, err := directives.Scope(ctx, %s)\n", authRuleVar(ent, "Read"))
+		fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+		fmt.Fprintf(builder, "    preds := make([]%s.Predicate, 0, len(matches))\n", pkg)
+		fmt.Fprintf(builder, "    for _, match := range matches {\n")
+		fmt.Fprintf(builder, "        switch match.Field {\n")
+		seen := map[string]struct{}{}
+		for _, name := range authRuleFields(rule) {
+			goName := authFieldName(ent, name, exportName)
+			if _, dup := seen[goName]; dup {
+				continue
+			}
+			seen[goName] = struct{}{}
+			fmt.Fprintf(builder, "        case %q:\n            preds = append(preds, %s.%sEq(match.Value))\n", goName, pkg, goName)
+		}
+		fmt.Fprintf(builder, "        }\n    }\n")
+		fmt.Fprintf(builder, "    return preds, nil\n}\n\n")
+	}
+
+	if _, ok := rules["Update"]; !ok {
+		if _, ok := rules["Delete"]; !ok {
+			return builder.String()
+		}
+	}
+	fmt.Fprintf(builder, "// authorize%[1]s checks rule against the stored %[1]s identified by id, read through client.\n", ent.Name)
+	fmt.Fprintf(builder, "func (r *Resolver) authorize%[1]s(ctx context.Context, client *gen.Client, id string, rule directives.Rule) error {\n", ent.Name)
+	fmt.Fprintf(builder, "    record, err := client.%s().ByID(ctx, id)\n", exportName(pluralize(ent.Name)))
+	fmt.Fprintf(builder, "    if err != nil {\n        return err\n    }\n")
+	fmt.Fprintf(builder, "    return directives.Authorize(ctx, rule, record)\n}\n\n")
+	return builder.String()
+}
+
+// renderAuthRuleVar renders the variable holding rule, the rule of ent for op, as a directives.Rule.
+func renderAuthRuleVar(builder *strings.Builder, ent Entity, op string, rule *dsl.AuthRule) {
+	fmt.Fprintf(builder, "var %s = directives.Rule{\n", authRuleVar(ent, op))
+	if roles := quotedRoles(rule.Roles); len(roles) > 0 {
+		fmt.Fprintf(builder, "    Roles: []string{%s},\n", strings.Join(roles, ", "))
+	}
+	if rule.Owner != "" {
+		fmt.Fprintf(builder, "    Owner: %q,\n", authFieldName(ent, rule.Owner, exportName))
+	}
+	if len(rule.Conditions) > 0 {
+		fmt.Fprintf(builder, "    Conditions: []directives.Condition{\n")
+		for _, cond := range rule.Conditions {
+			if cond.Field != "" {
+				fmt.Fprintf(builder, "        {Claim: %q, Field: %q},\n", cond.Claim, authFieldName(ent, cond.Field, exportName))
+			} else {
+				fmt.Fprintf(builder, "        {Claim: %q, Value: %q},\n", cond.Claim, cond.Value)
+			}
+		}
+		fmt.Fprintf(builder, "    },\n")
+	}
+	fmt.Fprintf(builder, "}\n\n")
+}
+
+// renderReadAuthCheck renders the check of the read rule of ent against the variable named record,
+// a loaded *gen.Xxx that may be nil, when the rule depends on the record.
+func renderReadAuthCheck(builder *strings.Builder, ent Entity, record, indent string) {
+	if !authRuleScoped(ent.Authorization.Read) {
+		return
+	}
+	fmt.Fprintf(builder, "%sif %s != nil {\n", indent, record)
+	fmt.Fprintf(builder, "%s    if err := directives.Authorize(ctx, %s, %s); err != nil {\n", indent, authRuleVar(ent, "Read"), record)
+	fmt.Fprintf(builder, "%s        return nil, err\n%s    }\n%s}\n", indent, indent, indent)
+}
+
+// graphqlGuardedFieldValue converts field of record for the GraphQL type of ent. Fields guarded by
+// a rule are nullable in GraphQL, so their values are passed by pointer.
+func graphqlGuardedFieldValue(field dsl.Field) string {
+	value := graphqlFieldValue(field)
+	if fieldAuthRule(field) == nil || field.Nullable || graphqlNillable(field) {
+		return value
+	}
+	return fmt.Sprintf("toGraphQLPtr(%s)", value)
+}
+
+// graphqlNillable reports whether gqlgen models the nullable GraphQL type of field without a
+// pointer, as it does for lists and JSON.
+func graphqlNillable(field dsl.Field) bool {
+	name, _ := graphqlNamedType(field)
+	return strings.HasPrefix(name, "[") || name == "JSON" || name == "JSONB"
+}
+
+func needsGraphQLPointerHelper(entities []Entity) bool {
+	for _, ent := range entities {
+		for _, field := range ent.Fields {
+			if field.Name != "id" && graphqlGuardedFieldValue(field) != graphqlFieldValue(field) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func renderGraphQLPointerHelper() string {
+	return strings.TrimSpace(`func toGraphQLPtr[T any](value T) *T {
+    return &value
+}`) + "\n\n"
+}
@@ -0,0 +1,259 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+// graphqlEdge is an edge exposed as a field of its entity's GraphQL type. To-one edges holding the
+// foreign key resolve through the target's dataloader, other to-one edges through Query<Edge>, and
+// to-many edges become connections paged like the root list fields, loaded for all parents sharing
+// the connection arguments by an edge dataloader. Polymorphic to-one edges resolve to a union of
+// their target entities.
+type graphqlEdge struct {
+	edge    dsl.Edge
+	join    edgeJoin
+	fk      dsl.Field
+	members []Entity
+}
+
+func (e graphqlEdge) fieldName() string {
+	return lowerCamel(e.edge.Name)
+}
+
+func (e graphqlEdge) polymorphic() bool {
+	return len(e.members) > 0
+}
+
+func (e graphqlEdge) connection() bool {
+	return !e.polymorphic() && e.edge.Kind != dsl.EdgeToOne
+}
+
+// edgeLoaderName names the dataloader of a connection edge, e.g. PostComments.
+func edgeLoaderName(ent Entity, edge dsl.Edge) string {
+	return ent.Name + exportName(edge.Name)
+}
+
+func edgeUnionName(ent Entity, edge dsl.Edge) string {
+	return ent.Name + exportName(edge.Name)
+}
+
+// graphqlEdges lists the edges of ent exposed in GraphQL. Edges whose field name clashes with a
+// column field, or whose keys are not strings like the rest of the GraphQL layer, are left out.
+func graphqlEdges(ent Entity, entityIndex map[string]Entity) []graphqlEdge {
+	taken := map[string]struct{}{"id": {}}
+	for _, field := range ent.Fields {
+		taken[lowerCamel(field.Name)] = struct{}{}
+	}
+	var edges []graphqlEdge
+	for _, edge := range ent.Edges {
+		if _, clash := taken[lowerCamel(edge.Name)]; clash {
+			continue
+		}
+		if len(edge.PolymorphicTargets) > 0 {
+			if gqlEdge, ok := polymorphicGraphQLEdge(ent, edge, entityIndex); ok {
+				edges = append(edges, gqlEdge)
+			}
+			continue
+		}
+		join, ok := resolveEdgeJoin(ent, edge, entityIndex)
+		if !ok || baseGoType(primaryField(join.target)) != "string" {
+			continue
+		}
+		gqlEdge := graphqlEdge{edge: edge, join: join}
+		if join.local {
+			gqlEdge.fk, _ = fieldByColumn(ent, join.sourceColumn)
+			if baseGoType(gqlEdge.fk) != "string" {
+				continue
+			}
+		} else if baseGoType(primaryField(ent)) != "string" {
+			continue
+		}
+		edges = append(edges, gqlEdge)
+	}
+	return edges
+}
+
+// polymorphicGraphQLEdge resolves a polymorphic to-one edge stored in a local column. Conditions
+// of the targets are SQL and cannot be evaluated by the resolver, so each member is tried in turn.
+func polymorphicGraphQLEdge(ent Entity, edge dsl.Edge, entityIndex map[string]Entity) (graphqlEdge, bool) {
+	if edge.Kind != dsl.EdgeToOne {
+		return graphqlEdge{}, false
+	}
+	fk, found := fieldByColumn(ent, edgeColumn(edge))
+	if !found || baseGoType(fk) != "string" {
+		return graphqlEdge{}, false
+	}
+	gqlEdge := graphqlEdge{edge: edge, fk: fk}
+	seen := map[string]struct{}{}
+	for _, target := range edge.PolymorphicTargets {
+		member, ok := entityIndex[target.Entity]
+		if !ok || baseGoType(primaryField(member)) != "string" {
+			continue
+		}
+		if _, dup := seen[member.Name]; dup {
+			continue
+		}
+		seen[member.Name] = struct{}{}
+		gqlEdge.members = append(gqlEdge.members, member)
+	}
+	return gqlEdge, len(gqlEdge.members) > 0
+}
+
+// renderEdgeFieldDefinition renders the field of gqlEdge on its entity's GraphQL type.
+func renderEdgeFieldDefinition(ent Entity, gqlEdge graphqlEdge) string {
+	const forceResolver = " @goField(forceResolver: true)"
+	name := gqlEdge.fieldName()
+	switch {
+	case gqlEdge.polymorphic():
+		return fmt.Sprintf("%s: %s%s", name, edgeUnionName(ent, gqlEdge.edge), forceResolver)
+	case gqlEdge.connection():
+		target := gqlEdge.join.target
+		def := fmt.Sprintf("%s(first: Int, after: String, last: Int, before: String, where: %[2]sWhereInput, orderBy: [%[2]sOrder!]): %[2]sConnection!%s", name, target.Name, forceResolver)
+		return appendAuthDirective(def, target.Authorization.Read)
+	default:
+		target := gqlEdge.join.target
+		return appendAuthDirective(fmt.Sprintf("%s: %s%s", name, target.Name, forceResolver), target.Authorization.Read)
+	}
+}
+
+func renderEdgeUnions(ent Entity, entityIndex map[string]Entity) string {
+	builder := &strings.Builder{}
+	for _, gqlEdge := range graphqlEdges(ent, entityIndex) {
+		if !gqlEdge.polymorphic() {
+			continue
+		}
+		names := make([]string, len(gqlEdge.members))
+		for i, member := range gqlEdge.members {
+			names[i] = member.Name
+		}
+		fmt.Fprintf(builder, "union %s = %s\n", edgeUnionName(ent, gqlEdge.edge), strings.Join(names, " | "))
+	}
+	return builder.String()
+}
+
+// renderEntityEdgeResolvers renders the object resolver of ent's GraphQL type, which gqlgen calls
+// for every edge field.
+func renderEntityEdgeResolvers(ent Entity, entityIndex map[string]Entity) string {
+	edges := graphqlEdges(ent, entityIndex)
+	if len(edges) == 0 {
+		return ""
+	}
+	builder := &strings.Builder{}
+	resolver := lowerCamel(ent.Name) + "Resolver"
+	fmt.Fprintf(builder, "func (r *Resolver) %[1]s() graphql.%[1]sResolver { return &%[2]s{r} }\n\n", ent.Name, resolver)
+	fmt.Fprintf(builder, "type %s struct{ *Resolver }\n\n", resolver)
+
+	for _, gqlEdge := range edges {
+		if gqlEdge.join.local || gqlEdge.polymorphic() {
+			fmt.Fprintf(builder, "// parent%[1]s loads the record behind obj, usually from the dataloader cache primed when obj was\n", ent.Name)
+			fmt.Fprintf(builder, "// resolved, so its foreign keys can be followed.\n")
+			fmt.Fprintf(builder, "func (r *Resolver) parent%[1]s(ctx context.Context, obj *graphql.%[1]s) (*gen.%[1]s, error) {\n", ent.Name)
+			fmt.Fprintf(builder, "    if obj == nil {\n        return nil, nil\n    }\n")
+			fmt.Fprintf(builder, "    nativeID, err := decode%sID(obj.ID)\n", ent.Name)
+			fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+			fmt.Fprintf(builder, "    return r.load%s(ctx, nativeID)\n}\n\n", ent.Name)
+			break
+		}
+	}
+
+	for _, gqlEdge := range edges {
+		method := exportName(gqlEdge.edge.Name)
+		switch {
+		case gqlEdge.polymorphic():
+			union := edgeUnionName(ent, gqlEdge.edge)
+			fmt.Fprintf(builder, "func (r *%s) %s(ctx context.Context, obj *graphql.%s) (graphql.%s, error) {\n", resolver, method, ent.Name, union)
+			writeEdgeForeignKey(builder, ent, gqlEdge.fk)
+			for _, member := range gqlEdge.members {
+				record := lowerCamel(member.Name) + "Record"
+				fmt.Fprintf(builder, "    %s, err := r.load%s(ctx, fk)\n", record, member.Name)
+				fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+				renderReadAuthCheck(builder, member, record, "    ")
+				fmt.Fprintf(builder, "    if %s != nil {\n", record)
+				fmt.Fprintf(builder, "        if err := r.applyBeforeReturn%s(ctx, %s); err != nil {\n            return nil, err\n        }\n", member.Name, record)
+				fmt.Fprintf(builder, "        return toGraphQL%s(%s), nil\n    }\n", member.Name, record)
+			}
+			fmt.Fprintf(builder, "    return nil, nil\n}\n\n")
+		case gqlEdge.connection():
+			target := gqlEdge.join.target.Name
+			// Targets whose read rule depends on the record are limited to the viewer's scope, which
+			// also keys the edge loader.
+			scoped := authRuleScoped(gqlEdge.join.target.Authorization.Read)
+			scopeWhere, keyArgs := "", "first, after, last, before, where, orderBy, count"
+			if scoped {
+				scopeWhere, keyArgs = ".Where(scope...)", "scope, "+keyArgs
+			}
+			fmt.Fprintf(builder, "func (r *%[1]s) %[2]s(ctx context.Context, obj *graphql.%[3]s, first *int, after *string, last *int, before *string, where *graphql.%[4]sWhereInput, orderBy []*graphql.%[4]sOrder) (*graphql.%[4]sConnection, error) {\n", resolver, method, ent.Name, target)
+			fmt.Fprintf(builder, "    if r.ORM == nil {\n        return nil, fmt.Errorf(\"orm client is not configured\")\n    }\n")
+			fmt.Fprintf(builder, "    nativeID, err := decode%sID(obj.ID)\n", ent.Name)
+			fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+			if scoped {
+				fmt.Fprintf(builder, "    scope, err := %sReadScope(ctx)\n", lowerCamel(target))
+				fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+			}
+			fmt.Fprintf(builder, "    if loaders := dataloaders.FromContext(ctx); loaders != nil {\n")
+			fmt.Fprintf(builder, "        window, _, query, err := %sPage(ctx, r.ORM.%s().Query()%s, first, after, last, before, where, orderBy)\n", lowerCamel(target), exportName(pluralize(target)), scopeWhere)
+			fmt.Fprintf(builder, "        if err != nil {\n            return nil, err\n        }\n")
+			fmt.Fprintf(builder, "        count := fieldSelected(ctx, \"totalCount\")\n")
+			fmt.Fprintf(builder, "        if loader := loaders.%s(pageKey(%s), query, count); loader != nil {\n", edgeLoaderName(ent, gqlEdge.edge), keyArgs)
+			fmt.Fprintf(builder, "            page, err := loader.Load(ctx, nativeID)\n")
+			fmt.Fprintf(builder, "            if err != nil {\n                return nil, err\n            }\n")
+			fmt.Fprintf(builder, "            connection, err := r.%sConnection(ctx, query, window, page.Records)\n", lowerCamel(target))
+			fmt.Fprintf(builder, "            if err != nil {\n                return nil, err\n            }\n")
+			fmt.Fprintf(builder, "            connection.TotalCount = page.TotalCount\n")
+			fmt.Fprintf(builder, "            return connection, nil\n        }\n    }\n")
+			fmt.Fprintf(builder, "    parent := &gen.%s{%s: nativeID}\n", ent.Name, exportName(primaryField(ent).Name))
+			fmt.Fprintf(builder, "    newQuery := func() *gen.%sQuery {\n        return r.ORM.%s().Query%s(parent)%s\n    }\n", target, exportName(pluralize(ent.Name)), method, scopeWhere)
+			fmt.Fprintf(builder, "    return r.paginate%s(ctx, newQuery, first, after, last, before, where, orderBy)\n}\n\n", exportName(pluralize(target)))
+		case gqlEdge.join.local:
+			target := gqlEdge.join.target.Name
+			fmt.Fprintf(builder, "func (r *%s) %s(ctx context.Context, obj *graphql.%s) (*graphql.%s, error) {\n", resolver, method, ent.Name, target)
+			writeEdgeForeignKey(builder, ent, gqlEdge.fk)
+			fmt.Fprintf(builder, "    record, err := r.load%s(ctx, fk)\n", target)
+			writeEdgeRecordReturn(builder, gqlEdge.join.target)
+		default:
+			target := gqlEdge.join.target.Name
+			fmt.Fprintf(builder, "func (r *%s) %s(ctx context.Context, obj *graphql.%s) (*graphql.%s, error) {\n", resolver, method, ent.Name, target)
+			fmt.Fprintf(builder, "    if r.ORM == nil {\n        return nil, fmt.Errorf(\"orm client is not configured\")\n    }\n")
+			fmt.Fprintf(builder, "    nativeID, err := decode%sID(obj.ID)\n", ent.Name)
+			fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+			fmt.Fprintf(builder, "    record, err := r.ORM.%s().Query%s(&gen.%s{%s: nativeID}).First(ctx)\n", exportName(pluralize(ent.Name)), method, ent.Name, exportName(primaryField(ent).Name))
+			writeEdgeRecordReturn(builder, gqlEdge.join.target)
+		}
+	}
+	return builder.String()
+}
+
+// writeEdgeForeignKey declares fk with the foreign key of obj's record, returning early when the
+// record or the key is missing.
+func writeEdgeForeignKey(builder *strings.Builder, ent Entity, fk dsl.Field) {
+	fmt.Fprintf(builder, "    parent, err := r.parent%s(ctx, obj)\n", ent.Name)
+	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+	fmt.Fprintf(builder, "    if parent == nil {\n        return nil, nil\n    }\n")
+	value := "parent." + exportName(fk.Name)
+	switch {
+	case isNullablePointerField(fk):
+		fmt.Fprintf(builder, "    if %s == nil {\n        return nil, nil\n    }\n", value)
+		fmt.Fprintf(builder, "    fk := *%s\n", value)
+	case isNullableSQLNullField(fk):
+		fmt.Fprintf(builder, "    if !%s.Valid {\n        return nil, nil\n    }\n", value)
+		fmt.Fprintf(builder, "    fk := %s.%s\n", value, sqlNullValueFieldAccessor(fk))
+	default:
+		fmt.Fprintf(builder, "    fk := %s\n", value)
+	}
+	fmt.Fprintf(builder, "    if fk == \"\" {\n        return nil, nil\n    }\n")
+}
+
+// writeEdgeRecordReturn returns record, the target of a to-one edge, once checked against the read
+// rule of target.
+func writeEdgeRecordReturn(builder *strings.Builder, targetEnt Entity) {
+	target := targetEnt.Name
+	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+	renderReadAuthCheck(builder, targetEnt, "record", "    ")
+	fmt.Fprintf(builder, "    if record == nil {\n        return nil, nil\n    }\n")
+	fmt.Fprintf(builder, "    if err := r.applyBeforeReturn%[1]s(ctx, record); err != nil {\n        return nil, err\n    }\n", target)
+	fmt.Fprintf(builder, "    r.prime%s(ctx, record)\n", target)
+	fmt.Fprintf(builder, "    return toGraphQL%s(record), nil\n}\n\n", target)
+}
@@ -0,0 +1,237 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+// refInput is a to-one edge holding its foreign key, set from the create and update inputs through
+// a <edge>ID field that takes the target's global ID in place of the raw column.
+type refInput struct {
+	edge   graphqlEdge
+	target Entity
+}
+
+func (in refInput) fieldName() string {
+	return lowerCamel(in.edge.edge.Name) + "ID"
+}
+
+func (in refInput) goName() string {
+	return exportName(in.edge.edge.Name) + "ID"
+}
+
+// edgeInput is a to-many edge changed by the create and update inputs: targets are linked with
+// add<Edge>IDs, unlinked with remove<Edge>IDs and clear<Edge>, and owned children, whose foreign
+// key references the parent, can be created inline with create<Edge>.
+type edgeInput struct {
+	linked linkedEdge
+	// childField is the Go name of the field of the child's create input holding the parent key,
+	// empty when children cannot be created inline.
+	childField string
+	// childGlobal reports whether childField takes a global ID rather than the raw key.
+	childGlobal bool
+}
+
+func (in edgeInput) addName() string {
+	return lowerCamel(in.linked.addMethod())
+}
+
+func (in edgeInput) removeName() string {
+	return lowerCamel(in.linked.removeMethod())
+}
+
+func (in edgeInput) clearName() string {
+	return lowerCamel(in.linked.clearMethod())
+}
+
+func (in edgeInput) createName() string {
+	return "create" + exportName(in.linked.join.edge.Name)
+}
+
+// refInputs maps the foreign key fields of ent to the to-one edges they hold. Fields whose edge
+// input name clashes with another field keep their raw column input.
+func refInputs(ent Entity, entityIndex map[string]Entity) map[string]refInput {
+	taken := map[string]struct{}{}
+	for _, field := range ent.Fields {
+		taken[lowerCamel(field.Name)] = struct{}{}
+	}
+	refs := map[string]refInput{}
+	for _, gqlEdge := range graphqlEdges(ent, entityIndex) {
+		if gqlEdge.polymorphic() || !gqlEdge.join.local || gqlEdge.fk.Name == "" || gqlEdge.fk.Name == "id" {
+			continue
+		}
+		if _, dup := refs[gqlEdge.fk.Name]; dup {
+			continue
+		}
+		ref := refInput{edge: gqlEdge, target: gqlEdge.join.target}
+		if _, clash := taken[ref.fieldName()]; clash && ref.fieldName() != lowerCamel(gqlEdge.fk.Name) {
+			continue
+		}
+		refs[gqlEdge.fk.Name] = ref
+	}
+	return refs
+}
+
+// edgeInputs lists the to-many edges of ent exposed in GraphQL that inputs can change.
+func edgeInputs(ent Entity, entityIndex map[string]Entity) []edgeInput {
+	exposed := map[string]struct{}{}
+	for _, gqlEdge := range graphqlEdges(ent, entityIndex) {
+		if gqlEdge.connection() {
+			exposed[gqlEdge.edge.Name] = struct{}{}
+		}
+	}
+	var inputs []edgeInput
+	for _, linked := range linkedEdges(ent, entityIndex) {
+		if _, ok := exposed[linked.join.edge.Name]; !ok {
+			continue
+		}
+		input := edgeInput{linked: linked}
+		join := linked.join
+		if fk, ok := fieldByColumn(join.target, join.targetColumn); ok && join.through == "" && lowerCamel(fk.Name) != "" && baseGoType(fk) == "string" {
+			if ref, ok := refInputs(join.target, entityIndex)[fk.Name]; ok && ref.target.Name == ent.Name {
+				input.childField, input.childGlobal = ref.goName(), join.sourceColumn == primaryColumn(ent)
+			} else {
+				input.childField = exportName(fk.Name)
+			}
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs
+}
+
+// renderEdgeInputFields renders the edge fields of ent's create input, or of its update input when
+// update is set.
+func renderEdgeInputFields(ent Entity, entityIndex map[string]Entity, update bool) string {
+	builder := &strings.Builder{}
+	for _, input := range edgeInputs(ent, entityIndex) {
+		fmt.Fprintf(builder, "  %s: [ID!]\n", input.addName())
+		if update && input.linked.unlink {
+			fmt.Fprintf(builder, "  %s: [ID!]\n", input.removeName())
+			fmt.Fprintf(builder, "  %s: Boolean\n", input.clearName())
+		}
+		if input.childField != "" {
+			fmt.Fprintf(builder, "  %s: [Create%sInput!]\n", input.createName(), input.linked.join.target.Name)
+		}
+	}
+	return builder.String()
+}
+
+// renderRefAssignment decodes the global ID given for ref into the foreign key field of model.
+func renderRefAssignment(ref refInput, field dsl.Field) string {
+	builder := &strings.Builder{}
+	local := lowerCamel(ref.edge.edge.Name) + "ID"
+	fmt.Fprintf(builder, "    if input.%s != nil {\n", ref.goName())
+	fmt.Fprintf(builder, "        %s, err := decode%sID(*input.%s)\n", local, ref.target.Name, ref.goName())
+	fmt.Fprintf(builder, "        if err != nil {\n            return nil, err\n        }\n")
+	goType := defaultGoType(field)
+	switch {
+	case strings.HasPrefix(goType, "*"):
+		fmt.Fprintf(builder, "        model.%s = &%s\n", exportName(field.Name), local)
+	case strings.HasPrefix(goType, "sql.Null"):
+		fmt.Fprintf(builder, "        model.%s = %s{%s: %s, Valid: true}\n", exportName(field.Name), goType, sqlNullFieldName(goType), local)
+	default:
+		fmt.Fprintf(builder, "        model.%s = %s\n", exportName(field.Name), local)
+	}
+	fmt.Fprintf(builder, "    }\n")
+	return builder.String()
+}
+
+// renderEdgeInputWrites renders the edge changes requested by the input of a create or update,
+// applied through client once record is written. Unlinking runs before linking, so an update can
+// replace an edge's targets by clearing and adding them at once.
+func renderEdgeInputWrites(ent Entity, entityIndex map[string]Entity, update bool) string {
+	builder := &strings.Builder{}
+	pluralName := exportName(pluralize(ent.Name))
+	for _, input := range edgeInputs(ent, entityIndex) {
+		linked := input.linked
+		target := linked.join.target
+		fkEdge := linked.join.through == ""
+		if update && linked.unlink {
+			fmt.Fprintf(builder, "    if input.%s != nil && *input.%[1]s {\n", exportName(input.clearName()))
+			fmt.Fprintf(builder, "        if err := client.%s().%s(ctx, record); err != nil {\n            return nil, err\n        }\n    }\n", pluralName, linked.clearMethod())
+			renderEdgeIDsWrite(builder, pluralName, target, exportName(input.removeName()), linked.removeMethod(), fkEdge)
+		}
+		renderEdgeIDsWrite(builder, pluralName, target, exportName(input.addName()), linked.addMethod(), fkEdge)
+		if input.childField == "" {
+			continue
+		}
+		// Children are checked against their own create rule, and their user errors name the fields
+		// of the child under its element of the input list.
+		fmt.Fprintf(builder, "    for i, child := range input.%s {\n", exportName(input.createName()))
+		fmt.Fprintf(builder, "        if child == nil {\n            continue\n        }\n")
+		fmt.Fprintf(builder, "        childInput := *child\n")
+		if authRuleRestricted(target.Authorization.Create) {
+			fmt.Fprintf(builder, "        if err := directives.Authorize(ctx, %s, childInput); err != nil {\n            return nil, err\n        }\n", authRuleVar(target, "Create"))
+		}
+		sourceKey, _ := fieldByColumn(ent, linked.join.sourceColumn)
+		if input.childGlobal {
+			fmt.Fprintf(builder, "        parentID := relay.ToGlobalID(%q, record.%s)\n", ent.Name, exportName(sourceKey.Name))
+		} else {
+			fmt.Fprintf(builder, "        parentID := record.%s\n", exportName(sourceKey.Name))
+		}
+		fmt.Fprintf(builder, "        childInput.%s = &parentID\n", input.childField)
+		fmt.Fprintf(builder, "        if _, err := r.create%s(ctx, client, childInput); err != nil {\n", target.Name)
+		fmt.Fprintf(builder, "            return nil, wrapNestedInputError(err, %sInputFields, %q, i)\n        }\n    }\n", lowerCamel(target.Name), input.createName())
+	}
+	return builder.String()
+}
+
+func renderEdgeIDsWrite(builder *strings.Builder, pluralName string, target Entity, inputField, method string, fkEdge bool) {
+	fmt.Fprintf(builder, "    if len(input.%s) > 0 {\n", inputField)
+	fmt.Fprintf(builder, "        ids, err := decodeIDs(input.%s, decode%sID)\n", inputField, target.Name)
+	fmt.Fprintf(builder, "        if err != nil {\n            return nil, err\n        }\n")
+	if authRuleScoped(target.Authorization.Update) {
+		// Linking and unlinking change the targets, so the viewer must be allowed to update each.
+		fmt.Fprintf(builder, "        for _, id := range ids {\n")
+		fmt.Fprintf(builder, "            if err := r.authorize%s(ctx, client, id, %s); err != nil {\n                return nil, err\n            }\n        }\n", target.Name, authRuleVar(target, "Update"))
+	}
+	fmt.Fprintf(builder, "        if err := client.%s().%s(ctx, record, ids...); err != nil {\n            return nil, err\n        }\n", pluralName, method)
+	if fkEdge {
+		// The targets' foreign key changed, so they must be reloaded if read again in this request.
+		fmt.Fprintf(builder, "        for _, id := range ids {\n            r.clear%s(ctx, id)\n        }\n", target.Name)
+	}
+	fmt.Fprintf(builder, "    }\n")
+}
+
+// inlineCreateRule returns the create rule of ent when it restricts access and ent can be created
+// inline through an edge input of another entity, whose resolver then checks it.
+func inlineCreateRule(ent Entity, entityIndex map[string]Entity) *dsl.AuthRule {
+	rule := ent.Authorization.Create
+	if !authRuleRestricted(rule) {
+		return nil
+	}
+	for _, parent := range entityIndex {
+		for _, input := range edgeInputs(parent, entityIndex) {
+			if input.childField != "" && input.linked.join.target.Name == ent.Name {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+func hasEdgeInputs(entities []Entity, entityIndex map[string]Entity) bool {
+	for _, ent := range entities {
+		if len(edgeInputs(ent, entityIndex)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func renderDecodeIDsHelper() string {
+	return strings.TrimSpace(`// decodeIDs decodes the global IDs given for an edge with the target's decode function.
+func decodeIDs(ids []string, decode func(string) (string, error)) ([]string, error) {
+    out := make([]string, len(ids))
+    for i, id := range ids {
+        nativeID, err := decode(id)
+        if err != nil {
+            return nil, err
+        }
+        out[i] = nativeID
+    }
+    return out, nil
+}`) + "\n\n"
+}
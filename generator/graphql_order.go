@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+// orderableFields lists the fields of ent that connections can sort by: the primary key, the
+// fields sorted by the entity's dsl.Query orders, and those opted in with dsl.GraphQLOrderable.
+// Nullable fields and fields without a total order are left out, because keyset cursors cannot
+// compare them.
+func orderableFields(ent Entity) []dsl.Field {
+	optedIn, _ := graphqlNameSet(ent, "orderable")
+	var fields []dsl.Field
+	for _, field := range ent.Fields {
+		if !field.IsPrimary && !fieldOrderedByQuery(ent, field) {
+			if _, ok := optedIn[field.Name]; !ok {
+				continue
+			}
+		}
+		if field.Nullable || !fieldSortable(field) {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func fieldOrderedByQuery(ent Entity, field dsl.Field) bool {
+	for _, order := range ent.Query.Orders {
+		if strings.EqualFold(order.Field, field.Name) || order.Field == fieldColumn(field) {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldSortable(field dsl.Field) bool {
+	gqlType, _ := graphqlNamedType(field)
+	if len(field.EnumValues) > 0 && field.EnumName != "" {
+		return true
+	}
+	switch gqlType {
+	case "ID", "String", "Int", "BigInt", "Float", "Date", "Timestamp", "Timestamptz":
+		return true
+	default:
+		return false
+	}
+}
+
+// orderFieldValue is the XxxOrderField enum value of field, e.g. CREATED_AT.
+func orderFieldValue(field dsl.Field) string {
+	return strings.ToUpper(toSnakeCase(field.Name))
+}
+
+func renderEntityOrderTypes(ent Entity) string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "enum %sOrderField {\n", ent.Name)
+	for _, field := range orderableFields(ent) {
+		fmt.Fprintf(builder, "  %s\n", orderFieldValue(field))
+	}
+	builder.WriteString("}\n\n")
+	fmt.Fprintf(builder, "input %sOrder {\n", ent.Name)
+	fmt.Fprintf(builder, "  field: %sOrderField!\n", ent.Name)
+	builder.WriteString("  direction: OrderDirection! = ASC\n")
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+func ordersFunc(ent Entity) string {
+	return lowerCamel(ent.Name) + "Orders"
+}
+
+// renderEntityOrders renders the conversion of orderBy into sort keys for XxxQuery.Order. Without
+// orderBy rows sort by primary key; the query breaks ties with it either way, so cursors stay
+// unique and are bound to the active ordering.
+func renderEntityOrders(ent Entity) string {
+	builder := &strings.Builder{}
+	pkg := predicatePackageName(ent)
+	fn := ordersFunc(ent)
+
+	fmt.Fprintf(builder, "// %s converts orderBy into sort keys for %sQuery.Order.\n", fn, ent.Name)
+	fmt.Fprintf(builder, "func %s(orderBy []*graphql.%sOrder) ([]%s.Order, error) {\n", fn, ent.Name, pkg)
+	fmt.Fprintf(builder, "    if len(orderBy) == 0 {\n")
+	fmt.Fprintf(builder, "        return []%[1]s.Order{%[1]s.Asc(%[1]s.Field%[2]s)}, nil\n    }\n", pkg, exportName(primaryField(ent).Name))
+	fmt.Fprintf(builder, "    orders := make([]%s.Order, len(orderBy))\n", pkg)
+	fmt.Fprintf(builder, "    for idx, order := range orderBy {\n")
+	fmt.Fprintf(builder, "        var column string\n")
+	fmt.Fprintf(builder, "        switch order.Field {\n")
+	for _, field := range orderableFields(ent) {
+		fmt.Fprintf(builder, "        case %q:\n            column = %s.Field%s\n", orderFieldValue(field), pkg, exportName(field.Name))
+	}
+	fmt.Fprintf(builder, "        default:\n            return nil, fmt.Errorf(\"unsupported %sOrderField %%q\", order.Field)\n        }\n", ent.Name)
+	fmt.Fprintf(builder, "        if order.Direction == graphql.OrderDirectionDesc {\n")
+	fmt.Fprintf(builder, "            orders[idx] = %s.Desc(column)\n", pkg)
+	fmt.Fprintf(builder, "        } else {\n")
+	fmt.Fprintf(builder, "            orders[idx] = %s.Asc(column)\n        }\n    }\n", pkg)
+	fmt.Fprintf(builder, "    return orders, nil\n}\n\n")
+	return builder.String()
+}
@@ -0,0 +1,169 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// renderConnectionHelpers renders the Relay argument handling shared by every connection resolver.
+func renderConnectionHelpers() string {
+	return strings.TrimSpace(`// pageWindow is the validated form of the Relay connection arguments first, after, last and before.
+type pageWindow struct {
+    limit  int
+    after  string
+    before string
+    last   bool
+}
+
+func newPageWindow(first *int, after *string, last *int, before *string) (pageWindow, error) {
+    if first != nil && last != nil {
+        return pageWindow{}, fmt.Errorf("first and last cannot be combined")
+    }
+    window := pageWindow{limit: defaultPageSize}
+    if first != nil {
+        if *first < 0 {
+            return pageWindow{}, fmt.Errorf("first must not be negative")
+        }
+        window.limit = *first
+    }
+    if last != nil {
+        if *last < 0 {
+            return pageWindow{}, fmt.Errorf("last must not be negative")
+        }
+        window.limit, window.last = *last, true
+    }
+    if after != nil {
+        window.after = *after
+    }
+    if before != nil {
+        window.before = *before
+    }
+    return window, nil
+}
+
+// trimPage drops the extra row fetched beyond the window's limit, which only tells whether more
+// rows exist in the paging direction.
+func trimPage[T any](records []T, window pageWindow) ([]T, bool) {
+    if len(records) <= window.limit {
+        return records, false
+    }
+    if window.last {
+        return records[len(records)-window.limit:], true
+    }
+    return records[:window.limit], true
+}
+
+func (w pageWindow) pageInfo(more bool, startCursor, endCursor *string) *graphql.PageInfo {
+    info := &graphql.PageInfo{StartCursor: startCursor, EndCursor: endCursor}
+    if w.last {
+        info.HasPreviousPage, info.HasNextPage = more, w.before != ""
+    } else {
+        info.HasNextPage, info.HasPreviousPage = more, w.after != ""
+    }
+    return info
+}
+
+// pageKey identifies a set of connection arguments, so that parents paged alike share an edge
+// dataloader.
+func pageKey(args ...any) string {
+    raw, err := json.Marshal(args)
+    if err != nil {
+        return fmt.Sprint(args...)
+    }
+    return string(raw)
+}
+
+// fieldSelected reports whether the current field's selection set requests name. Outside a
+// GraphQL operation every field counts as selected.
+func fieldSelected(ctx context.Context, name string) bool {
+    if !gql.HasOperationContext(ctx) || gql.GetFieldContext(ctx) == nil {
+        return true
+    }
+    for _, field := range gql.CollectAllFields(ctx) {
+        if field == name {
+            return true
+        }
+    }
+    return false
+}`) + "\n\n"
+}
+
+// renderEntityConnectionResolver renders the list field of ent and the paging helpers it shares
+// with the connection fields of edges targeting ent. Pages are read with keyset cursors, one row
+// beyond the requested size to detect further pages, and totalCount is only counted when selected.
+// The where argument narrows both the page and totalCount, and orderBy sets the sort keys the
+// cursors are built from.
+func renderEntityConnectionResolver(ent Entity) string {
+	builder := &strings.Builder{}
+	pluralName := exportName(pluralize(ent.Name))
+	pageFunc := lowerCamel(ent.Name) + "Page"
+	args := fmt.Sprintf("first *int, after *string, last *int, before *string, where *graphql.%[1]sWhereInput, orderBy []*graphql.%[1]sOrder", ent.Name)
+
+	fmt.Fprintf(builder, "func (r *queryResolver) %s(ctx context.Context, %s) (*graphql.%sConnection, error) {\n", pluralName, args, ent.Name)
+	fmt.Fprintf(builder, "    if r.ORM == nil {\n        return nil, fmt.Errorf(\"orm client is not configured\")\n    }\n")
+	if authRuleScoped(ent.Authorization.Read) {
+		fmt.Fprintf(builder, "    scope, err := %sReadScope(ctx)\n", lowerCamel(ent.Name))
+		fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+		fmt.Fprintf(builder, "    newQuery := func() *gen.%sQuery {\n", ent.Name)
+		fmt.Fprintf(builder, "        return r.ORM.%s().Query().Where(scope...)\n    }\n", pluralName)
+		fmt.Fprintf(builder, "    return r.paginate%s(ctx, newQuery, first, after, last, before, where, orderBy)\n}\n\n", pluralName)
+	} else {
+		fmt.Fprintf(builder, "    return r.paginate%[1]s(ctx, r.ORM.%[1]s().Query, first, after, last, before, where, orderBy)\n}\n\n", pluralName)
+	}
+
+	fmt.Fprintf(builder, "// paginate%s pages through the rows of newQuery, which is called once for the page and once\n", pluralName)
+	fmt.Fprintf(builder, "// more when totalCount is selected.\n")
+	fmt.Fprintf(builder, "func (r *Resolver) paginate%s(ctx context.Context, newQuery func() *gen.%sQuery, %s) (*graphql.%sConnection, error) {\n", pluralName, ent.Name, args, ent.Name)
+	fmt.Fprintf(builder, "    window, preds, query, err := %s(ctx, newQuery(), first, after, last, before, where, orderBy)\n", pageFunc)
+	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+	fmt.Fprintf(builder, "    records, err := query.All(ctx)\n")
+	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+	fmt.Fprintf(builder, "    connection, err := r.%sConnection(ctx, query, window, records)\n", lowerCamel(ent.Name))
+	fmt.Fprintf(builder, "    if err != nil {\n        return nil, err\n    }\n")
+	fmt.Fprintf(builder, "    if fieldSelected(ctx, \"totalCount\") {\n")
+	fmt.Fprintf(builder, "        total, err := newQuery().Where(preds...).Count(ctx)\n")
+	fmt.Fprintf(builder, "        if err != nil {\n            return nil, err\n        }\n")
+	fmt.Fprintf(builder, "        connection.TotalCount = total\n    }\n")
+	fmt.Fprintf(builder, "    return connection, nil\n}\n\n")
+
+	fmt.Fprintf(builder, "// %s narrows query to the page selected by the connection arguments, reading one row\n", pageFunc)
+	fmt.Fprintf(builder, "// beyond it. The where predicates are returned for counting the connection.\n")
+	fmt.Fprintf(builder, "func %s(ctx context.Context, query *gen.%sQuery, %s) (pageWindow, []%s.Predicate, *gen.%sQuery, error) {\n", pageFunc, ent.Name, args, predicatePackageName(ent), ent.Name)
+	fmt.Fprintf(builder, "    window, err := newPageWindow(first, after, last, before)\n")
+	fmt.Fprintf(builder, "    if err != nil {\n        return window, nil, nil, err\n    }\n")
+	fmt.Fprintf(builder, "    preds, err := %s(ctx, where)\n", wherePredicatesFunc(ent))
+	fmt.Fprintf(builder, "    if err != nil {\n        return window, nil, nil, err\n    }\n")
+	fmt.Fprintf(builder, "    orders, err := %s(orderBy)\n", ordersFunc(ent))
+	fmt.Fprintf(builder, "    if err != nil {\n        return window, nil, nil, err\n    }\n")
+	fmt.Fprintf(builder, "    query.\n")
+	fmt.Fprintf(builder, "        Where(preds...).\n")
+	fmt.Fprintf(builder, "        Order(orders...).\n")
+	fmt.Fprintf(builder, "        After(window.after).\n")
+	fmt.Fprintf(builder, "        Before(window.before)\n")
+	fmt.Fprintf(builder, "    if window.last {\n        query.Last(window.limit + 1)\n    } else {\n        query.Limit(window.limit + 1)\n    }\n")
+	fmt.Fprintf(builder, "    return window, preds, query, nil\n}\n\n")
+
+	fmt.Fprintf(builder, "// %sConnection builds the connection of records, the rows read for window by query.\n", lowerCamel(ent.Name))
+	fmt.Fprintf(builder, "func (r *Resolver) %[1]sConnection(ctx context.Context, query *gen.%[2]sQuery, window pageWindow, records []*gen.%[2]s) (*graphql.%[2]sConnection, error) {\n", lowerCamel(ent.Name), ent.Name)
+	fmt.Fprintf(builder, "    records, more := trimPage(records, window)\n")
+	fmt.Fprintf(builder, "    edges := make([]*graphql.%sEdge, len(records))\n", ent.Name)
+	fmt.Fprintf(builder, "    for idx, record := range records {\n")
+	fmt.Fprintf(builder, "        cursor, err := query.Cursor(record)\n")
+	fmt.Fprintf(builder, "        if err != nil {\n            return nil, err\n        }\n")
+	fmt.Fprintf(builder, "        if err := r.applyBeforeReturn%[1]s(ctx, record); err != nil {\n            return nil, err\n        }\n", ent.Name)
+	fmt.Fprintf(builder, "        r.prime%s(ctx, record)\n", ent.Name)
+	fmt.Fprintf(builder, "        edges[idx] = &graphql.%sEdge{\n", ent.Name)
+	fmt.Fprintf(builder, "            Cursor: cursor,\n")
+	fmt.Fprintf(builder, "            Node:   toGraphQL%s(record),\n", ent.Name)
+	fmt.Fprintf(builder, "        }\n    }\n")
+	fmt.Fprintf(builder, "    var startCursor, endCursor *string\n")
+	fmt.Fprintf(builder, "    if len(edges) > 0 {\n")
+	fmt.Fprintf(builder, "        startCursor = &edges[0].Cursor\n")
+	fmt.Fprintf(builder, "        endCursor = &edges[len(edges)-1].Cursor\n    }\n")
+	fmt.Fprintf(builder, "    return &graphql.%sConnection{\n", ent.Name)
+	fmt.Fprintf(builder, "        Edges:    edges,\n")
+	fmt.Fprintf(builder, "        PageInfo: window.pageInfo(more, startCursor, endCursor),\n")
+	fmt.Fprintf(builder, "    }, nil\n}\n\n")
+
+	return builder.String()
+}
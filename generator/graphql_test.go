@@ -118,7 +118,7 @@ func TestGraphQLAuthorizationDirectives(t *testing.T) {
 
 	schema := buildGraphQLGeneratedSection(entities)
 	mustContain(t, schema, "post(id: ID!): Post @auth")
-	mustContain(t, schema, "posts(first: Int, after: String, last: Int, before: String): PostConnection! @auth")
+	mustContain(t, schema, "posts(first: Int, after: String, last: Int, before: String, where: PostWhereInput, orderBy: [PostOrder!]): PostConnection! @auth")
 	mustContain(t, schema, "type Query {\n  node(id: ID!): Node\n  health: String!\n  post(")
 	mustNotContain(t, schema, "extend type Query")
 	mustContain(t, schema, "createPost(input: CreatePostInput!): CreatePostPayload! @auth(roles: [\"editor\"])\n")
@@ -133,6 +133,72 @@ func TestGraphQLAuthorizationDirectives(t *testing.T) {
 	mustNotContain(t, schema, "extend type Subscription")
 }
 
+func TestGraphQLOwnershipRules(t *testing.T) {
+	entities := []Entity{
+		{
+			Name: "Post",
+			Fields: []dsl.Field{
+				dsl.UUIDv7("id").Primary(),
+				dsl.Text("author_id"),
+				dsl.Text("tenant_id").ColumnName("tenant"),
+				dsl.Text("title"),
+				dsl.Integer("views").Authorize(dsl.AdminOnly().OrOwner("author_id")),
+				dsl.Text("notes").Optional().Authorize(dsl.RequireAuth().WhereClaim("tenant", "tenant")),
+				dsl.JSONB("meta").Authorize(dsl.AdminOnly()),
+			},
+			Annotations: []dsl.Annotation{
+				dsl.Authorization(dsl.AuthRules{
+					Create: dsl.RequireAuth().WhereClaimValue("tier", "gold"),
+					Read:   dsl.RequireAuth().WhereClaim("tenant", "tenant_id"),
+					Update: dsl.AdminOnly().OrOwner("author_id"),
+					Delete: dsl.OwnerOnly("author_id"),
+				}),
+				dsl.GraphQL("Post", dsl.GraphQLSubscriptions(dsl.SubscriptionEventUpdate, dsl.SubscriptionEventDelete)),
+			},
+		},
+	}
+	assignAuthorizationMetadata(entities)
+
+	schema := buildGraphQLGeneratedSection(entities)
+	mustContain(t, schema, "  views: Int @auth(roles: [\"admin\"], owner: \"authorID\")\n")
+	mustContain(t, schema, "  notes: String @auth @authClaim(claim: \"tenant\", field: \"tenantID\")\n")
+	mustContain(t, schema, "  meta: JSONB @auth(roles: [\"admin\"])\n")
+	mustContain(t, schema, "  title: String!\n")
+	mustContain(t, schema, "post(id: ID!): Post @auth\n")
+	mustContain(t, schema, "createPost(input: CreatePostInput!): CreatePostPayload! @auth @authClaim(claim: \"tier\", value: \"gold\")\n")
+	mustContain(t, schema, "updatePost(input: UpdatePostInput!): UpdatePostPayload! @auth\n")
+	mustContain(t, schema, "postDeleted: ID! @auth\n")
+
+	root := t.TempDir()
+	if err := writeGraphQLResolvers(root, entities, "example.com/app"); err != nil {
+		t.Fatalf("writeGraphQLResolvers: %v", err)
+	}
+	resolverSrc, err := os.ReadFile(filepath.Join(root, "graphql", "resolvers", "entities_gen.go"))
+	if err != nil {
+		t.Fatalf("read resolvers: %v", err)
+	}
+	src := string(resolverSrc)
+	mustContain(t, src, "\"example.com/app/graphql/directives\"")
+	mustContain(t, src, "var postReadRule = directives.Rule{\n\tConditions: []directives.Condition{\n\t\t{Claim: \"tenant\", Field: \"TenantID\"},\n\t},\n}")
+	mustContain(t, src, "var postUpdateRule = directives.Rule{\n\tRoles: []string{\"admin\"},\n\tOwner: \"AuthorID\",\n}")
+	mustContain(t, src, "var postDeleteRule = directives.Rule{\n\tOwner: \"AuthorID\",\n}")
+	mustNotContain(t, src, "postCreateRule")
+	mustContain(t, src, "case \"TenantID\":\n\t\t\tpreds = append(preds, post.TenantIDEq(match.Value))")
+	mustContain(t, src, "return r.ORM.Posts().Query().Where(scope...)")
+	mustContain(t, src, "if record != nil {\n\t\tif err := directives.Authorize(ctx, postReadRule, record); err != nil {")
+	mustContain(t, src, "if err := r.authorizePost(ctx, client, nativeID, postUpdateRule); err != nil {")
+	mustContain(t, src, "if err := r.authorizePost(ctx, r.ORM, nativeID, postDeleteRule); err != nil {")
+	mustContain(t, src, "if directives.Authorize(ctx, postUpdateRule, obj) != nil {")
+	mustContain(t, src, "if directives.Authorize(ctx, postDeleteRule, nil) != nil {")
+	mustContain(t, src, "Views:    toGraphQLPtr(int(record.Views)),")
+	mustContain(t, src, "Notes:    record.Notes,")
+	mustContain(t, src, "Meta:     record.Meta,")
+	mustContain(t, src, "func toGraphQLPtr[T any](value T) *T {")
+	if _, err := parser.ParseFile(token.NewFileSet(), "entities_gen.go", resolverSrc, parser.AllErrors); err != nil {
+		t.Fatalf("resolvers parse: %v", err)
+	}
+}
+
 func TestGraphQLResolverGeneration(t *testing.T) {
 	entities := []Entity{{
 		Name: "Widget",
@@ -175,6 +241,7 @@ func TestGraphQLResolverGeneration(t *testing.T) {
 		"type entityHooks struct",
 		"applyBeforeCreateWidget",
 		"applyBeforeReturnWidget",
+		"r.clearWidget(ctx, nativeID)",
 		modulePath + "/graphql",
 		modulePath + "/graphql/dataloaders",
 		"\"math\"",
@@ -215,7 +282,9 @@ func TestGraphQLResolverGeneration(t *testing.T) {
 	loaderExpectations := []string{
 		"configureEntityLoaders",
 		"func (l *Loaders) Widget()",
-		"orm.Widgets().ByID",
+		"orm.Widgets().Query().Where(widget.IDIn(keys...)).Limit(len(keys)).All(ctx)",
+		"results[record.ID] = record",
+		modulePath + "/orm/gen/widget",
 		modulePath + "/observability/metrics",
 	}
 	for _, needle := range loaderExpectations {
@@ -265,6 +334,386 @@ func TestGraphQLInitialismHandling(t *testing.T) {
 	mustContain(t, string(resolverSrc), "model.AvatarURL")
 	mustContain(t, string(resolverSrc), "input.APIToken")
 	mustContain(t, string(resolverSrc), "model.APIToken")
+	mustContain(t, string(resolverSrc), `if input.AvatarURL != nil || !reflect.DeepEqual(before.AvatarURL, model.AvatarURL) {`)
+	mustContain(t, string(resolverSrc), "UpdateOneID(nativeID).SetFrom(model, fields...).Save(ctx)")
+}
+
+func TestGraphQLExpectedVersion(t *testing.T) {
+	entities := []Entity{{
+		Name: "Post",
+		Fields: []dsl.Field{
+			dsl.UUIDv7("id").Primary(),
+			dsl.String("title"),
+			dsl.Integer("version").Version(),
+		},
+	}}
+
+	schema := buildGraphQLGeneratedSection(entities)
+	mustContain(t, schema, "input UpdatePostInput {\n  clientMutationId: String\n  id: ID!\n  title: String\n  expectedVersion: Int\n}")
+
+	root := t.TempDir()
+	if err := writeGraphQLResolvers(root, entities, "example.com/app"); err != nil {
+		t.Fatalf("writeGraphQLResolvers: %v", err)
+	}
+	resolverSrc, err := os.ReadFile(filepath.Join(root, "graphql", "resolvers", "entities_gen.go"))
+	if err != nil {
+		t.Fatalf("read resolvers: %v", err)
+	}
+	mustContain(t, string(resolverSrc), "update.ExpectVersion(int32(*input.ExpectedVersion))")
+	mustContain(t, string(resolverSrc), "record, err := update.Save(ctx)")
+}
+
+func TestGraphQLConnectionPagination(t *testing.T) {
+	entities := []Entity{{
+		Name: "Post",
+		Fields: []dsl.Field{
+			dsl.UUIDv7("id").Primary(),
+			dsl.String("title"),
+		},
+	}}
+
+	root := t.TempDir()
+	if err := writeGraphQLResolvers(root, entities, "example.com/app"); err != nil {
+		t.Fatalf("writeGraphQLResolvers: %v", err)
+	}
+	resolverSrc, err := os.ReadFile(filepath.Join(root, "graphql", "resolvers", "entities_gen.go"))
+	if err != nil {
+		t.Fatalf("read resolvers: %v", err)
+	}
+	src := string(resolverSrc)
+	mustContain(t, src, "\"example.com/app/orm/gen/post\"")
+	mustContain(t, src, "window, err := newPageWindow(first, after, last, before)")
+	mustContain(t, src, "Order(orders...).")
+	mustContain(t, src, "total, err := newQuery().Where(preds...).Count(ctx)")
+	mustContain(t, src, "query.Last(window.limit + 1)")
+	mustContain(t, src, "cursor, err := query.Cursor(record)")
+	mustContain(t, src, "PageInfo: window.pageInfo(more, startCursor, endCursor),")
+	mustContain(t, src, "if fieldSelected(ctx, \"totalCount\") {")
+}
+
+func TestGraphQLWhereInputs(t *testing.T) {
+	entities := []Entity{
+		{
+			Name: "User",
+			Fields: []dsl.Field{
+				dsl.UUIDv7("id").Primary(),
+				dsl.String("email"),
+			},
+		},
+		{
+			Name: "Post",
+			Fields: []dsl.Field{
+				dsl.UUIDv7("id").Primary(),
+				dsl.String("title"),
+				dsl.Integer("views"),
+				dsl.TimestampTZ("published_at").Optional(),
+				dsl.UUIDv7("author_id"),
+			},
+			Edges: []dsl.Edge{
+				dsl.ToOne("author", "User").Field("author_id"),
+			},
+			Annotations: []dsl.Annotation{
+				dsl.GraphQL("Post", dsl.GraphQLFilterable("title", "views", "published_at", "author")),
+			},
+		},
+	}
+
+	schema := buildGraphQLGeneratedSection(entities)
+	mustContain(t, schema, "posts(first: Int, after: String, last: Int, before: String, where: PostWhereInput, orderBy: [PostOrder!]): PostConnection!")
+	mustContain(t, schema, "input PostWhereInput {\n  not: PostWhereInput\n  and: [PostWhereInput!]\n  or: [PostWhereInput!]\n")
+	mustContain(t, schema, "  titleIn: [String!]\n")
+	mustContain(t, schema, "  titleContains: String\n")
+	mustContain(t, schema, "  viewsGte: Int\n")
+	mustContain(t, schema, "  publishedAtIsNull: Boolean\n")
+	mustContain(t, schema, "  hasAuthorWith: [UserWhereInput!]\n")
+	mustContain(t, schema, "input UserWhereInput {")
+	mustContain(t, schema, "  emailContains: String\n")
+	mustNotContain(t, schema, "authorIdEq")
+	mustNotContain(t, schema, "  authorId: ID\n")
+
+	root := t.TempDir()
+	if err := writeGraphQLResolvers(root, entities, "example.com/app"); err != nil {
+		t.Fatalf("writeGraphQLResolvers: %v", err)
+	}
+	resolverSrc, err := os.ReadFile(filepath.Join(root, "graphql", "resolvers", "entities_gen.go"))
+	if err != nil {
+		t.Fatalf("read resolvers: %v", err)
+	}
+	src := string(resolverSrc)
+	mustContain(t, src, "func postWherePredicates(ctx context.Context, where *graphql.PostWhereInput) ([]post.Predicate, error) {")
+	mustContain(t, src, "preds = append(preds, post.Not(post.And(not...)))")
+	mustContain(t, src, "preds = append(preds, post.TitleIn(where.TitleIn...))")
+	mustContain(t, src, "preds = append(preds, post.PublishedAtNotNull())")
+	mustContain(t, src, "target, err := userWherePredicates(ctx, item)")
+	mustContain(t, src, "preds = append(preds, post.HasAuthorWith(with...))")
+	mustContain(t, src, "preds, err := postWherePredicates(ctx, where)")
+}
+
+func TestGraphQLOrderInputs(t *testing.T) {
+	entities := []Entity{{
+		Name: "Post",
+		Fields: []dsl.Field{
+			dsl.UUIDv7("id").Primary(),
+			dsl.String("title"),
+			dsl.Integer("views"),
+			dsl.TimestampTZ("published_at").Optional(),
+			dsl.TimestampTZ("created_at"),
+		},
+		Query: dsl.Query().WithOrders(dsl.OrderBy("created_at", dsl.SortDesc)),
+		Annotations: []dsl.Annotation{
+			dsl.GraphQL("Post", dsl.GraphQLOrderable("views", "published_at")),
+		},
+	}}
+
+	schema := buildGraphQLGeneratedSection(entities)
+	mustContain(t, schema, "posts(first: Int, after: String, last: Int, before: String, where: PostWhereInput, orderBy: [PostOrder!]): PostConnection!")
+	mustContain(t, schema, "enum PostOrderField {\n  ID\n  VIEWS\n  CREATED_AT\n}\n")
+	mustContain(t, schema, "input PostOrder {\n  field: PostOrderField!\n  direction: OrderDirection! = ASC\n}\n")
+
+	root := t.TempDir()
+	if err := writeGraphQLResolvers(root, entities, "example.com/app"); err != nil {
+		t.Fatalf("writeGraphQLResolvers: %v", err)
+	}
+	resolverSrc, err := os.ReadFile(filepath.Join(root, "graphql", "resolvers", "entities_gen.go"))
+	if err != nil {
+		t.Fatalf("read resolvers: %v", err)
+	}
+	src := string(resolverSrc)
+	mustContain(t, src, "func postOrders(orderBy []*graphql.PostOrder) ([]post.Order, error) {")
+	mustContain(t, src, "return []post.Order{post.Asc(post.FieldID)}, nil")
+	mustContain(t, src, "case \"CREATED_AT\":\n            column = post.FieldCreatedAt")
+	mustNotContain(t, src, "case \"TITLE\":")
+	mustNotContain(t, src, "case \"PUBLISHED_AT\":")
+	mustContain(t, src, "orders[idx] = post.Desc(column)")
+	mustContain(t, src, "orders, err := postOrders(orderBy)")
+}
+
+func TestGraphQLAggregateField(t *testing.T) {
+	status := dsl.Enum("status", "DRAFT", "PUBLISHED")
+	status.EnumName = "PostStatus"
+	entities := []Entity{
+		{
+			Name: "Post",
+			Fields: []dsl.Field{
+				dsl.UUIDv7("id").Primary(),
+				status,
+				dsl.Integer("views"),
+				dsl.TimestampTZ("published_at").Optional(),
+				dsl.JSONB("meta"),
+				dsl.Text("title").ColumnName("headline"),
+			},
+			Annotations: []dsl.Annotation{dsl.GraphQL("Post", dsl.GraphQLAggregate())},
+		},
+		{
+			Name:        "Tag",
+			Fields:      []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.String("name"), dsl.Integer("uses")},
+			Annotations: []dsl.Annotation{dsl.GraphQL("Tag", dsl.GraphQLAggregate("uses"))},
+		},
+		{
+			Name:   "User",
+			Fields: []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.String("email")},
+		},
+	}
+
+	schema := buildGraphQLGeneratedSection(entities)
+	mustContain(t, schema, "postAggregate(where: PostWhereInput, groupBy: [PostAggregateField!]): [PostAggregateBucket!]!")
+	mustContain(t, schema, "enum PostAggregateField {\n  STATUS\n  VIEWS\n  PUBLISHED_AT\n}\n")
+	mustContain(t, schema, "type PostAggregateBucket {\n  status: PostStatus\n  views: Int\n  publishedAt: Timestamptz\n  count: Int!\n  sumViews: Float\n  avgViews: Float\n  minViews: Int\n  maxViews: Int\n  minPublishedAt: Timestamptz\n  maxPublishedAt: Timestamptz\n}\n")
+	mustContain(t, schema, "enum TagAggregateField {\n  USES\n}\n")
+	mustNotContain(t, schema, "NAME\n")
+	mustNotContain(t, schema, "userAggregate")
+
+	root := t.TempDir()
+	if err := writeGraphQLResolvers(root, entities, "example.com/app"); err != nil {
+		t.Fatalf("writeGraphQLResolvers: %v", err)
+	}
+	resolverSrc, err := os.ReadFile(filepath.Join(root, "graphql", "resolvers", "entities_gen.go"))
+	if err != nil {
+		t.Fatalf("read resolvers: %v", err)
+	}
+	src := string(resolverSrc)
+	mustContain(t, src, "func (r *queryResolver) PostAggregate(ctx context.Context, where *graphql.PostWhereInput, groupBy []graphql.PostAggregateField) ([]*graphql.PostAggregateBucket, error) {")
+	mustContain(t, src, "case \"STATUS\":\n            columns[idx] = post.FieldStatus")
+	mustContain(t, src, "if fieldSelected(ctx, \"sumViews\") {\n        aggregates = append(aggregates, post.Sum(post.FieldViews))\n    }")
+	mustContain(t, src, "if fieldSelected(ctx, \"maxPublishedAt\") {\n        aggregates = append(aggregates, post.Max(post.FieldPublishedAt))\n    }")
+	mustContain(t, src, "if len(aggregates) == 0 || fieldSelected(ctx, \"count\") {\n        aggregates = append(aggregates, post.Count())\n    }")
+	mustContain(t, src, "        GroupBy(columns...).\n        Order(orders...).\n        Aggregate(aggregates...).\n        Scan(ctx, &buckets)")
+	mustNotContain(t, src, "post.FieldMeta)")
+	mustNotContain(t, src, "post.FieldTitle)")
+	mustNotContain(t, src, "UserAggregate")
+}
+
+func TestGraphQLEdgeFields(t *testing.T) {
+	entities := []Entity{
+		{
+			Name:   "User",
+			Fields: []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.String("email")},
+			Edges:  []dsl.Edge{dsl.ToMany("posts", "Post").Ref("author_id")},
+		},
+		{
+			Name:   "Post",
+			Fields: []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.UUIDv7("author_id"), dsl.String("title")},
+			Edges: []dsl.Edge{
+				dsl.ToOne("author", "User").Field("author_id"),
+				dsl.ManyToMany("tags", "Tag"),
+			},
+		},
+		{
+			Name:   "Comment",
+			Fields: []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.UUIDv7("subject_id").Optional()},
+			Edges: []dsl.Edge{
+				dsl.ToOne("subject", "Post").Field("subject_id").Optional().Polymorphic(
+					dsl.PolymorphicTarget("Post", "subject_type = 'post'"),
+					dsl.PolymorphicTarget("Tag", "subject_type = 'tag'"),
+				),
+			},
+		},
+		{
+			Name:   "Tag",
+			Fields: []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.String("name")},
+		},
+	}
+
+	schema := buildGraphQLGeneratedSection(entities)
+	mustContain(t, schema, "  author: User @goField(forceResolver: true)\n")
+	mustContain(t, schema, "  tags(first: Int, after: String, last: Int, before: String, where: TagWhereInput, orderBy: [TagOrder!]): TagConnection! @goField(forceResolver: true)\n")
+	mustContain(t, schema, "  posts(first: Int, after: String, last: Int, before: String, where: PostWhereInput, orderBy: [PostOrder!]): PostConnection! @goField(forceResolver: true)\n")
+	mustContain(t, schema, "  subject: CommentSubject @goField(forceResolver: true)\n")
+	mustContain(t, schema, "union CommentSubject = Post | Tag\n")
+
+	root := t.TempDir()
+	if err := writeGraphQLResolvers(root, entities, "example.com/app"); err != nil {
+		t.Fatalf("writeGraphQLResolvers: %v", err)
+	}
+	resolverSrc, err := os.ReadFile(filepath.Join(root, "graphql", "resolvers", "entities_gen.go"))
+	if err != nil {
+		t.Fatalf("read resolvers: %v", err)
+	}
+	src := string(resolverSrc)
+	mustContain(t, src, "func (r *Resolver) Post() graphql.PostResolver { return &postResolver{r} }")
+	mustNotContain(t, src, "func (r *Resolver) Tag() graphql.TagResolver")
+	mustContain(t, src, "func (r *postResolver) Author(ctx context.Context, obj *graphql.Post) (*graphql.User, error) {")
+	mustContain(t, src, "record, err := r.loadUser(ctx, fk)")
+	mustContain(t, src, "return r.ORM.Posts().QueryTags(parent)")
+	mustContain(t, src, "return r.paginateTags(ctx, newQuery, first, after, last, before, where, orderBy)")
+	mustContain(t, src, "return r.paginateTags(ctx, r.ORM.Tags().Query, first, after, last, before, where, orderBy)")
+	mustContain(t, src, "if loader := loaders.PostTags(pageKey(first, after, last, before, where, orderBy, count), query, count); loader != nil {")
+	mustContain(t, src, "func tagPage(ctx context.Context, query *gen.TagQuery, ")
+	mustContain(t, src, "func (r *commentResolver) Subject(ctx context.Context, obj *graphql.Comment) (graphql.CommentSubject, error) {")
+	mustContain(t, src, "tagRecord, err := r.loadTag(ctx, fk)")
+
+	if err := writeGraphQLDataloaders(root, entities, "example.com/app"); err != nil {
+		t.Fatalf("writeGraphQLDataloaders: %v", err)
+	}
+	loaderSrc, err := os.ReadFile(filepath.Join(root, "graphql", "dataloaders", "entities_gen.go"))
+	if err != nil {
+		t.Fatalf("read dataloaders: %v", err)
+	}
+	mustContain(t, string(loaderSrc), "func (l *Loaders) PostTags(key string, query *gen.TagQuery, count bool) *EntityLoader[string, *EdgePage[*gen.Tag]] {")
+	mustContain(t, string(loaderSrc), "if err := l.orm.Posts().LoadTagsPage(ctx, query, parents...); err != nil {")
+	mustContain(t, string(loaderSrc), "totals, err = l.orm.Posts().CountTags(ctx, query, parents...)")
+	mustNotContain(t, string(loaderSrc), "func (l *Loaders) PostAuthor(")
+}
+
+func TestGraphQLNestedMutations(t *testing.T) {
+	entities := []Entity{
+		{
+			Name:   "User",
+			Fields: []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.String("email")},
+		},
+		{
+			Name:   "Post",
+			Fields: []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.UUIDv7("author_id"), dsl.String("title")},
+			Edges: []dsl.Edge{
+				dsl.ToOne("author", "User").Field("author_id"),
+				dsl.ToMany("comments", "Comment"),
+				dsl.ManyToMany("tags", "Tag"),
+			},
+		},
+		{
+			Name:   "Comment",
+			Fields: []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.UUIDv7("post_id"), dsl.Text("body")},
+			Edges:  []dsl.Edge{dsl.ToOne("post", "Post").Field("post_id")},
+			Annotations: []dsl.Annotation{
+				dsl.Authorization(dsl.AuthRules{Create: dsl.RequireRole("commenter")}),
+			},
+		},
+		{
+			Name:   "Tag",
+			Fields: []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.String("name"), dsl.String("owner_id")},
+			Annotations: []dsl.Annotation{
+				dsl.Authorization(dsl.AuthRules{Update: dsl.OwnerOnly("owner_id")}),
+			},
+		},
+	}
+	assignAuthorizationMetadata(entities)
+
+	schema := buildGraphQLGeneratedSection(entities)
+	mustContain(t, schema, "input CreatePostInput {\n  clientMutationId: String\n  id: ID\n  authorID: ID\n  title: String\n  addCommentIDs: [ID!]\n  createComments: [CreateCommentInput!]\n  addTagIDs: [ID!]\n}\n")
+	mustContain(t, schema, "  addTagIDs: [ID!]\n  removeTagIDs: [ID!]\n  clearTags: Boolean\n}\n")
+	mustNotContain(t, schema, "removeCommentIDs")
+
+	root := t.TempDir()
+	if err := writeGraphQLResolvers(root, entities, "example.com/app"); err != nil {
+		t.Fatalf("writeGraphQLResolvers: %v", err)
+	}
+	resolverSrc, err := os.ReadFile(filepath.Join(root, "graphql", "resolvers", "entities_gen.go"))
+	if err != nil {
+		t.Fatalf("read resolvers: %v", err)
+	}
+	src := string(resolverSrc)
+	mustContain(t, src, "if err := r.ORM.Tx(ctx, func(tx *gen.Tx) error {")
+	mustContain(t, src, "record, err = r.createPost(ctx, tx.Client, input)")
+	mustContain(t, src, "record, err := r.createTag(ctx, r.ORM, input)")
+	mustContain(t, src, "authorID, err := decodeUserID(*input.AuthorID)")
+	mustContain(t, src, "if input.AuthorID != nil || !reflect.DeepEqual(before.AuthorID, model.AuthorID) {")
+	mustContain(t, src, "ids, err := decodeIDs(input.RemoveTagIDs, decodeTagID)")
+	mustContain(t, src, "if err := client.Posts().ClearTags(ctx, record); err != nil {")
+	mustContain(t, src, "parentID := relay.ToGlobalID(\"Post\", record.ID)\n        childInput.PostID = &parentID")
+	mustContain(t, src, "var commentCreateRule = directives.Rule{\n\tRoles: []string{\"commenter\"},\n}")
+	mustContain(t, src, "for i, child := range input.CreateComments {")
+	mustContain(t, src, "childInput := *child\n        if err := directives.Authorize(ctx, commentCreateRule, childInput); err != nil {")
+	mustContain(t, src, "if _, err := r.createComment(ctx, client, childInput); err != nil {")
+	mustContain(t, src, "return nil, wrapNestedInputError(err, commentInputFields, \"createComments\", i)")
+	mustNotContain(t, src, "tagCreateRule")
+	mustContain(t, src, "ids, err := decodeIDs(input.AddTagIDs, decodeTagID)\n        if err != nil {\n            return nil, err\n        }\n        for _, id := range ids {\n            if err := r.authorizeTag(ctx, client, id, tagUpdateRule); err != nil {")
+	mustContain(t, src, "ids, err := decodeIDs(input.RemoveTagIDs, decodeTagID)\n        if err != nil {\n            return nil, err\n        }\n        for _, id := range ids {\n            if err := r.authorizeTag(ctx, client, id, tagUpdateRule); err != nil {")
+	mustNotContain(t, src, "r.authorizeComment(")
+	mustContain(t, src, "func decodeIDs(ids []string, decode func(string) (string, error)) ([]string, error) {")
+}
+
+func TestGraphQLMutationUserErrors(t *testing.T) {
+	entities := []Entity{
+		{
+			Name:   "User",
+			Fields: []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.String("email").Unique()},
+		},
+		{
+			Name:   "Post",
+			Fields: []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.UUIDv7("author_id"), dsl.String("title")},
+			Edges:  []dsl.Edge{dsl.ToOne("author", "User").Field("author_id")},
+		},
+	}
+
+	schema := buildGraphQLGeneratedSection(entities)
+	mustContain(t, schema, "type CreatePostPayload {\n  clientMutationId: String\n  post: Post\n  userErrors: [UserError!]!\n}\n")
+	mustContain(t, schema, "type UpdateUserPayload {\n  clientMutationId: String\n  user: User\n  userErrors: [UserError!]!\n}\n")
+	mustContain(t, graphqlBaseSchema, "type UserError {\n  field: [String!]\n  code: String!\n  message: String!\n}\n")
+
+	root := t.TempDir()
+	if err := writeGraphQLResolvers(root, entities, "example.com/app"); err != nil {
+		t.Fatalf("writeGraphQLResolvers: %v", err)
+	}
+	resolverSrc, err := os.ReadFile(filepath.Join(root, "graphql", "resolvers", "entities_gen.go"))
+	if err != nil {
+		t.Fatalf("read resolvers: %v", err)
+	}
+	src := string(resolverSrc)
+	mustContain(t, src, "var postInputFields = map[string]string{")
+	mustContain(t, src, "\"AuthorID\":  \"authorID\",\n\t\"author_id\": \"authorID\",")
+	mustContain(t, src, "userErrors, err := r.mutationFailure(err, postInputFields)")
+	mustContain(t, src, "return &graphql.UpdateUserPayload{ClientMutationID: input.ClientMutationID, UserErrors: userErrors}, nil")
+	mustContain(t, src, "UserErrors:       []*graphql.UserError{},")
 }
 
 func TestWriteGraphQLArtifactsEnsuresScalarHelpers(t *testing.T) {
@@ -328,6 +777,7 @@ func TestRunGQLGenWithStubbedRuntime(t *testing.T) {
 	if err := writeGraphQLArtifacts(root, entities, modulePath); err != nil {
 		t.Fatalf("writeGraphQLArtifacts: %v", err)
 	}
+	writeGeneratedORM(t, root, entities)
 
 	wd, err := os.Getwd()
 	if err != nil {
@@ -391,7 +841,7 @@ func TestRunGQLGenRespectsRegisteredScalarHelpers(t *testing.T) {
 	}
 
 	writeGraphQLDataloaderRuntime(t, root, modulePath)
-	writeORMEntityStubs(t, root, entities)
+	writeGeneratedORM(t, root, entities)
 
 	wd, err := os.Getwd()
 	if err != nil {
@@ -461,7 +911,7 @@ func TestRunGQLGenRespectsRegisteredScalarHelpers(t *testing.T) {
 	if err != nil {
 		t.Fatalf("read resolver runtime: %v", err)
 	}
-	if !strings.Contains(string(resolverSrc), "hooks         entityHooks") {
+	if !strings.Contains(strings.Join(strings.Fields(string(resolverSrc)), " "), "hooks entityHooks") {
 		t.Fatalf("unexpected resolver runtime content\n%s", resolverSrc)
 	}
 
@@ -500,7 +950,7 @@ func TestGraphQLJSONBScalarGeneration(t *testing.T) {
 	}
 
 	writeGraphQLDataloaderRuntime(t, root, modulePath)
-	writeORMEntityStubs(t, root, entities)
+	writeGeneratedORM(t, root, entities)
 
 	wd, err := os.Getwd()
 	if err != nil {
@@ -571,6 +1021,191 @@ func TestGraphQLJSONBScalarGeneration(t *testing.T) {
 	}
 }
 
+const edgeReadRulesTest = `package resolvers_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"example.com/app/graphql"
+	"example.com/app/graphql/relay"
+	"example.com/app/graphql/resolvers"
+	"example.com/app/oidc"
+	"example.com/app/orm/gen"
+	"github.com/deicod/erm/orm/pg"
+)
+
+type mockPool struct{ pgxmock.PgxConnIface }
+
+func (m *mockPool) Close() { _ = m.PgxConnIface.Close(context.Background()) }
+
+func TestEdgesEnforceReadRules(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	resolver := resolvers.New(gen.NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}}))
+	ctx := oidc.ToContext(context.Background(), oidc.Claims{Subject: "u2"})
+	comment := &graphql.Comment{ID: relay.ToGlobalID("Comment", "c1")}
+	subjectID := "p1"
+	commentRows := func() *pgxmock.Rows {
+		return mock.NewRows([]string{"id", "post_id", "subject_id"}).AddRow("c1", "p1", &subjectID)
+	}
+	postRows := func() *pgxmock.Rows {
+		return mock.NewRows([]string{"id", "author_id", "title"}).AddRow("p1", "u1", "draft")
+	}
+
+	mock.ExpectQuery("FROM comments WHERE id = \\$1").WithArgs("c1").WillReturnRows(commentRows())
+	mock.ExpectQuery("FROM posts WHERE id = \\$1").WithArgs("p1").WillReturnRows(postRows())
+	if post, err := resolver.Comment().Post(ctx, comment); err == nil || !strings.Contains(err.Error(), "not the owner") {
+		t.Fatalf("expected the post of another owner to be rejected, got %v, %v", post, err)
+	}
+
+	mock.ExpectQuery("FROM comments WHERE id = \\$1").WithArgs("c1").WillReturnRows(commentRows())
+	mock.ExpectQuery("FROM posts WHERE id = \\$1").WithArgs("p1").WillReturnRows(postRows())
+	if subject, err := resolver.Comment().Subject(ctx, comment); err == nil || !strings.Contains(err.Error(), "not the owner") {
+		t.Fatalf("expected the subject of another owner to be rejected, got %v, %v", subject, err)
+	}
+
+	mock.ExpectQuery("FROM posts WHERE author_id = \\$1 AND author_id = \\$2").
+		WithArgs("u1", "u2", pgxmock.AnyArg()).
+		WillReturnRows(mock.NewRows([]string{"id", "author_id", "title"}))
+	mock.ExpectQuery("COUNT.* FROM posts WHERE author_id = \\$1 AND author_id = \\$2").
+		WithArgs("u1", "u2").
+		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(int64(0)))
+	user := &graphql.User{ID: relay.ToGlobalID("User", "u1")}
+	if _, err := resolver.User().Posts(ctx, user, nil, nil, nil, nil, nil, nil); err != nil {
+		t.Fatalf("posts: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestWhereEdgesApplyReadScope(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	resolver := resolvers.New(gen.NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}}))
+	ctx := oidc.ToContext(context.Background(), oidc.Claims{Subject: "u2"})
+	title := "draft"
+	where := &graphql.UserWhereInput{HasPostsWith: []*graphql.PostWhereInput{{Title: &title}}}
+
+	// The draft post of u1 is hidden from u2, so it must not make u1 match.
+	mock.ExpectQuery("FROM users WHERE EXISTS \\(SELECT 1 FROM posts AS e_posts WHERE e_posts.author_id = users.id AND e_posts.author_id = \\$1 AND e_posts.title = \\$2\\)").
+		WithArgs("u2", "draft", pgxmock.AnyArg()).
+		WillReturnRows(mock.NewRows([]string{"id", "email"}))
+	mock.ExpectQuery("COUNT.* FROM users WHERE EXISTS \\(SELECT 1 FROM posts AS e_posts WHERE e_posts.author_id = users.id AND e_posts.author_id = \\$1 AND e_posts.title = \\$2\\)").
+		WithArgs("u2", "draft").
+		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(int64(0)))
+	connection, err := resolver.Query().Users(ctx, nil, nil, nil, nil, where, nil)
+	if err != nil {
+		t.Fatalf("users: %v", err)
+	}
+	if len(connection.Edges) != 0 || connection.TotalCount != 0 {
+		t.Fatalf("expected no users to match hidden posts, got %+v", connection)
+	}
+
+	hasPosts := true
+	if _, err := resolver.Query().Users(context.Background(), nil, nil, nil, nil, &graphql.UserWhereInput{HasPosts: &hasPosts}, nil); err == nil || !strings.Contains(err.Error(), "unauthorized") {
+		t.Fatalf("expected an anonymous hasPosts filter to be rejected, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+`
+
+func TestRunGQLGenEdgesEnforceReadRules(t *testing.T) {
+	root := t.TempDir()
+	modulePath := "example.com/app"
+
+	goMod := "module " + modulePath + "\n\ngo 1.21\n\nrequire (\n\tgithub.com/99designs/gqlgen v0.17.80\n\tgithub.com/vektah/gqlparser/v2 v2.5.30\n)\n"
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte(goMod), 0o644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+
+	entities := []Entity{
+		{
+			Name:   "User",
+			Fields: []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.String("email")},
+			Edges:  []dsl.Edge{dsl.ToMany("posts", "Post").Ref("author_id")},
+		},
+		{
+			Name:   "Post",
+			Fields: []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.UUIDv7("author_id"), dsl.String("title")},
+			Annotations: []dsl.Annotation{
+				dsl.Authorization(dsl.AuthRules{Read: dsl.AdminOnly().OrOwner("author_id")}),
+			},
+		},
+		{
+			Name:   "Comment",
+			Fields: []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.UUIDv7("post_id"), dsl.UUIDv7("subject_id").Optional()},
+			Edges: []dsl.Edge{
+				dsl.ToOne("post", "Post").Field("post_id"),
+				dsl.ToOne("subject", "Post").Field("subject_id").Optional().Polymorphic(
+					dsl.PolymorphicTarget("Post", "subject_type = 'post'"),
+				),
+			},
+		},
+	}
+	assignAuthorizationMetadata(entities)
+
+	if err := writeGraphQLArtifacts(root, entities, modulePath); err != nil {
+		t.Fatalf("writeGraphQLArtifacts: %v", err)
+	}
+	writeGraphQLDataloaderRuntime(t, root, modulePath)
+	writeGeneratedORM(t, root, entities)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("get wd: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	if err := os.Chdir(root); err != nil {
+		t.Fatalf("chdir temp project: %v", err)
+	}
+
+	testkit.ScaffoldGraphQLRuntime(t, root, modulePath)
+	if err := os.Remove(filepath.Join(root, "graphql", "graphql.go")); err != nil && !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("remove placeholder graphql.go: %v", err)
+	}
+	writeGraphQLResolverRuntime(t, root, modulePath)
+	writeGraphQLRelayRuntime(t, root)
+
+	tidy := exec.Command("go", "mod", "tidy")
+	tidy.Dir = root
+	if output, err := tidy.CombinedOutput(); err != nil {
+		t.Fatalf("go mod tidy: %v\n%s", err, output)
+	}
+	if err := runGQLGen(root); err != nil {
+		t.Fatalf("runGQLGen: %v", err)
+	}
+	resolverSrc, err := os.ReadFile(filepath.Join(root, "graphql", "resolvers", "entities_gen.go"))
+	if err != nil {
+		t.Fatalf("read resolvers: %v", err)
+	}
+	mustContain(t, string(resolverSrc), "window, _, query, err := postPage(ctx, r.ORM.Posts().Query().Where(scope...), first, after, last, before, where, orderBy)")
+	mustContain(t, string(resolverSrc), "if loader := loaders.UserPosts(pageKey(scope, first, after, last, before, where, orderBy, count), query, count); loader != nil {")
+	mustContain(t, string(resolverSrc), "preds = append(preds, user.HasPostsWith(with...))")
+	if err := os.WriteFile(filepath.Join(root, "graphql", "resolvers", "edges_test.go"), []byte(edgeReadRulesTest), 0o644); err != nil {
+		t.Fatalf("write resolver test: %v", err)
+	}
+
+	for _, args := range [][]string{{"mod", "tidy"}, {"test", "./graphql/resolvers/"}} {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = root
+		cmd.Env = append(os.Environ(), "GOWORK=off")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go %s: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+}
+
 func mustNotContain(t *testing.T, content, needle string) {
 	t.Helper()
 	if strings.Contains(content, needle) {
@@ -662,158 +1297,45 @@ func writeGraphQLDataloaderRuntime(t *testing.T, root, modulePath string) {
 	}
 }
 
-func writeORMEntityStubs(t *testing.T, root string, entities []Entity) {
+// writeGeneratedORM writes the ORM packages generated for entities, which the generated resolvers
+// import, and points github.com/deicod/erm at this repository in the go.mod of root.
+func writeGeneratedORM(t *testing.T, root string, entities []Entity) {
 	t.Helper()
 
-	if len(entities) == 0 {
-		return
-	}
-
-	builder := &strings.Builder{}
-	builder.WriteString("package gen\n\n")
-
-	imports := map[string]struct{}{"context": {}}
-	for _, ent := range entities {
-		for _, field := range ent.Fields {
-			goType := defaultGoType(field)
-			if strings.Contains(goType, "time.") {
-				imports["time"] = struct{}{}
-			}
-			if strings.Contains(goType, "json.RawMessage") {
-				imports["encoding/json"] = struct{}{}
-			}
-		}
+	if err := writeORMArtifacts(root, entities); err != nil {
+		t.Fatalf("writeORMArtifacts: %v", err)
 	}
-	if len(imports) > 0 {
-		builder.WriteString("import (\n")
-		keys := make([]string, 0, len(imports))
-		for key := range imports {
-			keys = append(keys, key)
-		}
-		sort.Strings(keys)
-		for _, key := range keys {
-			fmt.Fprintf(builder, "    \"%s\"\n", key)
-		}
-		builder.WriteString(")\n\n")
+	repoRoot, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatalf("repo root: %v", err)
 	}
-
-	for _, ent := range entities {
-		plural := exportName(pluralize(ent.Name))
-		fmt.Fprintf(builder, "type %s struct {\n", ent.Name)
-		for _, field := range ent.Fields {
-			fmt.Fprintf(builder, "    %s %s\n", exportName(field.Name), defaultGoType(field))
-		}
-		builder.WriteString("}\n\n")
-		fmt.Fprintf(builder, "type %sClient struct{}\n\n", ent.Name)
-		fmt.Fprintf(builder, "func (c *%sClient) ByID(context.Context, string) (*%s, error) { return nil, nil }\n\n", ent.Name, ent.Name)
-		fmt.Fprintf(builder, "func (c *%sClient) Count(context.Context) (int, error) { return 0, nil }\n\n", ent.Name)
-		fmt.Fprintf(builder, "func (c *%sClient) List(context.Context, int, int) ([]*%s, error) { return nil, nil }\n\n", ent.Name, ent.Name)
-		fmt.Fprintf(builder, "func (c *%sClient) Create(context.Context, *%s) (*%s, error) { return nil, nil }\n\n", ent.Name, ent.Name, ent.Name)
-		fmt.Fprintf(builder, "func (c *%sClient) Update(context.Context, *%s) (*%s, error) { return nil, nil }\n\n", ent.Name, ent.Name, ent.Name)
-		fmt.Fprintf(builder, "func (c *%sClient) Delete(context.Context, string) error { return nil }\n\n", ent.Name)
-		fmt.Fprintf(builder, "func (c *Client) %s() *%sClient { return &%sClient{} }\n\n", plural, ent.Name, ent.Name)
-	}
-
-	path := filepath.Join(root, "orm", "gen", "entities_stub.go")
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		t.Fatalf("mkdir orm entity stubs: %v", err)
+	goMod, err := os.OpenFile(filepath.Join(root, "go.mod"), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open go.mod: %v", err)
 	}
-	if err := os.WriteFile(path, []byte(builder.String()), 0o644); err != nil {
-		t.Fatalf("write orm entity stubs: %v", err)
+	defer goMod.Close()
+	if _, err := fmt.Fprintf(goMod, "\nrequire github.com/deicod/erm v0.0.0\n\nreplace github.com/deicod/erm => %s\n", filepath.ToSlash(repoRoot)); err != nil {
+		t.Fatalf("write go.mod: %v", err)
 	}
 }
 
 func writeGraphQLResolverRuntime(t *testing.T, root, modulePath string) {
 	t.Helper()
 
-	builder := &strings.Builder{}
-	builder.WriteString("package resolvers\n\n")
-	builder.WriteString("import (\n")
-	builder.WriteString("    \"context\"\n")
-	builder.WriteString("    \"errors\"\n")
-	builder.WriteString("    \"strconv\"\n")
-	builder.WriteString("    \"strings\"\n\n")
-	fmt.Fprintf(builder, "    \"%s/graphql\"\n", modulePath)
-	fmt.Fprintf(builder, "    \"%s/graphql/dataloaders\"\n", modulePath)
-	fmt.Fprintf(builder, "    \"%s/graphql/subscriptions\"\n", modulePath)
-	fmt.Fprintf(builder, "    \"%s/observability/metrics\"\n", modulePath)
-	fmt.Fprintf(builder, "    \"%s/orm/gen\"\n", modulePath)
-	builder.WriteString(")\n\n")
-	builder.WriteString("type Options struct {\n")
-	builder.WriteString("    ORM           *gen.Client\n")
-	builder.WriteString("    Collector     metrics.Collector\n")
-	builder.WriteString("    Subscriptions subscriptions.Broker\n")
-	builder.WriteString("}\n\n")
-	builder.WriteString("type Resolver struct {\n")
-	builder.WriteString("    ORM           *gen.Client\n")
-	builder.WriteString("    collector     metrics.Collector\n")
-	builder.WriteString("    subscriptions subscriptions.Broker\n")
-	builder.WriteString("    hooks         entityHooks\n")
-	builder.WriteString("}\n\n")
-	builder.WriteString("func New(orm *gen.Client) *Resolver {\n")
-	builder.WriteString("    return NewWithOptions(Options{ORM: orm})\n}\n\n")
-	builder.WriteString("func NewWithOptions(opts Options) *Resolver {\n")
-	builder.WriteString("    collector := opts.Collector\n")
-	builder.WriteString("    if collector == nil {\n        collector = metrics.NoopCollector{}\n    }\n")
-	builder.WriteString("    resolver := &Resolver{ORM: opts.ORM, collector: collector, subscriptions: opts.Subscriptions}\n")
-	builder.WriteString("    resolver.hooks = newEntityHooks()\n")
-	builder.WriteString("    return resolver\n}\n\n")
-	builder.WriteString("func (r *Resolver) WithLoaders(ctx context.Context) context.Context {\n")
-	builder.WriteString("    if r == nil || r.ORM == nil {\n        return ctx\n    }\n")
-	builder.WriteString("    loaders := dataloaders.New(r.ORM, r.collector)\n")
-	builder.WriteString("    return dataloaders.ToContext(ctx, loaders)\n}\n\n")
-	builder.WriteString("func (r *Resolver) Mutation() graphql.MutationResolver { return &mutationResolver{r} }\n")
-	builder.WriteString("func (r *Resolver) Query() graphql.QueryResolver       { return &queryResolver{r} }\n")
-	builder.WriteString("func (r *Resolver) Subscription() graphql.SubscriptionResolver {\n")
-	builder.WriteString("    return &subscriptionResolver{r}\n}\n\n")
-	builder.WriteString("type mutationResolver struct{ *Resolver }\n")
-	builder.WriteString("type queryResolver struct{ *Resolver }\n")
-	builder.WriteString("type subscriptionResolver struct{ *Resolver }\n\n")
-	builder.WriteString("func (r *Resolver) subscriptionBroker() subscriptions.Broker {\n")
-	builder.WriteString("    if r == nil {\n        return nil\n    }\n")
-	builder.WriteString("    return r.subscriptions\n}\n")
-
-	builder.WriteString("\nconst defaultPageSize = 50\n\n")
-	builder.WriteString("func encodeCursor(offset int) string {\n")
-	builder.WriteString("    return strconv.Itoa(offset)\n}\n\n")
-	builder.WriteString("func decodeCursor(cursor string) (int, error) {\n")
-	builder.WriteString("    return strconv.Atoi(cursor)\n}\n\n")
-	builder.WriteString("type SubscriptionTrigger string\n\n")
-	builder.WriteString("const (\n")
-	builder.WriteString("    SubscriptionTriggerCreated SubscriptionTrigger = \"created\"\n")
-	builder.WriteString("    SubscriptionTriggerUpdated SubscriptionTrigger = \"updated\"\n")
-	builder.WriteString("    SubscriptionTriggerDeleted SubscriptionTrigger = \"deleted\"\n")
-	builder.WriteString(")\n\n")
-	builder.WriteString("var ErrSubscriptionsDisabled = errors.New(\"graphql subscriptions disabled\")\n\n")
-	builder.WriteString("func publishSubscriptionEvent(ctx context.Context, broker subscriptions.Broker, entity string, trigger SubscriptionTrigger, payload any) {\n")
-	builder.WriteString("    if broker == nil || entity == \"\" {\n        return\n    }\n")
-	builder.WriteString("    _ = broker.Publish(ctx, subscriptionTopic(entity, trigger), payload)\n}\n\n")
-	builder.WriteString("func subscribeToEntity(ctx context.Context, broker subscriptions.Broker, entity string, trigger SubscriptionTrigger) (<-chan any, func(), error) {\n")
-	builder.WriteString("    if broker == nil {\n        return nil, nil, ErrSubscriptionsDisabled\n    }\n")
-	builder.WriteString("    stream, cancel, err := broker.Subscribe(ctx, subscriptionTopic(entity, trigger))\n")
-	builder.WriteString("    if err != nil {\n        return nil, nil, err\n    }\n")
-	builder.WriteString("    return stream, cancel, nil\n}\n\n")
-	builder.WriteString("func subscriptionTopic(entity string, trigger SubscriptionTrigger) string {\n")
-	builder.WriteString("    base := strings.ToLower(entity)\n")
-	builder.WriteString("    if base == \"\" {\n        base = \"entity\"\n    }\n")
-	builder.WriteString("    return base + \":\" + string(trigger)\n}\n\n")
-	builder.WriteString("func Topic(entity string, trigger SubscriptionTrigger) string {\n")
-	builder.WriteString("    return subscriptionTopic(entity, trigger)\n}\n")
-	builder.WriteString("\nfunc (r *mutationResolver) Noop(context.Context) (*bool, error) {\n")
-	builder.WriteString("    value := true\n")
-	builder.WriteString("    return &value, nil\n}\n\n")
-	builder.WriteString("func (r *queryResolver) Health(context.Context) (string, error) {\n")
-	builder.WriteString("    return \"ok\", nil\n}\n\n")
-	builder.WriteString("func (r *subscriptionResolver) Noop(context.Context) (<-chan *bool, error) {\n")
-	builder.WriteString("    ch := make(chan *bool)\n")
-	builder.WriteString("    close(ch)\n")
-	builder.WriteString("    return ch, nil\n}\n")
+	runtime, err := templates.RenderRuntimeScaffolds(modulePath)
+	if err != nil {
+		t.Fatalf("render runtime scaffolds: %v", err)
+	}
+	content, ok := runtime["graphql/resolvers/resolver.go"]
+	if !ok {
+		t.Fatalf("resolver runtime template missing")
+	}
 
 	path := filepath.Join(root, "graphql", "resolvers", "resolver.go")
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		t.Fatalf("mkdir resolver runtime: %v", err)
 	}
-	if err := os.WriteFile(path, []byte(builder.String()), 0o644); err != nil {
+	if err := os.WriteFile(path, content, 0o644); err != nil {
 		t.Fatalf("write resolver runtime: %v", err)
 	}
 }
@@ -0,0 +1,331 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+// whereOperatorSuffixes names the XxxWhereInput field of each operator after the filtered field,
+// e.g. title, titleNeq and titleIn.
+var whereOperatorSuffixes = map[dsl.ComparisonOperator]string{
+	dsl.OpEqual:       "",
+	dsl.OpNotEqual:    "Neq",
+	dsl.OpIn:          "In",
+	dsl.OpGreaterThan: "Gt",
+	dsl.OpGTE:         "Gte",
+	dsl.OpLessThan:    "Lt",
+	dsl.OpLTE:         "Lte",
+	dsl.OpContains:    "Contains",
+	dsl.OpIsNull:      "IsNull",
+}
+
+// whereField is a field of an XxxWhereInput along with the operators it accepts.
+type whereField struct {
+	field   dsl.Field
+	gqlType string
+	ops     []dsl.ComparisonOperator
+}
+
+// graphqlFilterable returns the names listed with dsl.GraphQLFilterable. ok is false when the
+// entity does not restrict its filters.
+func graphqlFilterable(ent Entity) (map[string]struct{}, bool) {
+	return graphqlNameSet(ent, "filterable")
+}
+
+// graphqlNameSet collects the names stored under key by GraphQL annotation options such as
+// dsl.GraphQLFilterable. ok is false when no annotation sets key.
+func graphqlNameSet(ent Entity, key string) (map[string]struct{}, bool) {
+	var names map[string]struct{}
+	for _, ann := range ent.Annotations {
+		if strings.ToLower(ann.Name) != dsl.AnnotationGraphQL {
+			continue
+		}
+		raw, ok := ann.Payload[key]
+		if !ok {
+			continue
+		}
+		if names == nil {
+			names = map[string]struct{}{}
+		}
+		switch vals := raw.(type) {
+		case []string:
+			for _, val := range vals {
+				names[val] = struct{}{}
+			}
+		case []any:
+			for _, val := range vals {
+				if name, ok := val.(string); ok {
+					names[name] = struct{}{}
+				}
+			}
+		}
+	}
+	return names, names != nil
+}
+
+// whereFields lists the filterable fields of ent. Fields whose GraphQL type has no natural
+// comparison, such as JSON or ranges, are left out.
+func whereFields(ent Entity) []whereField {
+	allowed, restricted := graphqlFilterable(ent)
+	var fields []whereField
+	for _, field := range ent.Fields {
+		if restricted {
+			if _, ok := allowed[field.Name]; !ok {
+				continue
+			}
+		}
+		gqlType, candidates := whereFieldOperators(field)
+		supported := fieldPredicateOps(field)
+		var ops []dsl.ComparisonOperator
+		for _, op := range candidates {
+			if slices.Contains(supported, op) {
+				ops = append(ops, op)
+			}
+		}
+		if len(ops) == 0 {
+			continue
+		}
+		fields = append(fields, whereField{field: field, gqlType: gqlType, ops: ops})
+	}
+	return fields
+}
+
+func whereFieldOperators(field dsl.Field) (string, []dsl.ComparisonOperator) {
+	gqlType, _ := graphqlNamedType(field)
+	var ops []dsl.ComparisonOperator
+	switch {
+	case len(field.EnumValues) > 0 && field.EnumName != "", gqlType == "ID":
+		ops = []dsl.ComparisonOperator{dsl.OpEqual, dsl.OpNotEqual, dsl.OpIn}
+	case gqlType == "Boolean":
+		ops = []dsl.ComparisonOperator{dsl.OpEqual, dsl.OpNotEqual}
+	case gqlType == "String":
+		ops = []dsl.ComparisonOperator{dsl.OpEqual, dsl.OpNotEqual, dsl.OpIn, dsl.OpContains}
+	case gqlType == "Int", gqlType == "BigInt", gqlType == "Float",
+		gqlType == "Date", gqlType == "Timestamp", gqlType == "Timestamptz":
+		ops = []dsl.ComparisonOperator{dsl.OpEqual, dsl.OpNotEqual, dsl.OpIn, dsl.OpGreaterThan, dsl.OpGTE, dsl.OpLessThan, dsl.OpLTE}
+	default:
+		return gqlType, nil
+	}
+	if field.Nullable {
+		ops = append(ops, dsl.OpIsNull)
+	}
+	return gqlType, ops
+}
+
+// whereEdges lists the edges of ent that can be filtered with hasX and hasXWith.
+func whereEdges(ent Entity, entityIndex map[string]Entity) []edgeJoin {
+	allowed, restricted := graphqlFilterable(ent)
+	var joins []edgeJoin
+	for _, edge := range ent.Edges {
+		if restricted {
+			if _, ok := allowed[edge.Name]; !ok {
+				continue
+			}
+		}
+		join, ok := resolveEdgeJoin(ent, edge, entityIndex)
+		if !ok {
+			continue
+		}
+		joins = append(joins, join)
+	}
+	return joins
+}
+
+func renderEntityWhereInput(ent Entity, entityIndex map[string]Entity) string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "input %sWhereInput {\n", ent.Name)
+	fmt.Fprintf(builder, "  not: %sWhereInput\n", ent.Name)
+	fmt.Fprintf(builder, "  and: [%sWhereInput!]\n", ent.Name)
+	fmt.Fprintf(builder, "  or: [%sWhereInput!]\n", ent.Name)
+	for _, wf := range whereFields(ent) {
+		name := lowerCamel(wf.field.Name)
+		for _, op := range wf.ops {
+			operand := wf.gqlType
+			switch op {
+			case dsl.OpIn:
+				operand = fmt.Sprintf("[%s!]", wf.gqlType)
+			case dsl.OpIsNull:
+				operand = "Boolean"
+			}
+			fmt.Fprintf(builder, "  %s%s: %s\n", name, whereOperatorSuffixes[op], operand)
+		}
+	}
+	for _, join := range whereEdges(ent, entityIndex) {
+		name := exportName(join.edge.Name)
+		fmt.Fprintf(builder, "  has%s: Boolean\n", name)
+		fmt.Fprintf(builder, "  has%sWith: [%sWhereInput!]\n", name, join.target.Name)
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+func wherePredicatesFunc(ent Entity) string {
+	return lowerCamel(ent.Name) + "WherePredicates"
+}
+
+// renderEntityWherePredicates renders the conversion of an XxxWhereInput into predicates for
+// XxxQuery.Where. Conditions of one input are joined with AND.
+func renderEntityWherePredicates(ent Entity, entityIndex map[string]Entity) string {
+	builder := &strings.Builder{}
+	pkg := predicatePackageName(ent)
+	fn := wherePredicatesFunc(ent)
+
+	fmt.Fprintf(builder, "// %s converts where into predicates for %sQuery.Where.\n", fn, ent.Name)
+	fmt.Fprintf(builder, "func %s(ctx context.Context, where *graphql.%sWhereInput) ([]%s.Predicate, error) {\n", fn, ent.Name, pkg)
+	fmt.Fprintf(builder, "    if where == nil {\n        return nil, nil\n    }\n")
+	fmt.Fprintf(builder, "    var preds []%s.Predicate\n", pkg)
+	fmt.Fprintf(builder, "    if where.Not != nil {\n")
+	fmt.Fprintf(builder, "        not, err := %s(ctx, where.Not)\n", fn)
+	fmt.Fprintf(builder, "        if err != nil {\n            return nil, err\n        }\n")
+	fmt.Fprintf(builder, "        preds = append(preds, %[1]s.Not(%[1]s.And(not...)))\n    }\n", pkg)
+	fmt.Fprintf(builder, "    for _, item := range where.And {\n")
+	fmt.Fprintf(builder, "        and, err := %s(ctx, item)\n", fn)
+	fmt.Fprintf(builder, "        if err != nil {\n            return nil, err\n        }\n")
+	fmt.Fprintf(builder, "        preds = append(preds, and...)\n    }\n")
+	fmt.Fprintf(builder, "    if where.Or != nil {\n")
+	fmt.Fprintf(builder, "        or := make([]%s.Predicate, 0, len(where.Or))\n", pkg)
+	fmt.Fprintf(builder, "        for _, item := range where.Or {\n")
+	fmt.Fprintf(builder, "            and, err := %s(ctx, item)\n", fn)
+	fmt.Fprintf(builder, "            if err != nil {\n                return nil, err\n            }\n")
+	fmt.Fprintf(builder, "            or = append(or, %s.And(and...))\n        }\n", pkg)
+	fmt.Fprintf(builder, "        preds = append(preds, %s.Or(or...))\n    }\n", pkg)
+
+	for _, wf := range whereFields(ent) {
+		for _, op := range wf.ops {
+			writeWhereCondition(builder, ent, wf, op)
+		}
+	}
+
+	for _, join := range whereEdges(ent, entityIndex) {
+		name := exportName(join.edge.Name)
+		// Targets the viewer may only partly read are matched within their read scope, so
+		// filters cannot probe hidden records.
+		scoped := authRuleScoped(join.target.Authorization.Read)
+		has := fmt.Sprintf("%s.Has%s()", pkg, name)
+		if scoped {
+			has = fmt.Sprintf("%s.Has%sWith(scope...)", pkg, name)
+		}
+		fmt.Fprintf(builder, "    if where.Has%s != nil {\n", name)
+		if scoped {
+			writeWhereReadScope(builder, join.target)
+		}
+		fmt.Fprintf(builder, "        if *where.Has%s {\n", name)
+		fmt.Fprintf(builder, "            preds = append(preds, %s)\n", has)
+		fmt.Fprintf(builder, "        } else {\n")
+		fmt.Fprintf(builder, "            preds = append(preds, %s.Not(%s))\n        }\n    }\n", pkg, has)
+		fmt.Fprintf(builder, "    if where.Has%sWith != nil {\n", name)
+		fmt.Fprintf(builder, "        var with []%s.Predicate\n", pkg)
+		if scoped {
+			writeWhereReadScope(builder, join.target)
+			fmt.Fprintf(builder, "        with = append(with, scope...)\n")
+		}
+		fmt.Fprintf(builder, "        for _, item := range where.Has%sWith {\n", name)
+		fmt.Fprintf(builder, "            target, err := %s(ctx, item)\n", wherePredicatesFunc(join.target))
+		fmt.Fprintf(builder, "            if err != nil {\n                return nil, err\n            }\n")
+		fmt.Fprintf(builder, "            with = append(with, target...)\n        }\n")
+		fmt.Fprintf(builder, "        preds = append(preds, %s.Has%sWith(with...))\n    }\n", pkg, name)
+	}
+
+	fmt.Fprintf(builder, "    return preds, nil\n}\n\n")
+	return builder.String()
+}
+
+// writeWhereReadScope renders the read scope of target into scope, failing the filter when the
+// viewer may not read target at all.
+func writeWhereReadScope(builder *strings.Builder, target Entity) {
+	fmt.Fprintf(builder, "        scope, err := %sReadScope(ctx)\n", lowerCamel(target.Name))
+	fmt.Fprintf(builder, "        if err != nil {\n            return nil, err\n        }\n")
+}
+
+func writeWhereCondition(builder *strings.Builder, ent Entity, wf whereField, op dsl.ComparisonOperator) {
+	pkg := predicatePackageName(ent)
+	input := "where." + exportName(wf.field.Name) + whereOperatorSuffixes[op]
+	predicate := pkg + "." + exportName(wf.field.Name)
+	switch op {
+	case dsl.OpIsNull:
+		fmt.Fprintf(builder, "    if %s != nil {\n", input)
+		fmt.Fprintf(builder, "        if *%s {\n", input)
+		fmt.Fprintf(builder, "            preds = append(preds, %s%s())\n", predicate, operatorSuffix(dsl.OpIsNull))
+		fmt.Fprintf(builder, "        } else {\n")
+		fmt.Fprintf(builder, "            preds = append(preds, %s%s())\n        }\n    }\n", predicate, operatorSuffix(dsl.OpNotNull))
+	case dsl.OpIn:
+		fmt.Fprintf(builder, "    if %s != nil {\n", input)
+		if wf.field.IsPrimary && wf.gqlType == "ID" {
+			fmt.Fprintf(builder, "        values := make([]string, len(%s))\n", input)
+			fmt.Fprintf(builder, "        for idx, value := range %s {\n", input)
+			fmt.Fprintf(builder, "            nativeID, err := decode%sID(value)\n", ent.Name)
+			fmt.Fprintf(builder, "            if err != nil {\n                return nil, err\n            }\n")
+			fmt.Fprintf(builder, "            values[idx] = nativeID\n        }\n")
+			fmt.Fprintf(builder, "        preds = append(preds, %s%s(values...))\n    }\n", predicate, operatorSuffix(op))
+			return
+		}
+		elem := "value"
+		if whereBindsStruct(wf.gqlType) {
+			// gqlgen binds list elements of struct types such as time.Time as pointers.
+			elem = "*value"
+		}
+		if converted := whereValueExpr(wf, elem); converted != "value" {
+			fmt.Fprintf(builder, "        values := make([]%s, len(%s))\n", baseGoType(wf.field), input)
+			fmt.Fprintf(builder, "        for idx, value := range %s {\n", input)
+			fmt.Fprintf(builder, "            values[idx] = %s\n        }\n", converted)
+			fmt.Fprintf(builder, "        preds = append(preds, %s%s(values...))\n    }\n", predicate, operatorSuffix(op))
+			return
+		}
+		fmt.Fprintf(builder, "        preds = append(preds, %s%s(%s...))\n    }\n", predicate, operatorSuffix(op), input)
+	default:
+		fmt.Fprintf(builder, "    if %s != nil {\n", input)
+		if wf.field.IsPrimary && wf.gqlType == "ID" {
+			fmt.Fprintf(builder, "        nativeID, err := decode%sID(*%s)\n", ent.Name, input)
+			fmt.Fprintf(builder, "        if err != nil {\n            return nil, err\n        }\n")
+			fmt.Fprintf(builder, "        preds = append(preds, %s%s(nativeID))\n    }\n", predicate, operatorSuffix(op))
+			return
+		}
+		fmt.Fprintf(builder, "        preds = append(preds, %s%s(%s))\n    }\n", predicate, operatorSuffix(op), whereValueExpr(wf, "*"+input))
+	}
+}
+
+// whereValueExpr converts expr from the Go type gqlgen binds for the GraphQL operand to the
+// field's Go type.
+func whereValueExpr(wf whereField, expr string) string {
+	if len(wf.field.EnumValues) > 0 && wf.field.EnumName != "" {
+		return fmt.Sprintf("fromGraphQLEnum(%s)", expr)
+	}
+	goType := baseGoType(wf.field)
+	var bound string
+	switch wf.gqlType {
+	case "Int":
+		bound = "int"
+	case "BigInt":
+		bound = "int64"
+	case "Float":
+		bound = "float64"
+	}
+	if bound == "" || bound == goType {
+		return expr
+	}
+	return fmt.Sprintf("%s(%s)", goType, expr)
+}
+
+func whereBindsStruct(gqlType string) bool {
+	switch gqlType {
+	case "Date", "Timestamp", "Timestamptz":
+		return true
+	default:
+		return false
+	}
+}
+
+// whereNeedsTime reports whether a where conversion copies time.Time list operands.
+func whereNeedsTime(entities []Entity) bool {
+	for _, ent := range entities {
+		for _, wf := range whereFields(ent) {
+			if whereBindsStruct(wf.gqlType) && slices.Contains(wf.ops, dsl.OpIn) {
+				return true
+			}
+		}
+	}
+	return false
+}
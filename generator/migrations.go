@@ -450,6 +450,10 @@ func buildMigrationPlan(entities []Entity) ([]entityMigration, []joinTableSpec)
 		}
 	}
 
+	for i := range plan {
+		applySoftDeleteIndexes(&plan[i])
+	}
+
 	joinTables := make([]joinTableSpec, 0, len(joinTableMap))
 	for _, jt := range joinTableMap {
 		joinTables = append(joinTables, jt)
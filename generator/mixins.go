@@ -0,0 +1,74 @@
+package generator
+
+import "github.com/deicod/erm/orm/dsl"
+
+// applyMixins merges mixin contributions into the entity. Primary-key fields from mixins come
+// first and the remaining mixin fields follow the schema's own fields, which keeps column order
+// (and therefore migrations and the schema snapshot) identical to spelling the fields out by hand.
+// Definitions on the schema win over mixin definitions with the same name.
+func applyMixins(ent *Entity) {
+	if len(ent.Mixins) == 0 {
+		return
+	}
+
+	seenFields := make(map[string]struct{}, len(ent.Fields))
+	for _, field := range ent.Fields {
+		seenFields[field.Name] = struct{}{}
+	}
+	var leading, trailing []dsl.Field
+	for _, mixin := range ent.Mixins {
+		for _, field := range mixin.Fields {
+			if _, ok := seenFields[field.Name]; ok {
+				continue
+			}
+			seenFields[field.Name] = struct{}{}
+			if field.IsPrimary {
+				leading = append(leading, field)
+			} else {
+				trailing = append(trailing, field)
+			}
+		}
+	}
+	fields := make([]dsl.Field, 0, len(leading)+len(ent.Fields)+len(trailing))
+	fields = append(fields, leading...)
+	fields = append(fields, ent.Fields...)
+	fields = append(fields, trailing...)
+	ent.Fields = fields
+
+	seenEdges := make(map[string]struct{}, len(ent.Edges))
+	for _, edge := range ent.Edges {
+		seenEdges[edge.Name] = struct{}{}
+	}
+	seenIndexes := make(map[string]struct{}, len(ent.Indexes))
+	for _, idx := range ent.Indexes {
+		seenIndexes[idx.Name] = struct{}{}
+	}
+	seenAnnotations := make(map[string]struct{}, len(ent.Annotations))
+	for _, ann := range ent.Annotations {
+		seenAnnotations[ann.Name] = struct{}{}
+	}
+	for _, mixin := range ent.Mixins {
+		for _, edge := range mixin.Edges {
+			if _, ok := seenEdges[edge.Name]; ok {
+				continue
+			}
+			seenEdges[edge.Name] = struct{}{}
+			ent.Edges = append(ent.Edges, edge)
+		}
+		for _, idx := range mixin.Indexes {
+			if _, ok := seenIndexes[idx.Name]; ok && idx.Name != "" {
+				continue
+			}
+			seenIndexes[idx.Name] = struct{}{}
+			ent.Indexes = append(ent.Indexes, idx)
+		}
+		for _, ann := range mixin.Annotations {
+			if _, ok := seenAnnotations[ann.Name]; ok {
+				continue
+			}
+			seenAnnotations[ann.Name] = struct{}{}
+			ent.Annotations = append(ent.Annotations, ann)
+		}
+		ent.Hooks = append(ent.Hooks, mixin.Hooks...)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/deicod/erm/orm/dsl"
@@ -26,6 +27,9 @@ func writeORMArtifacts(root string, entities []Entity) error {
 	if err := writeClients(root, entities); err != nil {
 		return err
 	}
+	if err := writePredicatePackages(root, entities); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -311,8 +315,10 @@ func writeClients(root string, entities []Entity) error {
 	fmt.Fprintf(buf, "package gen\n\n")
 
 	needsTime := false
+	needsJSON := false
 	needsID := false
 	hasEdges := false
+	needsPrivacy := false
 	for _, ent := range entities {
 		for _, field := range ent.Fields {
 			if field.HasDefaultNow || field.HasUpdateNow {
@@ -322,12 +328,27 @@ func writeClients(root string, entities []Entity) error {
 				needsID = true
 			}
 		}
+		for _, field := range partialUpdateFields(ent) {
+			setterType := updateSetterType(field)
+			if strings.Contains(setterType, "time.") {
+				needsTime = true
+			}
+			if strings.Contains(setterType, "json.") {
+				needsJSON = true
+			}
+		}
 		if len(ent.Edges) > 0 {
 			hasEdges = true
 		}
+		if hasPolicy(ent) || len(ent.Hooks) > 0 {
+			needsPrivacy = true
+		}
 	}
 
-	imports := []string{"context", "errors", "fmt"}
+	imports := []string{"context", "errors", "fmt", "iter"}
+	if needsJSON {
+		imports = append(imports, "encoding/json")
+	}
 	if hasEdges {
 		imports = append(imports, "strings")
 	}
@@ -344,6 +365,9 @@ func writeClients(root string, entities []Entity) error {
 	if needsID {
 		imports = append(imports, "github.com/deicod/erm/orm/id")
 	}
+	if needsPrivacy {
+		imports = append(imports, "github.com/deicod/erm/orm/runtime/privacy")
+	}
 
 	sort.Strings(imports)
 	fmt.Fprintf(buf, "import (\n")
@@ -356,8 +380,8 @@ func writeClients(root string, entities []Entity) error {
 
 	sort.Slice(entities, func(i, j int) bool { return entities[i].Name < entities[j].Name })
 
-	fmt.Fprintf(buf, "type Client struct {\n    db *pg.DB\n    cache cache.Store\n}\n\n")
-	fmt.Fprintf(buf, "func NewClient(db *pg.DB) *Client {\n    return &Client{db: db, cache: cache.Nop()}\n}\n\n")
+	fmt.Fprintf(buf, "type Client struct {\n    db *pg.DB\n    cache cache.Store\n    hooks *runtime.HookRegistry\n}\n\n")
+	fmt.Fprintf(buf, "func NewClient(db *pg.DB) *Client {\n    return &Client{db: db, cache: cache.Nop(), hooks: runtime.NewHookRegistry()}\n}\n\n")
 	fmt.Fprintf(buf, "func (c *Client) UseCache(store cache.Store) {\n")
 	fmt.Fprintf(buf, "    if c == nil {\n        return\n    }\n")
 	fmt.Fprintf(buf, "    if store == nil {\n        store = cache.Nop()\n    }\n")
@@ -366,6 +390,8 @@ func writeClients(root string, entities []Entity) error {
 	fmt.Fprintf(buf, "    if c == nil || c.cache == nil {\n        return cache.Nop()\n    }\n")
 	fmt.Fprintf(buf, "    return c.cache\n}\n\n")
 	fmt.Fprintf(buf, "func makeCacheKey(entity string, id any) string {\n    return \"orm:\" + entity + \":\" + fmt.Sprint(id)\n}\n\n")
+	emitOnConflictHelper(buf)
+	emitClientHooks(buf)
 
 	entityIndex := make(map[string]Entity, len(entities))
 	for _, ent := range entities {
@@ -373,9 +399,11 @@ func writeClients(root string, entities []Entity) error {
 	}
 
 	for _, ent := range entities {
-		fmt.Fprintf(buf, "func (c *Client) %s() *%sClient {\n    return &%sClient{db: c.db, cache: c.cacheStore()}\n}\n\n", exportName(pluralize(ent.Name)), ent.Name, ent.Name)
+		fmt.Fprintf(buf, "func (c *Client) %s() *%sClient {\n    return &%sClient{db: c.db, cache: c.cacheStore(), hooks: c.hooks}\n}\n\n", exportName(pluralize(ent.Name)), ent.Name, ent.Name)
 	}
 
+	emitTxClient(buf)
+
 	for _, ent := range entities {
 		emitEntityClients(buf, ent, entityIndex)
 	}
@@ -384,6 +412,9 @@ func writeClients(root string, entities []Entity) error {
 		emitValidationRecordHelper(buf, ent)
 	}
 
+	emitConstraintSchemas(buf, entities)
+	emitEdgeScope(buf, entities)
+
 	if hasEdges {
 		emitRelationshipHelpers(buf)
 	}
@@ -391,6 +422,43 @@ func writeClients(root string, entities []Entity) error {
 	return writeGoFile(path, buf.Bytes())
 }
 
+func emitTxClient(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "// Tx is a transactional client. Entity clients obtained from it share one database\n")
+	fmt.Fprintf(buf, "// transaction and buffer cache writes until Commit.\n")
+	fmt.Fprintf(buf, "type Tx struct {\n    *Client\n    tx *pg.Tx\n    cache *cache.Deferred\n}\n\n")
+
+	fmt.Fprintf(buf, "// BeginTx starts a transaction. Calling it on a transactional client creates a savepoint.\n")
+	fmt.Fprintf(buf, "func (c *Client) BeginTx(ctx context.Context, opts pgx.TxOptions) (*Tx, error) {\n")
+	fmt.Fprintf(buf, "    if c == nil || c.db == nil {\n        return nil, errors.New(\"database client is unavailable\")\n    }\n")
+	fmt.Fprintf(buf, "    raw, err := c.db.BeginTx(ctx, opts)\n")
+	fmt.Fprintf(buf, "    if err != nil {\n        return nil, err\n    }\n")
+	fmt.Fprintf(buf, "    deferred := cache.NewDeferred(c.cacheStore())\n")
+	fmt.Fprintf(buf, "    client := *c\n")
+	fmt.Fprintf(buf, "    client.db = raw.DB()\n")
+	fmt.Fprintf(buf, "    client.cache = deferred\n")
+	fmt.Fprintf(buf, "    return &Tx{Client: &client, tx: raw, cache: deferred}, nil\n}\n\n")
+
+	fmt.Fprintf(buf, "// Tx runs fn inside a transaction, committing when it returns nil and rolling back otherwise.\n")
+	fmt.Fprintf(buf, "func (c *Client) Tx(ctx context.Context, fn func(tx *Tx) error) error {\n")
+	fmt.Fprintf(buf, "    tx, err := c.BeginTx(ctx, pgx.TxOptions{})\n")
+	fmt.Fprintf(buf, "    if err != nil {\n        return err\n    }\n")
+	fmt.Fprintf(buf, "    defer func() {\n        if r := recover(); r != nil {\n            _ = tx.Rollback(ctx)\n            panic(r)\n        }\n    }()\n")
+	fmt.Fprintf(buf, "    if err := fn(tx); err != nil {\n")
+	fmt.Fprintf(buf, "        if rbErr := tx.Rollback(ctx); rbErr != nil {\n            return fmt.Errorf(\"%%w (rollback failed: %%v)\", err, rbErr)\n        }\n")
+	fmt.Fprintf(buf, "        return err\n    }\n")
+	fmt.Fprintf(buf, "    return tx.Commit(ctx)\n}\n\n")
+
+	fmt.Fprintf(buf, "// Commit commits the transaction and publishes buffered cache writes.\n")
+	fmt.Fprintf(buf, "func (tx *Tx) Commit(ctx context.Context) error {\n")
+	fmt.Fprintf(buf, "    if err := tx.tx.Commit(ctx); err != nil {\n        tx.cache.Discard()\n        return err\n    }\n")
+	fmt.Fprintf(buf, "    return tx.cache.Flush(ctx)\n}\n\n")
+
+	fmt.Fprintf(buf, "// Rollback aborts the transaction and drops buffered cache writes.\n")
+	fmt.Fprintf(buf, "func (tx *Tx) Rollback(ctx context.Context) error {\n")
+	fmt.Fprintf(buf, "    tx.cache.Discard()\n")
+	fmt.Fprintf(buf, "    return tx.tx.Rollback(ctx)\n}\n\n")
+}
+
 func emitEntityClients(buf *bytes.Buffer, ent Entity, entityIndex map[string]Entity) {
 	name := ent.Name
 	lower := strings.ToLower(name)
@@ -406,6 +474,15 @@ func emitEntityClients(buf *bytes.Buffer, ent Entity, entityIndex map[string]Ent
 	selectSQL := fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1", returning, pluralize(name), primaryColumn(ent))
 	listSQL := fmt.Sprintf("SELECT %s FROM %s ORDER BY %s LIMIT $1 OFFSET $2", returning, pluralize(name), primaryColumn(ent))
 	countSQL := fmt.Sprintf("SELECT COUNT(*) FROM %s", pluralize(name))
+	deleteSQL := fmt.Sprintf("DELETE FROM %s WHERE %s = $1", pluralize(name), primaryColumn(ent))
+	softDelete := isSoftDelete(ent)
+	if softDelete {
+		live := softDeleteScope("")
+		selectSQL += " AND " + live
+		listSQL = fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s LIMIT $1 OFFSET $2", returning, pluralize(name), live, primaryColumn(ent))
+		countSQL += " WHERE " + live
+		deleteSQL = fmt.Sprintf("UPDATE %s SET %s = now() WHERE %s = $1 AND %s", pluralize(name), dsl.SoftDeleteColumn, primaryColumn(ent), live)
+	}
 
 	updateCols := updatableColumns(ent)
 	updateSQL := ""
@@ -414,7 +491,16 @@ func emitEntityClients(buf *bytes.Buffer, ent Entity, entityIndex map[string]Ent
 		for i, col := range updateCols {
 			assignments[i] = fmt.Sprintf("%s = $%d", col, i+1)
 		}
-		updateSQL = fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d RETURNING %s", pluralize(name), strings.Join(assignments, ", "), primaryColumn(ent), len(updateCols)+1, returning)
+		where := fmt.Sprintf("%s = $%d", primaryColumn(ent), len(updateCols)+1)
+		if version, ok := versionField(ent); ok {
+			column := fieldColumn(version)
+			assignments = append(assignments, fmt.Sprintf("%s = %s + 1", column, column))
+			where += fmt.Sprintf(" AND %s = $%d", column, len(updateCols)+2)
+		}
+		if softDelete {
+			where += " AND " + softDeleteScope("")
+		}
+		updateSQL = fmt.Sprintf("UPDATE %s SET %s WHERE %s RETURNING %s", pluralize(name), strings.Join(assignments, ", "), where, returning)
 	}
 
 	fmt.Fprintf(buf, "const %sInsertQuery = `%s`\n", lower, insertSQL)
@@ -424,70 +510,55 @@ func emitEntityClients(buf *bytes.Buffer, ent Entity, entityIndex map[string]Ent
 		fmt.Fprintf(buf, "const %sUpdateQuery = `%s`\n", lower, updateSQL)
 	}
 	fmt.Fprintf(buf, "const %sCountQuery = `%s`\n", lower, countSQL)
-	fmt.Fprintf(buf, "const %sDeleteQuery = `DELETE FROM %s WHERE %s = $1`\n\n", lower, pluralize(name), primaryColumn(ent))
+	fmt.Fprintf(buf, "const %sDeleteQuery = `%s`\n", lower, deleteSQL)
+	if softDelete {
+		fmt.Fprintf(buf, "const %sHardDeleteQuery = `DELETE FROM %s WHERE %s = $1`\n", lower, pluralize(name), primaryColumn(ent))
+		fmt.Fprintf(buf, "const %sRestoreQuery = `UPDATE %s SET %s = NULL WHERE %s = $1 AND %s IS NOT NULL RETURNING %s`\n", lower, pluralize(name), dsl.SoftDeleteColumn, primaryColumn(ent), dsl.SoftDeleteColumn, returning)
+	}
+	fmt.Fprintf(buf, "\n")
 
-	fmt.Fprintf(buf, "type %sClient struct {\n    db *pg.DB\n    cache cache.Store\n}\n\n", name)
+	fmt.Fprintf(buf, "type %sClient struct {\n    db *pg.DB\n    cache cache.Store\n    hooks *runtime.HookRegistry\n}\n\n", name)
 
+	emitPolicyVar(buf, ent)
+	emitEntityHooks(buf, ent)
+	emitMutationType(buf, ent, updateSQL != "")
+	emitMutationOwnership(buf, ent)
+	emitSchemaHooks(buf, ent)
+	emitMutationMethods(buf, ent, updateSQL != "")
 	emitCreateMethod(buf, ent)
 	emitBulkCreateMethod(buf, ent)
+	emitCopyCreateMethod(buf, ent)
+	emitUpsertMethods(buf, ent)
 	emitByIDMethod(buf, ent)
 	emitListMethod(buf, ent)
 	emitCountMethod(buf, ent)
 	if updateSQL != "" {
 		emitUpdateMethod(buf, ent)
 		emitBulkUpdateMethod(buf, ent, updateCols)
+		emitUpdateOneBuilder(buf, ent)
+		emitUpdateWhereBuilder(buf, ent, scopesEdges(entityIndex))
 	}
 	emitDeleteMethod(buf, ent)
 	emitBulkDeleteMethod(buf, ent)
-	emitQueryBuilder(buf, ent)
+	emitDeleteWhereBuilder(buf, ent, scopesEdges(entityIndex))
+	emitSoftDeleteClientMethods(buf, ent)
+	emitQueryBuilder(buf, ent, entityIndex)
+	emitEdgePredicateMethods(buf, ent, entityIndex)
+	emitEdgeQueryMethods(buf, ent, entityIndex)
+	emitEdgePageMethods(buf, ent, entityIndex)
+	emitEdgeLinkMethods(buf, ent, entityIndex)
 	emitEdgeLoaders(buf, ent, entityIndex)
 }
 
 func emitCreateMethod(buf *bytes.Buffer, ent Entity) {
-	fmt.Fprintf(buf, "func (c *%sClient) Create(ctx context.Context, input *%s) (*%s, error) {\n", ent.Name, ent.Name, ent.Name)
-	fmt.Fprintf(buf, "    if input == nil {\n        return nil, errors.New(\"input cannot be nil\")\n    }\n")
+	emitPrepareCreate(buf, ent)
 
-	computed := computedFields(ent)
-	if len(computed) > 0 {
-		for _, field := range computed {
-			fmt.Fprintf(buf, "    if !runtime.IsZeroValue(input.%s) {\n", exportName(field.Name))
-			fmt.Fprintf(buf, "        return nil, fmt.Errorf(\"%s.%s is computed and cannot be set\")\n    }\n", ent.Name, exportName(field.Name))
-		}
-	}
-
-	needsNow := false
-	for _, field := range ent.Fields {
-		if field.HasDefaultNow || field.HasUpdateNow {
-			needsNow = true
-			break
-		}
-	}
-	if needsNow {
-		fmt.Fprintf(buf, "    now := time.Now().UTC()\n")
-	}
-
-	for _, field := range ent.Fields {
-		fieldName := exportName(field.Name)
-		switch {
-		case field.IsPrimary && field.Type == dsl.TypeUUID:
-			fmt.Fprintf(buf, "    if input.%s == \"\" {\n", fieldName)
-			fmt.Fprintf(buf, "        v, err := id.NewV7()\n")
-			fmt.Fprintf(buf, "        if err != nil { return nil, err }\n")
-			fmt.Fprintf(buf, "        input.%s = v\n", fieldName)
-			fmt.Fprintf(buf, "    }\n")
-		}
-		if field.HasDefaultNow {
-			fmt.Fprintf(buf, "    if input.%s.IsZero() { input.%s = now }\n", fieldName, fieldName)
-		}
-		if field.HasUpdateNow {
-			fmt.Fprintf(buf, "    input.%s = now\n", fieldName)
-		}
-	}
-
-	fmt.Fprintf(buf, "    if err := ValidationRegistry.Validate(ctx, %q, validation.OpCreate, %sValidationRecord(input), input); err != nil {\n        return nil, err\n    }\n", ent.Name, strings.ToLower(ent.Name))
+	fmt.Fprintf(buf, "func (c *%sClient) create(ctx context.Context, input *%s) (*%s, error) {\n", ent.Name, ent.Name, ent.Name)
+	fmt.Fprintf(buf, "    if err := c.prepareCreate(ctx, input); err != nil {\n        return nil, err\n    }\n")
 
 	insertFields := insertableFields(ent)
-	fmt.Fprintf(buf, "    row := c.db.Pool.QueryRow(ctx, %sInsertQuery", strings.ToLower(ent.Name))
+	emitWriterGuard(buf, "nil, ")
+	fmt.Fprintf(buf, "    row := writer.QueryRow(ctx, %sInsertQuery", strings.ToLower(ent.Name))
 	for _, field := range insertFields {
 		fmt.Fprintf(buf, ", input.%s", exportName(field.Name))
 	}
@@ -505,60 +576,56 @@ func emitCreateMethod(buf *bytes.Buffer, ent Entity) {
 	fmt.Fprintf(buf, "    return out, nil\n}\n\n")
 }
 
-func emitBulkCreateMethod(buf *bytes.Buffer, ent Entity) {
-	fmt.Fprintf(buf, "func (c *%sClient) BulkCreate(ctx context.Context, inputs []*%s) ([]*%s, error) {\n", ent.Name, ent.Name, ent.Name)
-	fmt.Fprintf(buf, "    if len(inputs) == 0 {\n        return []*%s{}, nil\n    }\n", ent.Name)
-	fmt.Fprintf(buf, "    rowsSpec := make([][]any, 0, len(inputs))\n")
-	computed := computedFields(ent)
-	needsNow := false
+// emitPrepareCreate emits the input checks, generated defaults and validation shared by every
+// insert path.
+func emitPrepareCreate(buf *bytes.Buffer, ent Entity) {
+	fmt.Fprintf(buf, "func (c *%sClient) prepareCreate(ctx context.Context, input *%s) error {\n", ent.Name, ent.Name)
+	fmt.Fprintf(buf, "    if input == nil {\n        return errors.New(\"input cannot be nil\")\n    }\n")
+	for _, field := range computedFields(ent) {
+		fmt.Fprintf(buf, "    if !runtime.IsZeroValue(input.%s) {\n", exportName(field.Name))
+		fmt.Fprintf(buf, "        return fmt.Errorf(\"%s.%s is computed and cannot be set\")\n    }\n", ent.Name, exportName(field.Name))
+	}
 	for _, field := range ent.Fields {
 		if field.HasDefaultNow || field.HasUpdateNow {
-			needsNow = true
+			fmt.Fprintf(buf, "    now := time.Now().UTC()\n")
 			break
 		}
 	}
-	fmt.Fprintf(buf, "    for _, input := range inputs {\n")
-	fmt.Fprintf(buf, "        if input == nil {\n            return nil, errors.New(\"input cannot be nil\")\n        }\n")
-	if needsNow {
-		fmt.Fprintf(buf, "        now := time.Now().UTC()\n")
-	}
-	if len(computed) > 0 {
-		for _, field := range computed {
-			fmt.Fprintf(buf, "        if !runtime.IsZeroValue(input.%s) {\n", exportName(field.Name))
-			fmt.Fprintf(buf, "            return nil, fmt.Errorf(\"%s.%s is computed and cannot be set\")\n        }\n", ent.Name, exportName(field.Name))
-		}
-	}
 	for _, field := range ent.Fields {
 		fieldName := exportName(field.Name)
-		switch {
-		case field.IsPrimary && field.Type == dsl.TypeUUID:
-			fmt.Fprintf(buf, "        if input.%s == \"\" {\n", fieldName)
-			fmt.Fprintf(buf, "            v, err := id.NewV7()\n")
-			fmt.Fprintf(buf, "            if err != nil { return nil, err }\n")
-			fmt.Fprintf(buf, "            input.%s = v\n", fieldName)
-			fmt.Fprintf(buf, "        }\n")
+		if field.IsPrimary && field.Type == dsl.TypeUUID {
+			fmt.Fprintf(buf, "    if input.%s == \"\" {\n", fieldName)
+			fmt.Fprintf(buf, "        v, err := id.NewV7()\n")
+			fmt.Fprintf(buf, "        if err != nil {\n            return err\n        }\n")
+			fmt.Fprintf(buf, "        input.%s = v\n", fieldName)
+			fmt.Fprintf(buf, "    }\n")
 		}
 		if field.HasDefaultNow {
-			fmt.Fprintf(buf, "        if input.%s.IsZero() { input.%s = now }\n", fieldName, fieldName)
+			fmt.Fprintf(buf, "    if input.%s.IsZero() {\n        input.%s = now\n    }\n", fieldName, fieldName)
 		}
 		if field.HasUpdateNow {
-			fmt.Fprintf(buf, "        input.%s = now\n", fieldName)
+			fmt.Fprintf(buf, "    input.%s = now\n", fieldName)
 		}
-	}
-	fmt.Fprintf(buf, "        if err := ValidationRegistry.Validate(ctx, %q, validation.OpCreate, %sValidationRecord(input), input); err != nil {\n            return nil, err\n        }\n", ent.Name, strings.ToLower(ent.Name))
-	insertFields := insertableFields(ent)
-	if len(insertFields) == 0 {
-		fmt.Fprintf(buf, "        row := []any{}\n")
-	} else {
-		fmt.Fprintf(buf, "        row := []any{")
-		for i, field := range insertFields {
-			if i > 0 {
-				fmt.Fprintf(buf, ", ")
-			}
-			fmt.Fprintf(buf, "input.%s", exportName(field.Name))
+		if isVersionField(field) {
+			fmt.Fprintf(buf, "    if input.%s == 0 {\n        input.%s = 1\n    }\n", fieldName, fieldName)
 		}
-		fmt.Fprintf(buf, "}\n")
 	}
+	fmt.Fprintf(buf, "    return ValidationRegistry.Validate(ctx, %q, validation.OpCreate, %sValidationRecord(input), input)\n}\n\n", ent.Name, strings.ToLower(ent.Name))
+}
+
+func emitBulkCreateMethod(buf *bytes.Buffer, ent Entity) {
+	fmt.Fprintf(buf, "func (c *%sClient) bulkCreate(ctx context.Context, inputs []*%s) ([]*%s, error) {\n", ent.Name, ent.Name, ent.Name)
+	fmt.Fprintf(buf, "    return c.insertRows(ctx, inputs, nil)\n}\n\n")
+
+	fmt.Fprintf(buf, "// insertRows inserts inputs, applying conflict when it is non-nil. Inserts that would exceed the\n")
+	fmt.Fprintf(buf, "// bind parameter limit run in chunks inside one transaction. Rows skipped by the conflict clause\n")
+	fmt.Fprintf(buf, "// are missing from the result.\n")
+	fmt.Fprintf(buf, "func (c *%sClient) insertRows(ctx context.Context, inputs []*%s, conflict *runtime.OnConflict) ([]*%s, error) {\n", ent.Name, ent.Name, ent.Name)
+	fmt.Fprintf(buf, "    if len(inputs) == 0 {\n        return []*%s{}, nil\n    }\n", ent.Name)
+	fmt.Fprintf(buf, "    rowsSpec := make([][]any, 0, len(inputs))\n")
+	fmt.Fprintf(buf, "    for _, input := range inputs {\n")
+	fmt.Fprintf(buf, "        if err := c.prepareCreate(ctx, input); err != nil {\n            return nil, err\n        }\n")
+	fmt.Fprintf(buf, "        row := %s\n", insertValues(ent, "input"))
 	fmt.Fprintf(buf, "        rowsSpec = append(rowsSpec, row)\n")
 	fmt.Fprintf(buf, "    }\n")
 	columns := entityColumns(ent)
@@ -568,25 +635,82 @@ func emitBulkCreateMethod(buf *bytes.Buffer, ent Entity) {
 	fmt.Fprintf(buf, "        Columns: %s,\n", quoteStringSlice(insertCols))
 	fmt.Fprintf(buf, "        Returning: %s,\n", quoteStringSlice(columns))
 	fmt.Fprintf(buf, "        Rows: rowsSpec,\n")
+	fmt.Fprintf(buf, "        OnConflict: conflict,\n")
 	fmt.Fprintf(buf, "    }\n")
+	fmt.Fprintf(buf, "    chunks := runtime.ChunkBulkInsert(spec)\n")
+	fmt.Fprintf(buf, "    db := c.db\n")
+	fmt.Fprintf(buf, "    var tx *pg.Tx\n")
+	fmt.Fprintf(buf, "    if len(chunks) > 1 && !db.InTx() {\n")
+	fmt.Fprintf(buf, "        raw, err := db.BeginTx(ctx, pgx.TxOptions{})\n")
+	fmt.Fprintf(buf, "        if err != nil {\n            return nil, err\n        }\n")
+	fmt.Fprintf(buf, "        defer func() { _ = raw.Rollback(ctx) }()\n")
+	fmt.Fprintf(buf, "        tx, db = raw, raw.DB()\n    }\n")
+	fmt.Fprintf(buf, "    created := make([]*%s, 0, len(inputs))\n", ent.Name)
+	fmt.Fprintf(buf, "    for _, chunk := range chunks {\n")
+	fmt.Fprintf(buf, "        items, err := c.insertChunk(ctx, db, chunk)\n")
+	fmt.Fprintf(buf, "        if err != nil {\n            return nil, err\n        }\n")
+	fmt.Fprintf(buf, "        created = append(created, items...)\n    }\n")
+	fmt.Fprintf(buf, "    if tx != nil {\n        if err := tx.Commit(ctx); err != nil {\n            return nil, err\n        }\n    }\n")
+	fmt.Fprintf(buf, "    if c.cache != nil {\n        for _, item := range created {\n            _ = c.cache.Set(ctx, makeCacheKey(%q, item.%s), item)\n        }\n    }\n", ent.Name, exportName(primaryField(ent).Name))
+	fmt.Fprintf(buf, "    return created, nil\n}\n\n")
+
+	fmt.Fprintf(buf, "func (c *%sClient) insertChunk(ctx context.Context, db *pg.DB, spec runtime.BulkInsertSpec) ([]*%s, error) {\n", ent.Name, ent.Name)
 	fmt.Fprintf(buf, "    sql, args, err := runtime.BuildBulkInsertSQL(spec)\n")
 	fmt.Fprintf(buf, "    if err != nil {\n        return nil, err\n    }\n")
-	fmt.Fprintf(buf, "    rows, err := c.db.Pool.Query(ctx, sql, args...)\n")
+	fmt.Fprintf(buf, "    writer := db.Writer()\n")
+	fmt.Fprintf(buf, "    if writer == nil {\n        return nil, errors.New(\"database writer pool is unavailable\")\n    }\n")
+	fmt.Fprintf(buf, "    rows, err := writer.Query(ctx, sql, args...)\n")
 	fmt.Fprintf(buf, "    if err != nil {\n        return nil, err\n    }\n")
 	fmt.Fprintf(buf, "    defer rows.Close()\n")
-	fmt.Fprintf(buf, "    var created []*%s\n", ent.Name)
+	fmt.Fprintf(buf, "    items := make([]*%s, 0, len(spec.Rows))\n", ent.Name)
 	fmt.Fprintf(buf, "    for rows.Next() {\n")
 	fmt.Fprintf(buf, "        item := new(%s)\n", ent.Name)
 	fmt.Fprintf(buf, "        if err := rows.Scan(%s); err != nil {\n            return nil, err\n        }\n", scanArgs(ent))
-	fmt.Fprintf(buf, "        created = append(created, item)\n")
-	fmt.Fprintf(buf, "        if c.cache != nil {\n            _ = c.cache.Set(ctx, makeCacheKey(%q, item.%s), item)\n        }\n", ent.Name, exportName(primaryField(ent).Name))
+	fmt.Fprintf(buf, "        items = append(items, item)\n")
 	fmt.Fprintf(buf, "    }\n")
-	fmt.Fprintf(buf, "    if err := rows.Err(); err != nil {\n        return nil, err\n    }\n")
-	fmt.Fprintf(buf, "    return created, nil\n}\n\n")
+	fmt.Fprintf(buf, "    return items, rows.Err()\n}\n\n")
+}
+
+// insertValues renders the values of the insert columns of holder, in insertColumns order.
+func insertValues(ent Entity, holder string) string {
+	fields := insertableFields(ent)
+	values := make([]string, len(fields))
+	for i, field := range fields {
+		values[i] = holder + "." + exportName(field.Name)
+	}
+	return "[]any{" + strings.Join(values, ", ") + "}"
+}
+
+func emitCopyCreateMethod(buf *bytes.Buffer, ent Entity) {
+	name := ent.Name
+	fmt.Fprintf(buf, "// CopyCreate streams inputs into %s with the COPY protocol and returns the number of rows\n", pluralize(name))
+	fmt.Fprintf(buf, "// copied. IDs, defaults and validation apply per row as in BulkCreate, and a failing row aborts\n")
+	fmt.Fprintf(buf, "// the whole copy. Rows are not read back, so the cache is left untouched. Hooks and policies see\n")
+	fmt.Fprintf(buf, "// a bulk create mutation without Inputs; ownership rules reject it.\n")
+	fmt.Fprintf(buf, "func (c *%sClient) CopyCreate(ctx context.Context, inputs iter.Seq[*%s]) (int64, error) {\n", name, name)
+	fmt.Fprintf(buf, "    m := &%sMutation{op: runtime.MutationCreate, bulk: true, copy: true, db: c.db}\n", name)
+	fmt.Fprintf(buf, "    return runtime.CastResult[int64](c.mutate(ctx, m, func(ctx context.Context, m *%sMutation) (any, error) {\n", name)
+	fmt.Fprintf(buf, "        src, stop := runtime.CopySource(inputs, func(input *%s) ([]any, error) {\n", name)
+	fmt.Fprintf(buf, "            if err := c.prepareCreate(ctx, input); err != nil {\n                return nil, err\n            }\n")
+	fmt.Fprintf(buf, "            return %s, nil\n        })\n", insertValues(ent, "input"))
+	fmt.Fprintf(buf, "        defer stop()\n")
+	fmt.Fprintf(buf, "        return c.db.CopyFrom(ctx, %q, %s, src)\n    }))\n}\n\n", pluralize(name), quoteStringSlice(insertColumns(ent)))
 }
 
 func emitByIDMethod(buf *bytes.Buffer, ent Entity) {
+	fmt.Fprintf(buf, "// ByID loads the %s with id, returning a *runtime.NotFoundError when no row visible to the\n", ent.Name)
+	fmt.Fprintf(buf, "// caller has it. It reads from the primary, or the transaction of the client, so records written\n")
+	fmt.Fprintf(buf, "// just before are found regardless of replica lag.\n")
 	fmt.Fprintf(buf, "func (c *%sClient) ByID(ctx context.Context, id string) (*%s, error) {\n", ent.Name, ent.Name)
+	if hasQueryPolicy(ent) {
+		emitPolicyFilters(buf, ent, "nil, ")
+		fmt.Fprintf(buf, "    if len(filters) > 0 {\n")
+		fmt.Fprintf(buf, "        q := c.Query()\n")
+		fmt.Fprintf(buf, "        q.predicates = append([]runtime.Predicate{{Column: %q, Operator: runtime.OpEqual, Value: id}}, filters...)\n", primaryColumn(ent))
+		fmt.Fprintf(buf, "        item, err := q.first(pg.WithPrimary(ctx))\n")
+		fmt.Fprintf(buf, "        if err == nil && item == nil {\n            return nil, &runtime.NotFoundError{Entity: %q, ID: id}\n        }\n", ent.Name)
+		fmt.Fprintf(buf, "        return item, err\n    }\n")
+	}
 	fmt.Fprintf(buf, "    var cachedKey string\n")
 	fmt.Fprintf(buf, "    if c.cache != nil {\n")
 	fmt.Fprintf(buf, "        cachedKey = makeCacheKey(%q, id)\n", ent.Name)
@@ -601,7 +725,7 @@ func emitByIDMethod(buf *bytes.Buffer, ent Entity) {
 		}
 		fmt.Fprintf(buf, "&out.%s", exportName(field.Name))
 	}
-	fmt.Fprintf(buf, "); err != nil {\n        if errors.Is(err, pgx.ErrNoRows) {\n            return nil, nil\n        }\n        return nil, err\n    }\n")
+	fmt.Fprintf(buf, "); err != nil {\n        if errors.Is(err, pgx.ErrNoRows) {\n            return nil, &runtime.NotFoundError{Entity: %q, ID: id}\n        }\n        return nil, err\n    }\n", ent.Name)
 	fmt.Fprintf(buf, "    if c.cache != nil {\n        cachedKey = makeCacheKey(%q, out.%s)\n        _ = c.cache.Set(ctx, cachedKey, out)\n    }\n", ent.Name, exportName(primaryField(ent).Name))
 	fmt.Fprintf(buf, "    return out, nil\n}\n\n")
 }
@@ -610,6 +734,15 @@ func emitListMethod(buf *bytes.Buffer, ent Entity) {
 	fmt.Fprintf(buf, "func (c *%sClient) List(ctx context.Context, limit, offset int) ([]*%s, error) {\n", ent.Name, ent.Name)
 	fmt.Fprintf(buf, "    if limit <= 0 { limit = 20 }\n")
 	fmt.Fprintf(buf, "    if offset < 0 { offset = 0 }\n")
+	if hasQueryPolicy(ent) {
+		emitPolicyFilters(buf, ent, "nil, ")
+		fmt.Fprintf(buf, "    if len(filters) > 0 {\n")
+		fmt.Fprintf(buf, "        q := c.Query()\n")
+		fmt.Fprintf(buf, "        q.predicates = filters\n")
+		fmt.Fprintf(buf, "        q.orders = []runtime.Order{{Column: %q, Direction: runtime.SortAsc}}\n", primaryColumn(ent))
+		fmt.Fprintf(buf, "        q.limit, q.offset, q.maxLimit = &limit, offset, 0\n")
+		fmt.Fprintf(buf, "        return q.all(pg.WithPrimary(ctx))\n    }\n")
+	}
 	fmt.Fprintf(buf, "    rows, err := c.db.Pool.Query(ctx, %sListQuery, limit, offset)\n", strings.ToLower(ent.Name))
 	fmt.Fprintf(buf, "    if err != nil { return nil, err }\n")
 	fmt.Fprintf(buf, "    defer rows.Close()\n")
@@ -628,6 +761,17 @@ func emitListMethod(buf *bytes.Buffer, ent Entity) {
 
 func emitCountMethod(buf *bytes.Buffer, ent Entity) {
 	fmt.Fprintf(buf, "func (c *%sClient) Count(ctx context.Context) (int, error) {\n", ent.Name)
+	if hasQueryPolicy(ent) {
+		emitPolicyFilters(buf, ent, "0, ")
+		fmt.Fprintf(buf, "    if len(filters) > 0 {\n")
+		if isSoftDelete(ent) {
+			fmt.Fprintf(buf, "        filters = append(filters, runtime.Predicate{Column: %q, Operator: runtime.OpIsNull})\n", dsl.SoftDeleteColumn)
+		}
+		fmt.Fprintf(buf, "        row := c.db.Aggregate(pg.WithPrimary(ctx), runtime.AggregateSpec{Table: %q, Predicates: filters, Aggregate: runtime.Aggregate{Func: runtime.AggCount}})\n", pluralize(ent.Name))
+		fmt.Fprintf(buf, "        var total int\n")
+		fmt.Fprintf(buf, "        if err := row.Scan(&total); err != nil {\n            return 0, err\n        }\n")
+		fmt.Fprintf(buf, "        return total, nil\n    }\n")
+	}
 	fmt.Fprintf(buf, "    row := c.db.Pool.QueryRow(ctx, %sCountQuery)\n", strings.ToLower(ent.Name))
 	fmt.Fprintf(buf, "    var total int\n")
 	fmt.Fprintf(buf, "    if err := row.Scan(&total); err != nil {\n        return 0, err\n    }\n")
@@ -635,7 +779,7 @@ func emitCountMethod(buf *bytes.Buffer, ent Entity) {
 }
 
 func emitUpdateMethod(buf *bytes.Buffer, ent Entity) {
-	fmt.Fprintf(buf, "func (c *%sClient) Update(ctx context.Context, input *%s) (*%s, error) {\n", ent.Name, ent.Name, ent.Name)
+	fmt.Fprintf(buf, "func (c *%sClient) update(ctx context.Context, input *%s) (*%s, error) {\n", ent.Name, ent.Name, ent.Name)
 	fmt.Fprintf(buf, "    if input == nil {\n        return nil, errors.New(\"input cannot be nil\")\n    }\n")
 	fmt.Fprintf(buf, "    if input.%s == \"\" {\n        return nil, errors.New(\"id is required\")\n    }\n", exportName(primaryField(ent).Name))
 
@@ -658,12 +802,18 @@ func emitUpdateMethod(buf *bytes.Buffer, ent Entity) {
 	fmt.Fprintf(buf, "    if err := ValidationRegistry.Validate(ctx, %q, validation.OpUpdate, %sValidationRecord(input), input); err != nil {\n        return nil, err\n    }\n", ent.Name, strings.ToLower(ent.Name))
 
 	updateCols := updatableColumns(ent)
-	fmt.Fprintf(buf, "    row := c.db.Pool.QueryRow(ctx, %sUpdateQuery", strings.ToLower(ent.Name))
+	emitWriterGuard(buf, "nil, ")
+	fmt.Fprintf(buf, "    row := writer.QueryRow(ctx, %sUpdateQuery", strings.ToLower(ent.Name))
 	for _, col := range updateCols {
 		field := findFieldByColumn(ent, col)
 		fmt.Fprintf(buf, ", input.%s", exportName(field.Name))
 	}
-	fmt.Fprintf(buf, ", input.%s)\n", exportName(primaryField(ent).Name))
+	version, versioned := versionField(ent)
+	if versioned {
+		fmt.Fprintf(buf, ", input.%s, input.%s)\n", exportName(primaryField(ent).Name), exportName(version.Name))
+	} else {
+		fmt.Fprintf(buf, ", input.%s)\n", exportName(primaryField(ent).Name))
+	}
 	fmt.Fprintf(buf, "    out := new(%s)\n", ent.Name)
 	fmt.Fprintf(buf, "    if err := row.Scan(")
 	for i, field := range ent.Fields {
@@ -672,7 +822,15 @@ func emitUpdateMethod(buf *bytes.Buffer, ent Entity) {
 		}
 		fmt.Fprintf(buf, "&out.%s", exportName(field.Name))
 	}
-	fmt.Fprintf(buf, "); err != nil {\n        return nil, err\n    }\n")
+	fmt.Fprintf(buf, "); err != nil {\n")
+	fmt.Fprintf(buf, "        if errors.Is(err, pgx.ErrNoRows) {\n")
+	if versioned {
+		emitStaleObjectError(buf, ent, "input", "            ")
+	} else {
+		emitNotFoundError(buf, ent, "input", "            ")
+	}
+	fmt.Fprintf(buf, "        }\n")
+	fmt.Fprintf(buf, "        return nil, err\n    }\n")
 	fmt.Fprintf(buf, "    if c.cache != nil {\n        _ = c.cache.Set(ctx, makeCacheKey(%q, out.%s), out)\n    }\n", ent.Name, exportName(primaryField(ent).Name))
 	fmt.Fprintf(buf, "    return out, nil\n}\n\n")
 }
@@ -685,7 +843,7 @@ func emitBulkUpdateMethod(buf *bytes.Buffer, ent Entity, updateCols []string) {
 	for i, col := range updateCols {
 		updateFields[i] = findFieldByColumn(ent, col)
 	}
-	fmt.Fprintf(buf, "func (c *%sClient) BulkUpdate(ctx context.Context, inputs []*%s) ([]*%s, error) {\n", ent.Name, ent.Name, ent.Name)
+	fmt.Fprintf(buf, "func (c *%sClient) bulkUpdate(ctx context.Context, inputs []*%s) ([]*%s, error) {\n", ent.Name, ent.Name, ent.Name)
 	fmt.Fprintf(buf, "    if len(inputs) == 0 {\n        return []*%s{}, nil\n    }\n", ent.Name)
 	fmt.Fprintf(buf, "    specs := make([]runtime.BulkUpdateRow, 0, len(inputs))\n")
 	needsNow := false
@@ -718,6 +876,10 @@ func emitBulkUpdateMethod(buf *bytes.Buffer, ent Entity, updateCols []string) {
 		fmt.Fprintf(buf, "input.%s", exportName(field.Name))
 	}
 	fmt.Fprintf(buf, "},\n")
+	version, versioned := versionField(ent)
+	if versioned {
+		fmt.Fprintf(buf, "            Version: input.%s,\n", exportName(version.Name))
+	}
 	fmt.Fprintf(buf, "        }\n")
 	fmt.Fprintf(buf, "        specs = append(specs, row)\n")
 	fmt.Fprintf(buf, "    }\n")
@@ -728,10 +890,31 @@ func emitBulkUpdateMethod(buf *bytes.Buffer, ent Entity, updateCols []string) {
 	fmt.Fprintf(buf, "        Columns: %s,\n", quoteStringSlice(updateCols))
 	fmt.Fprintf(buf, "        Returning: %s,\n", quoteStringSlice(columns))
 	fmt.Fprintf(buf, "        Rows: specs,\n")
+	if versioned {
+		fmt.Fprintf(buf, "        VersionColumn: %q,\n", fieldColumn(version))
+	}
+	if isSoftDelete(ent) {
+		fmt.Fprintf(buf, "        SoftDeleteColumn: %q,\n", dsl.SoftDeleteColumn)
+	}
 	fmt.Fprintf(buf, "    }\n")
 	fmt.Fprintf(buf, "    sql, args, err := runtime.BuildBulkUpdateSQL(spec)\n")
 	fmt.Fprintf(buf, "    if err != nil {\n        return nil, err\n    }\n")
-	fmt.Fprintf(buf, "    rows, err := c.db.Pool.Query(ctx, sql, args...)\n")
+	if versioned {
+		// A stale row must not leave the others updated, so the statement runs in a transaction
+		// that is only committed when every row matched.
+		fmt.Fprintf(buf, "    db := c.db\n")
+		fmt.Fprintf(buf, "    var tx *pg.Tx\n")
+		fmt.Fprintf(buf, "    if !db.InTx() {\n")
+		fmt.Fprintf(buf, "        raw, err := db.BeginTx(ctx, pgx.TxOptions{})\n")
+		fmt.Fprintf(buf, "        if err != nil {\n            return nil, err\n        }\n")
+		fmt.Fprintf(buf, "        defer func() { _ = raw.Rollback(ctx) }()\n")
+		fmt.Fprintf(buf, "        tx, db = raw, raw.DB()\n    }\n")
+		fmt.Fprintf(buf, "    writer := db.Writer()\n")
+		fmt.Fprintf(buf, "    if writer == nil {\n        return nil, errors.New(\"database writer pool is unavailable\")\n    }\n")
+	} else {
+		emitWriterGuard(buf, "nil, ")
+	}
+	fmt.Fprintf(buf, "    rows, err := writer.Query(ctx, sql, args...)\n")
 	fmt.Fprintf(buf, "    if err != nil {\n        return nil, err\n    }\n")
 	fmt.Fprintf(buf, "    defer rows.Close()\n")
 	fmt.Fprintf(buf, "    var updated []*%s\n", ent.Name)
@@ -739,36 +922,60 @@ func emitBulkUpdateMethod(buf *bytes.Buffer, ent Entity, updateCols []string) {
 	fmt.Fprintf(buf, "        item := new(%s)\n", ent.Name)
 	fmt.Fprintf(buf, "        if err := rows.Scan(%s); err != nil {\n            return nil, err\n        }\n", scanArgs(ent))
 	fmt.Fprintf(buf, "        updated = append(updated, item)\n")
-	fmt.Fprintf(buf, "        if c.cache != nil {\n            _ = c.cache.Set(ctx, makeCacheKey(%q, item.%s), item)\n        }\n", ent.Name, primaryFieldName)
+	if !versioned {
+		fmt.Fprintf(buf, "        if c.cache != nil {\n            _ = c.cache.Set(ctx, makeCacheKey(%q, item.%s), item)\n        }\n", ent.Name, primaryFieldName)
+	}
 	fmt.Fprintf(buf, "    }\n")
 	fmt.Fprintf(buf, "    if err := rows.Err(); err != nil {\n        return nil, err\n    }\n")
+	if versioned {
+		fmt.Fprintf(buf, "    if len(updated) < len(inputs) {\n")
+		fmt.Fprintf(buf, "        matched := make(map[string]struct{}, len(updated))\n")
+		fmt.Fprintf(buf, "        for _, item := range updated {\n            matched[item.%s] = struct{}{}\n        }\n", primaryFieldName)
+		fmt.Fprintf(buf, "        for _, input := range inputs {\n")
+		fmt.Fprintf(buf, "            if _, ok := matched[input.%s]; !ok {\n", primaryFieldName)
+		emitStaleObjectError(buf, ent, "input", "                ")
+		fmt.Fprintf(buf, "            }\n        }\n    }\n")
+		fmt.Fprintf(buf, "    rows.Close()\n")
+		fmt.Fprintf(buf, "    if tx != nil {\n        if err := tx.Commit(ctx); err != nil {\n            return nil, err\n        }\n    }\n")
+		fmt.Fprintf(buf, "    if c.cache != nil {\n        for _, item := range updated {\n            _ = c.cache.Set(ctx, makeCacheKey(%q, item.%s), item)\n        }\n    }\n", ent.Name, primaryFieldName)
+	}
 	fmt.Fprintf(buf, "    return updated, nil\n}\n\n")
 }
 
 func emitDeleteMethod(buf *bytes.Buffer, ent Entity) {
-	fmt.Fprintf(buf, "func (c *%sClient) Delete(ctx context.Context, id string) error {\n", ent.Name)
-	fmt.Fprintf(buf, "    if _, err := c.db.Pool.Exec(ctx, %sDeleteQuery, id); err != nil {\n        return err\n    }\n", strings.ToLower(ent.Name))
+	fmt.Fprintf(buf, "func (c *%sClient) delete(ctx context.Context, id string) error {\n", ent.Name)
+	emitWriterGuard(buf, "")
+	fmt.Fprintf(buf, "    if _, err := writer.Exec(ctx, %sDeleteQuery, id); err != nil {\n        return err\n    }\n", strings.ToLower(ent.Name))
 	fmt.Fprintf(buf, "    if c.cache != nil {\n        _ = c.cache.Delete(ctx, makeCacheKey(%q, id))\n    }\n", ent.Name)
 	fmt.Fprintf(buf, "    return nil\n}\n\n")
 }
 
 func emitBulkDeleteMethod(buf *bytes.Buffer, ent Entity) {
-	fmt.Fprintf(buf, "func (c *%sClient) BulkDelete(ctx context.Context, ids []string) (int64, error) {\n", ent.Name)
+	fmt.Fprintf(buf, "func (c *%sClient) bulkDelete(ctx context.Context, ids []string) (int64, error) {\n", ent.Name)
 	fmt.Fprintf(buf, "    if len(ids) == 0 {\n        return 0, nil\n    }\n")
 	fmt.Fprintf(buf, "    spec := runtime.BulkDeleteSpec{\n")
 	fmt.Fprintf(buf, "        Table: %q,\n", pluralize(ent.Name))
 	fmt.Fprintf(buf, "        PrimaryColumn: %q,\n", primaryColumn(ent))
 	fmt.Fprintf(buf, "        IDs: make([]any, len(ids)),\n")
+	if isSoftDelete(ent) {
+		fmt.Fprintf(buf, "        SoftDeleteColumn: %q,\n", dsl.SoftDeleteColumn)
+	}
 	fmt.Fprintf(buf, "    }\n")
 	fmt.Fprintf(buf, "    for i, id := range ids {\n        spec.IDs[i] = id\n    }\n")
 	fmt.Fprintf(buf, "    sql, args, err := runtime.BuildBulkDeleteSQL(spec)\n")
 	fmt.Fprintf(buf, "    if err != nil {\n        return 0, err\n    }\n")
-	fmt.Fprintf(buf, "    tag, err := c.db.Pool.Exec(ctx, sql, args...)\n")
+	emitWriterGuard(buf, "0, ")
+	fmt.Fprintf(buf, "    tag, err := writer.Exec(ctx, sql, args...)\n")
 	fmt.Fprintf(buf, "    if err != nil {\n        return 0, err\n    }\n")
 	fmt.Fprintf(buf, "    if c.cache != nil {\n        for _, id := range ids {\n            _ = c.cache.Delete(ctx, makeCacheKey(%q, id))\n        }\n    }\n", ent.Name)
 	fmt.Fprintf(buf, "    return int64(tag.RowsAffected()), nil\n}\n\n")
 }
 
+func emitWriterGuard(buf *bytes.Buffer, zero string) {
+	fmt.Fprintf(buf, "    writer := c.db.Writer()\n")
+	fmt.Fprintf(buf, "    if writer == nil {\n        return %serrors.New(\"database writer pool is unavailable\")\n    }\n", zero)
+}
+
 func emitValidationRecordHelper(buf *bytes.Buffer, ent Entity) {
 	fn := strings.ToLower(ent.Name) + "ValidationRecord"
 	fmt.Fprintf(buf, "func %s(input *%s) validation.Record {\n", fn, ent.Name)
@@ -783,24 +990,38 @@ func emitValidationRecordHelper(buf *bytes.Buffer, ent Entity) {
 	fmt.Fprintf(buf, "    }\n}\n\n")
 }
 
-func emitQueryBuilder(buf *bytes.Buffer, ent Entity) {
+func emitQueryBuilder(buf *bytes.Buffer, ent Entity, entityIndex map[string]Entity) {
 	spec := ent.Query
 	columns := entityColumns(ent)
 
 	fmt.Fprintf(buf, "type %sQuery struct {\n", ent.Name)
 	fmt.Fprintf(buf, "    db *pg.DB\n")
+	fmt.Fprintf(buf, "    hooks *runtime.HookRegistry\n")
+	fmt.Fprintf(buf, "    op runtime.QueryOp\n")
 	fmt.Fprintf(buf, "    predicates []runtime.Predicate\n")
 	fmt.Fprintf(buf, "    orders []runtime.Order\n")
 	fmt.Fprintf(buf, "    limit *int\n")
 	fmt.Fprintf(buf, "    offset int\n")
+	fmt.Fprintf(buf, "    after string\n")
+	fmt.Fprintf(buf, "    before string\n")
+	fmt.Fprintf(buf, "    fromEnd bool\n")
 	fmt.Fprintf(buf, "    defaultLimit int\n")
 	fmt.Fprintf(buf, "    maxLimit int\n")
+	if isSoftDelete(ent) {
+		fmt.Fprintf(buf, "    withDeleted bool\n")
+		fmt.Fprintf(buf, "    onlyDeleted bool\n")
+	}
+	fmt.Fprintf(buf, "    partition string\n")
+	fmt.Fprintf(buf, "    through *runtime.EdgeJoin\n")
+	fmt.Fprintf(buf, "    throughKeys any\n")
+	emitEagerQueryFields(buf, ent, entityIndex)
 	fmt.Fprintf(buf, "}\n\n")
 
 	fmt.Fprintf(buf, "func (c *%sClient) Query() *%sQuery {\n", ent.Name, ent.Name)
-	fmt.Fprintf(buf, "    return &%sQuery{db: c.db, defaultLimit: %d, maxLimit: %d}\n}\n\n", ent.Name, spec.DefaultLimit, spec.MaxLimit)
+	fmt.Fprintf(buf, "    return &%sQuery{db: c.db, hooks: c.hooks, defaultLimit: %d, maxLimit: %d}\n}\n\n", ent.Name, spec.DefaultLimit, spec.MaxLimit)
 
 	fmt.Fprintf(buf, "func (q *%sQuery) Limit(n int) *%sQuery {\n", ent.Name, ent.Name)
+	fmt.Fprintf(buf, "    q.fromEnd = false\n")
 	fmt.Fprintf(buf, "    if n <= 0 {\n        q.limit = nil\n        return q\n    }\n")
 	fmt.Fprintf(buf, "    q.limit = &n\n    return q\n}\n\n")
 
@@ -813,12 +1034,18 @@ func emitQueryBuilder(buf *bytes.Buffer, ent Entity) {
 		fieldIndex[strings.ToLower(field.Name)] = field
 	}
 
+	fmt.Fprintf(buf, "// Where adds predicates, such as those in the %s package, joined with AND.\n", predicatePackageName(ent))
+	fmt.Fprintf(buf, "func (q *%sQuery) Where(preds ...runtime.Predicate) *%sQuery {\n", ent.Name, ent.Name)
+	fmt.Fprintf(buf, "    q.predicates = append(q.predicates, preds...)\n")
+	fmt.Fprintf(buf, "    return q\n}\n\n")
+
 	for _, pred := range spec.Predicates {
 		methodName := "Where" + predicateMethodName(pred)
 		goType := predicateGoType(pred, fieldIndex)
 		column := predicateColumn(pred, fieldIndex)
-		fmt.Fprintf(buf, "func (q *%sQuery) %s(value %s) *%sQuery {\n", ent.Name, methodName, goType, ent.Name)
-		fmt.Fprintf(buf, "    q.predicates = append(q.predicates, runtime.Predicate{Column: %q, Operator: %s, Value: value})\n", column, runtimeOperatorLiteral(pred.Operator))
+		params, args := predicateParams(pred.Operator, goType)
+		fmt.Fprintf(buf, "func (q *%sQuery) %s(%s) *%sQuery {\n", ent.Name, methodName, params, ent.Name)
+		fmt.Fprintf(buf, "    q.predicates = append(q.predicates, %s)\n", predicateExpr(pred.Operator, strconv.Quote(column), args...))
 		fmt.Fprintf(buf, "    return q\n}\n\n")
 	}
 
@@ -830,13 +1057,24 @@ func emitQueryBuilder(buf *bytes.Buffer, ent Entity) {
 		fmt.Fprintf(buf, "    return q\n}\n\n")
 	}
 
-	emitQueryAll(buf, ent, columns)
+	emitKeysetQueryMethods(buf, ent)
+	emitSoftDeleteQueryMethods(buf, ent)
+	emitEagerQueryMethods(buf, ent, entityIndex)
+	emitQueryInterceptors(buf, ent, scopesEdges(entityIndex))
+	emitQueryAll(buf, ent, columns, len(eagerEdges(ent, entityIndex)) > 0)
 	emitQueryStream(buf, ent, columns)
 	emitQueryFirst(buf, ent)
 
+	hasCount := false
 	for _, agg := range spec.Aggregates {
 		emitAggregateMethod(buf, ent, agg, fieldIndex)
+		hasCount = hasCount || exportName(agg.Name) == "Count"
 	}
+	if !hasCount {
+		// Filtered connections count their rows through Count, so every query has one.
+		emitAggregateMethod(buf, ent, dsl.CountAggregate("Count"), fieldIndex)
+	}
+	emitGroupByBuilder(buf, ent)
 
 	fmt.Fprintf(buf, "func (q *%sQuery) clone() *%sQuery {\n", ent.Name, ent.Name)
 	fmt.Fprintf(buf, "    cp := *q\n")
@@ -853,15 +1091,28 @@ func emitQueryBuilder(buf *bytes.Buffer, ent Entity) {
 	fmt.Fprintf(buf, "    return limit\n}\n")
 }
 
-func emitQueryAll(buf *bytes.Buffer, ent Entity, columns []string) {
-	fmt.Fprintf(buf, "func (q *%sQuery) All(ctx context.Context) ([]*%s, error) {\n", ent.Name, ent.Name)
+func emitQueryAll(buf *bytes.Buffer, ent Entity, columns []string, eager bool) {
+	fmt.Fprintf(buf, "func (q *%sQuery) all(ctx context.Context) ([]*%s, error) {\n", ent.Name, ent.Name)
+	fmt.Fprintf(buf, "    return q.scan(ctx, nil)\n}\n\n")
+
+	fmt.Fprintf(buf, "// scan runs the select. When the query joins a link table for eager loading, owner supplies the\n")
+	fmt.Fprintf(buf, "// destination for each row's link key.\n")
+	fmt.Fprintf(buf, "func (q *%sQuery) scan(ctx context.Context, owner func() any) ([]*%s, error) {\n", ent.Name, ent.Name)
+	emitKeysetSpec(buf, "nil")
 	fmt.Fprintf(buf, "    spec := runtime.SelectSpec{\n")
 	fmt.Fprintf(buf, "        Table: %q,\n", pluralize(ent.Name))
 	fmt.Fprintf(buf, "        Columns: %s,\n", quoteStringSlice(columns))
-	fmt.Fprintf(buf, "        Predicates: q.predicates,\n")
+	fmt.Fprintf(buf, "        Predicates: %s,\n", queryPredicates(ent))
 	fmt.Fprintf(buf, "        Orders: q.orders,\n")
 	fmt.Fprintf(buf, "        Limit: q.effectiveLimit(),\n")
 	fmt.Fprintf(buf, "        Offset: q.offset,\n")
+	fmt.Fprintf(buf, "        PartitionBy: q.partition,\n")
+	fmt.Fprintf(buf, "        Through: q.through,\n")
+	fmt.Fprintf(buf, "        ThroughKeys: q.throughKeys,\n")
+	fmt.Fprintf(buf, "        KeyColumn: %q,\n", primaryColumn(ent))
+	fmt.Fprintf(buf, "        After: after,\n")
+	fmt.Fprintf(buf, "        Before: before,\n")
+	fmt.Fprintf(buf, "        FromEnd: q.fromEnd,\n")
 	fmt.Fprintf(buf, "    }\n")
 	fmt.Fprintf(buf, "    rows, err := q.db.Select(ctx, spec)\n")
 	fmt.Fprintf(buf, "    if err != nil {\n        return nil, err\n    }\n")
@@ -869,21 +1120,32 @@ func emitQueryAll(buf *bytes.Buffer, ent Entity, columns []string) {
 	fmt.Fprintf(buf, "    var result []*%s\n", ent.Name)
 	fmt.Fprintf(buf, "    for rows.Next() {\n")
 	fmt.Fprintf(buf, "        item := new(%s)\n", ent.Name)
-	fmt.Fprintf(buf, "        if err := rows.Scan(%s); err != nil {\n            return nil, err\n        }\n", scanArgs(ent))
+	fmt.Fprintf(buf, "        dest := %s\n", scanDest(ent))
+	fmt.Fprintf(buf, "        if owner != nil {\n            dest = append(dest, owner())\n        }\n")
+	fmt.Fprintf(buf, "        if err := rows.Scan(dest...); err != nil {\n            return nil, err\n        }\n")
 	fmt.Fprintf(buf, "        result = append(result, item)\n    }\n")
 	fmt.Fprintf(buf, "    if err := rows.Err(); err != nil {\n        return nil, err\n    }\n")
+	if eager {
+		fmt.Fprintf(buf, "    rows.Close()\n")
+		fmt.Fprintf(buf, "    if err := q.loadEdges(ctx, result); err != nil {\n        return nil, err\n    }\n")
+	}
 	fmt.Fprintf(buf, "    return result, nil\n}\n\n")
 }
 
 func emitQueryStream(buf *bytes.Buffer, ent Entity, columns []string) {
-	fmt.Fprintf(buf, "func (q *%sQuery) Stream(ctx context.Context) (*runtime.Stream[*%s], error) {\n", ent.Name, ent.Name)
+	fmt.Fprintf(buf, "func (q *%sQuery) stream(ctx context.Context) (*runtime.Stream[*%s], error) {\n", ent.Name, ent.Name)
+	emitKeysetSpec(buf, "nil")
 	fmt.Fprintf(buf, "    spec := runtime.SelectSpec{\n")
 	fmt.Fprintf(buf, "        Table: %q,\n", pluralize(ent.Name))
 	fmt.Fprintf(buf, "        Columns: %s,\n", quoteStringSlice(columns))
-	fmt.Fprintf(buf, "        Predicates: q.predicates,\n")
+	fmt.Fprintf(buf, "        Predicates: %s,\n", queryPredicates(ent))
 	fmt.Fprintf(buf, "        Orders: q.orders,\n")
 	fmt.Fprintf(buf, "        Limit: q.effectiveLimit(),\n")
 	fmt.Fprintf(buf, "        Offset: q.offset,\n")
+	fmt.Fprintf(buf, "        KeyColumn: %q,\n", primaryColumn(ent))
+	fmt.Fprintf(buf, "        After: after,\n")
+	fmt.Fprintf(buf, "        Before: before,\n")
+	fmt.Fprintf(buf, "        FromEnd: q.fromEnd,\n")
 	fmt.Fprintf(buf, "    }\n")
 	fmt.Fprintf(buf, "    rows, err := q.db.Select(ctx, spec)\n")
 	fmt.Fprintf(buf, "    if err != nil {\n        return nil, err\n    }\n")
@@ -895,11 +1157,11 @@ func emitQueryStream(buf *bytes.Buffer, ent Entity, columns []string) {
 }
 
 func emitQueryFirst(buf *bytes.Buffer, ent Entity) {
-	fmt.Fprintf(buf, "func (q *%sQuery) First(ctx context.Context) (*%s, error) {\n", ent.Name, ent.Name)
+	fmt.Fprintf(buf, "func (q *%sQuery) first(ctx context.Context) (*%s, error) {\n", ent.Name, ent.Name)
 	fmt.Fprintf(buf, "    clone := q.clone()\n")
 	fmt.Fprintf(buf, "    one := 1\n")
 	fmt.Fprintf(buf, "    clone.limit = &one\n")
-	fmt.Fprintf(buf, "    items, err := clone.All(ctx)\n")
+	fmt.Fprintf(buf, "    items, err := clone.all(ctx)\n")
 	fmt.Fprintf(buf, "    if err != nil {\n        return nil, err\n    }\n")
 	fmt.Fprintf(buf, "    if len(items) == 0 {\n        return nil, nil\n    }\n")
 	fmt.Fprintf(buf, "    return items[0], nil\n}\n\n")
@@ -912,10 +1174,16 @@ func emitAggregateMethod(buf *bytes.Buffer, ent Entity, agg dsl.Aggregate, field
 	}
 	goType := aggregateGoType(agg, fields)
 	column := aggregateColumn(agg, fields)
+	queryOp := "runtime.QueryAggregate"
+	if agg.Func == dsl.AggCount {
+		queryOp = "runtime.QueryCount"
+	}
 	fmt.Fprintf(buf, "func (q *%sQuery) %s(ctx context.Context) (%s, error) {\n", ent.Name, methodName, goType)
+	fmt.Fprintf(buf, "    return runtime.CastResult[%s](q.intercept(ctx, %s, func(ctx context.Context, q *%sQuery) (any, error) {\n        return q.%s(ctx)\n    }))\n}\n\n", goType, queryOp, ent.Name, lowerCamel(methodName))
+	fmt.Fprintf(buf, "func (q *%sQuery) %s(ctx context.Context) (%s, error) {\n", ent.Name, lowerCamel(methodName), goType)
 	fmt.Fprintf(buf, "    spec := runtime.AggregateSpec{\n")
 	fmt.Fprintf(buf, "        Table: %q,\n", pluralize(ent.Name))
-	fmt.Fprintf(buf, "        Predicates: q.predicates,\n")
+	fmt.Fprintf(buf, "        Predicates: %s,\n", queryPredicates(ent))
 	fmt.Fprintf(buf, "        Aggregate: runtime.Aggregate{Func: %s, Column: %q},\n", runtimeAggregateLiteral(agg.Func), column)
 	fmt.Fprintf(buf, "    }\n")
 	fmt.Fprintf(buf, "    row := q.db.Aggregate(ctx, spec)\n")
@@ -955,6 +1223,9 @@ func emitToOneLoader(buf *bytes.Buffer, source Entity, edge dsl.Edge, entityInde
 	fkFieldName := exportName(fkField.Name)
 	columns := entityColumns(target)
 	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s IN (%%s)", strings.Join(columns, ", "), pluralize(edge.Target), fieldColumn(targetPrimary))
+	if isSoftDelete(target) {
+		query += " AND " + softDeleteScope("")
+	}
 	constName := fmt.Sprintf("%s%sRelationQuery", strings.ToLower(source.Name), exportName(edge.Name))
 	fmt.Fprintf(buf, "const %s = `%s`\n", constName, query)
 
@@ -974,6 +1245,7 @@ func emitToOneLoader(buf *bytes.Buffer, source Entity, edge dsl.Edge, entityInde
 	fmt.Fprintf(buf, "        if isZero(fk) {\n            edges.%s = nil\n            continue\n        }\n        if _, ok := seen[fk]; !ok {\n            seen[fk] = struct{}{}\n            keys = append(keys, fk)\n        }\n    }\n", exportName(edge.Name))
 	fmt.Fprintf(buf, "    if len(keys) == 0 {\n        return nil\n    }\n")
 	fmt.Fprintf(buf, "    sql, args := buildInQuery(%s, keys)\n", constName)
+	emitLoaderPolicy(buf, target, "")
 	fmt.Fprintf(buf, "    rows, err := c.db.Pool.Query(ctx, sql, args...)\n    if err != nil {\n        return err\n    }\n   defer rows.Close()\n")
 	fmt.Fprintf(buf, "    related := make(map[keyType]*%s, len(keys))\n", edge.Target)
 	fmt.Fprintf(buf, "    for rows.Next() {\n        item := new(%s)\n        if err := rows.Scan(%s); err != nil {\n     return err\n        }\n        key := item.%s\n        related[key] = item\n    }\n", edge.Target, scanArgs(target), targetKeyField)
@@ -1008,6 +1280,9 @@ func emitToManyLoader(buf *bytes.Buffer, source Entity, edge dsl.Edge, entityInd
 	refFieldName := exportName(refField.Name)
 	columns := entityColumns(target)
 	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s IN (%%s)", strings.Join(columns, ", "), pluralize(edge.Target), targetRefColumn)
+	if isSoftDelete(target) {
+		query += " AND " + softDeleteScope("")
+	}
 	constName := fmt.Sprintf("%s%sRelationQuery", strings.ToLower(source.Name), exportName(edge.Name))
 	fmt.Fprintf(buf, "const %s = `%s`\n", constName, query)
 
@@ -1020,6 +1295,7 @@ func emitToManyLoader(buf *bytes.Buffer, source Entity, edge dsl.Edge, entityInd
 	fmt.Fprintf(buf, "    for _, parent := range parents {\n        if parent == nil {\n            continue\n        }\n    key := parent.%s\n        if isZero(key) {\n            edges := ensure%sEdges(parent)\n            if edges.%s == nil {\n                edges.%s = []*%s{}\n            }\n            edges.markLoaded(%q)\n            continue\n        }\n        if _, ok := seen[key]; !ok {\n            seen[key] = struct{}{}\n            keys = append(keys, key)\n        }\n        buckets[key] = append(buckets[key], parent)\n    }\n", sourceKeyName, source.Name, exportName(edge.Name), exportName(edge.Name), edge.Target, edge.Name)
 	fmt.Fprintf(buf, "    if len(keys) == 0 {\n        for _, parent := range parents {\n            if parent == nil {\n                continue\n            }\n            edges := ensure%sEdges(parent)\n            if edges.%s == nil {\n                edges.%s = []*%s{}\n            }\n            edges.markLoaded(%q)\n        }\n        return nil\n    }\n", source.Name, exportName(edge.Name), exportName(edge.Name), edge.Target, edge.Name)
 	fmt.Fprintf(buf, "    sql, args := buildInQuery(%s, keys)\n", constName)
+	emitLoaderPolicy(buf, target, "")
 	fmt.Fprintf(buf, "    rows, err := c.db.Pool.Query(ctx, sql, args...)\n    if err != nil {\n        return err\n    }\n   defer rows.Close()\n")
 	fmt.Fprintf(buf, "    for rows.Next() {\n        item := new(%s)\n        if err := rows.Scan(%s); err != nil {\n     return err\n        }\n        var owner keyType\n", edge.Target, scanArgs(target))
 	if isNullablePointerField(refField) {
@@ -1051,6 +1327,9 @@ func emitManyToManyLoader(buf *bytes.Buffer, source Entity, edge dsl.Edge, entit
 	selectCols := append([]string{}, columns...)
 	selectCols = append(selectCols, fmt.Sprintf("jt.%s", leftColumn))
 	query := fmt.Sprintf("SELECT %s FROM %s AS t JOIN %s AS jt ON t.%s = jt.%s WHERE jt.%s IN (%%s)", strings.Join(selectCols, ", "), pluralize(edge.Target), joinTable, fieldColumn(targetPrimary), rightColumn, leftColumn)
+	if isSoftDelete(target) {
+		query += " AND " + softDeleteScope("t")
+	}
 	constName := fmt.Sprintf("%s%sRelationQuery", strings.ToLower(source.Name), exportName(edge.Name))
 	fmt.Fprintf(buf, "const %s = `%s`\n", constName, query)
 
@@ -1063,6 +1342,7 @@ func emitManyToManyLoader(buf *bytes.Buffer, source Entity, edge dsl.Edge, entit
 	fmt.Fprintf(buf, "    for _, parent := range parents {\n        if parent == nil {\n            continue\n        }\n        key := parent.%s\n        if isZero(key) {\n            edges := ensure%sEdges(parent)\n            if edges.%s == nil {\n                edges.%s = []*%s{}\n            }\n            edges.markLoaded(%q)\n            continue\n        }\n        if _, ok := seen[key]; !ok {\n            seen[key] = struct{}{}\n            keys = append(keys, key)\n        }\n        buckets[key] = append(buckets[key], parent)\n    }\n", sourceKeyName, source.Name, exportName(edge.Name), exportName(edge.Name), edge.Target, edge.Name)
 	fmt.Fprintf(buf, "    if len(keys) == 0 {\n        for _, parent := range parents {\n            if parent == nil {\n                continue\n            }\n            edges := ensure%sEdges(parent)\n            if edges.%s == nil {\n                edges.%s = []*%s{}\n            }\n            edges.markLoaded(%q)\n        }\n        return nil\n    }\n", source.Name, exportName(edge.Name), exportName(edge.Name), edge.Target, edge.Name)
 	fmt.Fprintf(buf, "    sql, args := buildInQuery(%s, keys)\n", constName)
+	emitLoaderPolicy(buf, target, "t")
 	fmt.Fprintf(buf, "    rows, err := c.db.Pool.Query(ctx, sql, args...)\n    if err != nil {\n        return err\n    }\n    defer rows.Close()\n")
 	fmt.Fprintf(buf, "    for rows.Next() {\n        item := new(%s)\n        var owner keyType\n        if err := rows.Scan(%s); err != nil {\n            return err\n        }\n        parents, ok := buckets[owner]\n        if !ok {\n            continue\n        }\n        for _, parent := range parents {\n            edges := ensure%sEdges(parent)\n            edges.%s = append(edges.%s, item)\n        }\n    }\n", edge.Target, scanArgsWithExtra(target, "&owner"), source.Name, exportName(edge.Name), exportName(edge.Name))
 	fmt.Fprintf(buf, "    if err := rows.Err(); err != nil {\n        return err\n    }\n")
@@ -1070,6 +1350,14 @@ func emitManyToManyLoader(buf *bytes.Buffer, source Entity, edge dsl.Edge, entit
 	fmt.Fprintf(buf, "    return nil\n}\n\n")
 }
 
+func emitLoaderPolicy(buf *bytes.Buffer, target Entity, qualifier string) {
+	if !hasQueryPolicy(target) {
+		return
+	}
+	emitPolicyFilters(buf, target, "")
+	fmt.Fprintf(buf, "    sql, args = privacy.AppendFilters(sql, args, %q, filters)\n", qualifier)
+}
+
 func emitRelationshipHelpers(buf *bytes.Buffer) {
 	fmt.Fprintf(buf, "func buildInQuery[T any](base string, values []T) (string, []any) {\n")
 	fmt.Fprintf(buf, "    if len(values) == 0 {\n        return base, nil\n    }\n")
@@ -1221,6 +1509,24 @@ func operatorSuffix(op dsl.ComparisonOperator) string {
 		return "LTE"
 	case dsl.OpILike:
 		return "ILike"
+	case dsl.OpLike:
+		return "Like"
+	case dsl.OpIn:
+		return "In"
+	case dsl.OpNotIn:
+		return "NotIn"
+	case dsl.OpIsNull:
+		return "IsNull"
+	case dsl.OpNotNull:
+		return "NotNull"
+	case dsl.OpBetween:
+		return "Between"
+	case dsl.OpHasPrefix:
+		return "HasPrefix"
+	case dsl.OpHasSuffix:
+		return "HasSuffix"
+	case dsl.OpContains:
+		return "Contains"
 	default:
 		return "Eq"
 	}
@@ -1242,6 +1548,8 @@ func runtimeOperatorLiteral(op dsl.ComparisonOperator) string {
 		return "runtime.OpLTE"
 	case dsl.OpILike:
 		return "runtime.OpILike"
+	case dsl.OpLike:
+		return "runtime.OpLike"
 	default:
 		return "runtime.OpEqual"
 	}
@@ -1433,6 +1741,11 @@ func isReadOnlyField(field dsl.Field) bool {
 	return field.ReadOnly
 }
 
+func isImmutableField(field dsl.Field) bool {
+	immutable, _ := field.Annotations["immutable"].(bool)
+	return immutable
+}
+
 func placeholders(n int) string {
 	items := make([]string, n)
 	for i := 0; i < n; i++ {
@@ -1465,7 +1778,7 @@ func updatableColumns(ent Entity) []string {
 		if field.IsPrimary {
 			continue
 		}
-		if isReadOnlyField(field) {
+		if isReadOnlyField(field) || isImmutableField(field) || isVersionField(field) {
 			continue
 		}
 		if field.HasDefaultNow && !field.HasUpdateNow {
@@ -0,0 +1,95 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// maxIdentifierLength is PostgreSQL's NAMEDATALEN minus one. Longer names are truncated by the
+// database, so default constraint names beyond it cannot be predicted reliably.
+const maxIdentifierLength = 63
+
+// constraintSchemaVar names the runtime.ConstraintSchema emitted for ent.
+func constraintSchemaVar(ent Entity) string {
+	return lowerCamel(ent.Name) + "Constraints"
+}
+
+type tableConstraint struct {
+	name    string
+	kind    string
+	columns []string
+}
+
+// tableConstraints lists the named constraints the migrations create on table: its primary key,
+// unique columns and indexes, foreign keys and enum checks.
+func tableConstraints(table TableSnapshot) []tableConstraint {
+	var constraints []tableConstraint
+	add := func(name, kind string, columns ...string) {
+		if len(name) > maxIdentifierLength || len(columns) == 0 {
+			return
+		}
+		constraints = append(constraints, tableConstraint{name: name, kind: kind, columns: columns})
+	}
+	add(table.Name+"_pkey", "ConstraintUnique", table.PrimaryKey...)
+	for _, col := range table.Columns {
+		if col.Unique {
+			add(fmt.Sprintf("%s_%s_key", table.Name, col.Name), "ConstraintUnique", col.Name)
+		}
+		if len(col.EnumValues) > 0 {
+			add(enumConstraintName(table.Name, col.Name), "ConstraintCheck", col.Name)
+		}
+	}
+	for _, idx := range table.Indexes {
+		if idx.Unique {
+			add(idx.Name, "ConstraintUnique", idx.Columns...)
+		}
+	}
+	for _, fk := range table.ForeignKeys {
+		add(fk.Constraint, "ConstraintForeignKey", fk.Column)
+	}
+	sort.Slice(constraints, func(i, j int) bool { return constraints[i].name < constraints[j].name })
+	return constraints
+}
+
+// emitConstraintSchemas emits a runtime.ConstraintSchema per entity, built from the schema
+// snapshot the migrations are diffed against, so writes can report violations by field.
+func emitConstraintSchemas(buf *bytes.Buffer, entities []Entity) {
+	tables := map[string]TableSnapshot{}
+	for _, table := range buildSchemaSnapshot(entities, extensionFlags{}).Tables {
+		tables[table.Name] = table
+	}
+	for _, ent := range entities {
+		table := tables[pluralize(ent.Name)]
+		fields := map[string]string{}
+		var columns []string
+		for _, field := range ent.Fields {
+			if _, dup := fields[fieldColumn(field)]; !dup {
+				columns = append(columns, fieldColumn(field))
+			}
+			fields[fieldColumn(field)] = exportName(field.Name)
+		}
+		fmt.Fprintf(buf, "var %s = &runtime.ConstraintSchema{\n", constraintSchemaVar(ent))
+		fmt.Fprintf(buf, "    Entity: %q,\n", ent.Name)
+		fmt.Fprintf(buf, "    Constraints: map[string]runtime.Constraint{\n")
+		for _, constraint := range tableConstraints(table) {
+			var names []string
+			for _, column := range constraint.columns {
+				if name, ok := fields[column]; ok {
+					names = append(names, name)
+				}
+			}
+			fmt.Fprintf(buf, "        %q: {Kind: runtime.%s, Columns: %s", constraint.name, constraint.kind, quoteStringSlice(constraint.columns))
+			if len(names) > 0 {
+				fmt.Fprintf(buf, ", Fields: %s", quoteStringSlice(names))
+			}
+			fmt.Fprintf(buf, "},\n")
+		}
+		fmt.Fprintf(buf, "    },\n")
+		fmt.Fprintf(buf, "    Fields: map[string]string{\n")
+		for _, column := range columns {
+			fmt.Fprintf(buf, "        %q: %q,\n", column, fields[column])
+		}
+		fmt.Fprintf(buf, "    },\n}\n\n")
+	}
+}
@@ -0,0 +1,105 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+const constraintClientTest = `package gen_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"example.com/app/orm/gen"
+	"github.com/deicod/erm/orm/pg"
+	"github.com/deicod/erm/orm/runtime"
+)
+
+func TestTypedErrors(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := gen.NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+	ctx := context.Background()
+
+	mock.ExpectQuery("SELECT id, email, role FROM users").WithArgs("u9").WillReturnRows(mock.NewRows([]string{"id", "email", "role"}))
+	if user, err := client.Users().ByID(ctx, "u9"); !runtime.IsNotFound(err) || user != nil {
+		t.Fatalf("expected not found, got %v (err %v)", user, err)
+	}
+
+	mock.ExpectQuery("UPDATE users").WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), "u9").WillReturnRows(mock.NewRows([]string{"id", "email", "role"}))
+	var notFound *runtime.NotFoundError
+	if _, err := client.Users().Update(ctx, &gen.User{ID: "u9", Email: "a@example.com", Role: "admin"}); !errors.As(err, &notFound) || notFound.Entity != "User" || notFound.ID != "u9" {
+		t.Fatalf("expected not found from update, got %v", err)
+	}
+
+	mock.ExpectQuery("INSERT INTO users").WithArgs("u1", "a@example.com", "admin").
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "users_email_key", Detail: "Key (email)=(a@example.com) already exists."})
+	_, err = client.Users().Create(ctx, &gen.User{ID: "u1", Email: "a@example.com", Role: "admin"})
+	var constraintErr *runtime.ConstraintError
+	if !errors.As(err, &constraintErr) || constraintErr.Kind != runtime.ConstraintUnique || constraintErr.Entity != "User" || !reflect.DeepEqual(constraintErr.Fields, []string{"Email"}) {
+		t.Fatalf("expected unique violation on Email, got %v", err)
+	}
+
+	mock.ExpectQuery("INSERT INTO users").WithArgs("u2", "b@example.com", "root").
+		WillReturnError(&pgconn.PgError{Code: "23514", ConstraintName: "users_role_enum_check"})
+	_, err = client.Users().Create(ctx, &gen.User{ID: "u2", Email: "b@example.com", Role: "root"})
+	if !errors.As(err, &constraintErr) || constraintErr.Kind != runtime.ConstraintCheck || !reflect.DeepEqual(constraintErr.Fields, []string{"Role"}) {
+		t.Fatalf("expected check violation on Role, got %v", err)
+	}
+
+	mock.ExpectQuery("INSERT INTO posts").WithArgs("p1", "u9").
+		WillReturnError(&pgconn.PgError{Code: "23503", ConstraintName: "fk_posts_author_id"})
+	_, err = client.Posts().Create(ctx, &gen.Post{ID: "p1", AuthorID: "u9"})
+	if !runtime.IsConstraintError(err, runtime.ConstraintForeignKey) || !errors.As(err, &constraintErr) || !reflect.DeepEqual(constraintErr.Fields, []string{"AuthorID"}) {
+		t.Fatalf("expected foreign key violation on AuthorID, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+`
+
+func TestWriteORMArtifacts_ConstraintErrors(t *testing.T) {
+	entities := []Entity{
+		{
+			Name:   "User",
+			Fields: []dsl.Field{dsl.String("id").Primary(), dsl.String("email").Unique(), dsl.Enum("role", "admin", "member")},
+		},
+		{
+			Name:   "Post",
+			Fields: []dsl.Field{dsl.String("id").Primary(), dsl.String("author_id")},
+			Edges:  []dsl.Edge{dsl.ToOne("author", "User").Field("author_id")},
+		},
+	}
+	for i := range entities {
+		ensureDefaultQuery(&entities[i])
+	}
+
+	root := t.TempDir()
+	if err := writeORMArtifacts(root, entities); err != nil {
+		t.Fatalf("writeORMArtifacts: %v", err)
+	}
+	client, err := os.ReadFile(filepath.Join(root, "orm", "gen", "client_gen.go"))
+	if err != nil {
+		t.Fatalf("read client: %v", err)
+	}
+	src := string(client)
+	mustContain(t, src, "var userConstraints = &runtime.ConstraintSchema{")
+	mustContain(t, src, `"users_email_key":       {Kind: runtime.ConstraintUnique, Columns: []string{"email"}, Fields: []string{"Email"}},`)
+	mustContain(t, src, `"fk_posts_author_id": {Kind: runtime.ConstraintForeignKey, Columns: []string{"author_id"}, Fields: []string{"AuthorID"}},`)
+	mustContain(t, src, "return out, runtime.WrapConstraintError(err, postConstraints)")
+
+	runGeneratedORMTest(t, root, "constraint_test.go", constraintClientTest)
+}
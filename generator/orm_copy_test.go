@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+const copyClientTest = `package gen_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"example.com/app/orm/gen"
+	"github.com/deicod/erm/orm/pg"
+	"github.com/deicod/erm/orm/runtime"
+)
+
+func TestCopyCreate(t *testing.T) {
+	mock, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := gen.NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+
+	mock.ExpectCopyFrom(pgx.Identifier{"users"}, []string{"id", "email", "name", "visits"}).WillReturnResult(2)
+	count, err := client.Users().CopyCreate(context.Background(), slices.Values([]*gen.User{
+		{ID: "u1", Email: "ada@example.com"},
+		{ID: "u2", Email: "bob@example.com"},
+	}))
+	if err != nil {
+		t.Fatalf("copy create: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 copied rows, got %d", count)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestBulkCreateChunks(t *testing.T) {
+	mock, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := gen.NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+
+	columns := []string{"id", "email", "name", "visits"}
+	inputs := make([]*gen.User, 20000)
+	rows := make([][]any, len(inputs))
+	for i := range inputs {
+		inputs[i] = &gen.User{ID: fmt.Sprintf("u%d", i), Email: fmt.Sprintf("u%d@example.com", i)}
+		rows[i] = []any{inputs[i].ID, inputs[i].Email, "", int32(0)}
+	}
+	chunks := runtime.ChunkBulkInsert(runtime.BulkInsertSpec{Table: "users", Columns: columns, Returning: columns, Rows: rows})
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(chunks))
+	}
+	mock.ExpectBegin()
+	for _, chunk := range chunks {
+		sql, args, err := runtime.BuildBulkInsertSQL(chunk)
+		if err != nil {
+			t.Fatalf("build: %v", err)
+		}
+		result := mock.NewRows(columns)
+		for _, row := range chunk.Rows {
+			result.AddRow(row...)
+		}
+		mock.ExpectQuery(sql).WithArgs(args...).WillReturnRows(result)
+	}
+	mock.ExpectCommit()
+
+	created, err := client.Users().BulkCreate(context.Background(), inputs)
+	if err != nil {
+		t.Fatalf("bulk create: %v", err)
+	}
+	if len(created) != len(inputs) || created[len(created)-1].ID != "u19999" {
+		t.Fatalf("unexpected created rows: %d", len(created))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+`
+
+func TestWriteORMArtifacts_CopyCreate(t *testing.T) {
+	entities := []Entity{
+		{
+			Name:   "User",
+			Fields: []dsl.Field{dsl.String("id").Primary(), dsl.String("email").Unique(), dsl.String("name"), dsl.Integer("visits")},
+		},
+	}
+	for i := range entities {
+		applySoftDelete(&entities[i])
+		ensureDefaultQuery(&entities[i])
+	}
+
+	root := t.TempDir()
+	if err := writeORMArtifacts(root, entities); err != nil {
+		t.Fatalf("writeORMArtifacts: %v", err)
+	}
+	client, err := os.ReadFile(filepath.Join(root, "orm", "gen", "client_gen.go"))
+	if err != nil {
+		t.Fatalf("read client: %v", err)
+	}
+	mustContain(t, string(client), "func (c *UserClient) CopyCreate(ctx context.Context, inputs iter.Seq[*User]) (int64, error) {")
+	mustContain(t, string(client), "chunks := runtime.ChunkBulkInsert(spec)")
+
+	runGeneratedORMTest(t, root, "copy_test.go", copyClientTest)
+}
@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+// eagerEdges lists the edges that can be eager-loaded from ent's query builder.
+func eagerEdges(ent Entity, entityIndex map[string]Entity) []edgeJoin {
+	var joins []edgeJoin
+	for _, edge := range ent.Edges {
+		if join, ok := resolveEdgeJoin(ent, edge, entityIndex); ok {
+			joins = append(joins, join)
+		}
+	}
+	return joins
+}
+
+func eagerFieldName(edge dsl.Edge) string {
+	return "with" + exportName(edge.Name)
+}
+
+func emitEagerQueryFields(buf *bytes.Buffer, ent Entity, entityIndex map[string]Entity) {
+	for _, join := range eagerEdges(ent, entityIndex) {
+		fmt.Fprintf(buf, "    %s *%sQuery\n", eagerFieldName(join.edge), join.target.Name)
+	}
+}
+
+func emitEagerQueryMethods(buf *bytes.Buffer, ent Entity, entityIndex map[string]Entity) {
+	joins := eagerEdges(ent, entityIndex)
+	if len(joins) == 0 {
+		return
+	}
+	name := ent.Name
+	for _, join := range joins {
+		edgeName := exportName(join.edge.Name)
+		target := join.target.Name
+		fmt.Fprintf(buf, "// With%s eager-loads the %s edge of every result. opts configure the %s query that\n", edgeName, join.edge.Name, target)
+		fmt.Fprintf(buf, "// loads it; its predicates, orders and nested With calls apply, and Limit/Offset apply per %s.\n", name)
+		fmt.Fprintf(buf, "func (q *%sQuery) With%s(opts ...func(*%sQuery)) *%sQuery {\n", name, edgeName, target, name)
+		fmt.Fprintf(buf, "    query := &%sQuery{db: q.db, hooks: q.hooks}\n", target)
+		fmt.Fprintf(buf, "    for _, opt := range opts {\n        opt(query)\n    }\n")
+		fmt.Fprintf(buf, "    q.%s = query\n    return q\n}\n\n", eagerFieldName(join.edge))
+	}
+
+	fmt.Fprintf(buf, "func (q *%sQuery) loadEdges(ctx context.Context, items []*%s) error {\n", name, name)
+	fmt.Fprintf(buf, "    if len(items) == 0 {\n        return nil\n    }\n")
+	for _, join := range joins {
+		field := eagerFieldName(join.edge)
+		fmt.Fprintf(buf, "    if q.%s != nil {\n", field)
+		fmt.Fprintf(buf, "        if err := q.load%s(ctx, q.%s, items); err != nil {\n            return err\n        }\n    }\n", exportName(join.edge.Name), field)
+	}
+	fmt.Fprintf(buf, "    return nil\n}\n\n")
+
+	for _, join := range joins {
+		emitEagerLoader(buf, join)
+	}
+}
+
+// emitEagerLoader emits the loader for one edge: it buckets the parents by join key, runs the
+// target query once with the keys bound as an array and distributes the results.
+func emitEagerLoader(buf *bytes.Buffer, join edgeJoin) {
+	source, target := join.source.Name, join.target.Name
+	edgeName := exportName(join.edge.Name)
+	toOne := join.edge.Kind == dsl.EdgeToOne
+
+	keyField := primaryField(join.source)
+	if join.local {
+		keyField, _ = fieldByColumn(join.source, join.sourceColumn)
+	}
+	keyType := baseGoType(keyField)
+
+	fmt.Fprintf(buf, "func (q *%sQuery) load%s(ctx context.Context, query *%sQuery, items []*%s) error {\n", source, edgeName, target, source)
+	fmt.Fprintf(buf, "    byKey := make(map[%s][]*%s, len(items))\n", keyType, source)
+	fmt.Fprintf(buf, "    keys := make([]%s, 0, len(items))\n", keyType)
+	fmt.Fprintf(buf, "    for _, item := range items {\n")
+	fmt.Fprintf(buf, "        edges := ensure%sEdges(item)\n", source)
+	if toOne {
+		fmt.Fprintf(buf, "        edges.%s = nil\n", edgeName)
+	} else {
+		fmt.Fprintf(buf, "        edges.%s = []*%s{}\n", edgeName, target)
+	}
+	fmt.Fprintf(buf, "        edges.markLoaded(%q)\n", join.edge.Name)
+	emitEdgeKeyRead(buf, keyField, "item", "key")
+	fmt.Fprintf(buf, "        if _, ok := byKey[key]; !ok {\n            keys = append(keys, key)\n        }\n")
+	fmt.Fprintf(buf, "        byKey[key] = append(byKey[key], item)\n    }\n")
+	fmt.Fprintf(buf, "    if len(keys) == 0 {\n        return nil\n    }\n")
+
+	assign := fmt.Sprintf("edges.%s = append(edges.%s, node)", edgeName, edgeName)
+	if toOne {
+		assign = fmt.Sprintf("edges.%s = node", edgeName)
+	}
+	if join.through != "" {
+		fmt.Fprintf(buf, "    query = query.clone()\n")
+		fmt.Fprintf(buf, "    query.through, query.throughKeys = &%s, keys\n", join.literal())
+		fmt.Fprintf(buf, "    var owners []*%s\n", keyType)
+		fmt.Fprintf(buf, "    related, err := runtime.CastResult[[]*%s](query.intercept(ctx, runtime.QueryAll, func(ctx context.Context, typed *%sQuery) (any, error) {\n", target, target)
+		fmt.Fprintf(buf, "        owners = owners[:0]\n")
+		fmt.Fprintf(buf, "        return typed.scan(ctx, func() any {\n            owner := new(%s)\n            owners = append(owners, owner)\n            return owner\n        })\n    }))\n", keyType)
+		fmt.Fprintf(buf, "    if err != nil {\n        return err\n    }\n")
+		fmt.Fprintf(buf, "    if len(owners) != len(related) {\n        return fmt.Errorf(\"load %s: %%d link rows for %%d results\", len(owners), len(related))\n    }\n", join.edge.Name)
+		fmt.Fprintf(buf, "    for i, node := range related {\n")
+		fmt.Fprintf(buf, "        for _, item := range byKey[*owners[i]] {\n            edges := ensure%sEdges(item)\n            %s\n        }\n    }\n", source, assign)
+		fmt.Fprintf(buf, "    return nil\n}\n\n")
+		return
+	}
+
+	fmt.Fprintf(buf, "    query = query.clone().Where(runtime.In(%q, keys))\n", join.targetColumn)
+	fmt.Fprintf(buf, "    query.partition = %q\n", join.targetColumn)
+	fmt.Fprintf(buf, "    related, err := query.All(ctx)\n")
+	fmt.Fprintf(buf, "    if err != nil {\n        return err\n    }\n")
+	fmt.Fprintf(buf, "    for _, node := range related {\n")
+	ownerField, _ := fieldByColumn(join.target, join.targetColumn)
+	emitEdgeKeyRead(buf, ownerField, "node", "owner")
+	fmt.Fprintf(buf, "        for _, item := range byKey[owner] {\n            edges := ensure%sEdges(item)\n            %s\n        }\n    }\n", source, assign)
+	fmt.Fprintf(buf, "    return nil\n}\n\n")
+}
+
+// emitEdgeKeyRead declares dest with the value of the key field on holder, skipping the loop
+// iteration when the key is NULL or zero.
+func emitEdgeKeyRead(buf *bytes.Buffer, field dsl.Field, holder, dest string) {
+	value := holder + "." + exportName(field.Name)
+	switch {
+	case isNullablePointerField(field):
+		fmt.Fprintf(buf, "        if %s == nil {\n            continue\n        }\n        %s := *%s\n", value, dest, value)
+	case isNullableSQLNullField(field):
+		fmt.Fprintf(buf, "        if !%s.Valid {\n            continue\n        }\n        %s := %s.%s\n", value, dest, value, sqlNullValueFieldAccessor(field))
+	default:
+		fmt.Fprintf(buf, "        %s := %s\n", dest, value)
+	}
+	fmt.Fprintf(buf, "        if isZero(%s) {\n            continue\n        }\n", dest)
+}
+
+// scanDest renders the Scan destinations of a query row as a slice, so scan can append the link
+// owner when eager loading a many-to-many edge.
+func scanDest(ent Entity) string {
+	return "[]any{" + strings.Join(scanArgsForVar(ent, "item"), ", ") + "}"
+}
@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+const eagerClientTest = `package gen_test
+
+import (
+	"context"
+	"testing"
+
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"example.com/app/orm/gen"
+	"github.com/deicod/erm/orm/pg"
+)
+
+func TestWithEdges(t *testing.T) {
+	mock, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := gen.NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+
+	mock.ExpectQuery("SELECT id, author_id, title FROM posts LIMIT $1").
+		WithArgs(20).
+		WillReturnRows(mock.NewRows([]string{"id", "author_id", "title"}).AddRow("p1", "u1", "first").AddRow("p2", "u1", "second"))
+	mock.ExpectQuery("SELECT id, email FROM users WHERE id = ANY($1)").
+		WithArgs([]string{"u1"}).
+		WillReturnRows(mock.NewRows([]string{"id", "email"}).AddRow("u1", "ada@example.com"))
+	mock.ExpectQuery("SELECT id, post_id, author_id, body, deleted_at FROM (SELECT id, post_id, author_id, body, deleted_at, ROW_NUMBER() OVER (PARTITION BY post_id ORDER BY id DESC) AS erm_rank FROM comments WHERE post_id = ANY($1) AND deleted_at IS NULL) AS erm_ranked WHERE erm_rank <= $2 ORDER BY id DESC").
+		WithArgs([]string{"p1", "p2"}, 2).
+		WillReturnRows(mock.NewRows([]string{"id", "post_id", "author_id", "body", "deleted_at"}).AddRow("c1", "p1", "u2", "nice", nil))
+	mock.ExpectQuery("SELECT id, email FROM users WHERE id = ANY($1)").
+		WithArgs([]string{"u2"}).
+		WillReturnRows(mock.NewRows([]string{"id", "email"}).AddRow("u2", "bob@example.com"))
+	mock.ExpectQuery("SELECT tags.id, tags.name, erm_j.post_id FROM tags JOIN posts_tags AS erm_j ON erm_j.tag_id = tags.id WHERE erm_j.post_id = ANY($1)").
+		WithArgs([]string{"p1", "p2"}).
+		WillReturnRows(mock.NewRows([]string{"id", "name", "post_id"}).AddRow("t1", "go", "p1").AddRow("t1", "go", "p2"))
+
+	posts, err := client.Posts().Query().
+		WithAuthor().
+		WithComments(func(q *gen.CommentQuery) {
+			q.OrderByIDDesc().Limit(2).WithAuthor()
+		}).
+		WithTags().
+		All(context.Background())
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+	if len(posts) != 2 {
+		t.Fatalf("expected 2 posts, got %d", len(posts))
+	}
+	first, second := posts[0].Edges, posts[1].Edges
+	if first.Author == nil || first.Author != second.Author || first.Author.Email != "ada@example.com" {
+		t.Fatalf("unexpected authors: %+v %+v", first.Author, second.Author)
+	}
+	if len(first.Comments) != 1 || first.Comments[0].Edges.Author.Email != "bob@example.com" {
+		t.Fatalf("unexpected comments: %+v", first.Comments)
+	}
+	if second.Comments == nil || len(second.Comments) != 0 || !posts[1].EdgeLoaded("comments") {
+		t.Fatalf("expected loaded empty comments, got %+v", second.Comments)
+	}
+	if len(first.Tags) != 1 || len(second.Tags) != 1 || second.Tags[0].Name != "go" {
+		t.Fatalf("unexpected tags: %+v %+v", first.Tags, second.Tags)
+	}
+}
+`
+
+func TestWriteORMArtifacts_EagerLoading(t *testing.T) {
+	entities := []Entity{
+		{
+			Name:   "User",
+			Fields: []dsl.Field{dsl.String("id").Primary(), dsl.String("email")},
+			Edges:  []dsl.Edge{dsl.ToMany("posts", "Post").Ref("author_id")},
+		},
+		{
+			Name:   "Post",
+			Fields: []dsl.Field{dsl.String("id").Primary(), dsl.String("author_id"), dsl.String("title")},
+			Edges: []dsl.Edge{
+				dsl.ToOne("author", "User").Field("author_id"),
+				dsl.ToMany("comments", "Comment"),
+				dsl.ManyToMany("tags", "Tag"),
+			},
+		},
+		{
+			Name:        "Comment",
+			Fields:      []dsl.Field{dsl.String("id").Primary(), dsl.String("post_id"), dsl.String("author_id"), dsl.Text("body")},
+			Edges:       []dsl.Edge{dsl.ToOne("author", "User").Field("author_id")},
+			Annotations: []dsl.Annotation{dsl.SoftDelete()},
+			Query:       dsl.Query().WithOrders(dsl.OrderBy("id", dsl.SortDesc).Named("IDDesc")),
+		},
+		{
+			Name:   "Tag",
+			Fields: []dsl.Field{dsl.String("id").Primary(), dsl.String("name")},
+		},
+	}
+	for i := range entities {
+		applySoftDelete(&entities[i])
+		ensureDefaultQuery(&entities[i])
+	}
+
+	root := t.TempDir()
+	if err := writeORMArtifacts(root, entities); err != nil {
+		t.Fatalf("writeORMArtifacts: %v", err)
+	}
+	client, err := os.ReadFile(filepath.Join(root, "orm", "gen", "client_gen.go"))
+	if err != nil {
+		t.Fatalf("read client: %v", err)
+	}
+	mustContain(t, string(client), "func (q *PostQuery) WithComments(opts ...func(*CommentQuery)) *PostQuery {")
+	mustContain(t, string(client), "query.partition = \"post_id\"")
+
+	runGeneratedORMTest(t, root, "eager_test.go", eagerClientTest)
+}
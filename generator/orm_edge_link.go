@@ -0,0 +1,107 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// linkedEdge is a to-many or many-to-many edge whose targets can be linked to and unlinked from a
+// source row after both exist.
+type linkedEdge struct {
+	join edgeJoin
+	// unlink reports whether targets can be unlinked. Foreign key edges can only be unlinked when
+	// the target's column is nullable.
+	unlink bool
+}
+
+func (e linkedEdge) addMethod() string {
+	return "Add" + exportName(singularize(e.join.edge.Name)) + "IDs"
+}
+
+func (e linkedEdge) removeMethod() string {
+	return "Remove" + exportName(singularize(e.join.edge.Name)) + "IDs"
+}
+
+func (e linkedEdge) clearMethod() string {
+	return "Clear" + exportName(e.join.edge.Name)
+}
+
+// linkedEdges lists the edges of ent that get Add<Edge>IDs, Remove<Edge>IDs and Clear<Edge>.
+func linkedEdges(ent Entity, entityIndex map[string]Entity) []linkedEdge {
+	var edges []linkedEdge
+	for _, join := range pagedEdges(ent, entityIndex) {
+		if _, ok := fieldByColumn(ent, join.sourceColumn); !ok {
+			continue
+		}
+		if _, ok := fieldByColumn(join.target, primaryColumn(join.target)); !ok {
+			continue
+		}
+		edge := linkedEdge{join: join, unlink: true}
+		if join.through == "" {
+			fk, ok := fieldByColumn(join.target, join.targetColumn)
+			edge.unlink = ok && fk.Nullable
+		}
+		edges = append(edges, edge)
+	}
+	return edges
+}
+
+// emitEdgeLinkMethods emits the client methods changing which targets a row's edges hold. They
+// write the link table or the targets' foreign key directly, without running the targets' hooks.
+func emitEdgeLinkMethods(buf *bytes.Buffer, ent Entity, entityIndex map[string]Entity) {
+	for _, linked := range linkedEdges(ent, entityIndex) {
+		join := linked.join
+		source, target := ent.Name, join.target
+		edgeName := exportName(join.edge.Name)
+		sourceKey, _ := fieldByColumn(ent, join.sourceColumn)
+		targetKey, _ := fieldByColumn(target, primaryColumn(target))
+		idType := baseGoType(targetKey)
+		linkFunc := "link" + edgeName
+
+		fmt.Fprintf(buf, "// %s links the %s with ids to the %s edge of item.\n", linked.addMethod(), pluralize(target.Name), join.edge.Name)
+		fmt.Fprintf(buf, "func (c *%sClient) %s(ctx context.Context, item *%s, ids ...%s) error {\n", source, linked.addMethod(), source, idType)
+		fmt.Fprintf(buf, "    if len(ids) == 0 {\n        return nil\n    }\n")
+		fmt.Fprintf(buf, "    return c.%s(ctx, runtime.EdgeLinkAdd, item, ids)\n}\n\n", linkFunc)
+		if linked.unlink {
+			fmt.Fprintf(buf, "// %s unlinks the %s with ids from the %s edge of item.\n", linked.removeMethod(), pluralize(target.Name), join.edge.Name)
+			fmt.Fprintf(buf, "func (c *%sClient) %s(ctx context.Context, item *%s, ids ...%s) error {\n", source, linked.removeMethod(), source, idType)
+			fmt.Fprintf(buf, "    if len(ids) == 0 {\n        return nil\n    }\n")
+			fmt.Fprintf(buf, "    return c.%s(ctx, runtime.EdgeLinkRemove, item, ids)\n}\n\n", linkFunc)
+
+			fmt.Fprintf(buf, "// %s unlinks every target of the %s edge of item.\n", linked.clearMethod(), join.edge.Name)
+			fmt.Fprintf(buf, "func (c *%sClient) %s(ctx context.Context, item *%s) error {\n", source, linked.clearMethod(), source)
+			fmt.Fprintf(buf, "    return c.%s(ctx, runtime.EdgeLinkClear, item, nil)\n}\n\n", linkFunc)
+		}
+
+		fmt.Fprintf(buf, "func (c *%sClient) %s(ctx context.Context, op runtime.EdgeLinkOp, item *%s, ids []%s) error {\n", source, linkFunc, source, idType)
+		fmt.Fprintf(buf, "    if item == nil {\n        return errors.New(\"%s is required\")\n    }\n", toSnakeCase(source))
+		fmt.Fprintf(buf, "    spec := runtime.EdgeLinkSpec{\n")
+		fmt.Fprintf(buf, "        Join: %s,\n", join.literal())
+		fmt.Fprintf(buf, "        Op: op,\n")
+		fmt.Fprintf(buf, "        Source: item.%s,\n", exportName(sourceKey.Name))
+		fmt.Fprintf(buf, "        Targets: make([]any, len(ids)),\n")
+		if join.through == "" {
+			fmt.Fprintf(buf, "        TargetKey: %q,\n", primaryColumn(target))
+		}
+		fmt.Fprintf(buf, "    }\n")
+		fmt.Fprintf(buf, "    for i, id := range ids {\n        spec.Targets[i] = id\n    }\n")
+		fmt.Fprintf(buf, "    sql, args, err := runtime.BuildEdgeLinkSQL(spec)\n")
+		fmt.Fprintf(buf, "    if err != nil {\n        return err\n    }\n")
+		emitWriterGuard(buf, "")
+		if join.through != "" {
+			fmt.Fprintf(buf, "    _, err = writer.Exec(ctx, sql, args...)\n")
+			fmt.Fprintf(buf, "    return runtime.WrapConstraintError(err, %s)\n}\n\n", constraintSchemaVar(ent))
+			continue
+		}
+		// The targets' foreign key changed, so drop whatever was cached of the updated rows.
+		fmt.Fprintf(buf, "    rows, err := writer.Query(ctx, sql, args...)\n")
+		fmt.Fprintf(buf, "    if err != nil {\n        return runtime.WrapConstraintError(err, %s)\n    }\n", constraintSchemaVar(ent))
+		fmt.Fprintf(buf, "    defer rows.Close()\n")
+		fmt.Fprintf(buf, "    for rows.Next() {\n")
+		fmt.Fprintf(buf, "        var id %s\n", idType)
+		fmt.Fprintf(buf, "        if err := rows.Scan(&id); err != nil {\n            return err\n        }\n")
+		fmt.Fprintf(buf, "        if c.cache != nil {\n            _ = c.cache.Delete(ctx, makeCacheKey(%q, id))\n        }\n", target.Name)
+		fmt.Fprintf(buf, "    }\n")
+		fmt.Fprintf(buf, "    return runtime.WrapConstraintError(rows.Err(), %s)\n}\n\n", constraintSchemaVar(ent))
+	}
+}
@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+// pagedEdges lists the to-many and many-to-many edges of ent that can be loaded a page per parent.
+func pagedEdges(ent Entity, entityIndex map[string]Entity) []edgeJoin {
+	var joins []edgeJoin
+	for _, join := range eagerEdges(ent, entityIndex) {
+		if join.local || join.edge.Kind == dsl.EdgeToOne {
+			continue
+		}
+		joins = append(joins, join)
+	}
+	return joins
+}
+
+// emitEdgePageMethods emits Load<Edge>Page and Count<Edge> on the client. Both take the items'
+// keys as one array parameter, so a page of the edge and its size are read for many parents at once;
+// GraphQL dataloaders use them to resolve edge connections without a query per parent.
+func emitEdgePageMethods(buf *bytes.Buffer, ent Entity, entityIndex map[string]Entity) {
+	for _, join := range pagedEdges(ent, entityIndex) {
+		source, target := ent.Name, join.target.Name
+		edgeName := exportName(join.edge.Name)
+		key := exportName(primaryField(ent).Name)
+		keyType := baseGoType(primaryField(ent))
+
+		fmt.Fprintf(buf, "// Load%sPage loads the %s edge of items through query into their Edges. The query's limit,\n", edgeName, join.edge.Name)
+		fmt.Fprintf(buf, "// cursors and ordering apply to every item separately.\n")
+		fmt.Fprintf(buf, "func (c *%sClient) Load%sPage(ctx context.Context, query *%sQuery, items ...*%s) error {\n", source, edgeName, target, source)
+		fmt.Fprintf(buf, "    return (&%sQuery{db: c.db, hooks: c.hooks}).load%s(ctx, query, items)\n}\n\n", source, edgeName)
+
+		fmt.Fprintf(buf, "// Count%s counts the %s edge of items matching the predicates of query, keyed by item.\n", edgeName, join.edge.Name)
+		fmt.Fprintf(buf, "// Items without matching rows are absent from the result.\n")
+		fmt.Fprintf(buf, "func (c *%sClient) Count%s(ctx context.Context, query *%sQuery, items ...*%s) (map[%s]int, error) {\n", source, edgeName, target, source, keyType)
+		fmt.Fprintf(buf, "    keys := make([]%s, 0, len(items))\n", keyType)
+		fmt.Fprintf(buf, "    for _, item := range items {\n        if item != nil {\n            keys = append(keys, item.%s)\n        }\n    }\n", key)
+		fmt.Fprintf(buf, "    if len(keys) == 0 {\n        return map[%s]int{}, nil\n    }\n", keyType)
+		fmt.Fprintf(buf, "    return runtime.CastResult[map[%s]int](query.intercept(ctx, runtime.QueryCount, func(ctx context.Context, q *%sQuery) (any, error) {\n", keyType, target)
+		fmt.Fprintf(buf, "        spec := runtime.AggregateSpec{\n")
+		fmt.Fprintf(buf, "            Table: %q,\n", pluralize(target))
+		if join.through == "" {
+			fmt.Fprintf(buf, "            Predicates: append(append([]runtime.Predicate(nil), %s...), runtime.In(%q, keys)),\n", queryPredicates(join.target), join.targetColumn)
+			fmt.Fprintf(buf, "            Aggregate: runtime.Aggregate{Func: runtime.AggCount},\n")
+			fmt.Fprintf(buf, "            GroupBy: []string{%q},\n", join.targetColumn)
+		} else {
+			fmt.Fprintf(buf, "            Predicates: %s,\n", queryPredicates(join.target))
+			fmt.Fprintf(buf, "            Aggregate: runtime.Aggregate{Func: runtime.AggCount},\n")
+			fmt.Fprintf(buf, "            Through: &%s,\n", join.literal())
+			fmt.Fprintf(buf, "            ThroughKeys: keys,\n")
+		}
+		fmt.Fprintf(buf, "        }\n")
+		fmt.Fprintf(buf, "        rows, err := c.db.AggregateGroups(ctx, spec)\n")
+		fmt.Fprintf(buf, "        if err != nil {\n            return nil, err\n        }\n")
+		fmt.Fprintf(buf, "        defer rows.Close()\n")
+		fmt.Fprintf(buf, "        counts := make(map[%s]int, len(keys))\n", keyType)
+		fmt.Fprintf(buf, "        for rows.Next() {\n")
+		fmt.Fprintf(buf, "            var key %s\n            var count int\n", keyType)
+		fmt.Fprintf(buf, "            if err := rows.Scan(&key, &count); err != nil {\n                return nil, err\n            }\n")
+		fmt.Fprintf(buf, "            counts[key] = count\n        }\n")
+		fmt.Fprintf(buf, "        return counts, rows.Err()\n")
+		fmt.Fprintf(buf, "    }))\n}\n\n")
+	}
+}
@@ -0,0 +1,156 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+// edgeJoin is an edge resolved to the columns connecting source and target rows, mirroring
+// runtime.EdgeJoin. through is empty unless the edge is many-to-many; local is set when the source
+// row holds the foreign key.
+type edgeJoin struct {
+	edge          dsl.Edge
+	local         bool
+	source        Entity
+	target        Entity
+	sourceColumn  string
+	targetColumn  string
+	through       string
+	throughSource string
+	throughTarget string
+}
+
+// resolveEdgeJoin resolves the same columns the edge loaders use. ok is false for edges that
+// cannot be expressed as a join, such as polymorphic edges or to-one edges without a foreign key
+// on either side.
+func resolveEdgeJoin(source Entity, edge dsl.Edge, entityIndex map[string]Entity) (edgeJoin, bool) {
+	target, ok := entityIndex[edge.Target]
+	if !ok || len(edge.PolymorphicTargets) > 0 {
+		return edgeJoin{}, false
+	}
+	sourcePrimary := primaryField(source)
+	targetPrimary := primaryField(target)
+	join := edgeJoin{edge: edge, source: source, target: target}
+	switch edge.Kind {
+	case dsl.EdgeToOne:
+		if _, found := fieldByColumn(source, edgeColumn(edge)); found {
+			join.sourceColumn, join.targetColumn, join.local = edgeColumn(edge), fieldColumn(targetPrimary), true
+			return join, true
+		}
+		// A to-one edge without a local column is the inverse of a unique edge owned by the target.
+		fallthrough
+	case dsl.EdgeToMany:
+		refColumn := edgeRefColumn(source, edge, sourcePrimary)
+		if _, found := fieldByColumn(target, refColumn); !found || refColumn == "" {
+			return edgeJoin{}, false
+		}
+		join.sourceColumn, join.targetColumn = fieldColumn(sourcePrimary), refColumn
+		return join, true
+	case dsl.EdgeManyToMany:
+		joinTable, leftColumn, rightColumn := manyToManyJoinSpec(source, sourcePrimary, edge, targetPrimary)
+		if joinTable == "" {
+			return edgeJoin{}, false
+		}
+		join.sourceColumn, join.targetColumn = fieldColumn(sourcePrimary), fieldColumn(targetPrimary)
+		join.through, join.throughSource, join.throughTarget = joinTable, leftColumn, rightColumn
+		return join, true
+	}
+	return edgeJoin{}, false
+}
+
+// literal renders the runtime.EdgeJoin value for the edge.
+func (j edgeJoin) literal() string {
+	base := fmt.Sprintf("Name: %q, Target: %q, SourceTable: %q, TargetTable: %q, SourceColumn: %q, TargetColumn: %q",
+		toSnakeCase(j.edge.Name), j.target.Name, pluralize(j.source.Name), pluralize(j.target.Name), j.sourceColumn, j.targetColumn)
+	if j.through == "" {
+		return "runtime.EdgeJoin{" + base + "}"
+	}
+	return fmt.Sprintf("runtime.EdgeJoin{%s, Through: %q, ThroughSourceColumn: %q, ThroughTargetColumn: %q}", base, j.through, j.throughSource, j.throughTarget)
+}
+
+// edgeExistsExpr renders the HasEdge predicate for edge with the variadic preds expression. Soft
+// deleted targets never satisfy the predicate; query policy filters of the target are added when
+// the query runs, see emitEdgeScope.
+func edgeExistsExpr(join string, target Entity, preds string) string {
+	if isSoftDelete(target) {
+		return fmt.Sprintf("runtime.HasEdge(%s, append([]runtime.Predicate{runtime.IsNull(%q)}, %s...)...)", join, dsl.SoftDeleteColumn, preds)
+	}
+	return fmt.Sprintf("runtime.HasEdge(%s, %s...)", join, preds)
+}
+
+func emitEdgePredicateMethods(buf *bytes.Buffer, ent Entity, entityIndex map[string]Entity) {
+	for _, edge := range ent.Edges {
+		join, ok := resolveEdgeJoin(ent, edge, entityIndex)
+		if !ok {
+			continue
+		}
+		method := "WhereHas" + exportName(edge.Name)
+		fmt.Fprintf(buf, "// %s keeps %s rows with at least one %s edge.\n", method, ent.Name, edge.Name)
+		fmt.Fprintf(buf, "func (q *%sQuery) %s() *%sQuery {\n    return q.%sWith()\n}\n\n", ent.Name, method, ent.Name, method)
+		fmt.Fprintf(buf, "// %sWith keeps %s rows with at least one %s edge matching preds, which are\n", method, ent.Name, edge.Name)
+		fmt.Fprintf(buf, "// %s predicates.\n", join.target.Name)
+		fmt.Fprintf(buf, "func (q *%sQuery) %sWith(preds ...runtime.Predicate) *%sQuery {\n", ent.Name, method, ent.Name)
+		fmt.Fprintf(buf, "    q.predicates = append(q.predicates, %s)\n", edgeExistsExpr(join.literal(), join.target, "preds"))
+		fmt.Fprintf(buf, "    return q\n}\n\n")
+	}
+}
+
+func emitEdgePredicateFuncs(buf *bytes.Buffer, ent Entity, entityIndex map[string]Entity) {
+	for _, edge := range ent.Edges {
+		join, ok := resolveEdgeJoin(ent, edge, entityIndex)
+		if !ok {
+			continue
+		}
+		name := "Has" + exportName(edge.Name)
+		fmt.Fprintf(buf, "// %s matches %s rows with at least one %s edge.\n", name, ent.Name, edge.Name)
+		fmt.Fprintf(buf, "func %s() runtime.Predicate {\n    return %sWith()\n}\n\n", name, name)
+		fmt.Fprintf(buf, "// %sWith matches %s rows with at least one %s edge matching preds.\n", name, ent.Name, edge.Name)
+		fmt.Fprintf(buf, "func %sWith(preds ...runtime.Predicate) runtime.Predicate {\n", name)
+		fmt.Fprintf(buf, "    return %s\n}\n\n", edgeExistsExpr(join.literal(), join.target, "preds"))
+	}
+}
+
+// scopesEdges reports whether any entity has query policy rules, in which case queries pass their
+// predicates through runtime.ScopeEdges before running.
+func scopesEdges(entityIndex map[string]Entity) bool {
+	for _, ent := range entityIndex {
+		if hasQueryPolicy(ent) {
+			return true
+		}
+	}
+	return false
+}
+
+// emitEdgeScope emits the runtime.EdgeScope that adds the query policy filters of an edge target
+// inside HasEdge subqueries, so edge predicates cannot probe rows the caller may not read. A
+// denied read makes the subquery match no rows instead of failing the outer query.
+func emitEdgeScope(buf *bytes.Buffer, entities []Entity) {
+	var scoped []Entity
+	for _, ent := range entities {
+		if hasQueryPolicy(ent) {
+			scoped = append(scoped, ent)
+		}
+	}
+	if len(scoped) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "func edgeScope(ctx context.Context, entity string) ([]runtime.Predicate, error) {\n")
+	fmt.Fprintf(buf, "    var filters []runtime.Predicate\n    var err error\n")
+	fmt.Fprintf(buf, "    switch entity {\n")
+	for _, ent := range scoped {
+		fmt.Fprintf(buf, "    case %q:\n        filters, err = %s.EvalQuery(ctx, entity)\n", ent.Name, policyVarName(ent))
+	}
+	fmt.Fprintf(buf, "    default:\n        return nil, nil\n    }\n")
+	fmt.Fprintf(buf, "    if errors.Is(err, privacy.ErrDenied) {\n        return []runtime.Predicate{runtime.Or()}, nil\n    }\n")
+	fmt.Fprintf(buf, "    return filters, err\n}\n\n")
+}
+
+// emitScopeEdges emits a statement that scopes the HasEdge predicates held in target, returning
+// errPrefix plus the error when the scope fails.
+func emitScopeEdges(buf *bytes.Buffer, target, errPrefix string) {
+	fmt.Fprintf(buf, "    scoped, err := runtime.ScopeEdges(ctx, %s, edgeScope)\n", target)
+	fmt.Fprintf(buf, "    if err != nil {\n        return %serr\n    }\n", errPrefix)
+	fmt.Fprintf(buf, "    %s = scoped\n", target)
+}
@@ -0,0 +1,218 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+const edgePredicateClientTest = `package gen_test
+
+import (
+	"context"
+	"testing"
+
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"example.com/app/orm/gen"
+	"example.com/app/orm/gen/comment"
+	"example.com/app/orm/gen/post"
+	"example.com/app/orm/gen/user"
+	"github.com/deicod/erm/orm/pg"
+)
+
+func TestWhereHasEdges(t *testing.T) {
+	mock, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := gen.NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+
+	mock.ExpectQuery("SELECT id, author_id, title FROM posts WHERE EXISTS (SELECT 1 FROM users AS e_author WHERE e_author.id = posts.author_id AND e_author.email LIKE $1) AND EXISTS (SELECT 1 FROM posts_tags AS e_tags_j JOIN tags AS e_tags ON e_tags.id = e_tags_j.tag_id WHERE e_tags_j.post_id = posts.id) LIMIT $2").
+		WithArgs("%@corp", 10).
+		WillReturnRows(mock.NewRows([]string{"id", "author_id", "title"}))
+	if _, err := client.Posts().Query().WhereHasAuthorWith(user.EmailHasSuffix("@corp")).WhereHasTags().Limit(10).All(context.Background()); err != nil {
+		t.Fatalf("posts query: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT id, email FROM users WHERE EXISTS (SELECT 1 FROM posts AS e_posts WHERE e_posts.author_id = users.id AND EXISTS (SELECT 1 FROM comments AS e_posts_comments WHERE e_posts_comments.post_id = e_posts.id AND e_posts_comments.deleted_at IS NULL AND e_posts_comments.body LIKE $1)) LIMIT $2").
+		WithArgs("%go%", 10).
+		WillReturnRows(mock.NewRows([]string{"id", "email"}))
+	if _, err := client.Users().Query().WhereHasPostsWith(post.HasCommentsWith(comment.BodyContains("go"))).Limit(10).All(context.Background()); err != nil {
+		t.Fatalf("users query: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT id, post_id, body, deleted_at FROM comments WHERE post_id = $1 AND deleted_at IS NULL LIMIT $2").
+		WithArgs("p1", 5).
+		WillReturnRows(mock.NewRows([]string{"id", "post_id", "body", "deleted_at"}))
+	if _, err := client.Posts().QueryComments(&gen.Post{ID: "p1"}).Limit(5).All(context.Background()); err != nil {
+		t.Fatalf("comments query: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT id, name FROM tags WHERE EXISTS (SELECT 1 FROM posts_tags AS e_tags_j JOIN posts AS e_tags ON e_tags.id = e_tags_j.post_id WHERE e_tags_j.tag_id = tags.id AND e_tags.id = $1) LIMIT $2").
+		WithArgs("p1", 5).
+		WillReturnRows(mock.NewRows([]string{"id", "name"}))
+	if _, err := client.Posts().QueryTags(&gen.Post{ID: "p1"}).Limit(5).All(context.Background()); err != nil {
+		t.Fatalf("tags query: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestEdgePages(t *testing.T) {
+	mock, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := gen.NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+	ctx := context.Background()
+	posts := []*gen.Post{{ID: "p1"}, {ID: "p2"}}
+
+	mock.ExpectQuery("SELECT id, post_id, body, deleted_at FROM (SELECT id, post_id, body, deleted_at, ROW_NUMBER() OVER (PARTITION BY post_id ORDER BY id DESC) AS erm_rank FROM comments WHERE post_id = ANY($1) AND deleted_at IS NULL) AS erm_ranked WHERE erm_rank <= $2 ORDER BY id ASC").
+		WithArgs([]string{"p1", "p2"}, 2).
+		WillReturnRows(mock.NewRows([]string{"id", "post_id", "body", "deleted_at"}).
+			AddRow("c2", "p1", "b", nil).
+			AddRow("c3", "p1", "c", nil).
+			AddRow("c9", "p2", "z", nil))
+	comments := client.Comments().Query().Last(2)
+	if err := client.Posts().LoadCommentsPage(ctx, comments, posts...); err != nil {
+		t.Fatalf("load comments page: %v", err)
+	}
+	if len(posts[0].Edges.Comments) != 2 || posts[0].Edges.Comments[1].ID != "c3" || len(posts[1].Edges.Comments) != 1 {
+		t.Fatalf("unexpected comment pages: %+v %+v", posts[0].Edges.Comments, posts[1].Edges.Comments)
+	}
+
+	mock.ExpectQuery("SELECT post_id, COUNT(*) FROM comments WHERE deleted_at IS NULL AND post_id = ANY($1) GROUP BY post_id").
+		WithArgs([]string{"p1", "p2"}).
+		WillReturnRows(mock.NewRows([]string{"post_id", "count"}).AddRow("p1", 7).AddRow("p2", 1))
+	counts, err := client.Posts().CountComments(ctx, comments, posts...)
+	if err != nil {
+		t.Fatalf("count comments: %v", err)
+	}
+	if counts["p1"] != 7 || counts["p2"] != 1 {
+		t.Fatalf("unexpected comment counts: %v", counts)
+	}
+
+	mock.ExpectQuery("SELECT id, name, erm_owner FROM (SELECT tags.id, tags.name, erm_j.post_id AS erm_owner, ROW_NUMBER() OVER (PARTITION BY erm_j.post_id) AS erm_rank FROM tags JOIN posts_tags AS erm_j ON erm_j.tag_id = tags.id WHERE erm_j.post_id = ANY($1)) AS erm_ranked WHERE erm_rank <= $2").
+		WithArgs([]string{"p1", "p2"}, 3).
+		WillReturnRows(mock.NewRows([]string{"id", "name", "erm_owner"}).AddRow("t1", "go", "p2"))
+	tags := client.Tags().Query().Limit(3)
+	if err := client.Posts().LoadTagsPage(ctx, tags, posts...); err != nil {
+		t.Fatalf("load tags page: %v", err)
+	}
+	if len(posts[0].Edges.Tags) != 0 || len(posts[1].Edges.Tags) != 1 {
+		t.Fatalf("unexpected tag pages: %+v %+v", posts[0].Edges.Tags, posts[1].Edges.Tags)
+	}
+
+	mock.ExpectQuery("SELECT erm_j.post_id, COUNT(*) FROM tags JOIN posts_tags AS erm_j ON erm_j.tag_id = tags.id WHERE erm_j.post_id = ANY($1) GROUP BY erm_j.post_id").
+		WithArgs([]string{"p1", "p2"}).
+		WillReturnRows(mock.NewRows([]string{"post_id", "count"}).AddRow("p2", 1))
+	if counts, err := client.Posts().CountTags(ctx, tags, posts...); err != nil || len(counts) != 1 || counts["p2"] != 1 {
+		t.Fatalf("unexpected tag counts: %v, %v", counts, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestEdgeLinks(t *testing.T) {
+	mock, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := gen.NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+	ctx := context.Background()
+	post := &gen.Post{ID: "p1"}
+
+	mock.ExpectExec("INSERT INTO posts_tags (post_id, tag_id) VALUES ($1, $2), ($1, $3) ON CONFLICT DO NOTHING").
+		WithArgs("p1", "t1", "t2").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	if err := client.Posts().AddTagIDs(ctx, post, "t1", "t2"); err != nil {
+		t.Fatalf("add tags: %v", err)
+	}
+	mock.ExpectExec("DELETE FROM posts_tags WHERE post_id = $1 AND tag_id IN ($2)").
+		WithArgs("p1", "t1").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	if err := client.Posts().RemoveTagIDs(ctx, post, "t1"); err != nil {
+		t.Fatalf("remove tags: %v", err)
+	}
+	mock.ExpectExec("DELETE FROM posts_tags WHERE post_id = $1").
+		WithArgs("p1").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	if err := client.Posts().ClearTags(ctx, post); err != nil {
+		t.Fatalf("clear tags: %v", err)
+	}
+	mock.ExpectQuery("UPDATE comments SET post_id = $1 WHERE id IN ($2) RETURNING id").
+		WithArgs("p1", "c1").
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow("c1"))
+	if err := client.Posts().AddCommentIDs(ctx, post, "c1"); err != nil {
+		t.Fatalf("add comments: %v", err)
+	}
+	if err := client.Posts().AddTagIDs(ctx, post); err != nil {
+		t.Fatalf("adding no tags should be a no-op: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+`
+
+func TestWriteORMArtifacts_EdgePredicates(t *testing.T) {
+	entities := []Entity{
+		{
+			Name:   "User",
+			Fields: []dsl.Field{dsl.String("id").Primary(), dsl.String("email")},
+			Edges:  []dsl.Edge{dsl.ToMany("posts", "Post").Ref("author_id")},
+		},
+		{
+			Name:   "Post",
+			Fields: []dsl.Field{dsl.String("id").Primary(), dsl.String("author_id"), dsl.String("title")},
+			Edges: []dsl.Edge{
+				dsl.ToOne("author", "User").Field("author_id"),
+				dsl.ToMany("comments", "Comment"),
+				dsl.ManyToMany("tags", "Tag"),
+			},
+		},
+		{
+			Name:        "Comment",
+			Fields:      []dsl.Field{dsl.String("id").Primary(), dsl.String("post_id"), dsl.Text("body")},
+			Annotations: []dsl.Annotation{dsl.SoftDelete()},
+		},
+		{
+			Name:   "Tag",
+			Fields: []dsl.Field{dsl.String("id").Primary(), dsl.String("name")},
+		},
+	}
+	for i := range entities {
+		applySoftDelete(&entities[i])
+		ensureDefaultQuery(&entities[i])
+	}
+
+	root := t.TempDir()
+	if err := writeORMArtifacts(root, entities); err != nil {
+		t.Fatalf("writeORMArtifacts: %v", err)
+	}
+	client, err := os.ReadFile(filepath.Join(root, "orm", "gen", "client_gen.go"))
+	if err != nil {
+		t.Fatalf("read client: %v", err)
+	}
+	mustContain(t, string(client), "func (q *PostQuery) WhereHasAuthorWith(preds ...runtime.Predicate) *PostQuery {")
+	mustContain(t, string(client), "func (q *UserQuery) WhereHasPosts() *UserQuery {")
+	mustContain(t, string(client), "func (c *PostClient) QueryComments(item *Post) *CommentQuery {")
+	mustNotContain(t, string(client), "func (c *PostClient) QueryAuthor(")
+	mustContain(t, string(client), "func (c *PostClient) LoadCommentsPage(ctx context.Context, query *CommentQuery, items ...*Post) error {")
+	mustContain(t, string(client), "func (c *PostClient) AddCommentIDs(ctx context.Context, item *Post, ids ...string) error {")
+	mustNotContain(t, string(client), "func (c *PostClient) RemoveCommentIDs(")
+	mustContain(t, string(client), "func (c *PostClient) ClearTags(ctx context.Context, item *Post) error {")
+	mustContain(t, string(client), "func (c *PostClient) CountTags(ctx context.Context, query *TagQuery, items ...*Post) (map[string]int, error) {")
+	predicates, err := os.ReadFile(filepath.Join(root, "orm", "gen", "post", "where_gen.go"))
+	if err != nil {
+		t.Fatalf("read predicates: %v", err)
+	}
+	mustContain(t, string(predicates), `runtime.HasEdge(runtime.EdgeJoin{Name: "comments", Target: "Comment", SourceTable: "posts", TargetTable: "comments", SourceColumn: "id", TargetColumn: "post_id"}, append([]runtime.Predicate{runtime.IsNull("deleted_at")}, preds...)...)`)
+
+	runGeneratedORMTest(t, root, "edges_test.go", edgePredicateClientTest)
+}
@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// emitEdgeQueryMethods emits Query<Edge> on the client for edges whose targets reference the
+// source row, i.e. to-many, many-to-many and inverse to-one edges. The returned query is an
+// ordinary target query, so predicates, ordering and keyset pagination compose with it.
+func emitEdgeQueryMethods(buf *bytes.Buffer, ent Entity, entityIndex map[string]Entity) {
+	for _, join := range eagerEdges(ent, entityIndex) {
+		if join.local {
+			continue
+		}
+		target := join.target.Name
+		key := exportName(primaryField(ent).Name)
+		fmt.Fprintf(buf, "// Query%s returns a %s query restricted to the %s edge of item.\n", exportName(join.edge.Name), target, join.edge.Name)
+		fmt.Fprintf(buf, "func (c *%sClient) Query%s(item *%s) *%sQuery {\n", ent.Name, exportName(join.edge.Name), ent.Name, target)
+		fmt.Fprintf(buf, "    query := (&%sClient{db: c.db, cache: c.cache, hooks: c.hooks}).Query()\n", target)
+		if join.through == "" {
+			fmt.Fprintf(buf, "    return query.Where(runtime.Compare(%q, runtime.OpEqual, item.%s))\n}\n\n", join.targetColumn, key)
+			continue
+		}
+		// Walk the link table back from the target rows to the source row.
+		owner := edgeJoin{
+			edge:          join.edge,
+			source:        join.target,
+			target:        join.source,
+			sourceColumn:  join.targetColumn,
+			targetColumn:  join.sourceColumn,
+			through:       join.through,
+			throughSource: join.throughTarget,
+			throughTarget: join.throughSource,
+		}
+		fmt.Fprintf(buf, "    return query.Where(runtime.HasEdge(%s, runtime.Compare(%q, runtime.OpEqual, item.%s)))\n}\n\n", owner.literal(), join.sourceColumn, key)
+	}
+}
@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// emitGroupByBuilder emits XxxQuery.GroupBy and the XxxGroupBy builder computing several
+// aggregates per group of the rows matched by the query.
+func emitGroupByBuilder(buf *bytes.Buffer, ent Entity) {
+	name := ent.Name
+	builder := name + "GroupBy"
+
+	fmt.Fprintf(buf, "// GroupBy groups the rows matched by the query by columns, such as the Field constants of the\n")
+	fmt.Fprintf(buf, "// %s package, to compute aggregates per group. Without columns the aggregates cover every\n", predicatePackageName(ent))
+	fmt.Fprintf(buf, "// matched row in a single group.\n")
+	fmt.Fprintf(buf, "func (q *%sQuery) GroupBy(columns ...string) *%s {\n", name, builder)
+	fmt.Fprintf(buf, "    return &%s{query: q.clone(), columns: append([]string(nil), columns...)}\n}\n\n", builder)
+
+	fmt.Fprintf(buf, "// %s computes aggregates of %s rows per group.\n", builder, name)
+	fmt.Fprintf(buf, "type %s struct {\n", builder)
+	fmt.Fprintf(buf, "    query *%sQuery\n", name)
+	fmt.Fprintf(buf, "    columns []string\n")
+	fmt.Fprintf(buf, "    aggregates []runtime.Aggregate\n")
+	fmt.Fprintf(buf, "    having []runtime.Predicate\n")
+	fmt.Fprintf(buf, "    orders []runtime.Order\n")
+	fmt.Fprintf(buf, "    limit int\n")
+	fmt.Fprintf(buf, "}\n\n")
+
+	fmt.Fprintf(buf, "// Aggregate adds aggregates such as runtime.Count() and runtime.Sum(column), selected after the\n")
+	fmt.Fprintf(buf, "// grouped columns under their aliases.\n")
+	fmt.Fprintf(buf, "func (g *%s) Aggregate(aggs ...runtime.Aggregate) *%s {\n", builder, builder)
+	fmt.Fprintf(buf, "    g.aggregates = append(g.aggregates, aggs...)\n    return g\n}\n\n")
+
+	fmt.Fprintf(buf, "// Having keeps the groups matching every predicate. Aggregates are compared through their Expr,\n")
+	fmt.Fprintf(buf, "// e.g. runtime.Compare(runtime.Count().Expr(), runtime.OpGreaterThan, 1).\n")
+	fmt.Fprintf(buf, "func (g *%s) Having(preds ...runtime.Predicate) *%s {\n", builder, builder)
+	fmt.Fprintf(buf, "    g.having = append(g.having, preds...)\n    return g\n}\n\n")
+
+	fmt.Fprintf(buf, "// Order sorts the groups by grouped columns or aggregate aliases.\n")
+	fmt.Fprintf(buf, "func (g *%s) Order(orders ...runtime.Order) *%s {\n", builder, builder)
+	fmt.Fprintf(buf, "    g.orders = append(g.orders, orders...)\n    return g\n}\n\n")
+
+	fmt.Fprintf(buf, "// Limit caps the number of groups returned.\n")
+	fmt.Fprintf(buf, "func (g *%s) Limit(n int) *%s {\n", builder, builder)
+	fmt.Fprintf(buf, "    if n < 0 {\n        n = 0\n    }\n")
+	fmt.Fprintf(buf, "    g.limit = n\n    return g\n}\n\n")
+
+	fmt.Fprintf(buf, "// Scan reads a row per group into dest, a pointer to a slice of structs, matching columns and\n")
+	fmt.Fprintf(buf, "// aliases to fields as runtime.ScanGroups does. The query runs through interceptors as\n")
+	fmt.Fprintf(buf, "// runtime.QueryAggregate.\n")
+	fmt.Fprintf(buf, "func (g *%s) Scan(ctx context.Context, dest any) error {\n", builder)
+	fmt.Fprintf(buf, "    _, err := g.query.intercept(ctx, runtime.QueryAggregate, func(ctx context.Context, q *%sQuery) (any, error) {\n", name)
+	fmt.Fprintf(buf, "        spec := runtime.AggregateSpec{\n")
+	fmt.Fprintf(buf, "            Table: %q,\n", pluralize(name))
+	fmt.Fprintf(buf, "            Predicates: %s,\n", queryPredicates(ent))
+	fmt.Fprintf(buf, "            Aggregates: g.aggregates,\n")
+	fmt.Fprintf(buf, "            GroupBy: g.columns,\n")
+	fmt.Fprintf(buf, "            Having: g.having,\n")
+	fmt.Fprintf(buf, "            Orders: g.orders,\n")
+	fmt.Fprintf(buf, "            Limit: g.limit,\n")
+	fmt.Fprintf(buf, "        }\n")
+	fmt.Fprintf(buf, "        if err := spec.Validate(); err != nil {\n            return nil, err\n        }\n")
+	fmt.Fprintf(buf, "        rows, err := q.db.AggregateGroups(ctx, spec)\n")
+	fmt.Fprintf(buf, "        if err != nil {\n            return nil, err\n        }\n")
+	fmt.Fprintf(buf, "        return nil, runtime.ScanGroups(rows, dest)\n")
+	fmt.Fprintf(buf, "    })\n")
+	fmt.Fprintf(buf, "    return err\n}\n\n")
+}
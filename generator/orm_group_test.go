@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+const groupByClientTest = `package gen_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"example.com/app/orm/gen"
+	"example.com/app/orm/gen/sale"
+	"github.com/deicod/erm/orm/pg"
+	"github.com/deicod/erm/orm/runtime"
+)
+
+func TestGroupBy(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := gen.NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+	ctx := context.Background()
+
+	mock.ExpectQuery("^SELECT region, COUNT\\(\\*\\) AS count, SUM\\(amount\\) AS sum_amount, MAX\\(amount\\) AS top FROM sales WHERE amount > \\$1 AND deleted_at IS NULL GROUP BY region HAVING COUNT\\(\\*\\) > \\$2 ORDER BY sum_amount DESC LIMIT \\$3$").
+		WithArgs(int32(0), 1, 5).
+		WillReturnRows(mock.NewRows([]string{"region", "count", "sum_amount", "top"}).AddRow("eu", 2, 30.0, int32(20)).AddRow("us", 3, 12.0, int32(5)))
+
+	type row struct {
+		Region    string
+		Count     int
+		SumAmount float64
+		Largest   int32 ` + "`db:\"top\"`" + `
+	}
+	var rows []row
+	err = client.Sales().Query().
+		Where(sale.AmountGT(0)).
+		GroupBy(sale.FieldRegion).
+		Aggregate(sale.Count(), sale.Sum(sale.FieldAmount), sale.Max(sale.FieldAmount).As("top")).
+		Having(runtime.Compare(sale.Count().Expr(), runtime.OpGreaterThan, 1)).
+		Order(sale.Desc("sum_amount")).
+		Limit(5).
+		Scan(ctx, &rows)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	want := []row{{Region: "eu", Count: 2, SumAmount: 30, Largest: 20}, {Region: "us", Count: 3, SumAmount: 12, Largest: 5}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+
+	var ops []runtime.QueryOp
+	client.Sales().Intercept(func(next runtime.Querier) runtime.Querier {
+		return runtime.QuerierFunc(func(ctx context.Context, query runtime.Query) (any, error) {
+			ops = append(ops, query.Op())
+			return next.Query(ctx, query)
+		})
+	})
+	mock.ExpectQuery("^SELECT COUNT\\(\\*\\) AS count FROM sales WHERE deleted_at IS NULL$").
+		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(7))
+	var totals []*struct{ Count int }
+	if err := client.Sales().Query().GroupBy().Aggregate(sale.Count()).Scan(ctx, &totals); err != nil {
+		t.Fatalf("Scan totals: %v", err)
+	}
+	if len(totals) != 1 || totals[0].Count != 7 {
+		t.Fatalf("unexpected totals: %+v", totals)
+	}
+	if !reflect.DeepEqual(ops, []runtime.QueryOp{runtime.QueryAggregate}) {
+		t.Fatalf("unexpected intercepted ops: %v", ops)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+`
+
+func TestWriteORMArtifacts_GroupBy(t *testing.T) {
+	entities := []Entity{{
+		Name:        "Sale",
+		Fields:      []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.String("region"), dsl.Integer("amount")},
+		Annotations: []dsl.Annotation{dsl.SoftDelete()},
+	}}
+	for i := range entities {
+		applySoftDelete(&entities[i])
+		ensureDefaultQuery(&entities[i])
+	}
+
+	root := t.TempDir()
+	if err := writeORMArtifacts(root, entities); err != nil {
+		t.Fatalf("writeORMArtifacts: %v", err)
+	}
+	client, err := os.ReadFile(filepath.Join(root, "orm", "gen", "client_gen.go"))
+	if err != nil {
+		t.Fatalf("read client: %v", err)
+	}
+	src := string(client)
+	mustContain(t, src, "func (q *SaleQuery) GroupBy(columns ...string) *SaleGroupBy {")
+	mustContain(t, src, "func (g *SaleGroupBy) Scan(ctx context.Context, dest any) error {")
+	mustContain(t, src, "Predicates: q.scopedPredicates(),\n\t\t\tAggregates: g.aggregates,")
+	where, err := os.ReadFile(filepath.Join(root, "orm", "gen", "sale", "where_gen.go"))
+	if err != nil {
+		t.Fatalf("read predicates: %v", err)
+	}
+	mustContain(t, string(where), "func Sum(column string) runtime.Aggregate {")
+
+	runGeneratedORMTest(t, root, "group_test.go", groupByClientTest)
+}
@@ -0,0 +1,274 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+func emitClientHooks(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "// Use registers mutation hooks that run for every entity.\n")
+	fmt.Fprintf(buf, "func (c *Client) Use(hooks ...runtime.Hook) {\n")
+	fmt.Fprintf(buf, "    if c == nil {\n        return\n    }\n")
+	fmt.Fprintf(buf, "    if c.hooks == nil {\n        c.hooks = runtime.NewHookRegistry()\n    }\n")
+	fmt.Fprintf(buf, "    c.hooks.Use(\"\", hooks...)\n}\n\n")
+	fmt.Fprintf(buf, "// Intercept registers query interceptors that run for every entity.\n")
+	fmt.Fprintf(buf, "func (c *Client) Intercept(interceptors ...runtime.Interceptor) {\n")
+	fmt.Fprintf(buf, "    if c == nil {\n        return\n    }\n")
+	fmt.Fprintf(buf, "    if c.hooks == nil {\n        c.hooks = runtime.NewHookRegistry()\n    }\n")
+	fmt.Fprintf(buf, "    c.hooks.Intercept(\"\", interceptors...)\n}\n\n")
+}
+
+func emitEntityHooks(buf *bytes.Buffer, ent Entity) {
+	fmt.Fprintf(buf, "// Use registers mutation hooks that only run for %s writes.\n", ent.Name)
+	fmt.Fprintf(buf, "func (c *%sClient) Use(hooks ...runtime.Hook) {\n    c.hooks.Use(%q, hooks...)\n}\n\n", ent.Name, ent.Name)
+	fmt.Fprintf(buf, "// Intercept registers query interceptors that only run for %s queries.\n", ent.Name)
+	fmt.Fprintf(buf, "func (c *%sClient) Intercept(interceptors ...runtime.Interceptor) {\n    c.hooks.Intercept(%q, interceptors...)\n}\n\n", ent.Name, ent.Name)
+
+	fmt.Fprintf(buf, "func (c *%sClient) mutate(ctx context.Context, m *%sMutation, exec func(context.Context, *%sMutation) (any, error)) (any, error) {\n", ent.Name, ent.Name, ent.Name)
+	fmt.Fprintf(buf, "    return c.hooks.Mutate(ctx, %q, m, runtime.MutateFunc(func(ctx context.Context, mutation runtime.Mutation) (any, error) {\n", ent.Name)
+	fmt.Fprintf(buf, "        typed, ok := mutation.(*%sMutation)\n", ent.Name)
+	fmt.Fprintf(buf, "        if !ok {\n            return nil, fmt.Errorf(\"unexpected mutation type %%T\", mutation)\n        }\n")
+	if len(ent.Hooks) > 0 {
+		fmt.Fprintf(buf, "        typed.applySchemaHooks(ctx)\n")
+	}
+	if hasMutationPolicy(ent) {
+		fmt.Fprintf(buf, "        if err := %s.EvalMutation(ctx, typed); err != nil {\n            return nil, err\n        }\n", policyVarName(ent))
+	}
+	// Integrity violations surface as *runtime.ConstraintError naming the fields involved.
+	fmt.Fprintf(buf, "        out, err := exec(ctx, typed)\n")
+	fmt.Fprintf(buf, "        return out, runtime.WrapConstraintError(err, %s)\n    }))\n}\n\n", constraintSchemaVar(ent))
+}
+
+func emitMutationType(buf *bytes.Buffer, ent Entity, hasUpdate bool) {
+	name := ent.Name
+	pk := exportName(primaryField(ent).Name)
+
+	fmt.Fprintf(buf, "// %sMutation describes a pending %s write. Hooks may modify Input, Inputs, or IDs before\n", name, name)
+	fmt.Fprintf(buf, "// the statement runs, or return an error to veto it.\n")
+	fmt.Fprintf(buf, "type %sMutation struct {\n", name)
+	fmt.Fprintf(buf, "    op runtime.MutationOp\n")
+	fmt.Fprintf(buf, "    bulk bool\n")
+	fmt.Fprintf(buf, "    db *pg.DB\n")
+	fmt.Fprintf(buf, "    // Input is the record passed to Create or Update, or the values set by an UpdateWhere.\n")
+	fmt.Fprintf(buf, "    Input *%s\n", name)
+	fmt.Fprintf(buf, "    // Inputs holds the records passed to BulkCreate or BulkUpdate.\n")
+	fmt.Fprintf(buf, "    Inputs []*%s\n", name)
+	if isSoftDelete(ent) {
+		fmt.Fprintf(buf, "    // IDs lists the primary keys targeted by Delete, BulkDelete, HardDelete, or Restore.\n")
+	} else {
+		fmt.Fprintf(buf, "    // IDs lists the primary keys targeted by Delete or BulkDelete.\n")
+	}
+	fmt.Fprintf(buf, "    IDs []string\n")
+	fmt.Fprintf(buf, "    // Predicates selects the rows of UpdateWhere and DeleteWhere. Hooks may narrow it.\n")
+	fmt.Fprintf(buf, "    Predicates []runtime.Predicate\n")
+	fmt.Fprintf(buf, "    where bool\n")
+	fmt.Fprintf(buf, "    conflict *runtime.OnConflict\n")
+	fmt.Fprintf(buf, "    copy bool\n")
+	fmt.Fprintf(buf, "    // fields lists the fields a partial update writes; nil when every field is written.\n")
+	fmt.Fprintf(buf, "    fields []string\n")
+	if isSoftDelete(ent) {
+		fmt.Fprintf(buf, "    hard bool\n")
+		fmt.Fprintf(buf, "    restore bool\n")
+	}
+	fmt.Fprintf(buf, "    old *%s\n", name)
+	fmt.Fprintf(buf, "    oldErr error\n")
+	fmt.Fprintf(buf, "    oldLoaded bool\n")
+	fmt.Fprintf(buf, "}\n\n")
+
+	fmt.Fprintf(buf, "func (m *%sMutation) Entity() string {\n    return %q\n}\n\n", name, name)
+	fmt.Fprintf(buf, "func (m *%sMutation) Op() runtime.MutationOp {\n    return m.op\n}\n\n", name)
+	fmt.Fprintf(buf, "func (m *%sMutation) Bulk() bool {\n    return m.bulk\n}\n\n", name)
+	fmt.Fprintf(buf, "// IsWhere reports whether the mutation is an UpdateWhere or DeleteWhere, whose rows are selected\n")
+	fmt.Fprintf(buf, "// by Predicates rather than by primary key.\n")
+	fmt.Fprintf(buf, "func (m *%sMutation) IsWhere() bool {\n    return m.where\n}\n\n", name)
+	fmt.Fprintf(buf, "// IsCopy reports whether the mutation is a CopyCreate, whose rows are streamed and not\n")
+	fmt.Fprintf(buf, "// available as Inputs.\n")
+	fmt.Fprintf(buf, "func (m *%sMutation) IsCopy() bool {\n    return m.copy\n}\n\n", name)
+	fmt.Fprintf(buf, "// OnConflict returns the conflict clause of an Upsert or BulkUpsert, or nil for plain creates.\n")
+	fmt.Fprintf(buf, "// Hooks may modify it.\n")
+	fmt.Fprintf(buf, "func (m *%sMutation) OnConflict() *runtime.OnConflict {\n    return m.conflict\n}\n\n", name)
+	if isSoftDelete(ent) {
+		fmt.Fprintf(buf, "// IsHardDelete reports whether a delete mutation removes rows instead of setting deleted_at.\n")
+		fmt.Fprintf(buf, "func (m *%sMutation) IsHardDelete() bool {\n    return m.hard\n}\n\n", name)
+		fmt.Fprintf(buf, "// IsRestore reports whether an update mutation only clears deleted_at. Input is nil for restores.\n")
+		fmt.Fprintf(buf, "func (m *%sMutation) IsRestore() bool {\n    return m.restore\n}\n\n", name)
+	}
+
+	insertFields := insertableFields(ent)
+	fmt.Fprintf(buf, "// ChangedFields lists the schema fields the statement writes. For creates only fields with a\n")
+	fmt.Fprintf(buf, "// non-zero value are reported.\n")
+	fmt.Fprintf(buf, "func (m *%sMutation) ChangedFields() []string {\n", name)
+	if isSoftDelete(ent) {
+		fmt.Fprintf(buf, "    if m.restore {\n        return []string{%q}\n    }\n", dsl.SoftDeleteColumn)
+	}
+	fmt.Fprintf(buf, "    switch m.op {\n")
+	if len(insertFields) > 0 {
+		names := make([]string, len(insertFields))
+		values := make([]string, len(insertFields))
+		for i, field := range insertFields {
+			names[i] = field.Name
+			values[i] = "input." + exportName(field.Name)
+		}
+		fmt.Fprintf(buf, "    case runtime.MutationCreate:\n")
+		fmt.Fprintf(buf, "        names := %s\n", quoteStringSlice(names))
+		fmt.Fprintf(buf, "        seen := make([]bool, len(names))\n")
+		fmt.Fprintf(buf, "        for _, input := range m.records() {\n")
+		fmt.Fprintf(buf, "            values := []any{%s}\n", strings.Join(values, ", "))
+		fmt.Fprintf(buf, "            for i, value := range values {\n")
+		fmt.Fprintf(buf, "                if !runtime.IsZeroValue(value) {\n                    seen[i] = true\n                }\n")
+		fmt.Fprintf(buf, "            }\n        }\n")
+		fmt.Fprintf(buf, "        fields := make([]string, 0, len(names))\n")
+		fmt.Fprintf(buf, "        for i, name := range names {\n            if seen[i] {\n                fields = append(fields, name)\n            }\n        }\n")
+		fmt.Fprintf(buf, "        return fields\n")
+	}
+	if hasUpdate {
+		updateCols := updatableColumns(ent)
+		names := make([]string, len(updateCols))
+		for i, col := range updateCols {
+			names[i] = findFieldByColumn(ent, col).Name
+		}
+		fmt.Fprintf(buf, "    case runtime.MutationUpdate:\n")
+		fmt.Fprintf(buf, "        if m.fields != nil {\n            return append([]string{}, m.fields...)\n        }\n")
+		fmt.Fprintf(buf, "        return %s\n", quoteStringSlice(names))
+	}
+	fmt.Fprintf(buf, "    }\n    return nil\n}\n\n")
+
+	fmt.Fprintf(buf, "// Old loads the stored row targeted by a single-row Update or Delete, bypassing the cache.\n")
+	fmt.Fprintf(buf, "func (m *%sMutation) Old(ctx context.Context) (*%s, error) {\n", name, name)
+	fmt.Fprintf(buf, "    if m.bulk || m.op == runtime.MutationCreate {\n")
+	fmt.Fprintf(buf, "        return nil, errors.New(\"old values are only available for single-row update and delete mutations\")\n    }\n")
+	fmt.Fprintf(buf, "    if !m.oldLoaded {\n")
+	fmt.Fprintf(buf, "        var id string\n")
+	fmt.Fprintf(buf, "        if len(m.IDs) > 0 {\n            id = m.IDs[0]\n        } else if m.Input != nil {\n            id = m.Input.%s\n        }\n", pk)
+	fmt.Fprintf(buf, "        m.old, m.oldErr = (&%sClient{db: m.db}).ByID(ctx, id)\n", name)
+	fmt.Fprintf(buf, "        m.oldLoaded = true\n")
+	fmt.Fprintf(buf, "    }\n")
+	fmt.Fprintf(buf, "    return m.old, m.oldErr\n}\n\n")
+
+	fmt.Fprintf(buf, "// touches reports whether the mutation writes field.\n")
+	fmt.Fprintf(buf, "func (m *%sMutation) touches(field string) bool {\n", name)
+	fmt.Fprintf(buf, "    if m.fields == nil {\n        return true\n    }\n")
+	fmt.Fprintf(buf, "    for _, name := range m.fields {\n        if name == field {\n            return true\n        }\n    }\n")
+	fmt.Fprintf(buf, "    return false\n}\n\n")
+	fmt.Fprintf(buf, "// touch adds field to the fields of a partial update.\n")
+	fmt.Fprintf(buf, "func (m *%sMutation) touch(field string) {\n", name)
+	fmt.Fprintf(buf, "    if !m.touches(field) {\n        m.fields = append(m.fields, field)\n    }\n}\n\n")
+	fmt.Fprintf(buf, "func (m *%sMutation) records() []*%s {\n", name, name)
+	fmt.Fprintf(buf, "    if m.Input != nil {\n        return []*%s{m.Input}\n    }\n", name)
+	fmt.Fprintf(buf, "    return m.Inputs\n}\n\n")
+}
+
+func emitMutationMethods(buf *bytes.Buffer, ent Entity, hasUpdate bool) {
+	name := ent.Name
+
+	fmt.Fprintf(buf, "func (c *%sClient) Create(ctx context.Context, input *%s) (*%s, error) {\n", name, name, name)
+	fmt.Fprintf(buf, "    if input == nil {\n        return nil, errors.New(\"input cannot be nil\")\n    }\n")
+	fmt.Fprintf(buf, "    m := &%sMutation{op: runtime.MutationCreate, db: c.db, Input: input}\n", name)
+	fmt.Fprintf(buf, "    return runtime.CastResult[*%s](c.mutate(ctx, m, func(ctx context.Context, m *%sMutation) (any, error) {\n        return c.create(ctx, m.Input)\n    }))\n}\n\n", name, name)
+
+	fmt.Fprintf(buf, "func (c *%sClient) BulkCreate(ctx context.Context, inputs []*%s) ([]*%s, error) {\n", name, name, name)
+	fmt.Fprintf(buf, "    if len(inputs) == 0 {\n        return []*%s{}, nil\n    }\n", name)
+	fmt.Fprintf(buf, "    m := &%sMutation{op: runtime.MutationCreate, bulk: true, db: c.db, Inputs: inputs}\n", name)
+	fmt.Fprintf(buf, "    return runtime.CastResult[[]*%s](c.mutate(ctx, m, func(ctx context.Context, m *%sMutation) (any, error) {\n        return c.bulkCreate(ctx, m.Inputs)\n    }))\n}\n\n", name, name)
+
+	if hasUpdate {
+		fmt.Fprintf(buf, "func (c *%sClient) Update(ctx context.Context, input *%s) (*%s, error) {\n", name, name, name)
+		fmt.Fprintf(buf, "    if input == nil {\n        return nil, errors.New(\"input cannot be nil\")\n    }\n")
+		fmt.Fprintf(buf, "    m := &%sMutation{op: runtime.MutationUpdate, db: c.db, Input: input}\n", name)
+		fmt.Fprintf(buf, "    return runtime.CastResult[*%s](c.mutate(ctx, m, func(ctx context.Context, m *%sMutation) (any, error) {\n        return c.update(ctx, m.Input)\n    }))\n}\n\n", name, name)
+
+		fmt.Fprintf(buf, "func (c *%sClient) BulkUpdate(ctx context.Context, inputs []*%s) ([]*%s, error) {\n", name, name, name)
+		fmt.Fprintf(buf, "    if len(inputs) == 0 {\n        return []*%s{}, nil\n    }\n", name)
+		fmt.Fprintf(buf, "    m := &%sMutation{op: runtime.MutationUpdate, bulk: true, db: c.db, Inputs: inputs}\n", name)
+		fmt.Fprintf(buf, "    return runtime.CastResult[[]*%s](c.mutate(ctx, m, func(ctx context.Context, m *%sMutation) (any, error) {\n        return c.bulkUpdate(ctx, m.Inputs)\n    }))\n}\n\n", name, name)
+	}
+
+	fmt.Fprintf(buf, "func (c *%sClient) Delete(ctx context.Context, id string) error {\n", name)
+	fmt.Fprintf(buf, "    m := &%sMutation{op: runtime.MutationDelete, db: c.db, IDs: []string{id}}\n", name)
+	fmt.Fprintf(buf, "    _, err := c.mutate(ctx, m, func(ctx context.Context, m *%sMutation) (any, error) {\n", name)
+	fmt.Fprintf(buf, "        if len(m.IDs) != 1 {\n            return nil, errors.New(\"delete expects exactly one id\")\n        }\n")
+	fmt.Fprintf(buf, "        return nil, c.delete(ctx, m.IDs[0])\n    })\n")
+	fmt.Fprintf(buf, "    return err\n}\n\n")
+
+	fmt.Fprintf(buf, "func (c *%sClient) BulkDelete(ctx context.Context, ids []string) (int64, error) {\n", name)
+	fmt.Fprintf(buf, "    if len(ids) == 0 {\n        return 0, nil\n    }\n")
+	fmt.Fprintf(buf, "    m := &%sMutation{op: runtime.MutationDelete, bulk: true, db: c.db, IDs: ids}\n", name)
+	fmt.Fprintf(buf, "    return runtime.CastResult[int64](c.mutate(ctx, m, func(ctx context.Context, m *%sMutation) (any, error) {\n        return c.bulkDelete(ctx, m.IDs)\n    }))\n}\n\n", name)
+}
+
+func emitQueryInterceptors(buf *bytes.Buffer, ent Entity, scopeEdges bool) {
+	name := ent.Name
+
+	fmt.Fprintf(buf, "func (q *%sQuery) Entity() string {\n    return %q\n}\n\n", name, name)
+	fmt.Fprintf(buf, "// Op reports the terminal being executed while the query passes through interceptors.\n")
+	fmt.Fprintf(buf, "func (q *%sQuery) Op() runtime.QueryOp {\n    return q.op\n}\n\n", name)
+
+	fmt.Fprintf(buf, "func (q *%sQuery) All(ctx context.Context) ([]*%s, error) {\n", name, name)
+	fmt.Fprintf(buf, "    return runtime.CastResult[[]*%s](q.intercept(ctx, runtime.QueryAll, func(ctx context.Context, q *%sQuery) (any, error) {\n        return q.all(ctx)\n    }))\n}\n\n", name, name)
+	fmt.Fprintf(buf, "func (q *%sQuery) Stream(ctx context.Context) (*runtime.Stream[*%s], error) {\n", name, name)
+	fmt.Fprintf(buf, "    return runtime.CastResult[*runtime.Stream[*%s]](q.intercept(ctx, runtime.QueryStream, func(ctx context.Context, q *%sQuery) (any, error) {\n        return q.stream(ctx)\n    }))\n}\n\n", name, name)
+	fmt.Fprintf(buf, "func (q *%sQuery) First(ctx context.Context) (*%s, error) {\n", name, name)
+	fmt.Fprintf(buf, "    return runtime.CastResult[*%s](q.intercept(ctx, runtime.QueryFirst, func(ctx context.Context, q *%sQuery) (any, error) {\n        return q.first(ctx)\n    }))\n}\n\n", name, name)
+
+	fmt.Fprintf(buf, "func (q *%sQuery) intercept(ctx context.Context, op runtime.QueryOp, exec func(context.Context, *%sQuery) (any, error)) (any, error) {\n", name, name)
+	fmt.Fprintf(buf, "    cp := q.clone()\n")
+	fmt.Fprintf(buf, "    cp.op = op\n")
+	fmt.Fprintf(buf, "    return q.hooks.Query(ctx, %q, cp, runtime.QuerierFunc(func(ctx context.Context, query runtime.Query) (any, error) {\n", name)
+	fmt.Fprintf(buf, "        typed, ok := query.(*%sQuery)\n", name)
+	fmt.Fprintf(buf, "        if !ok {\n            return nil, fmt.Errorf(\"unexpected query type %%T\", query)\n        }\n")
+	if hasQueryPolicy(ent) {
+		fmt.Fprintf(buf, "        filters, err := %s.EvalQuery(ctx, %q)\n", policyVarName(ent), name)
+		fmt.Fprintf(buf, "        if err != nil {\n            return nil, err\n        }\n")
+		fmt.Fprintf(buf, "        typed.predicates = append(typed.predicates, filters...)\n")
+	}
+	if scopeEdges {
+		emitScopeEdges(buf, "typed.predicates", "nil, ")
+	}
+	fmt.Fprintf(buf, "        return exec(ctx, typed)\n    }))\n}\n\n")
+}
+
+// emitSchemaHooks emits the declarative hooks contributed by mixins. They run after hooks
+// registered with Use and before privacy policies, defaults and validation.
+func emitSchemaHooks(buf *bytes.Buffer, ent Entity) {
+	if len(ent.Hooks) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "func (m *%sMutation) applySchemaHooks(ctx context.Context) {\n", ent.Name)
+	for _, hook := range ent.Hooks {
+		field, ok := policyOwnerField(ent, hook.Field)
+		if !ok || hook.Kind != dsl.HookViewerSubject {
+			continue
+		}
+		ops := hook.Ops
+		if len(ops) == 0 {
+			ops = []dsl.MutationOp{dsl.MutationCreate, dsl.MutationUpdate}
+		}
+		conds := make([]string, 0, len(ops))
+		for _, op := range ops {
+			if op == dsl.MutationDelete {
+				continue
+			}
+			conds = append(conds, "m.op == "+runtimeMutationOpLiteral(op))
+		}
+		if len(conds) == 0 {
+			continue
+		}
+		fieldName := exportName(field.Name)
+		fmt.Fprintf(buf, "    if %s {\n", strings.Join(conds, " || "))
+		fmt.Fprintf(buf, "        if viewer, ok := privacy.FromContext(ctx); ok && viewer.Subject != \"\" {\n")
+		fmt.Fprintf(buf, "            for _, input := range m.records() {\n                if input == nil {\n                    continue\n                }\n")
+		switch {
+		case isNullablePointerField(field):
+			fmt.Fprintf(buf, "                subject := viewer.Subject\n                input.%s = &subject\n", fieldName)
+		case isNullableSQLNullField(field):
+			fmt.Fprintf(buf, "                input.%s.%s = viewer.Subject\n                input.%s.Valid = true\n", fieldName, sqlNullValueFieldAccessor(field), fieldName)
+		default:
+			fmt.Fprintf(buf, "                input.%s = viewer.Subject\n", fieldName)
+		}
+		fmt.Fprintf(buf, "            }\n            m.touch(%q)\n        }\n    }\n", field.Name)
+	}
+	fmt.Fprintf(buf, "}\n\n")
+}
@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// emitKeysetQueryMethods emits After, Before and Cursor on the query builder. Cursors hold the
+// values of the query's sort columns followed by the primary key, so pages stay stable while rows
+// are inserted or deleted.
+func emitKeysetQueryMethods(buf *bytes.Buffer, ent Entity) {
+	name := ent.Name
+	query := name + "Query"
+
+	fmt.Fprintf(buf, "// After keeps the rows sorting after the row cursor was created for. Cursors come from Cursor on\n")
+	fmt.Fprintf(buf, "// a query with the same order; an empty cursor clears the bound.\n")
+	fmt.Fprintf(buf, "func (q *%s) After(cursor string) *%s {\n    q.after = cursor\n    return q\n}\n\n", query, query)
+
+	fmt.Fprintf(buf, "// Before keeps the rows sorting before the row cursor was created for. Combine it with Last to\n")
+	fmt.Fprintf(buf, "// page backwards.\n")
+	fmt.Fprintf(buf, "func (q *%s) Before(cursor string) *%s {\n    q.before = cursor\n    return q\n}\n\n", query, query)
+
+	fmt.Fprintf(buf, "// Last limits the query to its last n rows, which are still returned in the query's order.\n")
+	fmt.Fprintf(buf, "func (q *%s) Last(n int) *%s {\n", query, query)
+	fmt.Fprintf(buf, "    q.Limit(n)\n    q.fromEnd = q.limit != nil\n    return q\n}\n\n")
+
+	fmt.Fprintf(buf, "// Order appends sort keys after those added by the OrderBy methods.\n")
+	fmt.Fprintf(buf, "func (q *%s) Order(orders ...runtime.Order) *%s {\n", query, query)
+	fmt.Fprintf(buf, "    q.orders = append(q.orders, orders...)\n    return q\n}\n\n")
+
+	fmt.Fprintf(buf, "// Cursor returns the opaque cursor of item for the query's order, for use with After and Before.\n")
+	fmt.Fprintf(buf, "func (q *%s) Cursor(item *%s) (string, error) {\n", query, name)
+	fmt.Fprintf(buf, "    if item == nil {\n        return \"\", errors.New(\"item cannot be nil\")\n    }\n")
+	fmt.Fprintf(buf, "    orders, fields, err := q.keysetFields(item)\n")
+	fmt.Fprintf(buf, "    if err != nil {\n        return \"\", err\n    }\n")
+	fmt.Fprintf(buf, "    return runtime.EncodeCursor(orders, fields...)\n}\n\n")
+
+	fmt.Fprintf(buf, "func (q *%s) keyset(cursor string) ([]any, error) {\n", query)
+	fmt.Fprintf(buf, "    if cursor == \"\" {\n        return nil, nil\n    }\n")
+	fmt.Fprintf(buf, "    orders, fields, err := q.keysetFields(new(%s))\n", name)
+	fmt.Fprintf(buf, "    if err != nil {\n        return nil, err\n    }\n")
+	fmt.Fprintf(buf, "    return runtime.DecodeCursor(cursor, orders, fields...)\n}\n\n")
+
+	fmt.Fprintf(buf, "// keysetFields returns the keyset order of the query and pointers to the matching fields of item.\n")
+	fmt.Fprintf(buf, "// Nullable columns are rejected, as row comparisons with NULL drop those rows from later pages.\n")
+	fmt.Fprintf(buf, "func (q *%s) keysetFields(item *%s) ([]runtime.Order, []any, error) {\n", query, name)
+	fmt.Fprintf(buf, "    orders := runtime.KeysetOrders(q.orders, %q)\n", primaryColumn(ent))
+	fmt.Fprintf(buf, "    fields := make([]any, len(orders))\n")
+	fmt.Fprintf(buf, "    for i, order := range orders {\n")
+	fmt.Fprintf(buf, "        switch order.Column {\n")
+	var nullable []string
+	for _, field := range ent.Fields {
+		if field.Nullable {
+			nullable = append(nullable, fieldColumn(field))
+			continue
+		}
+		fmt.Fprintf(buf, "        case %q:\n            fields[i] = &item.%s\n", fieldColumn(field), exportName(field.Name))
+	}
+	if len(nullable) > 0 {
+		fmt.Fprintf(buf, "        case %s:\n", quoteArgs(nullable))
+		fmt.Fprintf(buf, "            return nil, nil, fmt.Errorf(\"%s cannot be paginated by nullable column %%q\", order.Column)\n", name)
+	}
+	fmt.Fprintf(buf, "        default:\n            return nil, nil, fmt.Errorf(\"%s cannot be paginated by %%q\", order.Column)\n", name)
+	fmt.Fprintf(buf, "        }\n    }\n")
+	fmt.Fprintf(buf, "    return orders, fields, nil\n}\n\n")
+}
+
+// emitKeysetSpec emits the decoded After and Before bounds used by the select spec of scan and
+// stream.
+func emitKeysetSpec(buf *bytes.Buffer, errReturn string) {
+	fmt.Fprintf(buf, "    after, err := q.keyset(q.after)\n")
+	fmt.Fprintf(buf, "    if err != nil {\n        return %s, err\n    }\n", errReturn)
+	fmt.Fprintf(buf, "    before, err := q.keyset(q.before)\n")
+	fmt.Fprintf(buf, "    if err != nil {\n        return %s, err\n    }\n", errReturn)
+}
@@ -0,0 +1,133 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+const keysetClientTest = `package gen_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"example.com/app/orm/gen"
+	"github.com/deicod/erm/orm/pg"
+	"github.com/deicod/erm/orm/runtime"
+)
+
+func TestKeysetPagination(t *testing.T) {
+	mock, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := gen.NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+	ctx := context.Background()
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT id, title, created_at FROM posts ORDER BY created_at DESC, id DESC LIMIT $1").
+		WithArgs(2).
+		WillReturnRows(mock.NewRows([]string{"id", "title", "created_at"}).
+			AddRow("p3", "C", created).
+			AddRow("p2", "B", created))
+	query := client.Posts().Query().OrderByCreatedAtDesc().Limit(2)
+	page, err := query.All(ctx)
+	if err != nil || len(page) != 2 {
+		t.Fatalf("first page: %v %v", page, err)
+	}
+	cursor, err := query.Cursor(page[len(page)-1])
+	if err != nil {
+		t.Fatalf("cursor: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT id, title, created_at FROM posts WHERE (created_at, id) < ($1, $2) ORDER BY created_at DESC, id DESC LIMIT $3").
+		WithArgs(created, "p2", 2).
+		WillReturnRows(mock.NewRows([]string{"id", "title", "created_at"}).AddRow("p1", "A", created))
+	if _, err := client.Posts().Query().OrderByCreatedAtDesc().After(cursor).Limit(2).All(ctx); err != nil {
+		t.Fatalf("next page: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT * FROM (SELECT id, title, created_at FROM posts WHERE (created_at, id) > ($1, $2) ORDER BY created_at ASC, id ASC LIMIT $3) AS erm_page ORDER BY created_at DESC, id DESC").
+		WithArgs(created, "p2", 2).
+		WillReturnRows(mock.NewRows([]string{"id", "title", "created_at"}).AddRow("p3", "C", created))
+	if _, err := client.Posts().Query().OrderByCreatedAtDesc().Before(cursor).Last(2).All(ctx); err != nil {
+		t.Fatalf("previous page: %v", err)
+	}
+
+	if _, err := client.Posts().Query().After(cursor).All(ctx); !errors.Is(err, runtime.ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor for a cursor of another order, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestKeysetRejectsNullableColumns(t *testing.T) {
+	mock, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := gen.NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+	ctx := context.Background()
+	published := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Drafts without publication date compare as NULL, so a seek past the first page would never
+	// return them.
+	mock.ExpectQuery("SELECT id, published_at FROM drafts ORDER BY published_at ASC, id ASC LIMIT $1").
+		WithArgs(1).
+		WillReturnRows(mock.NewRows([]string{"id", "published_at"}).AddRow("d1", &published))
+	query := client.Drafts().Query().Order(runtime.Order{Column: "published_at", Direction: runtime.SortAsc}).Limit(1)
+	page, err := query.All(ctx)
+	if err != nil || len(page) != 1 {
+		t.Fatalf("first page: %v %v", page, err)
+	}
+	if _, err := query.Cursor(page[0]); err == nil || !strings.Contains(err.Error(), "nullable column") {
+		t.Fatalf("expected cursors over a nullable column to be rejected, got %v", err)
+	}
+	if _, err := client.Drafts().Query().Order(runtime.Order{Column: "published_at", Direction: runtime.SortAsc}).After("cursor").All(ctx); err == nil || !strings.Contains(err.Error(), "nullable column") {
+		t.Fatalf("expected seeking past a nullable column to be rejected, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+`
+
+func TestWriteORMArtifacts_KeysetPagination(t *testing.T) {
+	entities := []Entity{
+		{
+			Name:   "Post",
+			Fields: []dsl.Field{dsl.String("id").Primary(), dsl.String("title"), dsl.TimestampTZ("created_at")},
+			Query:  dsl.Query().WithOrders(dsl.OrderBy("created_at", dsl.SortDesc).Named("CreatedAtDesc")),
+		},
+		{
+			Name:   "Draft",
+			Fields: []dsl.Field{dsl.String("id").Primary(), dsl.TimestampTZ("published_at").Optional()},
+		},
+	}
+	for i := range entities {
+		applySoftDelete(&entities[i])
+		ensureDefaultQuery(&entities[i])
+	}
+
+	root := t.TempDir()
+	if err := writeORMArtifacts(root, entities); err != nil {
+		t.Fatalf("writeORMArtifacts: %v", err)
+	}
+	client, err := os.ReadFile(filepath.Join(root, "orm", "gen", "client_gen.go"))
+	if err != nil {
+		t.Fatalf("read client: %v", err)
+	}
+	mustContain(t, string(client), "func (q *PostQuery) After(cursor string) *PostQuery {")
+	mustContain(t, string(client), "func (q *PostQuery) Cursor(item *Post) (string, error) {")
+
+	runGeneratedORMTest(t, root, "keyset_test.go", keysetClientTest)
+}
@@ -0,0 +1,169 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+func hasPolicy(ent Entity) bool {
+	return !ent.Policy.IsZero()
+}
+
+func hasQueryPolicy(ent Entity) bool {
+	return len(ent.Policy.QueryRules) > 0
+}
+
+func hasMutationPolicy(ent Entity) bool {
+	return len(ent.Policy.MutationRules) > 0
+}
+
+func policyVarName(ent Entity) string {
+	return lowerCamel(ent.Name) + "Policy"
+}
+
+// mutationOwnerColumns lists the columns referenced by FilterOwner mutation rules.
+func mutationOwnerColumns(ent Entity) []dsl.Field {
+	var fields []dsl.Field
+	seen := map[string]struct{}{}
+	for _, rule := range ent.Policy.MutationRules {
+		if rule.Kind != dsl.PolicyFilterOwner {
+			continue
+		}
+		field, ok := policyOwnerField(ent, rule.Field)
+		if !ok {
+			continue
+		}
+		column := fieldColumn(field)
+		if _, dup := seen[column]; dup {
+			continue
+		}
+		seen[column] = struct{}{}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func emitPolicyVar(buf *bytes.Buffer, ent Entity) {
+	if !hasPolicy(ent) {
+		return
+	}
+	fmt.Fprintf(buf, "var %s = privacy.Policy{\n", policyVarName(ent))
+	if hasQueryPolicy(ent) {
+		fmt.Fprintf(buf, "    Query: []privacy.Rule{\n")
+		for _, rule := range ent.Policy.QueryRules {
+			fmt.Fprintf(buf, "        %s,\n", policyRuleLiteral(ent, rule))
+		}
+		fmt.Fprintf(buf, "    },\n")
+	}
+	if hasMutationPolicy(ent) {
+		fmt.Fprintf(buf, "    Mutation: []privacy.Rule{\n")
+		for _, rule := range ent.Policy.MutationRules {
+			fmt.Fprintf(buf, "        %s,\n", policyRuleLiteral(ent, rule))
+		}
+		fmt.Fprintf(buf, "    },\n")
+	}
+	fmt.Fprintf(buf, "}\n\n")
+}
+
+func policyRuleLiteral(ent Entity, rule dsl.PolicyRule) string {
+	var expr string
+	switch rule.Kind {
+	case dsl.PolicyAlwaysAllow:
+		expr = "privacy.AlwaysAllow()"
+	case dsl.PolicyAlwaysDeny:
+		expr = "privacy.AlwaysDeny()"
+	case dsl.PolicyAllowIfAuthenticated:
+		expr = "privacy.AllowIfAuthenticated()"
+	case dsl.PolicyDenyIfAnonymous:
+		expr = "privacy.DenyIfAnonymous()"
+	case dsl.PolicyAllowIfRole:
+		expr = fmt.Sprintf("privacy.AllowIfRole(%s)", quoteArgs(rule.Roles))
+	case dsl.PolicyDenyUnlessRole:
+		expr = fmt.Sprintf("privacy.DenyUnlessRole(%s)", quoteArgs(rule.Roles))
+	case dsl.PolicyFilterOwner:
+		column := rule.Field
+		if field, ok := policyOwnerField(ent, rule.Field); ok {
+			column = fieldColumn(field)
+		}
+		expr = fmt.Sprintf("privacy.FilterOwner(%q)", column)
+	default:
+		expr = "privacy.AlwaysDeny()"
+	}
+	if len(rule.Ops) > 0 {
+		ops := make([]string, len(rule.Ops))
+		for i, op := range rule.Ops {
+			ops[i] = runtimeMutationOpLiteral(op)
+		}
+		expr += fmt.Sprintf(".On(%s)", strings.Join(ops, ", "))
+	}
+	return expr
+}
+
+func runtimeMutationOpLiteral(op dsl.MutationOp) string {
+	switch op {
+	case dsl.MutationUpdate:
+		return "runtime.MutationUpdate"
+	case dsl.MutationDelete:
+		return "runtime.MutationDelete"
+	default:
+		return "runtime.MutationCreate"
+	}
+}
+
+func quoteArgs(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = fmt.Sprintf("%q", item)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// emitPolicyFilters emits a statement that evaluates the query policy into `filters`, returning
+// errPrefix plus the error when the policy denies the read.
+func emitPolicyFilters(buf *bytes.Buffer, ent Entity, errPrefix string) {
+	fmt.Fprintf(buf, "    filters, err := %s.EvalQuery(ctx, %q)\n", policyVarName(ent), ent.Name)
+	fmt.Fprintf(buf, "    if err != nil {\n        return %serr\n    }\n", errPrefix)
+}
+
+func emitMutationOwnership(buf *bytes.Buffer, ent Entity) {
+	owners := mutationOwnerColumns(ent)
+	if len(owners) == 0 {
+		return
+	}
+	name := ent.Name
+	table := pluralize(name)
+	pk := primaryField(ent)
+
+	fmt.Fprintf(buf, "// OwnedBy reports whether every row the mutation writes has column set to subject. Updates and\n")
+	fmt.Fprintf(buf, "// deletes also check the stored rows; upserts, UpdateWhere and DeleteWhere only write stored rows\n")
+	fmt.Fprintf(buf, "// owned by subject. It implements privacy.OwnershipChecker.\n")
+	fmt.Fprintf(buf, "func (m *%sMutation) OwnedBy(ctx context.Context, column, subject string) (bool, error) {\n", name)
+	fmt.Fprintf(buf, "    var query string\n")
+	fmt.Fprintf(buf, "    switch column {\n")
+	for _, field := range owners {
+		column := fieldColumn(field)
+		fmt.Fprintf(buf, "    case %q:\n", column)
+		fmt.Fprintf(buf, "        for _, input := range m.records() {\n")
+		fmt.Fprintf(buf, "            if input != nil && m.touches(%q) && !privacy.MatchesSubject(input.%s, subject) {\n                return false, nil\n            }\n        }\n", field.Name, exportName(field.Name))
+		fmt.Fprintf(buf, "        query = %q\n", fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = ANY($1) AND %s IS DISTINCT FROM $2", table, fieldColumn(pk), column))
+	}
+	fmt.Fprintf(buf, "    default:\n        return false, fmt.Errorf(\"unknown owner column %%q\", column)\n    }\n")
+	emitConflictOwnership(buf)
+	fmt.Fprintf(buf, "    if m.copy {\n        // Streamed rows cannot be inspected before they are written.\n        return false, nil\n    }\n")
+	fmt.Fprintf(buf, "    if m.op == runtime.MutationCreate {\n        return true, nil\n    }\n")
+	fmt.Fprintf(buf, "    if m.where {\n        m.Predicates = append(m.Predicates, runtime.Compare(column, runtime.OpEqual, subject))\n        return true, nil\n    }\n")
+	fmt.Fprintf(buf, "    ids := m.IDs\n")
+	fmt.Fprintf(buf, "    if m.op == runtime.MutationUpdate && len(ids) == 0 {\n")
+	fmt.Fprintf(buf, "        ids = make([]string, 0, len(m.records()))\n")
+	fmt.Fprintf(buf, "        for _, input := range m.records() {\n            if input != nil {\n                ids = append(ids, input.%s)\n            }\n        }\n", exportName(pk.Name))
+	fmt.Fprintf(buf, "    }\n")
+	fmt.Fprintf(buf, "    if len(ids) == 0 {\n        return true, nil\n    }\n")
+	fmt.Fprintf(buf, "    var foreign int\n")
+	fmt.Fprintf(buf, "    // Stored owners are read from the primary, as a lagging replica could still show rows whose\n")
+	fmt.Fprintf(buf, "    // owner has changed.\n")
+	fmt.Fprintf(buf, "    if err := m.db.QueryRow(pg.WithPrimary(ctx), %q, query, ids, subject).Scan(&foreign); err != nil {\n        return false, err\n    }\n", table)
+	fmt.Fprintf(buf, "    return foreign == 0, nil\n}\n\n")
+}
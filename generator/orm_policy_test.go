@@ -0,0 +1,190 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+const policyClientTest = `package gen
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"github.com/deicod/erm/oidc"
+	"github.com/deicod/erm/orm/pg"
+	"github.com/deicod/erm/orm/runtime"
+	"github.com/deicod/erm/orm/runtime/privacy"
+)
+
+func TestPostPolicy(t *testing.T) {
+	mock, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+	posts := client.Posts()
+
+	if _, err := posts.Query().All(context.Background()); !errors.Is(err, privacy.ErrDenied) {
+		t.Fatalf("expected anonymous query to be denied, got %v", err)
+	}
+
+	alice := oidc.ToContext(context.Background(), oidc.Claims{Subject: "alice"})
+	mock.ExpectQuery("SELECT id, author_id, title FROM posts WHERE author_id = $1 LIMIT $2").
+		WithArgs("alice", 20).
+		WillReturnRows(mock.NewRows([]string{"id", "author_id", "title"}).AddRow("post-1", "alice", "Hello"))
+	items, err := posts.Query().Limit(20).All(alice)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected filtered query, got %v (err %v)", items, err)
+	}
+
+	mock.ExpectQuery("SELECT id, author_id, title FROM posts WHERE id = $1 AND author_id = $2 LIMIT $3").
+		WithArgs("post-2", "alice", 1).
+		WillReturnRows(mock.NewRows([]string{"id", "author_id", "title"}))
+	post, err := posts.ByID(alice, "post-2")
+	if !runtime.IsNotFound(err) || post != nil {
+		t.Fatalf("expected foreign post to be hidden, got %v (err %v)", post, err)
+	}
+
+	mock.ExpectQuery("SELECT id, author_id, title FROM posts WHERE author_id IN ($1) AND author_id = $2").
+		WithArgs("bob", "alice").
+		WillReturnRows(mock.NewRows([]string{"id", "author_id", "title"}))
+	bob := &User{ID: "bob"}
+	if err := client.Users().LoadPosts(alice, bob); err != nil {
+		t.Fatalf("load posts: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT id FROM users WHERE EXISTS (SELECT 1 FROM posts AS e_posts WHERE e_posts.author_id = users.id AND e_posts.title = $1 AND e_posts.author_id = $2) LIMIT $3").
+		WithArgs("Secret", "alice", 10).
+		WillReturnRows(mock.NewRows([]string{"id"}))
+	if users, err := client.Users().Query().WhereHasPostsWith(runtime.Compare("title", runtime.OpEqual, "Secret")).Limit(10).All(alice); err != nil || len(users) != 0 {
+		t.Fatalf("expected posts of other authors not to satisfy the edge predicate, got %v (err %v)", users, err)
+	}
+	mock.ExpectQuery("SELECT id FROM users WHERE EXISTS (SELECT 1 FROM posts AS e_posts WHERE e_posts.author_id = users.id AND FALSE) LIMIT $1").
+		WithArgs(10).
+		WillReturnRows(mock.NewRows([]string{"id"}))
+	if _, err := client.Users().Query().WhereHasPosts().Limit(10).All(context.Background()); err != nil {
+		t.Fatalf("expected an anonymous edge predicate to match nothing, got %v", err)
+	}
+
+	admin := privacy.WithViewer(context.Background(), privacy.Viewer{Subject: "root", Roles: []string{"admin"}})
+	mock.ExpectQuery("SELECT COUNT(*) FROM posts").
+		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(3))
+	if total, err := posts.Count(admin); err != nil || total != 3 {
+		t.Fatalf("expected admin count, got %d (err %v)", total, err)
+	}
+
+	if _, err := posts.Create(alice, &Post{ID: "post-3", AuthorID: "bob", Title: "Spoof"}); !errors.Is(err, privacy.ErrDenied) {
+		t.Fatalf("expected create for another author to be denied, got %v", err)
+	}
+
+	mock.ExpectQuery("SELECT COUNT(*) FROM posts WHERE id = ANY($1) AND author_id IS DISTINCT FROM $2").
+		WithArgs([]string{"post-1"}, "alice").
+		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("UPDATE posts SET author_id = $1, title = $2 WHERE id = $3 RETURNING id, author_id, title").
+		WithArgs("alice", "Edited", "post-1").
+		WillReturnRows(mock.NewRows([]string{"id", "author_id", "title"}).AddRow("post-1", "alice", "Edited"))
+	if _, err := posts.Update(alice, &Post{ID: "post-1", AuthorID: "alice", Title: "Edited"}); err != nil {
+		t.Fatalf("expected owner update to succeed: %v", err)
+	}
+
+	if err := posts.Delete(alice, "post-1"); !errors.Is(err, privacy.ErrDenied) {
+		t.Fatalf("expected delete to be denied for non-admins, got %v", err)
+	}
+
+	mock.ExpectExec("DELETE FROM posts WHERE id = $1").
+		WithArgs("post-1").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	if err := posts.Delete(privacy.Bypass(context.Background()), "post-1"); err != nil {
+		t.Fatalf("expected bypassed delete to succeed: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestPostOwnershipReadsPrimary(t *testing.T) {
+	primary, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	replica, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	db := &pg.DB{Pool: &mockPool{PgxConnIface: primary}}
+	db.AddReplica(pg.ReplicaConfig{Name: "replica-a"}, &mockPool{PgxConnIface: replica})
+	db.UseReplicaHealthCheck(func(context.Context, pg.Pool) (pg.ReplicaHealthReport, error) {
+		return pg.ReplicaHealthReport{ReadOnly: true}, nil
+	})
+	db.UseReplicaPolicies("replica", map[string]pg.ReplicaReadOptions{"replica": {DisableFallback: true}})
+	posts := NewClient(db).Posts()
+
+	alice := oidc.ToContext(context.Background(), oidc.Claims{Subject: "alice"})
+	primary.ExpectQuery("SELECT COUNT(*) FROM posts WHERE id = ANY($1) AND author_id IS DISTINCT FROM $2").
+		WithArgs([]string{"post-1"}, "alice").
+		WillReturnRows(primary.NewRows([]string{"count"}).AddRow(1))
+	if _, err := posts.Update(alice, &Post{ID: "post-1", AuthorID: "alice", Title: "Taken"}); !errors.Is(err, privacy.ErrDenied) {
+		t.Fatalf("expected update of a post owned by someone else on the primary to be denied, got %v", err)
+	}
+
+	if err := primary.ExpectationsWereMet(); err != nil {
+		t.Fatalf("primary expectations: %v", err)
+	}
+	if err := replica.ExpectationsWereMet(); err != nil {
+		t.Fatalf("replica expectations: %v", err)
+	}
+}
+`
+
+func TestWriteORMClients_PolicyEnforcement(t *testing.T) {
+	entities := []Entity{
+		{
+			Name: "Post",
+			Fields: []dsl.Field{
+				dsl.UUIDv7("id").Primary(),
+				dsl.String("author_id"),
+				dsl.String("title"),
+			},
+			Edges: []dsl.Edge{
+				dsl.ToOne("author", "User").Field("author_id").Inverse("posts"),
+			},
+			Policy: dsl.NewPolicy().
+				Query(dsl.AllowIfRole("admin"), dsl.FilterOwner("author_id")).
+				Mutation(dsl.AllowIfRole("admin"), dsl.AlwaysDeny().On(dsl.MutationDelete), dsl.FilterOwner("author_id")),
+		},
+		{
+			Name: "User",
+			Fields: []dsl.Field{
+				dsl.String("id").Primary(),
+			},
+		},
+	}
+	synthesizeInverseEdges(entities)
+	for i := range entities {
+		ensureDefaultQuery(&entities[i])
+	}
+
+	root := t.TempDir()
+	if err := writeORMArtifacts(root, entities); err != nil {
+		t.Fatalf("writeORMArtifacts: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(root, "orm", "gen", "client_gen.go"))
+	if err != nil {
+		t.Fatalf("read client: %v", err)
+	}
+	src := string(content)
+	mustContain(t, src, "var postPolicy = privacy.Policy{")
+	mustContain(t, src, "privacy.AlwaysDeny().On(runtime.MutationDelete)")
+	mustContain(t, src, "func (m *PostMutation) OwnedBy(ctx context.Context, column, subject string) (bool, error) {")
+	mustContain(t, src, `case "Post": filters, err = postPolicy.EvalQuery(ctx, entity)`)
+
+	runGeneratedORMTest(t, root, "policy_test.go", policyClientTest)
+}
@@ -0,0 +1,176 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+// predicatePackageName is the package holding an entity's typed predicates, e.g. orm/gen/post.
+func predicatePackageName(ent Entity) string {
+	name := strings.ToLower(ent.Name)
+	if token.IsKeyword(name) {
+		return name + "ent"
+	}
+	return name
+}
+
+// predicateExpr renders the runtime.Predicate expression for op applied to the column expression.
+// args holds the Go expressions for the operand(s): none for null checks, two for Between, one
+// otherwise.
+func predicateExpr(op dsl.ComparisonOperator, column string, args ...string) string {
+	arg := func(i int) string {
+		if i < len(args) {
+			return args[i]
+		}
+		return "nil"
+	}
+	switch op {
+	case dsl.OpIsNull:
+		return fmt.Sprintf("runtime.IsNull(%s)", column)
+	case dsl.OpNotNull:
+		return fmt.Sprintf("runtime.NotNull(%s)", column)
+	case dsl.OpIn:
+		return fmt.Sprintf("runtime.In(%s, %s)", column, arg(0))
+	case dsl.OpNotIn:
+		return fmt.Sprintf("runtime.NotIn(%s, %s)", column, arg(0))
+	case dsl.OpBetween:
+		return fmt.Sprintf("runtime.Between(%s, %s, %s)", column, arg(0), arg(1))
+	case dsl.OpLike:
+		return fmt.Sprintf("runtime.Like(%s, %s)", column, arg(0))
+	case dsl.OpHasPrefix:
+		return fmt.Sprintf("runtime.HasPrefix(%s, %s)", column, arg(0))
+	case dsl.OpHasSuffix:
+		return fmt.Sprintf("runtime.HasSuffix(%s, %s)", column, arg(0))
+	case dsl.OpContains:
+		return fmt.Sprintf("runtime.Contains(%s, %s)", column, arg(0))
+	default:
+		return fmt.Sprintf("runtime.Predicate{Column: %s, Operator: %s, Value: %s}", column, runtimeOperatorLiteral(op), arg(0))
+	}
+}
+
+// predicateParams returns the parameter list and operand expressions for a predicate on a value
+// of goType.
+func predicateParams(op dsl.ComparisonOperator, goType string) (string, []string) {
+	switch op {
+	case dsl.OpIsNull, dsl.OpNotNull:
+		return "", nil
+	case dsl.OpIn, dsl.OpNotIn:
+		return "values ..." + goType, []string{"values"}
+	case dsl.OpBetween:
+		return "low, high " + goType, []string{"low", "high"}
+	case dsl.OpLike, dsl.OpHasPrefix, dsl.OpHasSuffix, dsl.OpContains:
+		return "value string", []string{"value"}
+	default:
+		return "value " + goType, []string{"value"}
+	}
+}
+
+// fieldPredicateOps lists the predicates generated for a field, based on its Go type.
+func fieldPredicateOps(field dsl.Field) []dsl.ComparisonOperator {
+	var ops []dsl.ComparisonOperator
+	switch baseGoType(field) {
+	case "string":
+		ops = []dsl.ComparisonOperator{dsl.OpEqual, dsl.OpNotEqual, dsl.OpIn, dsl.OpNotIn, dsl.OpGreaterThan, dsl.OpGTE, dsl.OpLessThan, dsl.OpLTE, dsl.OpBetween, dsl.OpLike, dsl.OpILike, dsl.OpHasPrefix, dsl.OpHasSuffix, dsl.OpContains}
+	case "int16", "int32", "int64", "int", "float32", "float64", "time.Time":
+		ops = []dsl.ComparisonOperator{dsl.OpEqual, dsl.OpNotEqual, dsl.OpIn, dsl.OpNotIn, dsl.OpGreaterThan, dsl.OpGTE, dsl.OpLessThan, dsl.OpLTE, dsl.OpBetween}
+	case "bool":
+		ops = []dsl.ComparisonOperator{dsl.OpEqual, dsl.OpNotEqual}
+	}
+	if field.Nullable {
+		ops = append(ops, dsl.OpIsNull, dsl.OpNotNull)
+	}
+	return ops
+}
+
+func writePredicatePackages(root string, entities []Entity) error {
+	entityIndex := make(map[string]Entity, len(entities))
+	for _, ent := range entities {
+		entityIndex[ent.Name] = ent
+	}
+	for _, ent := range entities {
+		if err := writePredicatePackage(root, ent, entityIndex); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePredicatePackage(root string, ent Entity, entityIndex map[string]Entity) error {
+	pkg := predicatePackageName(ent)
+	body := &bytes.Buffer{}
+	needsTime := false
+
+	fmt.Fprintf(body, "// Table is the table backing %s.\n", ent.Name)
+	fmt.Fprintf(body, "const Table = %q\n\n", pluralize(ent.Name))
+	fmt.Fprintf(body, "// Predicate is a condition on %s rows.\n", ent.Name)
+	fmt.Fprintf(body, "type Predicate = runtime.Predicate\n\n")
+	fmt.Fprintf(body, "// Order is a sort key of %s rows.\n", ent.Name)
+	fmt.Fprintf(body, "type Order = runtime.Order\n\n")
+	fmt.Fprintf(body, "// Aggregate is an aggregate of grouped %s rows.\n", ent.Name)
+	fmt.Fprintf(body, "type Aggregate = runtime.Aggregate\n\n")
+	fmt.Fprintf(body, "const (\n")
+	for _, field := range ent.Fields {
+		fmt.Fprintf(body, "    // Field%s is the column of the %s field.\n", exportName(field.Name), field.Name)
+		fmt.Fprintf(body, "    Field%s = %q\n", exportName(field.Name), fieldColumn(field))
+	}
+	fmt.Fprintf(body, ")\n\n")
+	emitConflictTargets(body, ent)
+
+	fmt.Fprintf(body, "// And matches %s rows that satisfy every predicate.\n", ent.Name)
+	fmt.Fprintf(body, "func And(preds ...runtime.Predicate) runtime.Predicate {\n    return runtime.And(preds...)\n}\n\n")
+	fmt.Fprintf(body, "// Or matches %s rows that satisfy at least one predicate.\n", ent.Name)
+	fmt.Fprintf(body, "func Or(preds ...runtime.Predicate) runtime.Predicate {\n    return runtime.Or(preds...)\n}\n\n")
+	fmt.Fprintf(body, "// Not negates pred.\n")
+	fmt.Fprintf(body, "func Not(pred runtime.Predicate) runtime.Predicate {\n    return runtime.Not(pred)\n}\n\n")
+	fmt.Fprintf(body, "// Asc sorts %s rows by column in ascending order, for use with %sQuery.Order.\n", ent.Name, ent.Name)
+	fmt.Fprintf(body, "func Asc(column string) runtime.Order {\n    return runtime.Order{Column: column, Direction: runtime.SortAsc}\n}\n\n")
+	fmt.Fprintf(body, "// Desc sorts %s rows by column in descending order, for use with %sQuery.Order.\n", ent.Name, ent.Name)
+	fmt.Fprintf(body, "func Desc(column string) runtime.Order {\n    return runtime.Order{Column: column, Direction: runtime.SortDesc}\n}\n\n")
+	fmt.Fprintf(body, "// Count counts the %s rows of each group, for use with %sGroupBy.Aggregate.\n", ent.Name, ent.Name)
+	fmt.Fprintf(body, "func Count() runtime.Aggregate {\n    return runtime.Count()\n}\n\n")
+	for _, fn := range []struct{ name, doc string }{
+		{"Sum", "adds up column"},
+		{"Avg", "averages column"},
+		{"Min", "selects the smallest value of column"},
+		{"Max", "selects the largest value of column"},
+	} {
+		fmt.Fprintf(body, "// %s %s per group, for use with %sGroupBy.Aggregate.\n", fn.name, fn.doc, ent.Name)
+		fmt.Fprintf(body, "func %s(column string) runtime.Aggregate {\n    return runtime.%s(column)\n}\n\n", fn.name, fn.name)
+	}
+
+	for _, field := range ent.Fields {
+		goType := baseGoType(field)
+		column := "Field" + exportName(field.Name)
+		for _, op := range fieldPredicateOps(field) {
+			params, args := predicateParams(op, goType)
+			if goType == "time.Time" && len(args) > 0 {
+				needsTime = true
+			}
+			fmt.Fprintf(body, "func %s%s(%s) runtime.Predicate {\n", exportName(field.Name), operatorSuffix(op), params)
+			fmt.Fprintf(body, "    return %s\n}\n\n", predicateExpr(op, column, args...))
+		}
+	}
+
+	emitEdgePredicateFuncs(body, ent, entityIndex)
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by erm. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "// Package %s holds typed predicates for %s queries, for use with %sQuery.Where, sort orders for\n", pkg, ent.Name, ent.Name)
+	fmt.Fprintf(buf, "// %sQuery.Order, aggregates for %sGroupBy.Aggregate, and the conflict targets of its unique\n", ent.Name, ent.Name)
+	fmt.Fprintf(buf, "// fields and indexes, for use with %sClient.Upsert.\n", ent.Name)
+	fmt.Fprintf(buf, "package %s\n\n", pkg)
+	fmt.Fprintf(buf, "import (\n")
+	if needsTime {
+		fmt.Fprintf(buf, "    \"time\"\n\n")
+	}
+	fmt.Fprintf(buf, "    \"github.com/deicod/erm/orm/runtime\"\n)\n\n")
+	buf.Write(body.Bytes())
+
+	path := filepath.Join(root, "orm", "gen", pkg, "where_gen.go")
+	return writeGoFile(path, buf.Bytes())
+}
@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+const predicateClientTest = `package gen_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"example.com/app/orm/gen"
+	"example.com/app/orm/gen/post"
+	"github.com/deicod/erm/orm/pg"
+)
+
+func TestWherePredicates(t *testing.T) {
+	mock, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := gen.NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := since.AddDate(0, 1, 0)
+
+	mock.ExpectQuery("SELECT id, title, views, published_at FROM posts WHERE (title LIKE $1 OR id = ANY($2)) AND NOT (published_at IS NULL) AND published_at BETWEEN $3 AND $4 AND views >= $5 LIMIT $6").
+		WithArgs("%go%", []string{"p1", "p2"}, since, until, int32(10), 5).
+		WillReturnRows(mock.NewRows([]string{"id", "title", "views", "published_at"}))
+	_, err = client.Posts().Query().
+		Where(
+			post.Or(post.TitleContains("go"), post.IDIn("p1", "p2")),
+			post.Not(post.PublishedAtIsNull()),
+			post.PublishedAtBetween(since, until),
+		).
+		WhereViewsGTE(10).
+		Limit(5).
+		All(context.Background())
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+`
+
+func TestWriteORMArtifacts_PredicatePackages(t *testing.T) {
+	ent := Entity{
+		Name: "Post",
+		Fields: []dsl.Field{
+			dsl.String("id").Primary(),
+			dsl.String("title"),
+			dsl.Integer("views"),
+			dsl.TimestampTZ("published_at").Optional(),
+		},
+		Query: dsl.Query().WithPredicates(dsl.NewPredicate("views", dsl.OpGTE)),
+	}
+	ensureDefaultQuery(&ent)
+
+	root := t.TempDir()
+	if err := writeORMArtifacts(root, []Entity{ent}); err != nil {
+		t.Fatalf("writeORMArtifacts: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(root, "orm", "gen", "post", "where_gen.go"))
+	if err != nil {
+		t.Fatalf("read predicates: %v", err)
+	}
+	src := string(content)
+	mustContain(t, src, "package post")
+	mustContain(t, src, "func TitleHasPrefix(value string) runtime.Predicate {\n\treturn runtime.HasPrefix(FieldTitle, value)\n}")
+	mustContain(t, src, "func ViewsIn(values ...int32) runtime.Predicate {")
+	mustContain(t, src, "func PublishedAtNotNull() runtime.Predicate {")
+
+	runGeneratedORMTest(t, root, "where_test.go", predicateClientTest)
+}
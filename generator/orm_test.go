@@ -66,6 +66,18 @@ func TestWriteORMClients_EdgeHelpers(t *testing.T) {
 
 	content := string(clientSrc)
 	mustContain(t, content, "var ValidationRegistry = validation.NewRegistry()")
+	mustContain(t, content, "func (c *Client) BeginTx(ctx context.Context, opts pgx.TxOptions) (*Tx, error) {")
+	mustContain(t, content, "func (c *Client) Tx(ctx context.Context, fn func(tx *Tx) error) error {")
+	mustContain(t, content, "deferred := cache.NewDeferred(c.cacheStore())")
+	mustContain(t, content, "func (c *Client) Use(hooks ...runtime.Hook) {")
+	mustContain(t, content, "func (c *UserClient) Intercept(interceptors ...runtime.Interceptor) {")
+	mustContain(t, content, "type UserMutation struct {")
+	mustContain(t, content, "return runtime.CastResult[*User](c.mutate(ctx, m, func(ctx context.Context, m *UserMutation) (any, error) {")
+	mustContain(t, content, "return runtime.CastResult[[]*Post](q.intercept(ctx, runtime.QueryAll, func(ctx context.Context, q *PostQuery) (any, error) {")
+	mustContain(t, content, "return runtime.CastResult[int](q.intercept(ctx, runtime.QueryCount, func(ctx context.Context, q *PostQuery) (any, error) {")
+	mustContain(t, content, "writer := c.db.Writer()")
+	mustContain(t, content, "row := c.db.Pool.QueryRow(ctx, userSelectQuery, id)")
+	mustContain(t, content, "rows, err := c.db.Pool.Query(ctx, sql, args...)")
 	mustContain(t, content, "const userInsertQuery = `INSERT INTO users")
 	mustContain(t, content, "const userSelectQuery = `SELECT id")
 	mustContain(t, content, "const userListQuery = `SELECT id")
@@ -138,34 +150,7 @@ func TestToOneLoaderHandlesOptionalForeignKeys(t *testing.T) {
 		t.Fatalf("writeORMArtifacts: %v", err)
 	}
 
-	repoRoot, err := filepath.Abs("..")
-	if err != nil {
-		t.Fatalf("repo root: %v", err)
-	}
-	modulePath := "example.com/app"
-	goMod := fmt.Sprintf("module %s\n\ngo 1.21\n\nrequire github.com/deicod/erm v0.0.0\n\nreplace github.com/deicod/erm => %s\n", modulePath, filepath.ToSlash(repoRoot))
-	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte(goMod), 0o644); err != nil {
-		t.Fatalf("write go.mod: %v", err)
-	}
-
-	goModTidy := exec.Command("go", "mod", "tidy")
-	goModTidy.Dir = root
-	goModTidy.Env = append(os.Environ(), "GOWORK=off")
-	if output, err := goModTidy.CombinedOutput(); err != nil {
-		t.Fatalf("go mod tidy: %v\n%s", err, output)
-	}
-
-	gofmt := exec.Command("gofmt", "-w", filepath.Join(root, "orm"))
-	if output, err := gofmt.CombinedOutput(); err != nil {
-		t.Fatalf("gofmt: %v\n%s", err, output)
-	}
-
-	goTest := exec.Command("go", "test", "./...")
-	goTest.Dir = root
-	goTest.Env = append(os.Environ(), "GOWORK=off")
-	if output, err := goTest.CombinedOutput(); err != nil {
-		t.Fatalf("go test: %v\n%s", err, output)
-	}
+	testGeneratedModule(t, root)
 }
 
 func TestWriteORMClients_ComputedFields(t *testing.T) {
@@ -203,6 +188,32 @@ func TestWriteORMClients_ComputedFields(t *testing.T) {
 	mustContain(t, content, "row := []any{input.ID, input.FirstName, input.LastName}")
 }
 
+func TestWriteORMClients_MixinHooks(t *testing.T) {
+	ent := Entity{
+		Name:   "Post",
+		Fields: []dsl.Field{dsl.String("title")},
+		Mixins: []dsl.Mixin{dsl.UUIDv7IDMixin(), dsl.AuditMixin()},
+	}
+	applyMixins(&ent)
+	ensureDefaultQuery(&ent)
+
+	root := t.TempDir()
+	if err := writeClients(root, []Entity{ent}); err != nil {
+		t.Fatalf("writeClients: %v", err)
+	}
+	clientSrc, err := os.ReadFile(filepath.Join(root, "orm", "gen", "client_gen.go"))
+	if err != nil {
+		t.Fatalf("read client: %v", err)
+	}
+
+	content := string(clientSrc)
+	mustContain(t, content, "func (m *PostMutation) applySchemaHooks(ctx context.Context) {")
+	mustContain(t, content, "if m.op == runtime.MutationCreate || m.op == runtime.MutationUpdate {")
+	mustContain(t, content, "input.UpdatedBy = &subject")
+	mustContain(t, content, "typed.applySchemaHooks(ctx)")
+	mustContain(t, content, "UPDATE posts SET title = $1, updated_by = $2 WHERE id = $3")
+}
+
 func TestWriteRegistry_EdgeMetadata(t *testing.T) {
 	entities := []Entity{
 		{
@@ -254,6 +265,67 @@ func TestExportNamePreservesInitialisms(t *testing.T) {
 	}
 }
 
+// runGeneratedORMTest writes src, a test of the package generated under root/orm/gen, to the file
+// name next to the generated client and runs the tests of the generated module. A mockPool
+// adapting pgxmock connections to pg.Pool is declared in the package of src.
+func runGeneratedORMTest(t *testing.T, root, name, src string) {
+	t.Helper()
+	dir := filepath.Join(root, "orm", "gen")
+	pkg := strings.TrimSpace(strings.TrimPrefix(strings.SplitN(src, "\n", 2)[0], "package"))
+	mockPool := fmt.Sprintf(`package %s
+
+import (
+	"context"
+
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+)
+
+type mockPool struct{ pgxmock.PgxConnIface }
+
+func (m *mockPool) Close() { _ = m.PgxConnIface.Close(context.Background()) }
+`, pkg)
+	if err := os.WriteFile(filepath.Join(dir, "mockpool_test.go"), []byte(mockPool), 0o644); err != nil {
+		t.Fatalf("write mock pool: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	testGeneratedModule(t, root)
+}
+
+// testGeneratedModule runs the tests of the module generated under root as example.com/app, with
+// github.com/deicod/erm replaced by this repository.
+func testGeneratedModule(t *testing.T, root string) {
+	t.Helper()
+	repoRoot, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatalf("repo root: %v", err)
+	}
+	goMod := fmt.Sprintf("module example.com/app\n\ngo 1.23\n\nrequire github.com/deicod/erm v0.0.0\n\nreplace github.com/deicod/erm => %s\n", filepath.ToSlash(repoRoot))
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte(goMod), 0o644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+
+	goModTidy := exec.Command("go", "mod", "tidy")
+	goModTidy.Dir = root
+	goModTidy.Env = append(os.Environ(), "GOWORK=off")
+	if output, err := goModTidy.CombinedOutput(); err != nil {
+		t.Fatalf("go mod tidy: %v\n%s", err, output)
+	}
+
+	gofmt := exec.Command("gofmt", "-w", filepath.Join(root, "orm"))
+	if output, err := gofmt.CombinedOutput(); err != nil {
+		t.Fatalf("gofmt: %v\n%s", err, output)
+	}
+
+	goTest := exec.Command("go", "test", "./...")
+	goTest.Dir = root
+	goTest.Env = append(os.Environ(), "GOWORK=off")
+	if output, err := goTest.CombinedOutput(); err != nil {
+		t.Fatalf("go test: %v\n%s", err, output)
+	}
+}
+
 func mustContain(t *testing.T, content, needle string) {
 	t.Helper()
 	normalize := func(s string) string {
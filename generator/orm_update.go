@@ -0,0 +1,249 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+// partialUpdateFields lists the fields UpdateOneID builders can write, in schema order.
+func partialUpdateFields(ent Entity) []dsl.Field {
+	columns := updatableColumns(ent)
+	fields := make([]dsl.Field, 0, len(columns))
+	for _, column := range columns {
+		fields = append(fields, findFieldByColumn(ent, column))
+	}
+	return fields
+}
+
+// addableField reports whether the builder gets an AddX method for field: plain numeric columns
+// whose Go type supports +=.
+func addableField(field dsl.Field) bool {
+	if field.Nullable {
+		return false
+	}
+	switch baseGoType(field) {
+	case "int16", "int32", "int64", "float32", "float64":
+		return true
+	}
+	return false
+}
+
+func emitUpdateOneBuilder(buf *bytes.Buffer, ent Entity) {
+	fields := partialUpdateFields(ent)
+	if len(fields) == 0 {
+		return
+	}
+	name := ent.Name
+	builder := name + "UpdateOne"
+	pk := exportName(primaryField(ent).Name)
+
+	fmt.Fprintf(buf, "// %s updates the columns of one %s that its setters touch; every other column keeps its\n", builder, name)
+	fmt.Fprintf(buf, "// stored value. Hooks see the touched fields through %sMutation.ChangedFields.\n", name)
+	fmt.Fprintf(buf, "type %s struct {\n", builder)
+	fmt.Fprintf(buf, "    client *%sClient\n", name)
+	fmt.Fprintf(buf, "    input *%s\n", name)
+	fmt.Fprintf(buf, "    fields []string\n")
+	fmt.Fprintf(buf, "    ops map[string]runtime.AssignOp\n")
+	fmt.Fprintf(buf, "    err error\n")
+	fmt.Fprintf(buf, "}\n\n")
+
+	fmt.Fprintf(buf, "// UpdateOneID starts a partial update of the %s with the given id.\n", name)
+	fmt.Fprintf(buf, "func (c *%sClient) UpdateOneID(id string) *%s {\n", name, builder)
+	fmt.Fprintf(buf, "    return &%s{client: c, input: &%s{%s: id}, ops: make(map[string]runtime.AssignOp)}\n}\n\n", builder, name, pk)
+
+	emitUpdateSetters(buf, builder, fields)
+
+	if version, ok := versionField(ent); ok {
+		fmt.Fprintf(buf, "// ExpectVersion makes Save fail with runtime.ErrStaleObject unless the stored row still has\n")
+		fmt.Fprintf(buf, "// version v.\n")
+		fmt.Fprintf(buf, "func (u *%s) ExpectVersion(v %s) *%s {\n", builder, baseGoType(version), builder)
+		fmt.Fprintf(buf, "    u.input.%s = v\n    return u\n}\n\n", exportName(version.Name))
+	}
+
+	fmt.Fprintf(buf, "// SetFrom copies the named fields from model, clearing nullable fields that are nil on model.\n")
+	fmt.Fprintf(buf, "// Naming a field that cannot be updated fails Save.\n")
+	fmt.Fprintf(buf, "func (u *%s) SetFrom(model *%s, fields ...string) *%s {\n", builder, name, builder)
+	fmt.Fprintf(buf, "    if model == nil {\n        return u\n    }\n")
+	fmt.Fprintf(buf, "    for _, field := range fields {\n")
+	fmt.Fprintf(buf, "        switch field {\n")
+	for _, field := range fields {
+		fmt.Fprintf(buf, "        case %q:\n", field.Name)
+		fmt.Fprintf(buf, "            u.input.%s = model.%s\n", exportName(field.Name), exportName(field.Name))
+		fmt.Fprintf(buf, "            u.touch(%q, runtime.AssignSet)\n", field.Name)
+	}
+	fmt.Fprintf(buf, "        default:\n")
+	fmt.Fprintf(buf, "            u.err = fmt.Errorf(\"%s field %%q cannot be updated\", field)\n", name)
+	fmt.Fprintf(buf, "        }\n    }\n    return u\n}\n\n")
+
+	fmt.Fprintf(buf, "// Save writes the touched columns and returns the updated row.\n")
+	fmt.Fprintf(buf, "func (u *%s) Save(ctx context.Context) (*%s, error) {\n", builder, name)
+	fmt.Fprintf(buf, "    if u.err != nil {\n        return nil, u.err\n    }\n")
+	emitUpdateNowTouches(buf, fields)
+	fmt.Fprintf(buf, "    m := &%sMutation{op: runtime.MutationUpdate, db: u.client.db, Input: u.input, fields: append([]string{}, u.fields...)}\n", name)
+	fmt.Fprintf(buf, "    return runtime.CastResult[*%s](u.client.mutate(ctx, m, func(ctx context.Context, m *%sMutation) (any, error) {\n", name, name)
+	fmt.Fprintf(buf, "        return u.client.updateFields(ctx, m.Input, m.fields, u.ops)\n    }))\n}\n\n")
+
+	fmt.Fprintf(buf, "// Exec is Save without the returned row.\n")
+	fmt.Fprintf(buf, "func (u *%s) Exec(ctx context.Context) error {\n", builder)
+	fmt.Fprintf(buf, "    _, err := u.Save(ctx)\n    return err\n}\n\n")
+
+	emitUpdateAssignmentsMethod(buf, ent, fields)
+	emitUpdateFieldsMethod(buf, ent)
+}
+
+// emitUpdateSetters emits the touch bookkeeping and the SetX/ClearX/AddX methods shared by the
+// UpdateOneID and UpdateWhere builders.
+func emitUpdateSetters(buf *bytes.Buffer, builder string, fields []dsl.Field) {
+	fmt.Fprintf(buf, "func (u *%s) touch(field string, op runtime.AssignOp) {\n", builder)
+	fmt.Fprintf(buf, "    if _, ok := u.ops[field]; !ok {\n        u.fields = append(u.fields, field)\n    }\n")
+	fmt.Fprintf(buf, "    u.ops[field] = op\n}\n\n")
+	for _, field := range fields {
+		emitUpdateOneSetters(buf, builder, field)
+	}
+}
+
+// emitUpdateNowTouches refreshes UpdateNow fields that no setter touched.
+func emitUpdateNowTouches(buf *bytes.Buffer, fields []dsl.Field) {
+	for _, field := range fields {
+		if field.HasUpdateNow {
+			fmt.Fprintf(buf, "    if _, ok := u.ops[%q]; !ok {\n", field.Name)
+			fmt.Fprintf(buf, "        u.input.%s = time.Now().UTC()\n", exportName(field.Name))
+			fmt.Fprintf(buf, "        u.touch(%q, runtime.AssignSet)\n    }\n", field.Name)
+		}
+	}
+}
+
+func emitUpdateOneSetters(buf *bytes.Buffer, builder string, field dsl.Field) {
+	fieldName := exportName(field.Name)
+	fmt.Fprintf(buf, "func (u *%s) Set%s(v %s) *%s {\n", builder, fieldName, updateSetterType(field), builder)
+	switch {
+	case isNullablePointerField(field) && strings.HasPrefix(defaultGoType(field), "*"):
+		fmt.Fprintf(buf, "    u.input.%s = &v\n", fieldName)
+	case isNullableSQLNullField(field):
+		fmt.Fprintf(buf, "    u.input.%s = %s{%s: v, Valid: true}\n", fieldName, defaultGoType(field), sqlNullValueFieldAccessor(field))
+	default:
+		fmt.Fprintf(buf, "    u.input.%s = v\n", fieldName)
+	}
+	fmt.Fprintf(buf, "    u.touch(%q, runtime.AssignSet)\n    return u\n}\n\n", field.Name)
+
+	if field.Nullable {
+		fmt.Fprintf(buf, "func (u *%s) Clear%s() *%s {\n", builder, fieldName, builder)
+		fmt.Fprintf(buf, "    var zero %s\n    u.input.%s = zero\n", defaultGoType(field), fieldName)
+		fmt.Fprintf(buf, "    u.touch(%q, runtime.AssignClear)\n    return u\n}\n\n", field.Name)
+	}
+
+	if addableField(field) {
+		fmt.Fprintf(buf, "// Add%s adds delta to the stored value. After Set%s it adjusts the value being set instead.\n", fieldName, fieldName)
+		fmt.Fprintf(buf, "func (u *%s) Add%s(delta %s) *%s {\n", builder, fieldName, defaultGoType(field), builder)
+		fmt.Fprintf(buf, "    switch u.ops[%q] {\n", field.Name)
+		fmt.Fprintf(buf, "    case runtime.AssignSet:\n        u.input.%s += delta\n        return u\n", fieldName)
+		fmt.Fprintf(buf, "    case runtime.AssignAdd:\n        u.input.%s += delta\n", fieldName)
+		fmt.Fprintf(buf, "    default:\n        u.input.%s = delta\n    }\n", fieldName)
+		fmt.Fprintf(buf, "    u.touch(%q, runtime.AssignAdd)\n    return u\n}\n\n", field.Name)
+	}
+}
+
+// updateSetterType returns the parameter type of a builder's SetX method: nullable fields take
+// the underlying value and wrap it themselves.
+func updateSetterType(field dsl.Field) string {
+	switch {
+	case isNullablePointerField(field):
+		return strings.TrimPrefix(defaultGoType(field), "*")
+	case isNullableSQLNullField(field):
+		return sqlNullValueGoType(sqlNullValueFieldAccessor(field))
+	default:
+		return defaultGoType(field)
+	}
+}
+
+// emitUpdateAssignmentsMethod emits the translation of touched fields into SET assignments. fields
+// and ops may have been changed by hooks, so unknown fields are rejected here rather than in the
+// builders.
+func emitUpdateAssignmentsMethod(buf *bytes.Buffer, ent Entity, fields []dsl.Field) {
+	name := ent.Name
+	fmt.Fprintf(buf, "// updateAssignments returns the assignments writing fields of input, and the validation keys\n")
+	fmt.Fprintf(buf, "// of those fields. For fields updated with AssignAdd input holds the delta.\n")
+	fmt.Fprintf(buf, "func (c *%sClient) updateAssignments(input *%s, fields []string, ops map[string]runtime.AssignOp) ([]runtime.Assignment, []string, error) {\n", name, name)
+	fmt.Fprintf(buf, "    assignments := make([]runtime.Assignment, 0, len(fields))\n")
+	fmt.Fprintf(buf, "    keys := make([]string, 0, len(fields))\n")
+	fmt.Fprintf(buf, "    for _, field := range fields {\n")
+	fmt.Fprintf(buf, "        assignment := runtime.Assignment{Op: ops[field]}\n")
+	fmt.Fprintf(buf, "        switch field {\n")
+	for _, field := range fields {
+		fmt.Fprintf(buf, "        case %q:\n", field.Name)
+		fmt.Fprintf(buf, "            assignment.Column, assignment.Value = %q, input.%s\n", fieldColumn(field), exportName(field.Name))
+		fmt.Fprintf(buf, "            keys = append(keys, %q)\n", exportName(field.Name))
+	}
+	fmt.Fprintf(buf, "        default:\n            return nil, nil, fmt.Errorf(\"%s field %%q cannot be updated\", field)\n        }\n", name)
+	fmt.Fprintf(buf, "        assignments = append(assignments, assignment)\n    }\n")
+	fmt.Fprintf(buf, "    return assignments, keys, nil\n}\n\n")
+}
+
+// emitUpdateFieldsMethod emits the executor of UpdateOneID builders. Versioned entities always
+// increment the version and, when input carries one, require it to match.
+func emitUpdateFieldsMethod(buf *bytes.Buffer, ent Entity) {
+	name := ent.Name
+	pk := primaryField(ent)
+	fmt.Fprintf(buf, "func (c *%sClient) updateFields(ctx context.Context, input *%s, fields []string, ops map[string]runtime.AssignOp) (*%s, error) {\n", name, name, name)
+	fmt.Fprintf(buf, "    if input == nil {\n        return nil, errors.New(\"input cannot be nil\")\n    }\n")
+	fmt.Fprintf(buf, "    if input.%s == \"\" {\n        return nil, errors.New(\"id is required\")\n    }\n", exportName(pk.Name))
+	fmt.Fprintf(buf, "    assignments, keys, err := c.updateAssignments(input, fields, ops)\n")
+	fmt.Fprintf(buf, "    if err != nil {\n        return nil, err\n    }\n")
+	fmt.Fprintf(buf, "    if len(assignments) == 0 {\n        return c.ByID(ctx, input.%s)\n    }\n", exportName(pk.Name))
+	fmt.Fprintf(buf, "    if err := ValidationRegistry.ValidateFields(ctx, %q, validation.OpUpdate, %sValidationRecord(input), input, keys); err != nil {\n        return nil, err\n    }\n", name, strings.ToLower(name))
+	preds := fmt.Sprintf("runtime.Compare(%q, runtime.OpEqual, input.%s)", fieldColumn(pk), exportName(pk.Name))
+	if isSoftDelete(ent) {
+		preds += fmt.Sprintf(", runtime.IsNull(%q)", dsl.SoftDeleteColumn)
+	}
+	fmt.Fprintf(buf, "    preds := []runtime.Predicate{%s}\n", preds)
+	version, versioned := versionField(ent)
+	if versioned {
+		fmt.Fprintf(buf, "    assignments = append(assignments, %s)\n", versionBump(version))
+		fmt.Fprintf(buf, "    if input.%s != 0 {\n", exportName(version.Name))
+		fmt.Fprintf(buf, "        preds = append(preds, runtime.Compare(%q, runtime.OpEqual, input.%s))\n    }\n", fieldColumn(version), exportName(version.Name))
+	}
+	fmt.Fprintf(buf, "    sql, args, err := runtime.BuildUpdateSQL(runtime.UpdateSpec{\n")
+	fmt.Fprintf(buf, "        Table: %q,\n", pluralize(name))
+	fmt.Fprintf(buf, "        Assignments: assignments,\n")
+	fmt.Fprintf(buf, "        Predicates: preds,\n")
+	fmt.Fprintf(buf, "        Returning: %s,\n", quoteStringSlice(entityColumns(ent)))
+	fmt.Fprintf(buf, "    })\n")
+	fmt.Fprintf(buf, "    if err != nil {\n        return nil, err\n    }\n")
+	emitWriterGuard(buf, "nil, ")
+	fmt.Fprintf(buf, "    out := new(%s)\n", name)
+	fmt.Fprintf(buf, "    if err := writer.QueryRow(ctx, sql, args...).Scan(%s); err != nil {\n", strings.Join(scanArgsForVar(ent, "out"), ", "))
+	if versioned {
+		fmt.Fprintf(buf, "        if input.%s != 0 && errors.Is(err, pgx.ErrNoRows) {\n", exportName(version.Name))
+		emitStaleObjectError(buf, ent, "input", "            ")
+		fmt.Fprintf(buf, "        }\n")
+	}
+	fmt.Fprintf(buf, "        if errors.Is(err, pgx.ErrNoRows) {\n")
+	emitNotFoundError(buf, ent, "input", "            ")
+	fmt.Fprintf(buf, "        }\n")
+	fmt.Fprintf(buf, "        return nil, err\n    }\n")
+	fmt.Fprintf(buf, "    if c.cache != nil {\n        _ = c.cache.Set(ctx, makeCacheKey(%q, out.%s), out)\n    }\n", name, exportName(pk.Name))
+	fmt.Fprintf(buf, "    return out, nil\n}\n\n")
+}
+
+// sqlNullValueGoType returns the Go type of the value field of a database/sql Null type.
+func sqlNullValueGoType(accessor string) string {
+	switch accessor {
+	case "Bool":
+		return "bool"
+	case "Int16":
+		return "int16"
+	case "Int32":
+		return "int32"
+	case "Int64":
+		return "int64"
+	case "Float64":
+		return "float64"
+	case "Time":
+		return "time.Time"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+const updateClientTest = `package gen_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"example.com/app/orm/gen"
+	"github.com/deicod/erm/orm/pg"
+	"github.com/deicod/erm/orm/runtime"
+)
+
+func TestUpdateOne(t *testing.T) {
+	mock, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := gen.NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+	var changed [][]string
+	client.Posts().Use(func(next runtime.Mutator) runtime.Mutator {
+		return runtime.MutateFunc(func(ctx context.Context, m runtime.Mutation) (any, error) {
+			changed = append(changed, m.ChangedFields())
+			return next.Mutate(ctx, m)
+		})
+	})
+
+	mock.ExpectQuery("UPDATE posts SET title = $1, body = NULL, view_count = view_count + $2 WHERE id = $3 RETURNING id, title, body, view_count").
+		WithArgs("Hello", int32(1), "p1").
+		WillReturnRows(mock.NewRows([]string{"id", "title", "body", "view_count"}).AddRow("p1", "Hello", nil, int32(8)))
+	mock.ExpectQuery("UPDATE notes SET title = $1 WHERE id = $2 AND deleted_at IS NULL RETURNING id, title, deleted_at").
+		WithArgs("Todo", "n1").
+		WillReturnRows(mock.NewRows([]string{"id", "title", "deleted_at"}).AddRow("n1", "Todo", nil))
+
+	ctx := context.Background()
+	post, err := client.Posts().UpdateOneID("p1").SetTitle("Hello").ClearBody().AddViewCount(1).Save(ctx)
+	if err != nil {
+		t.Fatalf("update post: %v", err)
+	}
+	if post.ViewCount != 8 || post.Body != nil {
+		t.Fatalf("unexpected post: %+v", post)
+	}
+	if want := [][]string{{"title", "body", "view_count"}}; !reflect.DeepEqual(changed, want) {
+		t.Fatalf("changed fields = %v, want %v", changed, want)
+	}
+	if _, err := client.Notes().UpdateOneID("n1").SetFrom(&gen.Note{Title: "Todo"}, "title").Save(ctx); err != nil {
+		t.Fatalf("update note: %v", err)
+	}
+	if _, err := client.Posts().UpdateOneID("p1").SetFrom(&gen.Post{}, "id").Save(ctx); err == nil {
+		t.Fatalf("expected error for non-updatable field")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+`
+
+func TestWriteORMArtifacts_UpdateOne(t *testing.T) {
+	entities := []Entity{
+		{
+			Name:   "Post",
+			Fields: []dsl.Field{dsl.String("id").Primary(), dsl.String("title"), dsl.Text("body").Optional(), dsl.Integer("view_count")},
+		},
+		{
+			Name:        "Note",
+			Fields:      []dsl.Field{dsl.String("id").Primary(), dsl.String("title")},
+			Annotations: []dsl.Annotation{dsl.SoftDelete()},
+		},
+		{
+			Name:   "Setting",
+			Fields: []dsl.Field{dsl.String("id").Primary(), dsl.JSONB("value").Optional()},
+		},
+	}
+	for i := range entities {
+		applySoftDelete(&entities[i])
+		ensureDefaultQuery(&entities[i])
+	}
+
+	root := t.TempDir()
+	if err := writeORMArtifacts(root, entities); err != nil {
+		t.Fatalf("writeORMArtifacts: %v", err)
+	}
+	client, err := os.ReadFile(filepath.Join(root, "orm", "gen", "client_gen.go"))
+	if err != nil {
+		t.Fatalf("read client: %v", err)
+	}
+	mustContain(t, string(client), "func (c *PostClient) UpdateOneID(id string) *PostUpdateOne {")
+	mustContain(t, string(client), "func (u *PostUpdateOne) AddViewCount(delta int32) *PostUpdateOne {")
+	mustContain(t, string(client), "func (u *PostUpdateOne) ClearBody() *PostUpdateOne {")
+	mustContain(t, string(client), "func (u *SettingUpdateOne) SetValue(v json.RawMessage) *SettingUpdateOne { u.input.Value = v")
+
+	runGeneratedORMTest(t, root, "update_test.go", updateClientTest)
+}
@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+func emitOnConflictHelper(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "// OnConflict builds the conflict clause for Upsert and BulkUpsert. Targets for unique fields and\n")
+	fmt.Fprintf(buf, "// indexes are generated in the entity packages (for example user.ConflictEmail). Without an\n")
+	fmt.Fprintf(buf, "// action option the upsert overwrites every updatable column with the proposed values.\n")
+	fmt.Fprintf(buf, "func OnConflict(target runtime.ConflictTarget, opts ...runtime.ConflictOption) runtime.OnConflict {\n")
+	fmt.Fprintf(buf, "    return runtime.NewOnConflict(target, opts...)\n}\n\n")
+}
+
+func emitUpsertMethods(buf *bytes.Buffer, ent Entity) {
+	name := ent.Name
+	defaults := quoteStringSlice(updatableColumns(ent))
+
+	fmt.Fprintf(buf, "// Upsert inserts input or, when it conflicts with a stored row on conflict.Target, applies the\n")
+	fmt.Fprintf(buf, "// conflict action. It returns the inserted or updated row, or nil when the row was skipped by DO\n")
+	fmt.Fprintf(buf, "// NOTHING or the update WHERE clause. Hooks and policies see a create mutation.\n")
+	fmt.Fprintf(buf, "func (c *%sClient) Upsert(ctx context.Context, input *%s, conflict runtime.OnConflict) (*%s, error) {\n", name, name, name)
+	fmt.Fprintf(buf, "    if input == nil {\n        return nil, errors.New(\"input cannot be nil\")\n    }\n")
+	fmt.Fprintf(buf, "    conflict = conflict.WithDefaultUpdates(%s...)\n", defaults)
+	emitConflictVersion(buf, ent)
+	fmt.Fprintf(buf, "    m := &%sMutation{op: runtime.MutationCreate, db: c.db, Input: input, conflict: &conflict}\n", name)
+	fmt.Fprintf(buf, "    return runtime.CastResult[*%s](c.mutate(ctx, m, func(ctx context.Context, m *%sMutation) (any, error) {\n", name, name)
+	fmt.Fprintf(buf, "        rows, err := c.insertRows(ctx, []*%s{m.Input}, m.conflict)\n", name)
+	fmt.Fprintf(buf, "        if err != nil || len(rows) == 0 {\n            return (*%s)(nil), err\n        }\n", name)
+	fmt.Fprintf(buf, "        return rows[0], nil\n    }))\n}\n\n")
+
+	fmt.Fprintf(buf, "// BulkUpsert upserts inputs in one statement. Rows skipped by the conflict clause are missing\n")
+	fmt.Fprintf(buf, "// from the result. Two inputs must not conflict with each other.\n")
+	fmt.Fprintf(buf, "func (c *%sClient) BulkUpsert(ctx context.Context, inputs []*%s, conflict runtime.OnConflict) ([]*%s, error) {\n", name, name, name)
+	fmt.Fprintf(buf, "    if len(inputs) == 0 {\n        return []*%s{}, nil\n    }\n", name)
+	fmt.Fprintf(buf, "    conflict = conflict.WithDefaultUpdates(%s...)\n", defaults)
+	emitConflictVersion(buf, ent)
+	fmt.Fprintf(buf, "    m := &%sMutation{op: runtime.MutationCreate, bulk: true, db: c.db, Inputs: inputs, conflict: &conflict}\n", name)
+	fmt.Fprintf(buf, "    return runtime.CastResult[[]*%s](c.mutate(ctx, m, func(ctx context.Context, m *%sMutation) (any, error) {\n", name, name)
+	fmt.Fprintf(buf, "        return c.insertRows(ctx, m.Inputs, m.conflict)\n    }))\n}\n\n")
+}
+
+// emitConflictVersion makes DO UPDATE increment the version of versioned entities.
+func emitConflictVersion(buf *bytes.Buffer, ent Entity) {
+	if version, ok := versionField(ent); ok {
+		fmt.Fprintf(buf, "    conflict.VersionColumn = %q\n", fieldColumn(version))
+	}
+}
+
+// conflictTargets returns the generated conflict targets of ent, keyed by their exported suffix:
+// one per unique field and one per unique index. Soft-delete entities arbitrate on the partial
+// indexes that only cover live rows.
+func conflictTargets(ent Entity) ([]string, map[string]string) {
+	var names []string
+	targets := make(map[string]string)
+	add := func(name string, columns []string, where string) {
+		if _, ok := targets[name]; ok || name == "" {
+			return
+		}
+		names = append(names, name)
+		if where == "" {
+			targets[name] = fmt.Sprintf("runtime.ConflictColumns(%s)", quoteArgs(columns))
+			return
+		}
+		targets[name] = fmt.Sprintf("runtime.ConflictTarget{Columns: %s, Where: %q}", quoteStringSlice(columns), where)
+	}
+	live := ""
+	if isSoftDelete(ent) {
+		live = softDeleteScope("")
+	}
+	for _, field := range ent.Fields {
+		switch {
+		case field.IsPrimary:
+			add(exportName(field.Name), []string{fieldColumn(field)}, "")
+		case field.IsUnique:
+			add(exportName(field.Name), []string{fieldColumn(field)}, live)
+		}
+	}
+	for _, idx := range ent.Indexes {
+		if !idx.IsUnique || len(idx.Columns) == 0 {
+			continue
+		}
+		where := idx.Where
+		switch {
+		case live == "":
+		case where == "":
+			where = live
+		case !strings.Contains(where, live):
+			where = fmt.Sprintf("(%s) AND %s", where, live)
+		}
+		add(exportName(idx.Name), idx.Columns, where)
+	}
+	return names, targets
+}
+
+func emitConflictTargets(buf *bytes.Buffer, ent Entity) {
+	names, targets := conflictTargets(ent)
+	if len(names) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "// Conflict targets for %sClient.Upsert and BulkUpsert.\n", ent.Name)
+	fmt.Fprintf(buf, "var (\n")
+	for _, name := range names {
+		fmt.Fprintf(buf, "    Conflict%s = %s\n", name, targets[name])
+	}
+	fmt.Fprintf(buf, ")\n\n")
+}
+
+// emitConflictOwnership restricts DO UPDATE to rows the subject owns, so an upsert cannot take
+// over a row that an ownership rule would have kept the viewer from updating.
+func emitConflictOwnership(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "    if m.conflict != nil && !m.conflict.DoNothing {\n")
+	fmt.Fprintf(buf, "        m.conflict.Where = append(m.conflict.Where, runtime.Compare(column, runtime.OpEqual, subject))\n    }\n")
+}
@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+const upsertClientTest = `package gen_test
+
+import (
+	"context"
+	"testing"
+
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"example.com/app/orm/gen"
+	"example.com/app/orm/gen/user"
+	"github.com/deicod/erm/orm/pg"
+	"github.com/deicod/erm/orm/runtime"
+)
+
+func TestUpsert(t *testing.T) {
+	mock, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := gen.NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+	columns := []string{"id", "email", "name", "visits"}
+
+	mock.ExpectQuery("INSERT INTO users (id, email, name, visits) VALUES ($1, $2, $3, $4) ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name, visits = EXCLUDED.visits RETURNING id, email, name, visits").
+		WithArgs("u1", "ada@example.com", "Ada", int32(1)).
+		WillReturnRows(mock.NewRows(columns).AddRow("u0", "ada@example.com", "Ada", int32(1)))
+	mock.ExpectQuery("INSERT INTO users (id, email, name, visits) VALUES ($1, $2, $3, $4), ($5, $6, $7, $8) ON CONFLICT (email) DO UPDATE SET visits = users.visits + EXCLUDED.visits WHERE users.name <> $9 RETURNING id, email, name, visits").
+		WithArgs("u1", "ada@example.com", "Ada", int32(1), "u2", "bob@example.com", "Bob", int32(2), "banned").
+		WillReturnRows(mock.NewRows(columns).AddRow("u0", "ada@example.com", "Ada", int32(2)))
+	mock.ExpectQuery("INSERT INTO users (id, email, name, visits) VALUES ($1, $2, $3, $4) ON CONFLICT ON CONSTRAINT users_pkey DO NOTHING RETURNING id, email, name, visits").
+		WithArgs("u0", "ada@example.com", "Ada", int32(1)).
+		WillReturnRows(mock.NewRows(columns))
+
+	ctx := context.Background()
+	stored, err := client.Users().Upsert(ctx, &gen.User{ID: "u1", Email: "ada@example.com", Name: "Ada", Visits: 1}, gen.OnConflict(user.ConflictEmail))
+	if err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	if stored == nil || stored.ID != "u0" {
+		t.Fatalf("expected stored row u0, got %+v", stored)
+	}
+	rows, err := client.Users().BulkUpsert(ctx, []*gen.User{
+		{ID: "u1", Email: "ada@example.com", Name: "Ada", Visits: 1},
+		{ID: "u2", Email: "bob@example.com", Name: "Bob", Visits: 2},
+	}, gen.OnConflict(user.ConflictEmail, runtime.UpdateIncrement(user.FieldVisits), runtime.UpdateWhere(user.NameNotEq("banned"))))
+	if err != nil {
+		t.Fatalf("bulk upsert: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Visits != 2 {
+		t.Fatalf("unexpected bulk rows: %+v", rows)
+	}
+	skipped, err := client.Users().Upsert(ctx, &gen.User{ID: "u0", Email: "ada@example.com", Name: "Ada", Visits: 1}, gen.OnConflict(runtime.ConflictConstraint("users_pkey"), runtime.DoNothing()))
+	if err != nil {
+		t.Fatalf("upsert do nothing: %v", err)
+	}
+	if skipped != nil {
+		t.Fatalf("expected skipped row, got %+v", skipped)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+`
+
+func TestWriteORMArtifacts_Upsert(t *testing.T) {
+	entities := []Entity{
+		{
+			Name:   "User",
+			Fields: []dsl.Field{dsl.String("id").Primary(), dsl.String("email").Unique(), dsl.String("name"), dsl.Integer("visits")},
+		},
+		{
+			Name:        "Account",
+			Fields:      []dsl.Field{dsl.String("id").Primary(), dsl.String("org_id"), dsl.String("handle").Unique()},
+			Indexes:     []dsl.Index{dsl.Idx("accounts_org_handle").On("org_id", "handle").Unique()},
+			Annotations: []dsl.Annotation{dsl.SoftDelete()},
+		},
+	}
+	for i := range entities {
+		applySoftDelete(&entities[i])
+		ensureDefaultQuery(&entities[i])
+	}
+
+	root := t.TempDir()
+	if err := writeORMArtifacts(root, entities); err != nil {
+		t.Fatalf("writeORMArtifacts: %v", err)
+	}
+	client, err := os.ReadFile(filepath.Join(root, "orm", "gen", "client_gen.go"))
+	if err != nil {
+		t.Fatalf("read client: %v", err)
+	}
+	mustContain(t, string(client), "func (c *UserClient) Upsert(ctx context.Context, input *User, conflict runtime.OnConflict) (*User, error) {")
+	mustContain(t, string(client), "func (c *UserClient) BulkUpsert(ctx context.Context, inputs []*User, conflict runtime.OnConflict) ([]*User, error) {")
+	accounts, err := os.ReadFile(filepath.Join(root, "orm", "gen", "account", "where_gen.go"))
+	if err != nil {
+		t.Fatalf("read account predicates: %v", err)
+	}
+	mustContain(t, string(accounts), "ConflictID")
+	mustContain(t, string(accounts), `ConflictHandle     = runtime.ConflictTarget{Columns: []string{"handle"}, Where: "deleted_at IS NULL"}`)
+	mustContain(t, string(accounts), `ConflictAccountsOrgHandle = runtime.ConflictTarget{Columns: []string{"org_id", "handle"}, Where: "deleted_at IS NULL"}`)
+
+	runGeneratedORMTest(t, root, "upsert_test.go", upsertClientTest)
+}
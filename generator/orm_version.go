@@ -0,0 +1,42 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+// isVersionField reports whether field is the optimistic lock declared with dsl.Field.Version.
+func isVersionField(field dsl.Field) bool {
+	version, _ := field.Annotations["version"].(bool)
+	return version
+}
+
+// versionField returns the optimistic lock field of ent, if it has one.
+func versionField(ent Entity) (dsl.Field, bool) {
+	for _, field := range ent.Fields {
+		if isVersionField(field) {
+			return field, true
+		}
+	}
+	return dsl.Field{}, false
+}
+
+// emitStaleObjectError emits the error returned when no row matched the expected version of
+// holder.
+func emitStaleObjectError(buf *bytes.Buffer, ent Entity, holder, indent string) {
+	version, _ := versionField(ent)
+	fmt.Fprintf(buf, "%sreturn nil, &runtime.StaleObjectError{Entity: %q, ID: %s.%s, Version: int64(%s.%s)}\n",
+		indent, ent.Name, holder, exportName(primaryField(ent).Name), holder, exportName(version.Name))
+}
+
+// emitNotFoundError emits the error returned when no row has the primary key of holder.
+func emitNotFoundError(buf *bytes.Buffer, ent Entity, holder, indent string) {
+	fmt.Fprintf(buf, "%sreturn nil, &runtime.NotFoundError{Entity: %q, ID: %s.%s}\n", indent, ent.Name, holder, exportName(primaryField(ent).Name))
+}
+
+// versionBump renders the assignment that increments the version column of ent.
+func versionBump(field dsl.Field) string {
+	return fmt.Sprintf("runtime.Assignment{Column: %q, Op: runtime.AssignAdd, Value: %s(1)}", fieldColumn(field), baseGoType(field))
+}
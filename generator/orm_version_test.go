@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+const versionClientTest = `package gen_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"example.com/app/orm/gen"
+	"github.com/deicod/erm/orm/pg"
+	"github.com/deicod/erm/orm/runtime"
+)
+
+func TestOptimisticLock(t *testing.T) {
+	mock, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := gen.NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+	ctx := context.Background()
+
+	mock.ExpectQuery("UPDATE posts SET title = $1, version = version + 1 WHERE id = $2 AND version = $3 RETURNING id, title, version").
+		WithArgs("Hello", "p1", int32(3)).
+		WillReturnRows(mock.NewRows([]string{"id", "title", "version"}).AddRow("p1", "Hello", int32(4)))
+	post, err := client.Posts().Update(ctx, &gen.Post{ID: "p1", Title: "Hello", Version: 3})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if post.Version != 4 {
+		t.Fatalf("version = %d, want 4", post.Version)
+	}
+
+	mock.ExpectQuery("UPDATE posts SET title = $1, version = version + 1 WHERE id = $2 AND version = $3 RETURNING id, title, version").
+		WithArgs("Hello", "p1", int32(3)).
+		WillReturnRows(mock.NewRows([]string{"id", "title", "version"}))
+	_, err = client.Posts().Update(ctx, &gen.Post{ID: "p1", Title: "Hello", Version: 3})
+	var stale *runtime.StaleObjectError
+	if !errors.Is(err, runtime.ErrStaleObject) || !errors.As(err, &stale) || stale.ID != "p1" || stale.Version != 3 {
+		t.Fatalf("expected stale object error, got %v", err)
+	}
+
+	mock.ExpectQuery("UPDATE posts SET title = $1, version = version + $2 WHERE id = $3 AND version = $4 RETURNING id, title, version").
+		WithArgs("Draft", int32(1), "p1", int32(4)).
+		WillReturnRows(mock.NewRows([]string{"id", "title", "version"}))
+	if _, err := client.Posts().UpdateOneID("p1").SetTitle("Draft").ExpectVersion(4).Save(ctx); !errors.Is(err, runtime.ErrStaleObject) {
+		t.Fatalf("expected stale object error from UpdateOneID, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("WITH data(id, title, erm_version) AS (VALUES ($1, $2, $3), ($4, $5, $6)) UPDATE posts AS t SET title = data.title, version = t.version + 1 FROM data WHERE t.id = data.id AND t.version = data.erm_version RETURNING id, title, version").
+		WithArgs("p1", "A", int32(4), "p2", "B", int32(1)).
+		WillReturnRows(mock.NewRows([]string{"id", "title", "version"}).AddRow("p1", "A", int32(5)))
+	mock.ExpectRollback()
+	_, err = client.Posts().BulkUpdate(ctx, []*gen.Post{{ID: "p1", Title: "A", Version: 4}, {ID: "p2", Title: "B", Version: 1}})
+	if !errors.As(err, &stale) || stale.ID != "p2" {
+		t.Fatalf("expected stale object error for p2, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+`
+
+func TestWriteORMArtifacts_OptimisticLock(t *testing.T) {
+	entities := []Entity{
+		{
+			Name:   "Post",
+			Fields: []dsl.Field{dsl.String("id").Primary(), dsl.String("title"), dsl.Integer("version").Version()},
+		},
+	}
+	for i := range entities {
+		applySoftDelete(&entities[i])
+		ensureDefaultQuery(&entities[i])
+	}
+
+	root := t.TempDir()
+	if err := writeORMArtifacts(root, entities); err != nil {
+		t.Fatalf("writeORMArtifacts: %v", err)
+	}
+	client, err := os.ReadFile(filepath.Join(root, "orm", "gen", "client_gen.go"))
+	if err != nil {
+		t.Fatalf("read client: %v", err)
+	}
+	mustContain(t, string(client), "func (u *PostUpdateOne) ExpectVersion(v int32) *PostUpdateOne {")
+	mustContain(t, string(client), "return nil, &runtime.StaleObjectError{Entity: \"Post\", ID: input.ID, Version: int64(input.Version)}")
+
+	runGeneratedORMTest(t, root, "version_test.go", versionClientTest)
+}
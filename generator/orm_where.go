@@ -0,0 +1,170 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+func emitUpdateWhereBuilder(buf *bytes.Buffer, ent Entity, scopeEdges bool) {
+	fields := partialUpdateFields(ent)
+	if len(fields) == 0 {
+		return
+	}
+	name := ent.Name
+	builder := name + "Update"
+
+	fmt.Fprintf(buf, "// %s updates every %s matching its predicates in a single UPDATE statement. Only the\n", builder, name)
+	fmt.Fprintf(buf, "// columns its setters touch are written.\n")
+	fmt.Fprintf(buf, "type %s struct {\n", builder)
+	fmt.Fprintf(buf, "    client *%sClient\n", name)
+	fmt.Fprintf(buf, "    predicates []runtime.Predicate\n")
+	fmt.Fprintf(buf, "    input *%s\n", name)
+	fmt.Fprintf(buf, "    fields []string\n")
+	fmt.Fprintf(buf, "    ops map[string]runtime.AssignOp\n")
+	fmt.Fprintf(buf, "}\n\n")
+
+	fmt.Fprintf(buf, "// UpdateWhere starts an update of the %s rows matching preds. Without predicates every row\n", name)
+	fmt.Fprintf(buf, "// is updated.\n")
+	fmt.Fprintf(buf, "func (c *%sClient) UpdateWhere(preds ...runtime.Predicate) *%s {\n", name, builder)
+	fmt.Fprintf(buf, "    return &%s{client: c, predicates: preds, input: new(%s), ops: make(map[string]runtime.AssignOp)}\n}\n\n", builder, name)
+
+	fmt.Fprintf(buf, "// Where narrows the rows to update.\n")
+	fmt.Fprintf(buf, "func (u *%s) Where(preds ...runtime.Predicate) *%s {\n", builder, builder)
+	fmt.Fprintf(buf, "    u.predicates = append(u.predicates, preds...)\n    return u\n}\n\n")
+
+	emitUpdateSetters(buf, builder, fields)
+
+	fmt.Fprintf(buf, "// Save writes the touched columns of every matching row and returns the updated rows.\n")
+	fmt.Fprintf(buf, "func (u *%s) Save(ctx context.Context) ([]*%s, error) {\n", builder, name)
+	emitUpdateNowTouches(buf, fields)
+	fmt.Fprintf(buf, "    m := &%sMutation{op: runtime.MutationUpdate, bulk: true, where: true, db: u.client.db, Input: u.input, fields: append([]string{}, u.fields...), Predicates: append([]runtime.Predicate(nil), u.predicates...)}\n", name)
+	fmt.Fprintf(buf, "    return runtime.CastResult[[]*%s](u.client.mutate(ctx, m, func(ctx context.Context, m *%sMutation) (any, error) {\n", name, name)
+	fmt.Fprintf(buf, "        return u.client.updateWhere(ctx, m.Input, m.fields, u.ops, m.Predicates)\n    }))\n}\n\n")
+
+	fmt.Fprintf(buf, "// Exec is Save returning the number of updated rows.\n")
+	fmt.Fprintf(buf, "func (u *%s) Exec(ctx context.Context) (int64, error) {\n", builder)
+	fmt.Fprintf(buf, "    rows, err := u.Save(ctx)\n    return int64(len(rows)), err\n}\n\n")
+
+	emitUpdateWhereMethod(buf, ent, scopeEdges)
+}
+
+func emitUpdateWhereMethod(buf *bytes.Buffer, ent Entity, scopeEdges bool) {
+	name := ent.Name
+	pk := exportName(primaryField(ent).Name)
+	fmt.Fprintf(buf, "func (c *%sClient) updateWhere(ctx context.Context, input *%s, fields []string, ops map[string]runtime.AssignOp, preds []runtime.Predicate) ([]*%s, error) {\n", name, name, name)
+	fmt.Fprintf(buf, "    if input == nil {\n        return nil, errors.New(\"input cannot be nil\")\n    }\n")
+	fmt.Fprintf(buf, "    assignments, keys, err := c.updateAssignments(input, fields, ops)\n")
+	fmt.Fprintf(buf, "    if err != nil {\n        return nil, err\n    }\n")
+	fmt.Fprintf(buf, "    if len(assignments) == 0 {\n        return []*%s{}, nil\n    }\n", name)
+	fmt.Fprintf(buf, "    if err := ValidationRegistry.ValidateFields(ctx, %q, validation.OpUpdate, %sValidationRecord(input), input, keys); err != nil {\n        return nil, err\n    }\n", name, strings.ToLower(name))
+	if isSoftDelete(ent) {
+		fmt.Fprintf(buf, "    preds = append(append([]runtime.Predicate(nil), preds...), runtime.IsNull(%q))\n", dsl.SoftDeleteColumn)
+	}
+	if scopeEdges {
+		emitScopeEdges(buf, "preds", "nil, ")
+	}
+	if version, ok := versionField(ent); ok {
+		fmt.Fprintf(buf, "    assignments = append(assignments, %s)\n", versionBump(version))
+	}
+	fmt.Fprintf(buf, "    sql, args, err := runtime.BuildUpdateSQL(runtime.UpdateSpec{\n")
+	fmt.Fprintf(buf, "        Table: %q,\n", pluralize(name))
+	fmt.Fprintf(buf, "        Assignments: assignments,\n")
+	fmt.Fprintf(buf, "        Predicates: preds,\n")
+	fmt.Fprintf(buf, "        Returning: %s,\n", quoteStringSlice(entityColumns(ent)))
+	fmt.Fprintf(buf, "    })\n")
+	fmt.Fprintf(buf, "    if err != nil {\n        return nil, err\n    }\n")
+	emitWriterGuard(buf, "nil, ")
+	fmt.Fprintf(buf, "    rows, err := writer.Query(ctx, sql, args...)\n")
+	fmt.Fprintf(buf, "    if err != nil {\n        return nil, err\n    }\n")
+	fmt.Fprintf(buf, "    defer rows.Close()\n")
+	fmt.Fprintf(buf, "    updated := []*%s{}\n", name)
+	fmt.Fprintf(buf, "    for rows.Next() {\n")
+	fmt.Fprintf(buf, "        item := new(%s)\n", name)
+	fmt.Fprintf(buf, "        if err := rows.Scan(%s); err != nil {\n            return nil, err\n        }\n", scanArgs(ent))
+	fmt.Fprintf(buf, "        updated = append(updated, item)\n")
+	fmt.Fprintf(buf, "        if c.cache != nil {\n            _ = c.cache.Set(ctx, makeCacheKey(%q, item.%s), item)\n        }\n", name, pk)
+	fmt.Fprintf(buf, "    }\n")
+	fmt.Fprintf(buf, "    if err := rows.Err(); err != nil {\n        return nil, err\n    }\n")
+	fmt.Fprintf(buf, "    return updated, nil\n}\n\n")
+}
+
+func emitDeleteWhereBuilder(buf *bytes.Buffer, ent Entity, scopeEdges bool) {
+	name := ent.Name
+	builder := name + "Delete"
+	softDelete := isSoftDelete(ent)
+	pkField := primaryField(ent)
+
+	if softDelete {
+		fmt.Fprintf(buf, "// %s soft-deletes every live %s matching its predicates in a single statement, or removes\n", builder, name)
+		fmt.Fprintf(buf, "// them after Hard.\n")
+	} else {
+		fmt.Fprintf(buf, "// %s deletes every %s matching its predicates in a single DELETE statement.\n", builder, name)
+	}
+	fmt.Fprintf(buf, "type %s struct {\n", builder)
+	fmt.Fprintf(buf, "    client *%sClient\n", name)
+	fmt.Fprintf(buf, "    predicates []runtime.Predicate\n")
+	if softDelete {
+		fmt.Fprintf(buf, "    hard bool\n")
+	}
+	fmt.Fprintf(buf, "}\n\n")
+
+	fmt.Fprintf(buf, "// DeleteWhere starts a delete of the %s rows matching preds. Without predicates every row is\n", name)
+	fmt.Fprintf(buf, "// deleted.\n")
+	fmt.Fprintf(buf, "func (c *%sClient) DeleteWhere(preds ...runtime.Predicate) *%s {\n", name, builder)
+	fmt.Fprintf(buf, "    return &%s{client: c, predicates: preds}\n}\n\n", builder)
+
+	fmt.Fprintf(buf, "// Where narrows the rows to delete.\n")
+	fmt.Fprintf(buf, "func (d *%s) Where(preds ...runtime.Predicate) *%s {\n", builder, builder)
+	fmt.Fprintf(buf, "    d.predicates = append(d.predicates, preds...)\n    return d\n}\n\n")
+
+	hard := ""
+	if softDelete {
+		fmt.Fprintf(buf, "// Hard removes the matching rows permanently, whether or not they were soft-deleted.\n")
+		fmt.Fprintf(buf, "func (d *%s) Hard() *%s {\n    d.hard = true\n    return d\n}\n\n", builder, builder)
+		hard = ", hard: d.hard"
+	}
+
+	fmt.Fprintf(buf, "// Exec runs the delete and returns the number of affected rows.\n")
+	fmt.Fprintf(buf, "func (d *%s) Exec(ctx context.Context) (int64, error) {\n", builder)
+	fmt.Fprintf(buf, "    m := &%sMutation{op: runtime.MutationDelete, bulk: true, where: true%s, db: d.client.db, Predicates: append([]runtime.Predicate(nil), d.predicates...)}\n", name, hard)
+	fmt.Fprintf(buf, "    return runtime.CastResult[int64](d.client.mutate(ctx, m, func(ctx context.Context, m *%sMutation) (any, error) {\n", name)
+	if softDelete {
+		fmt.Fprintf(buf, "        return d.client.deleteWhere(ctx, m.Predicates, m.hard)\n    }))\n}\n\n")
+	} else {
+		fmt.Fprintf(buf, "        return d.client.deleteWhere(ctx, m.Predicates)\n    }))\n}\n\n")
+	}
+
+	if softDelete {
+		fmt.Fprintf(buf, "func (c *%sClient) deleteWhere(ctx context.Context, preds []runtime.Predicate, hard bool) (int64, error) {\n", name)
+	} else {
+		fmt.Fprintf(buf, "func (c *%sClient) deleteWhere(ctx context.Context, preds []runtime.Predicate) (int64, error) {\n", name)
+	}
+	if scopeEdges {
+		emitScopeEdges(buf, "preds", "0, ")
+	}
+	fmt.Fprintf(buf, "    spec := runtime.DeleteSpec{\n")
+	fmt.Fprintf(buf, "        Table: %q,\n", pluralize(name))
+	fmt.Fprintf(buf, "        Predicates: preds,\n")
+	fmt.Fprintf(buf, "        Returning: []string{%q},\n", fieldColumn(pkField))
+	fmt.Fprintf(buf, "    }\n")
+	if softDelete {
+		fmt.Fprintf(buf, "    if !hard {\n        spec.SoftDeleteColumn = %q\n    }\n", dsl.SoftDeleteColumn)
+	}
+	fmt.Fprintf(buf, "    sql, args, err := runtime.BuildDeleteSQL(spec)\n")
+	fmt.Fprintf(buf, "    if err != nil {\n        return 0, err\n    }\n")
+	emitWriterGuard(buf, "0, ")
+	fmt.Fprintf(buf, "    rows, err := writer.Query(ctx, sql, args...)\n")
+	fmt.Fprintf(buf, "    if err != nil {\n        return 0, err\n    }\n")
+	fmt.Fprintf(buf, "    defer rows.Close()\n")
+	fmt.Fprintf(buf, "    var affected int64\n")
+	fmt.Fprintf(buf, "    for rows.Next() {\n")
+	fmt.Fprintf(buf, "        var id string\n")
+	fmt.Fprintf(buf, "        if err := rows.Scan(&id); err != nil {\n            return 0, err\n        }\n")
+	fmt.Fprintf(buf, "        affected++\n")
+	fmt.Fprintf(buf, "        if c.cache != nil {\n            _ = c.cache.Delete(ctx, makeCacheKey(%q, id))\n        }\n", name)
+	fmt.Fprintf(buf, "    }\n")
+	fmt.Fprintf(buf, "    return affected, rows.Err()\n}\n\n")
+}
@@ -0,0 +1,158 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+const whereMutationClientTest = `package gen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"example.com/app/orm/gen/note"
+	"example.com/app/orm/gen/post"
+	"github.com/deicod/erm/orm/pg"
+	"github.com/deicod/erm/orm/runtime"
+	"github.com/deicod/erm/orm/runtime/privacy"
+)
+
+type mapStore map[string]any
+
+func (s mapStore) Get(_ context.Context, key string) (any, bool, error) {
+	value, ok := s[key]
+	return value, ok, nil
+}
+
+func (s mapStore) Set(_ context.Context, key string, value any) error {
+	s[key] = value
+	return nil
+}
+
+func (s mapStore) Delete(_ context.Context, key string) error {
+	delete(s, key)
+	return nil
+}
+
+func TestWhereMutations(t *testing.T) {
+	mock, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+	store := mapStore{}
+	client.UseCache(store)
+	var where []bool
+	client.Posts().Use(func(next runtime.Mutator) runtime.Mutator {
+		return runtime.MutateFunc(func(ctx context.Context, m runtime.Mutation) (any, error) {
+			where = append(where, m.(*PostMutation).IsWhere())
+			return next.Mutate(ctx, m)
+		})
+	})
+
+	alice := privacy.WithViewer(context.Background(), privacy.Viewer{Subject: "alice"})
+	cutoff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	postColumns := []string{"id", "author_id", "title", "archived", "created_at"}
+	mock.ExpectQuery("UPDATE posts SET archived = $1 WHERE created_at < $2 AND archived = $3 AND author_id = $4 RETURNING id, author_id, title, archived, created_at").
+		WithArgs(true, cutoff, false, "alice").
+		WillReturnRows(mock.NewRows(postColumns).
+			AddRow("p1", "alice", "One", true, cutoff).
+			AddRow("p2", "alice", "Two", true, cutoff))
+	mock.ExpectQuery("DELETE FROM posts WHERE archived = $1 AND author_id = $2 RETURNING id").
+		WithArgs(true, "alice").
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow("p1"))
+	mock.ExpectQuery("UPDATE notes SET title = $1 WHERE title = $2 AND deleted_at IS NULL RETURNING id, title, deleted_at").
+		WithArgs("done", "todo").
+		WillReturnRows(mock.NewRows([]string{"id", "title", "deleted_at"}).AddRow("n1", "done", nil))
+	mock.ExpectQuery("UPDATE notes SET deleted_at = now() WHERE title LIKE $1 AND deleted_at IS NULL RETURNING id").
+		WithArgs("tmp%").
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow("n1").AddRow("n2"))
+	mock.ExpectQuery("DELETE FROM notes WHERE title = $1 RETURNING id").
+		WithArgs("done").
+		WillReturnRows(mock.NewRows([]string{"id"}))
+
+	archived, err := client.Posts().UpdateWhere(post.CreatedAtLT(cutoff)).Where(post.ArchivedEq(false)).SetArchived(true).Save(alice)
+	if err != nil {
+		t.Fatalf("update where: %v", err)
+	}
+	if len(archived) != 2 || !archived[1].Archived {
+		t.Fatalf("unexpected updated rows: %+v", archived)
+	}
+	if _, ok := store[makeCacheKey("Post", "p2")]; !ok {
+		t.Fatalf("expected updated rows to be cached")
+	}
+	deleted, err := client.Posts().DeleteWhere(post.ArchivedEq(true)).Exec(alice)
+	if err != nil {
+		t.Fatalf("delete where: %v", err)
+	}
+	if deleted != 1 {
+		t.Fatalf("expected 1 deleted row, got %d", deleted)
+	}
+	if _, ok := store[makeCacheKey("Post", "p1")]; ok {
+		t.Fatalf("expected deleted row to leave the cache")
+	}
+	if len(where) != 2 || !where[0] || !where[1] {
+		t.Fatalf("expected hooks to see where mutations, got %v", where)
+	}
+
+	ctx := context.Background()
+	if n, err := client.Notes().UpdateWhere(note.TitleEq("todo")).SetTitle("done").Exec(ctx); err != nil || n != 1 {
+		t.Fatalf("note update where: %d, %v", n, err)
+	}
+	if n, err := client.Notes().DeleteWhere(note.TitleHasPrefix("tmp")).Exec(ctx); err != nil || n != 2 {
+		t.Fatalf("note soft delete where: %d, %v", n, err)
+	}
+	if n, err := client.Notes().DeleteWhere(note.TitleEq("done")).Hard().Exec(ctx); err != nil || n != 0 {
+		t.Fatalf("note hard delete where: %d, %v", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+`
+
+func TestWriteORMArtifacts_WhereMutations(t *testing.T) {
+	entities := []Entity{
+		{
+			Name: "Post",
+			Fields: []dsl.Field{
+				dsl.String("id").Primary(),
+				dsl.String("author_id"),
+				dsl.String("title"),
+				dsl.Boolean("archived"),
+				dsl.TimestampTZ("created_at"),
+			},
+			Policy: dsl.NewPolicy().Mutation(dsl.FilterOwner("author_id")),
+		},
+		{
+			Name:        "Note",
+			Fields:      []dsl.Field{dsl.String("id").Primary(), dsl.String("title")},
+			Annotations: []dsl.Annotation{dsl.SoftDelete()},
+		},
+	}
+	for i := range entities {
+		applySoftDelete(&entities[i])
+		ensureDefaultQuery(&entities[i])
+	}
+
+	root := t.TempDir()
+	if err := writeORMArtifacts(root, entities); err != nil {
+		t.Fatalf("writeORMArtifacts: %v", err)
+	}
+	client, err := os.ReadFile(filepath.Join(root, "orm", "gen", "client_gen.go"))
+	if err != nil {
+		t.Fatalf("read client: %v", err)
+	}
+	mustContain(t, string(client), "func (c *PostClient) UpdateWhere(preds ...runtime.Predicate) *PostUpdate {")
+	mustContain(t, string(client), "func (c *PostClient) DeleteWhere(preds ...runtime.Predicate) *PostDelete {")
+	mustContain(t, string(client), "func (d *NoteDelete) Hard() *NoteDelete {")
+
+	runGeneratedORMTest(t, root, "where_mutation_test.go", whereMutationClientTest)
+}
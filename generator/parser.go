@@ -56,6 +56,9 @@ type Entity struct {
 	Query         dsl.QuerySpec
 	Annotations   []dsl.Annotation
 	Authorization dsl.AuthRules
+	Policy        dsl.Policy
+	Mixins        []dsl.Mixin
+	Hooks         []dsl.Hook
 }
 
 func loadEntities(root string) ([]Entity, error) {
@@ -107,6 +110,15 @@ func loadEntities(root string) ([]Entity, error) {
 	}
 
 	evaluator := newExprEvaluator()
+	for _, astFile := range files {
+		for _, decl := range astFile.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Name == nil || fn.Type.Params.NumFields() > 0 {
+				continue
+			}
+			evaluator.funcs[fn.Name.Name] = fn
+		}
+	}
 	entities := make([]Entity, 0, len(typeDecls))
 	for _, astFile := range files {
 		for _, decl := range astFile.Decls {
@@ -151,6 +163,18 @@ func loadEntities(root string) ([]Entity, error) {
 					return nil, fmt.Errorf("%s.%s: %w", recv, fn.Name.Name, err)
 				}
 				ent.Annotations = annotations
+			case "Policy":
+				policy, err := evaluator.evalPolicy(fn)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s: %w", recv, fn.Name.Name, err)
+				}
+				ent.Policy = policy
+			case "Mixin":
+				mixins, err := evaluator.evalMixinSlice(fn)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s: %w", recv, fn.Name.Name, err)
+				}
+				ent.Mixins = mixins
 			}
 		}
 	}
@@ -172,7 +196,9 @@ func loadEntities(root string) ([]Entity, error) {
 		}
 	}
 	for i := range out {
+		applyMixins(&out[i])
 		ensureDefaultField(&out[i])
+		applySoftDelete(&out[i])
 		ensureDefaultQuery(&out[i])
 	}
 	synthesizeInverseEdges(out)
@@ -401,6 +427,9 @@ func extractAuthRule(value any) (*dsl.AuthRule, bool) {
 			}
 			rule.Roles = roles
 		}
+		if raw, ok := v["owner"].(string); ok {
+			rule.Owner = raw
+		}
 		return normalizeAuthRule(rule), true
 	}
 	return nil, false
@@ -419,7 +448,7 @@ func normalizeAuthRule(rule *dsl.AuthRule) *dsl.AuthRule {
 	if rule == nil {
 		return nil
 	}
-	normalized := &dsl.AuthRule{Requirement: rule.Requirement}
+	normalized := &dsl.AuthRule{Requirement: rule.Requirement, Owner: strings.TrimSpace(rule.Owner)}
 	if len(rule.Roles) > 0 {
 		seen := map[string]struct{}{}
 		for _, role := range rule.Roles {
@@ -435,12 +464,27 @@ func normalizeAuthRule(rule *dsl.AuthRule) *dsl.AuthRule {
 		}
 		sort.Strings(normalized.Roles)
 	}
+	for _, cond := range rule.Conditions {
+		cond.Claim = strings.TrimSpace(cond.Claim)
+		cond.Field = strings.TrimSpace(cond.Field)
+		if cond.Claim == "" {
+			continue
+		}
+		normalized.Conditions = append(normalized.Conditions, cond)
+	}
 	return normalized
 }
 
-type exprEvaluator struct{}
+type exprEvaluator struct {
+	// funcs holds the schema package's parameterless functions so schemas can share helpers such
+	// as custom mixins.
+	funcs   map[string]*ast.FuncDecl
+	calling map[string]bool
+}
 
-func newExprEvaluator() *exprEvaluator { return &exprEvaluator{} }
+func newExprEvaluator() *exprEvaluator {
+	return &exprEvaluator{funcs: map[string]*ast.FuncDecl{}, calling: map[string]bool{}}
+}
 
 func (e *exprEvaluator) evalFieldSlice(fn *ast.FuncDecl) ([]dsl.Field, error) {
 	if fn.Body == nil {
@@ -539,6 +583,73 @@ func (e *exprEvaluator) evalQuerySpec(fn *ast.FuncDecl) (dsl.QuerySpec, error) {
 	return dsl.QuerySpec{}, errors.New("no return statement found")
 }
 
+func (e *exprEvaluator) evalPolicy(fn *ast.FuncDecl) (dsl.Policy, error) {
+	if fn.Body == nil {
+		return dsl.Policy{}, errors.New("missing body")
+	}
+	for _, stmt := range fn.Body.List {
+		ret, ok := stmt.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) == 0 {
+			continue
+		}
+		val, err := e.evalExpr(ret.Results[0])
+		if err != nil {
+			return dsl.Policy{}, err
+		}
+		policy, ok := val.(dsl.Policy)
+		if !ok {
+			return dsl.Policy{}, fmt.Errorf("expected dsl.Policy, got %T", val)
+		}
+		return policy, nil
+	}
+	return dsl.Policy{}, errors.New("no return statement found")
+}
+
+func (e *exprEvaluator) evalMixinSlice(fn *ast.FuncDecl) ([]dsl.Mixin, error) {
+	if fn.Body == nil {
+		return nil, errors.New("missing body")
+	}
+	for _, stmt := range fn.Body.List {
+		ret, ok := stmt.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) == 0 {
+			continue
+		}
+		val, err := e.evalExpr(ret.Results[0])
+		if err != nil {
+			return nil, err
+		}
+		if val == nil {
+			return nil, nil
+		}
+		mixins, ok := val.([]dsl.Mixin)
+		if !ok {
+			return nil, fmt.Errorf("expected []dsl.Mixin, got %T", val)
+		}
+		return mixins, nil
+	}
+	return nil, errors.New("no return statement found")
+}
+
+func (e *exprEvaluator) evalLocalCall(name string) (any, error) {
+	fn, ok := e.funcs[name]
+	if !ok || fn.Body == nil {
+		return nil, fmt.Errorf("unsupported function %s: only parameterless functions declared in the schema package can be called", name)
+	}
+	if e.calling[name] {
+		return nil, fmt.Errorf("recursive call to %s", name)
+	}
+	e.calling[name] = true
+	defer delete(e.calling, name)
+	for _, stmt := range fn.Body.List {
+		ret, ok := stmt.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) == 0 {
+			continue
+		}
+		return e.evalExpr(ret.Results[0])
+	}
+	return nil, fmt.Errorf("%s: no return statement found", name)
+}
+
 func (e *exprEvaluator) evalAnnotationSlice(fn *ast.FuncDecl) ([]dsl.Annotation, error) {
 	if fn.Body == nil {
 		return nil, errors.New("missing body")
@@ -625,6 +736,9 @@ func (e *exprEvaluator) evalExpr(expr ast.Expr) (any, error) {
 			}
 		case token.ADD:
 			return val, nil
+		case token.AND:
+			// &dsl.AuthRule{...}; composite literals already evaluate to the value the DSL expects.
+			return val, nil
 		default:
 			return nil, fmt.Errorf("unsupported unary operator %s", exp.Op)
 		}
@@ -698,6 +812,20 @@ func (e *exprEvaluator) evalCompositeLit(lit *ast.CompositeLit) (any, error) {
 					annotations = append(annotations, annotation)
 				}
 				return annotations, nil
+			case "Mixin":
+				var mixins []dsl.Mixin
+				for _, elt := range lit.Elts {
+					val, err := e.evalExpr(elt)
+					if err != nil {
+						return nil, err
+					}
+					mixin, ok := val.(dsl.Mixin)
+					if !ok {
+						return nil, fmt.Errorf("expected dsl.Mixin, got %T", val)
+					}
+					mixins = append(mixins, mixin)
+				}
+				return mixins, nil
 			}
 		}
 	case *ast.SelectorExpr:
@@ -801,6 +929,16 @@ func (e *exprEvaluator) evalAuthRuleLiteral(lit *ast.CompositeLit) (*dsl.AuthRul
 			default:
 				return nil, fmt.Errorf("unsupported roles type %T", val)
 			}
+		case "Owner":
+			val, err := e.evalExpr(kv.Value)
+			if err != nil {
+				return nil, err
+			}
+			owner, ok := val.(string)
+			if !ok {
+				return nil, fmt.Errorf("unsupported owner type %T", val)
+			}
+			rule.Owner = owner
 		default:
 			return nil, fmt.Errorf("unsupported field %s in dsl.AuthRule literal", ident.Name)
 		}
@@ -809,6 +947,9 @@ func (e *exprEvaluator) evalAuthRuleLiteral(lit *ast.CompositeLit) (*dsl.AuthRul
 }
 
 func (e *exprEvaluator) evalCallExpr(call *ast.CallExpr) (any, error) {
+	if ident, ok := call.Fun.(*ast.Ident); ok && len(call.Args) == 0 {
+		return e.evalLocalCall(ident.Name)
+	}
 	selector, ok := call.Fun.(*ast.SelectorExpr)
 	if !ok {
 		return nil, fmt.Errorf("unsupported call expression %T", call.Fun)
@@ -849,6 +990,14 @@ func (e *exprEvaluator) evalCallExpr(call *ast.CallExpr) (any, error) {
 		return executeOrderMethod(b, selector.Sel.Name, args)
 	case dsl.Aggregate:
 		return executeAggregateMethod(b, selector.Sel.Name, args)
+	case dsl.Policy:
+		return executePolicyMethod(b, selector.Sel.Name, args)
+	case dsl.PolicyRule:
+		return executePolicyRuleMethod(b, selector.Sel.Name, args)
+	case *dsl.AuthRule:
+		return executeAuthRuleMethod(b, selector.Sel.Name, args)
+	case dsl.Mixin:
+		return executeMixinMethod(b, selector.Sel.Name, args)
 	default:
 		return nil, fmt.Errorf("unsupported call base %T", base)
 	}
@@ -1030,6 +1179,12 @@ func executeDSLFunc(name string, args []any) (any, error) {
 			events = append(events, event)
 		}
 		return dsl.GraphQLSubscriptions(events...), nil
+	case "GraphQLFilterable":
+		return dsl.GraphQLFilterable(argStrings(args)...), nil
+	case "GraphQLOrderable":
+		return dsl.GraphQLOrderable(argStrings(args)...), nil
+	case "GraphQLAggregate":
+		return dsl.GraphQLAggregate(argStrings(args)...), nil
 	case "Geometry":
 		return dsl.Geometry(argString(args, 0)), nil
 	case "Geography":
@@ -1101,12 +1256,59 @@ func executeDSLFunc(name string, args []any) (any, error) {
 		return dsl.Public(), nil
 	case "AdminOnly":
 		return dsl.AdminOnly(), nil
+	case "OwnerOnly":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("OwnerOnly requires a field name")
+		}
+		return dsl.OwnerOnly(argString(args, 0)), nil
 	case "ContentAuth":
 		return dsl.ContentAuth(), nil
 	case "UserAuth":
 		return dsl.UserAuth(), nil
 	case "ReadOnlyAuth":
 		return dsl.ReadOnlyAuth(), nil
+	case "NewPolicy":
+		return dsl.NewPolicy(), nil
+	case "AlwaysAllow":
+		return dsl.AlwaysAllow(), nil
+	case "AlwaysDeny":
+		return dsl.AlwaysDeny(), nil
+	case "AllowIfAuthenticated":
+		return dsl.AllowIfAuthenticated(), nil
+	case "DenyIfAnonymous":
+		return dsl.DenyIfAnonymous(), nil
+	case "AllowIfRole":
+		return dsl.AllowIfRole(argStrings(args)...), nil
+	case "DenyUnlessRole":
+		return dsl.DenyUnlessRole(argStrings(args)...), nil
+	case "FilterOwner":
+		if strings.TrimSpace(argString(args, 0)) == "" {
+			return nil, fmt.Errorf("FilterOwner requires a field name")
+		}
+		return dsl.FilterOwner(argString(args, 0)), nil
+	case "SoftDelete":
+		return dsl.SoftDelete(), nil
+	case "NewMixin":
+		return dsl.NewMixin(argString(args, 0)), nil
+	case "UUIDv7IDMixin":
+		return dsl.UUIDv7IDMixin(), nil
+	case "TimeMixin":
+		return dsl.TimeMixin(), nil
+	case "AuditMixin":
+		return dsl.AuditMixin(), nil
+	case "ViewerSubjectHook":
+		if strings.TrimSpace(argString(args, 0)) == "" {
+			return nil, fmt.Errorf("ViewerSubjectHook requires a field name")
+		}
+		ops := make([]dsl.MutationOp, 0, len(args))
+		for i := 1; i < len(args); i++ {
+			op, err := argMutationOp(args, i)
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, op)
+		}
+		return dsl.ViewerSubjectHook(argString(args, 0), ops...), nil
 	default:
 		return nil, errorWithSuggestion("unsupported dsl function %s", name, dslFunctionNames)
 	}
@@ -1124,10 +1326,14 @@ func executeFieldMethod(f dsl.Field, name string, args []any) (any, error) {
 		return f.Unique(), nil
 	case "NotEmpty":
 		return f.NotEmpty(), nil
+	case "Immutable":
+		return f.Immutable(), nil
 	case "DefaultNow":
 		return f.DefaultNow(), nil
 	case "UpdateNow":
 		return f.UpdateNow(), nil
+	case "Version":
+		return f.Version(), nil
 	case "WithDefault":
 		return f.WithDefault(argString(args, 0)), nil
 	case "WithGoType":
@@ -1170,6 +1376,15 @@ func executeFieldMethod(f dsl.Field, name string, args []any) (any, error) {
 		default:
 			return nil, fmt.Errorf("Computed expects dsl.ComputedColumn, got %T", args[0])
 		}
+	case "Authorize":
+		var rule *dsl.AuthRule
+		if len(args) > 0 {
+			rule, _ = extractAuthRule(args[0])
+		}
+		if rule == nil {
+			return nil, fmt.Errorf("Authorize expects a dsl.AuthRule")
+		}
+		return f.Authorize(rule), nil
 	default:
 		return nil, errorWithSuggestion("unsupported field method %s", name, fieldMethodNames)
 	}
@@ -1416,6 +1631,124 @@ func executeAggregateMethod(agg dsl.Aggregate, name string, args []any) (any, er
 	}
 }
 
+func executeMixinMethod(mixin dsl.Mixin, name string, args []any) (any, error) {
+	switch name {
+	case "WithFields":
+		fields := make([]dsl.Field, len(args))
+		for i, arg := range args {
+			field, ok := arg.(dsl.Field)
+			if !ok {
+				return nil, fmt.Errorf("expected dsl.Field, got %T", arg)
+			}
+			fields[i] = field
+		}
+		return mixin.WithFields(fields...), nil
+	case "WithEdges":
+		edges := make([]dsl.Edge, len(args))
+		for i, arg := range args {
+			edge, ok := arg.(dsl.Edge)
+			if !ok {
+				return nil, fmt.Errorf("expected dsl.Edge, got %T", arg)
+			}
+			edges[i] = edge
+		}
+		return mixin.WithEdges(edges...), nil
+	case "WithIndexes":
+		indexes := make([]dsl.Index, len(args))
+		for i, arg := range args {
+			index, ok := arg.(dsl.Index)
+			if !ok {
+				return nil, fmt.Errorf("expected dsl.Index, got %T", arg)
+			}
+			indexes[i] = index
+		}
+		return mixin.WithIndexes(indexes...), nil
+	case "WithAnnotations":
+		annotations := make([]dsl.Annotation, len(args))
+		for i, arg := range args {
+			annotation, ok := arg.(dsl.Annotation)
+			if !ok {
+				return nil, fmt.Errorf("expected dsl.Annotation, got %T", arg)
+			}
+			annotations[i] = annotation
+		}
+		return mixin.WithAnnotations(annotations...), nil
+	case "WithHooks":
+		hooks := make([]dsl.Hook, len(args))
+		for i, arg := range args {
+			hook, ok := arg.(dsl.Hook)
+			if !ok {
+				return nil, fmt.Errorf("expected dsl.Hook, got %T", arg)
+			}
+			hooks[i] = hook
+		}
+		return mixin.WithHooks(hooks...), nil
+	default:
+		return nil, errorWithSuggestion("unsupported mixin method %s", name, mixinMethodNames)
+	}
+}
+
+func executePolicyMethod(policy dsl.Policy, name string, args []any) (any, error) {
+	rules := make([]dsl.PolicyRule, len(args))
+	for i, arg := range args {
+		rule, ok := arg.(dsl.PolicyRule)
+		if !ok {
+			return nil, fmt.Errorf("expected dsl.PolicyRule, got %T", arg)
+		}
+		rules[i] = rule
+	}
+	switch name {
+	case "Query":
+		return policy.Query(rules...), nil
+	case "Mutation":
+		return policy.Mutation(rules...), nil
+	default:
+		return nil, errorWithSuggestion("unsupported policy method %s", name, policyMethodNames)
+	}
+}
+
+func executePolicyRuleMethod(rule dsl.PolicyRule, name string, args []any) (any, error) {
+	switch name {
+	case "On":
+		ops := make([]dsl.MutationOp, 0, len(args))
+		for i := range args {
+			op, err := argMutationOp(args, i)
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, op)
+		}
+		return rule.On(ops...), nil
+	default:
+		return nil, errorWithSuggestion("unsupported policy rule method %s", name, policyRuleMethodNames)
+	}
+}
+
+func executeAuthRuleMethod(rule *dsl.AuthRule, name string, args []any) (any, error) {
+	switch name {
+	case "OrOwner":
+		return rule.OrOwner(argString(args, 0)), nil
+	case "WhereClaim":
+		return rule.WhereClaim(argString(args, 0), argString(args, 1)), nil
+	case "WhereClaimValue":
+		return rule.WhereClaimValue(argString(args, 0), argString(args, 1)), nil
+	default:
+		return nil, errorWithSuggestion("unsupported auth rule method %s", name, authRuleMethodNames)
+	}
+}
+
+func argStrings(args []any) []string {
+	out := make([]string, 0, len(args))
+	for i := range args {
+		candidate := strings.TrimSpace(argString(args, i))
+		if candidate == "" {
+			continue
+		}
+		out = append(out, candidate)
+	}
+	return out
+}
+
 func argString(args []any, idx int) string {
 	if idx >= len(args) {
 		return ""
@@ -1566,8 +1899,10 @@ var fieldMethodNames = []string{
 	"Unique",
 	"UniqueConstraint",
 	"NotEmpty",
+	"Immutable",
 	"DefaultNow",
 	"UpdateNow",
+	"Version",
 	"WithDefault",
 	"WithGoType",
 	"Default",
@@ -1579,6 +1914,7 @@ var fieldMethodNames = []string{
 	"Scale",
 	"ArrayElement",
 	"Computed",
+	"Authorize",
 }
 
 var edgeMethodNames = []string{
@@ -1625,6 +1961,44 @@ var orderMethodNames = []string{"Named"}
 
 var aggregateMethodNames = []string{"On", "WithGoType"}
 
+var policyMethodNames = []string{"Query", "Mutation"}
+
+var mixinMethodNames = []string{"WithFields", "WithEdges", "WithIndexes", "WithAnnotations", "WithHooks"}
+
+var policyRuleMethodNames = []string{"On"}
+
+var authRuleMethodNames = []string{"OrOwner", "WhereClaim", "WhereClaimValue"}
+
+func argMutationOp(args []any, idx int) (dsl.MutationOp, error) {
+	if idx >= len(args) {
+		return "", fmt.Errorf("missing mutation op")
+	}
+	switch v := args[idx].(type) {
+	case dsl.MutationOp:
+		if v == "" {
+			return "", fmt.Errorf("invalid mutation op")
+		}
+		return v, nil
+	case string:
+		if op, ok := mutationOpLookup[v]; ok {
+			return op, nil
+		}
+		if op, ok := mutationOpLookup[strings.TrimPrefix(v, "dsl.")]; ok {
+			return op, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported mutation op %v", args[idx])
+}
+
+var mutationOpLookup = map[string]dsl.MutationOp{
+	"MutationCreate": dsl.MutationCreate,
+	"MutationUpdate": dsl.MutationUpdate,
+	"MutationDelete": dsl.MutationDelete,
+	"create":         dsl.MutationCreate,
+	"update":         dsl.MutationUpdate,
+	"delete":         dsl.MutationDelete,
+}
+
 func argSubscriptionEvent(args []any, idx int) (dsl.SubscriptionEvent, error) {
 	if idx >= len(args) {
 		return "", fmt.Errorf("missing subscription event")
@@ -1845,6 +2219,9 @@ var dslFunctionNames = []string{
 	"CountAggregate",
 	"GraphQL",
 	"GraphQLSubscriptions",
+	"GraphQLFilterable",
+	"GraphQLOrderable",
+	"GraphQLAggregate",
 	"Geometry",
 	"Geography",
 	"Vector",
@@ -1861,9 +2238,24 @@ var dslFunctionNames = []string{
 	"PublicAccess",
 	"Public",
 	"AdminOnly",
+	"OwnerOnly",
 	"ContentAuth",
 	"UserAuth",
 	"ReadOnlyAuth",
+	"NewPolicy",
+	"AlwaysAllow",
+	"AlwaysDeny",
+	"AllowIfAuthenticated",
+	"DenyIfAnonymous",
+	"AllowIfRole",
+	"DenyUnlessRole",
+	"FilterOwner",
+	"SoftDelete",
+	"NewMixin",
+	"UUIDv7IDMixin",
+	"TimeMixin",
+	"AuditMixin",
+	"ViewerSubjectHook",
 }
 
 func sortedKeys[V any](m map[string]V) []string {
@@ -1883,6 +2275,15 @@ var comparisonLookup = map[string]dsl.ComparisonOperator{
 	"OpGTE":         dsl.OpGTE,
 	"OpLTE":         dsl.OpLTE,
 	"OpILike":       dsl.OpILike,
+	"OpLike":        dsl.OpLike,
+	"OpIn":          dsl.OpIn,
+	"OpNotIn":       dsl.OpNotIn,
+	"OpIsNull":      dsl.OpIsNull,
+	"OpNotNull":     dsl.OpNotNull,
+	"OpBetween":     dsl.OpBetween,
+	"OpHasPrefix":   dsl.OpHasPrefix,
+	"OpHasSuffix":   dsl.OpHasSuffix,
+	"OpContains":    dsl.OpContains,
 }
 
 var sortDirectionLookup = map[string]dsl.SortDirection{
@@ -517,3 +517,300 @@ func TestLoadEntitiesErrorsWhenNoSchemas(t *testing.T) {
 		t.Fatalf("unexpected error message: %v", discoveryErr.Error())
 	}
 }
+
+func TestLoadEntitiesParsesPolicy(t *testing.T) {
+	dir := t.TempDir()
+	schemaDir := filepath.Join(dir, "schema")
+	if err := os.MkdirAll(schemaDir, 0o755); err != nil {
+		t.Fatalf("mkdir schema: %v", err)
+	}
+
+	source := `package schema
+
+import "github.com/deicod/erm/orm/dsl"
+
+type Post struct{ dsl.Schema }
+
+func (Post) Fields() []dsl.Field {
+        return []dsl.Field{
+                dsl.UUIDv7("id").Primary(),
+                dsl.UUIDv7("author_id"),
+        }
+}
+
+func (Post) Policy() dsl.Policy {
+        return dsl.NewPolicy().
+                Query(dsl.AllowIfRole("admin", "editor"), dsl.FilterOwner("author_id")).
+                Mutation(dsl.DenyIfAnonymous(), dsl.AlwaysDeny().On(dsl.MutationDelete))
+}
+`
+	if err := os.WriteFile(filepath.Join(schemaDir, "post.schema.go"), []byte(source), 0o644); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+
+	entities, err := loadEntities(dir)
+	if err != nil {
+		t.Fatalf("loadEntities: %v", err)
+	}
+	policy := findEntity(entities, "Post").Policy
+	if len(policy.QueryRules) != 2 || len(policy.MutationRules) != 2 {
+		t.Fatalf("unexpected policy: %+v", policy)
+	}
+	if got := policy.QueryRules[0]; got.Kind != dsl.PolicyAllowIfRole || strings.Join(got.Roles, ",") != "admin,editor" {
+		t.Fatalf("unexpected role rule: %+v", got)
+	}
+	if got := policy.QueryRules[1]; got.Kind != dsl.PolicyFilterOwner || got.Field != "author_id" {
+		t.Fatalf("unexpected filter rule: %+v", got)
+	}
+	if got := policy.MutationRules[1]; got.Kind != dsl.PolicyAlwaysDeny || len(got.Ops) != 1 || got.Ops[0] != dsl.MutationDelete {
+		t.Fatalf("unexpected delete rule: %+v", got)
+	}
+}
+
+func TestLoadEntitiesRejectsPolicyOnUnknownField(t *testing.T) {
+	dir := t.TempDir()
+	schemaDir := filepath.Join(dir, "schema")
+	if err := os.MkdirAll(schemaDir, 0o755); err != nil {
+		t.Fatalf("mkdir schema: %v", err)
+	}
+
+	source := `package schema
+
+import "github.com/deicod/erm/orm/dsl"
+
+type Post struct{ dsl.Schema }
+
+func (Post) Fields() []dsl.Field {
+        return []dsl.Field{dsl.UUIDv7("id").Primary()}
+}
+
+func (Post) Policy() dsl.Policy {
+        return dsl.NewPolicy().Query(dsl.FilterOwner("owner_id"))
+}
+`
+	if err := os.WriteFile(filepath.Join(schemaDir, "post.schema.go"), []byte(source), 0o644); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+
+	_, err := loadEntities(dir)
+	if err == nil || !strings.Contains(err.Error(), `policy filters on unknown field "owner_id"`) {
+		t.Fatalf("expected unknown policy field error, got %v", err)
+	}
+}
+
+func TestLoadEntitiesParsesAuthRules(t *testing.T) {
+	dir := t.TempDir()
+	schemaDir := filepath.Join(dir, "schema")
+	if err := os.MkdirAll(schemaDir, 0o755); err != nil {
+		t.Fatalf("mkdir schema: %v", err)
+	}
+
+	source := `package schema
+
+import "github.com/deicod/erm/orm/dsl"
+
+type Post struct{ dsl.Schema }
+
+func (Post) Fields() []dsl.Field {
+        return []dsl.Field{
+                dsl.UUIDv7("id").Primary(),
+                dsl.Text("author_id"),
+                dsl.Text("tenant_id"),
+                dsl.Text("notes").Authorize(dsl.OwnerOnly("author_id")),
+        }
+}
+
+func (Post) Annotations() []dsl.Annotation {
+        return []dsl.Annotation{
+                dsl.Authorization(dsl.AuthRules{
+                        Read:   dsl.RequireAuth().WhereClaim("tenant", "tenant_id"),
+                        Update: dsl.AdminOnly().OrOwner("author_id").WhereClaimValue("tier", "gold"),
+                        Delete: &dsl.AuthRule{Requirement: dsl.AuthRequirementAuthenticated, Owner: "author_id"},
+                }),
+        }
+}
+`
+	if err := os.WriteFile(filepath.Join(schemaDir, "post.schema.go"), []byte(source), 0o644); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+
+	entities, err := loadEntities(dir)
+	if err != nil {
+		t.Fatalf("loadEntities: %v", err)
+	}
+	post := findEntity(entities, "Post")
+	read := post.Authorization.Read
+	if read == nil || len(read.Conditions) != 1 || read.Conditions[0] != (dsl.AuthCondition{Claim: "tenant", Field: "tenant_id"}) {
+		t.Fatalf("unexpected read rule: %+v", read)
+	}
+	update := post.Authorization.Update
+	if update == nil || update.Owner != "author_id" || strings.Join(update.Roles, ",") != "admin" || len(update.Conditions) != 1 || update.Conditions[0].Value != "gold" {
+		t.Fatalf("unexpected update rule: %+v", update)
+	}
+	if del := post.Authorization.Delete; del == nil || del.Owner != "author_id" {
+		t.Fatalf("unexpected delete rule: %+v", del)
+	}
+	var notes dsl.Field
+	for _, field := range post.Fields {
+		if field.Name == "notes" {
+			notes = field
+		}
+	}
+	if rule := fieldAuthRule(notes); rule == nil || rule.Owner != "author_id" || len(rule.Roles) != 0 {
+		t.Fatalf("unexpected field rule: %+v", rule)
+	}
+}
+
+func TestLoadEntitiesParsesGraphQLOptions(t *testing.T) {
+	dir := t.TempDir()
+	schemaDir := filepath.Join(dir, "schema")
+	if err := os.MkdirAll(schemaDir, 0o755); err != nil {
+		t.Fatalf("mkdir schema: %v", err)
+	}
+
+	source := `package schema
+
+import "github.com/deicod/erm/orm/dsl"
+
+type Sale struct{ dsl.Schema }
+
+func (Sale) Fields() []dsl.Field {
+        return []dsl.Field{
+                dsl.UUIDv7("id").Primary(),
+                dsl.Text("region"),
+                dsl.Integer("amount"),
+        }
+}
+
+func (Sale) Annotations() []dsl.Annotation {
+        return []dsl.Annotation{
+                dsl.GraphQL("Sale",
+                        dsl.GraphQLFilterable("region"),
+                        dsl.GraphQLOrderable("amount"),
+                        dsl.GraphQLAggregate("region", "amount"),
+                ),
+        }
+}
+`
+	if err := os.WriteFile(filepath.Join(schemaDir, "sale.schema.go"), []byte(source), 0o644); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+
+	entities, err := loadEntities(dir)
+	if err != nil {
+		t.Fatalf("loadEntities: %v", err)
+	}
+	sale := findEntity(entities, "Sale")
+	if !graphqlAggregate(sale) {
+		t.Fatalf("expected Sale to opt into the aggregate query: %+v", sale.Annotations)
+	}
+	var grouped []string
+	for _, field := range aggregateFields(sale) {
+		grouped = append(grouped, field.field.Name)
+	}
+	if strings.Join(grouped, ",") != "region,amount" {
+		t.Fatalf("unexpected aggregate fields: %v", grouped)
+	}
+	if filterable, ok := graphqlFilterable(sale); !ok || len(filterable) != 1 {
+		t.Fatalf("unexpected filterable fields: %v", filterable)
+	}
+}
+
+func TestLoadEntitiesMergesMixins(t *testing.T) {
+	dir := t.TempDir()
+	schemaDir := filepath.Join(dir, "schema")
+	if err := os.MkdirAll(schemaDir, 0o755); err != nil {
+		t.Fatalf("mkdir schema: %v", err)
+	}
+
+	source := `package schema
+
+import "github.com/deicod/erm/orm/dsl"
+
+func SlugMixin() dsl.Mixin {
+        return dsl.NewMixin("slug").
+                WithFields(dsl.String("slug").NotEmpty()).
+                WithIndexes(dsl.Idx("posts_slug_key").On("slug").Unique())
+}
+
+type Post struct{ dsl.Schema }
+
+func (Post) Mixin() []dsl.Mixin {
+        return []dsl.Mixin{dsl.UUIDv7IDMixin(), SlugMixin(), dsl.TimeMixin(), dsl.AuditMixin()}
+}
+
+func (Post) Fields() []dsl.Field {
+        return []dsl.Field{
+                dsl.String("title"),
+                dsl.Text("slug"),
+        }
+}
+`
+	if err := os.WriteFile(filepath.Join(schemaDir, "post.schema.go"), []byte(source), 0o644); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+
+	entities, err := loadEntities(dir)
+	if err != nil {
+		t.Fatalf("loadEntities: %v", err)
+	}
+	post := findEntity(entities, "Post")
+	var names []string
+	for _, field := range post.Fields {
+		names = append(names, field.Name)
+	}
+	if got := strings.Join(names, ","); got != "id,title,slug,created_at,updated_at,created_by,updated_by" {
+		t.Fatalf("unexpected field order: %s", got)
+	}
+	if post.Fields[2].Type != dsl.TypeText {
+		t.Fatalf("expected schema slug field to win over mixin, got %+v", post.Fields[2])
+	}
+	if len(post.Indexes) != 1 || strings.Join(post.Indexes[0].Columns, ",") != "slug" {
+		t.Fatalf("expected mixin index, got %+v", post.Indexes)
+	}
+	if len(post.Hooks) != 2 || post.Hooks[0].Field != "created_by" || post.Hooks[1].Kind != dsl.HookViewerSubject {
+		t.Fatalf("unexpected hooks: %+v", post.Hooks)
+	}
+	if immutable, _ := post.Fields[5].Annotations["immutable"].(bool); !immutable {
+		t.Fatalf("expected created_by to be immutable")
+	}
+}
+
+func TestLoadEntitiesAddsSoftDeleteColumn(t *testing.T) {
+	dir := t.TempDir()
+	schemaDir := filepath.Join(dir, "schema")
+	if err := os.MkdirAll(schemaDir, 0o755); err != nil {
+		t.Fatalf("mkdir schema: %v", err)
+	}
+
+	source := `package schema
+
+import "github.com/deicod/erm/orm/dsl"
+
+type Post struct{ dsl.Schema }
+
+func (Post) Fields() []dsl.Field {
+        return []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.String("title")}
+}
+
+func (Post) Annotations() []dsl.Annotation {
+        return []dsl.Annotation{dsl.SoftDelete()}
+}
+`
+	if err := os.WriteFile(filepath.Join(schemaDir, "post.schema.go"), []byte(source), 0o644); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+
+	entities, err := loadEntities(dir)
+	if err != nil {
+		t.Fatalf("loadEntities: %v", err)
+	}
+	post := findEntity(entities, "Post")
+	last := post.Fields[len(post.Fields)-1]
+	if last.Name != "deleted_at" || last.Type != dsl.TypeTimestampTZ || !last.Nullable || !isImmutableField(last) {
+		t.Fatalf("expected nullable immutable deleted_at column, got %+v", last)
+	}
+	if !isSoftDelete(post) {
+		t.Fatalf("expected soft delete annotation to be parsed")
+	}
+}
@@ -26,6 +26,7 @@ func TestEnsureRuntimeScaffoldsIdempotent(t *testing.T) {
 		"graphql/resolvers/resolver.go",
 		"graphql/resolvers/entities_gen.go",
 		"graphql/resolvers/entities_hooks.go",
+		"graphql/resolvers/errors.go",
 		"graphql/server/schema.go",
 		"graphql/server/server.go",
 		"graphql/subscriptions/bus.go",
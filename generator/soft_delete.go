@@ -0,0 +1,145 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+func isSoftDelete(ent Entity) bool {
+	for _, ann := range ent.Annotations {
+		if ann.Name == dsl.AnnotationSoftDelete {
+			return true
+		}
+	}
+	return false
+}
+
+// applySoftDelete appends the deleted_at column to soft-delete entities that do not declare it.
+// The column is immutable so full-row updates never clear it; Restore is the only way back.
+func applySoftDelete(ent *Entity) {
+	if !isSoftDelete(*ent) {
+		return
+	}
+	for i, field := range ent.Fields {
+		if fieldColumn(field) == dsl.SoftDeleteColumn {
+			ent.Fields[i] = field.Immutable()
+			return
+		}
+	}
+	ent.Fields = append(ent.Fields, dsl.TimestampTZ(dsl.SoftDeleteColumn).Optional().Immutable())
+}
+
+// applySoftDeleteIndexes turns unique columns into unique indexes and scopes every unique index to
+// live rows, so a soft-deleted row never blocks reusing its values.
+func applySoftDeleteIndexes(m *entityMigration) {
+	if !isSoftDelete(m.Entity) {
+		return
+	}
+	live := dsl.SoftDeleteColumn + " IS NULL"
+	table := pluralize(m.Entity.Name)
+	indexes := make([]dsl.Index, 0, len(m.Entity.Indexes)+1)
+	for i, field := range m.Fields {
+		if !field.IsUnique || field.IsPrimary {
+			continue
+		}
+		m.Fields[i].IsUnique = false
+		column := fieldColumn(field)
+		indexes = append(indexes, dsl.Idx(fmt.Sprintf("%s_%s_key", table, column)).On(column).Unique())
+	}
+	indexes = append(indexes, m.Entity.Indexes...)
+	for i, idx := range indexes {
+		if !idx.IsUnique {
+			continue
+		}
+		switch {
+		case idx.Where == "":
+			indexes[i].Where = live
+		case !strings.Contains(idx.Where, live):
+			indexes[i].Where = fmt.Sprintf("(%s) AND %s", idx.Where, live)
+		}
+	}
+	m.Entity.Indexes = indexes
+}
+
+// softDeleteScope returns the SQL condition that hides soft-deleted rows, optionally qualified.
+func softDeleteScope(qualifier string) string {
+	if qualifier != "" {
+		return qualifier + "." + dsl.SoftDeleteColumn + " IS NULL"
+	}
+	return dsl.SoftDeleteColumn + " IS NULL"
+}
+
+// queryPredicates is the expression the query terminals pass to the SQL builders.
+func queryPredicates(ent Entity) string {
+	if isSoftDelete(ent) {
+		return "q.scopedPredicates()"
+	}
+	return "q.predicates"
+}
+
+func emitSoftDeleteQueryMethods(buf *bytes.Buffer, ent Entity) {
+	if !isSoftDelete(ent) {
+		return
+	}
+	name := ent.Name
+	fmt.Fprintf(buf, "// WithDeleted includes soft-deleted rows in the results.\n")
+	fmt.Fprintf(buf, "func (q *%sQuery) WithDeleted() *%sQuery {\n", name, name)
+	fmt.Fprintf(buf, "    q.withDeleted, q.onlyDeleted = true, false\n    return q\n}\n\n")
+	fmt.Fprintf(buf, "// OnlyDeleted restricts the results to soft-deleted rows.\n")
+	fmt.Fprintf(buf, "func (q *%sQuery) OnlyDeleted() *%sQuery {\n", name, name)
+	fmt.Fprintf(buf, "    q.withDeleted, q.onlyDeleted = false, true\n    return q\n}\n\n")
+	fmt.Fprintf(buf, "func (q *%sQuery) scopedPredicates() []runtime.Predicate {\n", name)
+	fmt.Fprintf(buf, "    switch {\n")
+	fmt.Fprintf(buf, "    case q.withDeleted:\n        return q.predicates\n")
+	fmt.Fprintf(buf, "    case q.onlyDeleted:\n        return append(append([]runtime.Predicate(nil), q.predicates...), runtime.Predicate{Column: %q, Operator: runtime.OpNotNull})\n", dsl.SoftDeleteColumn)
+	fmt.Fprintf(buf, "    default:\n        return append(append([]runtime.Predicate(nil), q.predicates...), runtime.Predicate{Column: %q, Operator: runtime.OpIsNull})\n", dsl.SoftDeleteColumn)
+	fmt.Fprintf(buf, "    }\n}\n\n")
+}
+
+func emitSoftDeleteClientMethods(buf *bytes.Buffer, ent Entity) {
+	if !isSoftDelete(ent) {
+		return
+	}
+	name := ent.Name
+	lower := strings.ToLower(name)
+	pk := exportName(primaryField(ent).Name)
+
+	fmt.Fprintf(buf, "// Restore clears deleted_at on a soft-deleted %s. It runs as an update mutation with IDs set\n", name)
+	fmt.Fprintf(buf, "// and returns nil when no deleted row matches id.\n")
+	fmt.Fprintf(buf, "func (c *%sClient) Restore(ctx context.Context, id string) (*%s, error) {\n", name, name)
+	fmt.Fprintf(buf, "    m := &%sMutation{op: runtime.MutationUpdate, restore: true, db: c.db, IDs: []string{id}}\n", name)
+	fmt.Fprintf(buf, "    return runtime.CastResult[*%s](c.mutate(ctx, m, func(ctx context.Context, m *%sMutation) (any, error) {\n", name, name)
+	fmt.Fprintf(buf, "        if len(m.IDs) != 1 {\n            return nil, errors.New(\"restore expects exactly one id\")\n        }\n")
+	fmt.Fprintf(buf, "        return c.restore(ctx, m.IDs[0])\n    }))\n}\n\n")
+
+	fmt.Fprintf(buf, "func (c *%sClient) restore(ctx context.Context, id string) (*%s, error) {\n", name, name)
+	emitWriterGuard(buf, "nil, ")
+	fmt.Fprintf(buf, "    row := writer.QueryRow(ctx, %sRestoreQuery, id)\n", lower)
+	fmt.Fprintf(buf, "    out := new(%s)\n", name)
+	fmt.Fprintf(buf, "    if err := row.Scan(%s); err != nil {\n", scanArgsForOut(ent))
+	fmt.Fprintf(buf, "        if errors.Is(err, pgx.ErrNoRows) {\n            return nil, nil\n        }\n        return nil, err\n    }\n")
+	fmt.Fprintf(buf, "    if c.cache != nil {\n        _ = c.cache.Set(ctx, makeCacheKey(%q, out.%s), out)\n    }\n", name, pk)
+	fmt.Fprintf(buf, "    return out, nil\n}\n\n")
+
+	fmt.Fprintf(buf, "// HardDelete removes the %s row permanently, whether or not it was soft-deleted. Hooks and\n", name)
+	fmt.Fprintf(buf, "// policies see a regular delete mutation.\n")
+	fmt.Fprintf(buf, "func (c *%sClient) HardDelete(ctx context.Context, id string) error {\n", name)
+	fmt.Fprintf(buf, "    m := &%sMutation{op: runtime.MutationDelete, hard: true, db: c.db, IDs: []string{id}}\n", name)
+	fmt.Fprintf(buf, "    _, err := c.mutate(ctx, m, func(ctx context.Context, m *%sMutation) (any, error) {\n", name)
+	fmt.Fprintf(buf, "        if len(m.IDs) != 1 {\n            return nil, errors.New(\"delete expects exactly one id\")\n        }\n")
+	fmt.Fprintf(buf, "        return nil, c.hardDelete(ctx, m.IDs[0])\n    })\n")
+	fmt.Fprintf(buf, "    return err\n}\n\n")
+
+	fmt.Fprintf(buf, "func (c *%sClient) hardDelete(ctx context.Context, id string) error {\n", name)
+	emitWriterGuard(buf, "")
+	fmt.Fprintf(buf, "    if _, err := writer.Exec(ctx, %sHardDeleteQuery, id); err != nil {\n        return err\n    }\n", lower)
+	fmt.Fprintf(buf, "    if c.cache != nil {\n        _ = c.cache.Delete(ctx, makeCacheKey(%q, id))\n    }\n", name)
+	fmt.Fprintf(buf, "    return nil\n}\n\n")
+}
+
+func scanArgsForOut(ent Entity) string {
+	return strings.Join(scanArgsForVar(ent, "out"), ", ")
+}
@@ -0,0 +1,190 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+const softDeleteClientTest = `package gen
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+
+	"github.com/deicod/erm/orm/pg"
+	"github.com/deicod/erm/orm/runtime"
+)
+
+func TestSoftDelete(t *testing.T) {
+	mock, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock: %v", err)
+	}
+	client := NewClient(&pg.DB{Pool: &mockPool{PgxConnIface: mock}})
+	ctx := context.Background()
+	users := client.Users()
+	cols := []string{"id", "email", "deleted_at"}
+
+	mock.ExpectExec("UPDATE users SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL").
+		WithArgs("u1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	if err := users.Delete(ctx, "u1"); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+
+	mock.ExpectExec("UPDATE users SET deleted_at = now() WHERE id IN ($1, $2) AND deleted_at IS NULL").
+		WithArgs("u2", "u3").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	if n, err := users.BulkDelete(ctx, []string{"u2", "u3"}); err != nil || n != 2 {
+		t.Fatalf("bulk soft delete: %d (err %v)", n, err)
+	}
+
+	mock.ExpectQuery("SELECT id, email, deleted_at FROM users WHERE id = $1 AND deleted_at IS NULL").
+		WithArgs("u1").
+		WillReturnRows(mock.NewRows(cols))
+	if user, err := users.ByID(ctx, "u1"); !runtime.IsNotFound(err) || user != nil {
+		t.Fatalf("expected deleted user to be hidden, got %v (err %v)", user, err)
+	}
+
+	mock.ExpectQuery("SELECT COUNT(*) FROM users WHERE deleted_at IS NULL").
+		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(4))
+	if total, err := users.Count(ctx); err != nil || total != 4 {
+		t.Fatalf("count: %d (err %v)", total, err)
+	}
+
+	mock.ExpectQuery("SELECT id, email, deleted_at FROM users WHERE deleted_at IS NULL LIMIT $1").
+		WithArgs(10).
+		WillReturnRows(mock.NewRows(cols))
+	if _, err := users.Query().Limit(10).All(ctx); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT id, email, deleted_at FROM users LIMIT $1").
+		WithArgs(10).
+		WillReturnRows(mock.NewRows(cols))
+	if _, err := users.Query().WithDeleted().Limit(10).All(ctx); err != nil {
+		t.Fatalf("query with deleted: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT id, email, deleted_at FROM users WHERE deleted_at IS NOT NULL LIMIT $1").
+		WithArgs(10).
+		WillReturnRows(mock.NewRows(cols))
+	if _, err := users.Query().OnlyDeleted().Limit(10).All(ctx); err != nil {
+		t.Fatalf("query only deleted: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT id, user_id, version, deleted_at FROM posts WHERE user_id IN ($1) AND deleted_at IS NULL").
+		WithArgs("u9").
+		WillReturnRows(mock.NewRows([]string{"id", "user_id", "version", "deleted_at"}))
+	if err := users.LoadPosts(ctx, &User{ID: "u9"}); err != nil {
+		t.Fatalf("load posts: %v", err)
+	}
+
+	mock.ExpectQuery("UPDATE users SET email = $1 WHERE id = $2 AND deleted_at IS NULL RETURNING id, email, deleted_at").
+		WithArgs("b@example.com", "u1").
+		WillReturnRows(mock.NewRows(cols))
+	if user, err := users.Update(ctx, &User{ID: "u1", Email: "b@example.com"}); !runtime.IsNotFound(err) || user != nil {
+		t.Fatalf("expected deleted user not to be updated, got %v (err %v)", user, err)
+	}
+
+	mock.ExpectQuery("WITH data(id, email) AS (VALUES ($1, $2)) UPDATE users AS t SET email = data.email FROM data WHERE t.id = data.id AND t.deleted_at IS NULL RETURNING id, email, deleted_at").
+		WithArgs("u1", "b@example.com").
+		WillReturnRows(mock.NewRows(cols))
+	if updated, err := users.BulkUpdate(ctx, []*User{{ID: "u1", Email: "b@example.com"}}); err != nil || len(updated) != 0 {
+		t.Fatalf("expected deleted user not to be bulk updated, got %v (err %v)", updated, err)
+	}
+
+	mock.ExpectQuery("UPDATE posts SET user_id = $1, version = version + 1 WHERE id = $2 AND version = $3 AND deleted_at IS NULL RETURNING id, user_id, version, deleted_at").
+		WithArgs("u1", "p1", int32(2)).
+		WillReturnRows(mock.NewRows([]string{"id", "user_id", "version", "deleted_at"}))
+	if _, err := client.Posts().Update(ctx, &Post{ID: "p1", UserID: "u1", Version: 2}); !errors.Is(err, runtime.ErrStaleObject) {
+		t.Fatalf("expected update of a deleted post to be stale, got %v", err)
+	}
+
+	mock.ExpectQuery("UPDATE users SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL RETURNING id, email, deleted_at").
+		WithArgs("u1").
+		WillReturnRows(mock.NewRows(cols).AddRow("u1", "a@example.com", nil))
+	restored, err := users.Restore(ctx, "u1")
+	if err != nil || restored == nil || restored.DeletedAt != nil {
+		t.Fatalf("restore: %v (err %v)", restored, err)
+	}
+
+	mock.ExpectExec("DELETE FROM users WHERE id = $1").
+		WithArgs("u1").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	if err := users.HardDelete(ctx, "u1"); err != nil {
+		t.Fatalf("hard delete: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+`
+
+func softDeleteEntities() []Entity {
+	entities := []Entity{
+		{
+			Name: "User",
+			Fields: []dsl.Field{
+				dsl.String("id").Primary(),
+				dsl.String("email").Unique(),
+			},
+			Edges: []dsl.Edge{
+				dsl.ToMany("posts", "Post").Ref("user_id"),
+			},
+			Indexes: []dsl.Index{
+				dsl.Idx("users_email_lower_key").On("lower(email)").Unique(),
+			},
+			Annotations: []dsl.Annotation{dsl.SoftDelete()},
+		},
+		{
+			Name: "Post",
+			Fields: []dsl.Field{
+				dsl.String("id").Primary(),
+				dsl.String("user_id"),
+				dsl.Integer("version").Version(),
+			},
+			Annotations: []dsl.Annotation{dsl.SoftDelete()},
+		},
+	}
+	for i := range entities {
+		applySoftDelete(&entities[i])
+		ensureDefaultQuery(&entities[i])
+	}
+	return entities
+}
+
+func TestRenderInitialMigration_SoftDeletePartialUniqueIndexes(t *testing.T) {
+	sql := renderInitialMigration(softDeleteEntities(), extensionFlags{})
+	mustContain(t, sql, "    email text NOT NULL,\n    deleted_at timestamptz,")
+	mustContain(t, sql, "CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users (email) WHERE deleted_at IS NULL;")
+	mustContain(t, sql, "CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_key ON users (lower(email)) WHERE deleted_at IS NULL;")
+	if strings.Contains(sql, "email text NOT NULL UNIQUE") {
+		t.Fatalf("expected inline unique constraint to become a partial index:\n%s", sql)
+	}
+}
+
+func TestWriteORMClients_SoftDelete(t *testing.T) {
+	root := t.TempDir()
+	if err := writeORMArtifacts(root, softDeleteEntities()); err != nil {
+		t.Fatalf("writeORMArtifacts: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(root, "orm", "gen", "client_gen.go"))
+	if err != nil {
+		t.Fatalf("read client: %v", err)
+	}
+	src := string(content)
+	mustContain(t, src, "func (q *UserQuery) WithDeleted() *UserQuery {")
+	mustContain(t, src, "func (c *UserClient) Restore(ctx context.Context, id string) (*User, error) {")
+	mustContain(t, src, "UPDATE users SET email = $1 WHERE id = $2 AND deleted_at IS NULL")
+
+	runGeneratedORMTest(t, root, "soft_delete_test.go", softDeleteClientTest)
+}
@@ -68,11 +68,15 @@ type schemaSignature struct {
 }
 
 type entitySignature struct {
-	Name    string           `json:"name"`
-	Fields  []fieldSignature `json:"fields"`
-	Edges   []edgeSignature  `json:"edges"`
-	Indexes []indexSignature `json:"indexes"`
-	Query   querySignature   `json:"query"`
+	Name          string                   `json:"name"`
+	Fields        []fieldSignature         `json:"fields"`
+	Edges         []edgeSignature          `json:"edges"`
+	Indexes       []indexSignature         `json:"indexes"`
+	Query         querySignature           `json:"query"`
+	Policy        policySignature          `json:"policy"`
+	Hooks         []hookRecord             `json:"hooks"`
+	Authorization authorizationSignature   `json:"authorization"`
+	Annotations   []entityAnnotationRecord `json:"annotations"`
 }
 
 type fieldSignature struct {
@@ -143,6 +147,52 @@ type aggregateRecord struct {
 	GoType string `json:"go_type"`
 }
 
+// policySignature keeps the rules in declaration order, as the first matching rule wins.
+type policySignature struct {
+	Query    []policyRuleRecord `json:"query"`
+	Mutation []policyRuleRecord `json:"mutation"`
+}
+
+type policyRuleRecord struct {
+	Kind  string   `json:"kind"`
+	Roles []string `json:"roles"`
+	Field string   `json:"field"`
+	Ops   []string `json:"ops"`
+}
+
+type hookRecord struct {
+	Kind  string   `json:"kind"`
+	Field string   `json:"field"`
+	Ops   []string `json:"ops"`
+}
+
+type authorizationSignature struct {
+	Create *authRuleRecord `json:"create"`
+	Read   *authRuleRecord `json:"read"`
+	Update *authRuleRecord `json:"update"`
+	Delete *authRuleRecord `json:"delete"`
+}
+
+type authRuleRecord struct {
+	Requirement string                `json:"requirement"`
+	Roles       []string              `json:"roles"`
+	Owner       string                `json:"owner"`
+	Conditions  []authConditionRecord `json:"conditions"`
+}
+
+type authConditionRecord struct {
+	Claim string `json:"claim"`
+	Field string `json:"field"`
+	Value string `json:"value"`
+}
+
+// entityAnnotationRecord keeps entity annotations in declaration order, as later GraphQL options
+// can override earlier ones.
+type entityAnnotationRecord struct {
+	Name    string             `json:"name"`
+	Payload []annotationRecord `json:"payload"`
+}
+
 type annotationRecord struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
@@ -181,11 +231,15 @@ func buildSchemaSignature(entities []Entity) schemaSignature {
 	sig.Entities = make([]entitySignature, 0, len(sorted))
 	for _, ent := range sorted {
 		sig.Entities = append(sig.Entities, entitySignature{
-			Name:    ent.Name,
-			Fields:  encodeFields(ent.Fields),
-			Edges:   encodeEdges(ent.Edges),
-			Indexes: encodeIndexes(ent.Indexes),
-			Query:   encodeQuery(ent.Query),
+			Name:          ent.Name,
+			Fields:        encodeFields(ent.Fields),
+			Edges:         encodeEdges(ent.Edges),
+			Indexes:       encodeIndexes(ent.Indexes),
+			Query:         encodeQuery(ent.Query),
+			Policy:        encodePolicy(ent.Policy),
+			Hooks:         encodeHooks(ent.Hooks),
+			Authorization: encodeAuthorization(ent.Authorization),
+			Annotations:   encodeEntityAnnotations(ent.Annotations),
 		})
 	}
 	return sig
@@ -334,6 +388,76 @@ func encodeAggregates(aggregates []dsl.Aggregate) []aggregateRecord {
 	return out
 }
 
+func encodePolicy(policy dsl.Policy) policySignature {
+	return policySignature{
+		Query:    encodePolicyRules(policy.QueryRules),
+		Mutation: encodePolicyRules(policy.MutationRules),
+	}
+}
+
+func encodePolicyRules(rules []dsl.PolicyRule) []policyRuleRecord {
+	out := make([]policyRuleRecord, len(rules))
+	for i, rule := range rules {
+		out[i] = policyRuleRecord{
+			Kind:  string(rule.Kind),
+			Roles: append([]string{}, rule.Roles...),
+			Field: rule.Field,
+			Ops:   encodeMutationOps(rule.Ops),
+		}
+	}
+	return out
+}
+
+// encodeHooks keeps the hooks in declaration order, the order they run in.
+func encodeHooks(hooks []dsl.Hook) []hookRecord {
+	out := make([]hookRecord, len(hooks))
+	for i, hook := range hooks {
+		out[i] = hookRecord{Kind: string(hook.Kind), Field: hook.Field, Ops: encodeMutationOps(hook.Ops)}
+	}
+	return out
+}
+
+func encodeMutationOps(ops []dsl.MutationOp) []string {
+	out := make([]string, len(ops))
+	for i, op := range ops {
+		out[i] = string(op)
+	}
+	return out
+}
+
+func encodeAuthorization(rules dsl.AuthRules) authorizationSignature {
+	return authorizationSignature{
+		Create: encodeAuthRule(rules.Create),
+		Read:   encodeAuthRule(rules.Read),
+		Update: encodeAuthRule(rules.Update),
+		Delete: encodeAuthRule(rules.Delete),
+	}
+}
+
+func encodeAuthRule(rule *dsl.AuthRule) *authRuleRecord {
+	if rule == nil {
+		return nil
+	}
+	conditions := make([]authConditionRecord, len(rule.Conditions))
+	for i, cond := range rule.Conditions {
+		conditions[i] = authConditionRecord{Claim: cond.Claim, Field: cond.Field, Value: cond.Value}
+	}
+	return &authRuleRecord{
+		Requirement: string(rule.Requirement),
+		Roles:       append([]string{}, rule.Roles...),
+		Owner:       rule.Owner,
+		Conditions:  conditions,
+	}
+}
+
+func encodeEntityAnnotations(annotations []dsl.Annotation) []entityAnnotationRecord {
+	out := make([]entityAnnotationRecord, len(annotations))
+	for i, ann := range annotations {
+		out[i] = entityAnnotationRecord{Name: ann.Name, Payload: encodeAnnotations(ann.Payload)}
+	}
+	return out
+}
+
 func encodeAnnotations(annotations map[string]any) []annotationRecord {
 	if len(annotations) == 0 {
 		return []annotationRecord{}
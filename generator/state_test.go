@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/deicod/erm/orm/dsl"
+)
+
+func policyStateEntities(policy dsl.Policy, hooks ...dsl.Hook) []Entity {
+	return []Entity{{
+		Name:   "Post",
+		Fields: []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.String("author_id")},
+		Policy: policy,
+		Hooks:  hooks,
+	}}
+}
+
+// assertComponentChanged fails unless component, last generated from before, is planned as changed
+// for after.
+func assertComponentChanged(t *testing.T, before, after []Entity, component ComponentName) {
+	t.Helper()
+	prevHash, err := schemaInputHash(before)
+	if err != nil {
+		t.Fatalf("hash before: %v", err)
+	}
+	nextHash, err := schemaInputHash(after)
+	if err != nil {
+		t.Fatalf("hash after: %v", err)
+	}
+	state := generatorState{Components: map[ComponentName]componentState{
+		component: {InputHash: componentInputHash(prevHash, component)},
+	}}
+	if plan := buildComponentPlan(t.TempDir(), GenerateOptions{}, state, prevHash, component); plan.Changed {
+		t.Fatalf("expected %s to be up to date for the recorded schema", component)
+	}
+	if plan := buildComponentPlan(t.TempDir(), GenerateOptions{}, state, nextHash, component); !plan.Changed {
+		t.Fatalf("expected %s to be marked as changed", component)
+	}
+}
+
+func TestSchemaInputHashCoversPolicy(t *testing.T) {
+	before := policyStateEntities(dsl.NewPolicy().Query(dsl.FilterOwner("author_id")))
+	after := policyStateEntities(dsl.NewPolicy().Query(dsl.AllowIfRole("admin"), dsl.FilterOwner("author_id")))
+	assertComponentChanged(t, before, after, ComponentORM)
+
+	mutation := policyStateEntities(dsl.NewPolicy().Query(dsl.FilterOwner("author_id")).Mutation(dsl.AlwaysDeny().On(dsl.MutationDelete)))
+	assertComponentChanged(t, before, mutation, ComponentORM)
+}
+
+func TestSchemaInputHashCoversHooks(t *testing.T) {
+	before := policyStateEntities(dsl.NewPolicy(), dsl.ViewerSubjectHook("author_id", dsl.MutationCreate))
+	after := policyStateEntities(dsl.NewPolicy(), dsl.ViewerSubjectHook("author_id", dsl.MutationCreate, dsl.MutationUpdate))
+	assertComponentChanged(t, before, after, ComponentORM)
+}
+
+func authStateEntities(rules dsl.AuthRules, annotations ...dsl.Annotation) []Entity {
+	return []Entity{{
+		Name:          "Post",
+		Fields:        []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.String("author_id")},
+		Authorization: rules,
+		Annotations:   annotations,
+	}}
+}
+
+func TestSchemaInputHashCoversAuthorization(t *testing.T) {
+	before := authStateEntities(dsl.AuthRules{Read: dsl.RequireAuth()})
+	assertComponentChanged(t, before, authStateEntities(dsl.AuthRules{Read: dsl.OwnerOnly("author_id")}), ComponentGraphQL)
+	assertComponentChanged(t, before, authStateEntities(dsl.AuthRules{Read: dsl.RequireAuth().WhereClaim("tenant", "tenant_id")}), ComponentGraphQL)
+	assertComponentChanged(t, before, authStateEntities(dsl.AuthRules{Read: dsl.RequireAuth(), Delete: dsl.AdminOnly()}), ComponentGraphQL)
+}
+
+func TestSchemaInputHashCoversGraphQLAnnotations(t *testing.T) {
+	before := authStateEntities(dsl.AuthRules{}, dsl.GraphQL("Post", dsl.GraphQLFilterable("author_id")))
+	after := authStateEntities(dsl.AuthRules{}, dsl.GraphQL("Post", dsl.GraphQLFilterable("author_id"), dsl.GraphQLAggregate()))
+	assertComponentChanged(t, before, after, ComponentGraphQL)
+}
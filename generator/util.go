@@ -87,6 +87,42 @@ func pluralize(name string) string {
 	return word + "s"
 }
 
+// singularize reverses pluralize for the common English endings, e.g. for naming a single target
+// of a to-many edge. Words without a plural ending are returned in snake case unchanged.
+func singularize(name string) string {
+	word := toSnakeCase(name)
+	irregular := map[string]string{
+		"people":   "person",
+		"men":      "man",
+		"women":    "woman",
+		"children": "child",
+		"teeth":    "tooth",
+		"feet":     "foot",
+		"mice":     "mouse",
+		"geese":    "goose",
+	}
+	for plural, single := range irregular {
+		if word == plural || strings.HasSuffix(word, "_"+plural) {
+			return strings.TrimSuffix(word, plural) + single
+		}
+	}
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "ves") && len(word) > 3:
+		return word[:len(word)-3] + "f"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "uses"), strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zes"),
+		strings.HasSuffix(word, "oes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		return word
+	case strings.HasSuffix(word, "s") && len(word) > 1:
+		return word[:len(word)-1]
+	}
+	return word
+}
+
 func defaultJoinTableName(left, right string) string {
 	parts := []string{pluralize(left), pluralize(right)}
 	sort.Strings(parts)
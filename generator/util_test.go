@@ -32,6 +32,29 @@ func TestPluralize(t *testing.T) {
 	}
 }
 
+func TestSingularize(t *testing.T) {
+	cases := map[string]string{
+		"tags":          "tag",
+		"categories":    "category",
+		"addresses":     "address",
+		"buses":         "bus",
+		"heroes":        "hero",
+		"leaves":        "leaf",
+		"people":        "person",
+		"status":        "status",
+		"child_nodes":   "child_node",
+		"LoginSessions": "login_session",
+	}
+
+	for in, want := range cases {
+		t.Run(in, func(t *testing.T) {
+			if got := singularize(in); got != want {
+				t.Fatalf("singularize(%q) = %q, want %q", in, got, want)
+			}
+		})
+	}
+}
+
 func TestGeneratorsUsePluralizedNames(t *testing.T) {
 	entities := []Entity{
 		{Name: "Company", Fields: []dsl.Field{{Name: "id", Type: dsl.TypeUUID, GoType: "string", IsPrimary: true}}},
@@ -62,9 +85,9 @@ func TestGeneratorsUsePluralizedNames(t *testing.T) {
 		t.Fatalf("writeGraphQLArtifacts: %v", err)
 	}
 	schema := readFile(t, filepath.Join(root, "graphql", "schema.graphqls"))
-	assertContains(t, schema, "companies(first: Int, after: String, last: Int, before: String): CompanyConnection!")
-	assertContains(t, schema, "addresses(first: Int, after: String, last: Int, before: String): AddressConnection!")
-	assertContains(t, schema, "buses(first: Int, after: String, last: Int, before: String): BusConnection!")
+	assertContains(t, schema, "companies(first: Int, after: String, last: Int, before: String, where: CompanyWhereInput, orderBy: [CompanyOrder!]): CompanyConnection!")
+	assertContains(t, schema, "addresses(first: Int, after: String, last: Int, before: String, where: AddressWhereInput, orderBy: [AddressOrder!]): AddressConnection!")
+	assertContains(t, schema, "buses(first: Int, after: String, last: Int, before: String, where: BusWhereInput, orderBy: [BusOrder!]): BusConnection!")
 
 	migration := renderInitialMigration(entities, extensionFlags{})
 	assertContains(t, migration, "CREATE TABLE IF NOT EXISTS companies")
@@ -238,12 +238,116 @@ func validateEntities(entities []Entity) error {
 		}
 	}
 
+	for _, ent := range entities {
+		versions := 0
+		for _, field := range ent.Fields {
+			if !isVersionField(field) {
+				continue
+			}
+			versions++
+			switch {
+			case versions > 1:
+				problems = append(problems, SchemaValidationError{
+					Entity:     ent.Name,
+					Field:      field.Name,
+					Detail:     "entity declares more than one version field",
+					Suggestion: "Call .Version() on a single field.",
+				})
+			case field.Nullable || !isIntegerGoType(baseGoType(field)):
+				problems = append(problems, SchemaValidationError{
+					Entity:     ent.Name,
+					Field:      field.Name,
+					Detail:     fmt.Sprintf("version field must be a non-nullable integer, %q is %s", field.Name, defaultGoType(field)),
+					Suggestion: "Use dsl.Integer or dsl.BigInt without .Optional() for the version field.",
+				})
+			}
+		}
+	}
+
+	for _, ent := range entities {
+		rules := append(append([]dsl.PolicyRule(nil), ent.Policy.QueryRules...), ent.Policy.MutationRules...)
+		for _, rule := range rules {
+			if rule.Kind != dsl.PolicyFilterOwner {
+				continue
+			}
+			if _, ok := policyOwnerField(ent, rule.Field); ok {
+				continue
+			}
+			problems = append(problems, SchemaValidationError{
+				Entity:     ent.Name,
+				Field:      rule.Field,
+				Detail:     fmt.Sprintf("policy filters on unknown field %q", rule.Field),
+				Suggestion: fmt.Sprintf("Pass a field or column name declared in %s.Fields() to dsl.FilterOwner.", ent.Name),
+			})
+		}
+		for _, hook := range ent.Hooks {
+			field, ok := policyOwnerField(ent, hook.Field)
+			switch {
+			case !ok:
+				problems = append(problems, SchemaValidationError{
+					Entity:     ent.Name,
+					Field:      hook.Field,
+					Detail:     fmt.Sprintf("mixin hook sets unknown field %q", hook.Field),
+					Suggestion: "Declare the field in the mixin or in Fields().",
+				})
+			case baseGoType(field) != "string":
+				problems = append(problems, SchemaValidationError{
+					Entity:     ent.Name,
+					Field:      hook.Field,
+					Detail:     fmt.Sprintf("viewer subject hook requires a string field, %q is %s", hook.Field, baseGoType(field)),
+					Suggestion: "Use dsl.String or dsl.Text for fields filled from the viewer subject.",
+				})
+			}
+		}
+		if fields := authRuleFields(ent.Authorization.Create); len(fields) > 0 {
+			problems = append(problems, SchemaValidationError{
+				Entity:     ent.Name,
+				Field:      fields[0],
+				Detail:     "create rule compares the viewer with a field of a record that does not exist yet",
+				Suggestion: "Use OrOwner and WhereClaim on read, update and delete rules; require roles or WhereClaimValue for creates.",
+			})
+		}
+		authRules := []*dsl.AuthRule{ent.Authorization.Read, ent.Authorization.Update, ent.Authorization.Delete}
+		for _, field := range ent.Fields {
+			authRules = append(authRules, fieldAuthRule(field))
+		}
+		for _, rule := range authRules {
+			for _, name := range authRuleFields(rule) {
+				field, ok := policyOwnerField(ent, name)
+				switch {
+				case !ok:
+					problems = append(problems, SchemaValidationError{
+						Entity:     ent.Name,
+						Field:      name,
+						Detail:     fmt.Sprintf("auth rule compares the viewer with unknown field %q", name),
+						Suggestion: fmt.Sprintf("Pass a field or column name declared in %s.Fields() to OrOwner or WhereClaim.", ent.Name),
+					})
+				case baseGoType(field) != "string":
+					problems = append(problems, SchemaValidationError{
+						Entity:     ent.Name,
+						Field:      name,
+						Detail:     fmt.Sprintf("auth rule compares claims with string fields, %q is %s", name, baseGoType(field)),
+						Suggestion: "Store owners and claim values in dsl.String, dsl.Text or dsl.UUID fields.",
+					})
+				}
+			}
+		}
+	}
+
 	if len(problems) == 0 {
 		return nil
 	}
 	return &SchemaValidationErrorList{Problems: problems}
 }
 
+func isIntegerGoType(goType string) bool {
+	switch goType {
+	case "int16", "int32", "int64":
+		return true
+	}
+	return false
+}
+
 func suggestEntityName(target string, metas map[string]entityMeta) string {
 	if len(metas) == 0 {
 		return ""
@@ -284,3 +388,12 @@ func isGeneratedInverse(edge dsl.Edge) bool {
 	}
 	return false
 }
+
+func policyOwnerField(ent Entity, name string) (dsl.Field, bool) {
+	for _, field := range ent.Fields {
+		if field.Name == name || fieldColumn(field) == name {
+			return field, true
+		}
+	}
+	return dsl.Field{}, false
+}
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/deicod/erm/orm/dsl"
 )
 
 func TestValidateEntitiesDetectsForeignKeyTypeMismatch(t *testing.T) {
@@ -68,3 +70,57 @@ func TestValidateEntitiesRequiresForeignKeyField(t *testing.T) {
 		t.Fatalf("expected suggestion to mention Post.Fields(), got %q", problem.Suggestion)
 	}
 }
+
+func TestValidateEntitiesRejectsNonIntegerVersionField(t *testing.T) {
+	entities := []Entity{{
+		Name:   "Post",
+		Fields: []dsl.Field{dsl.UUIDv7("id").Primary(), dsl.Text("revision").Version()},
+	}}
+
+	err := validateEntities(entities)
+	var validationErr *SchemaValidationErrorList
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected SchemaValidationErrorList, got %v", err)
+	}
+	if len(validationErr.Problems) != 1 {
+		t.Fatalf("expected 1 validation problem, got %d", len(validationErr.Problems))
+	}
+	if problem := validationErr.Problems[0]; problem.Field != "revision" || !strings.Contains(problem.Detail, "non-nullable integer") {
+		t.Fatalf("unexpected problem: %+v", problem)
+	}
+}
+
+func TestValidateEntitiesChecksAuthRuleFields(t *testing.T) {
+	entities := []Entity{{
+		Name: "Post",
+		Fields: []dsl.Field{
+			dsl.UUIDv7("id").Primary(),
+			dsl.Text("author_id"),
+			dsl.Integer("tenant_id"),
+			dsl.Text("notes").Authorize(dsl.AdminOnly().OrOwner("editor_id")),
+		},
+		Authorization: dsl.AuthRules{
+			Create: dsl.OwnerOnly("author_id"),
+			Read:   dsl.RequireAuth().WhereClaim("tenant", "tenant_id"),
+			Update: dsl.OwnerOnly("author_id"),
+		},
+	}}
+
+	err := validateEntities(entities)
+	var validationErr *SchemaValidationErrorList
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected SchemaValidationErrorList, got %v", err)
+	}
+	details := make([]string, len(validationErr.Problems))
+	for i, problem := range validationErr.Problems {
+		details[i] = problem.Detail
+	}
+	want := []string{
+		"create rule compares the viewer with a field of a record that does not exist yet",
+		`auth rule compares claims with string fields, "tenant_id" is int32`,
+		`auth rule compares the viewer with unknown field "editor_id"`,
+	}
+	if strings.Join(details, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected problems:\n%s", strings.Join(details, "\n"))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"github.com/deicod/erm/observability/metrics"
 	"github.com/deicod/erm/orm/gen"
+	"github.com/deicod/erm/orm/gen/user"
 )
 
 func configureEntityLoaders(loaders *Loaders, orm *gen.Client, collector metrics.Collector) {
@@ -12,15 +13,13 @@ func configureEntityLoaders(loaders *Loaders, orm *gen.Client, collector metrics
 		return
 	}
 	loaders.register("User", newEntityLoader[string, *gen.User]("users", collector, func(ctx context.Context, keys []string) (map[string]*gen.User, error) {
-		results := make(map[string]*gen.User, len(keys))
-		for _, key := range keys {
-			record, err := orm.Users().ByID(ctx, key)
-			if err != nil {
-				return nil, err
-			}
-			if record != nil {
-				results[key] = record
-			}
+		records, err := orm.Users().Query().Where(user.IDIn(keys...)).Limit(len(keys)).All(ctx)
+		if err != nil {
+			return nil, err
+		}
+		results := make(map[string]*gen.User, len(records))
+		for _, record := range records {
+			results[record.ID] = record
 		}
 		return results, nil
 	}))
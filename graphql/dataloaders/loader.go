@@ -2,6 +2,8 @@ package dataloaders
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -11,6 +13,10 @@ import (
 
 // Loaders aggregates entity-specific dataloaders.
 type Loaders struct {
+	orm       *gen.Client
+	collector metrics.Collector
+
+	mu      sync.Mutex
 	entries map[string]any
 }
 
@@ -19,7 +25,7 @@ func New(orm *gen.Client, collector metrics.Collector) *Loaders {
 	if collector == nil {
 		collector = metrics.NoopCollector{}
 	}
-	loaders := &Loaders{entries: make(map[string]any)}
+	loaders := &Loaders{orm: orm, collector: collector, entries: make(map[string]any)}
 	if orm == nil {
 		return loaders
 	}
@@ -31,6 +37,8 @@ func (l *Loaders) register(name string, loader any) {
 	if l == nil {
 		return
 	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	if l.entries == nil {
 		l.entries = make(map[string]any)
 	}
@@ -41,75 +49,278 @@ func (l *Loaders) get(name string) any {
 	if l == nil {
 		return nil
 	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	if l.entries == nil {
 		return nil
 	}
 	return l.entries[name]
 }
 
-// EntityLoader caches entities fetched by key with optional batching instrumentation.
+// getOrRegister returns the loader registered under name, registering the one built by create
+// on first use. Edge loaders are created lazily because their names include the page arguments.
+func (l *Loaders) getOrRegister(name string, create func() any) any {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if loader, ok := l.entries[name]; ok {
+		return loader
+	}
+	if l.entries == nil {
+		l.entries = make(map[string]any)
+	}
+	loader := create()
+	l.entries[name] = loader
+	return loader
+}
+
+// EdgePage is the page of an edge loaded for one parent. TotalCount is only set when the loader
+// was asked to count.
+type EdgePage[V any] struct {
+	Records    []V
+	TotalCount int
+}
+
+const (
+	// DefaultBatchWait is how long a loader collects keys before fetching them.
+	DefaultBatchWait = 2 * time.Millisecond
+	// DefaultMaxBatch caps the number of keys fetched together.
+	DefaultMaxBatch = 100
+)
+
+// LoaderOption configures batching for an EntityLoader.
+type LoaderOption func(*loaderConfig)
+
+type loaderConfig struct {
+	wait     time.Duration
+	maxBatch int
+}
+
+// WithBatchWait sets how long the loader collects keys before fetching them.
+func WithBatchWait(wait time.Duration) LoaderOption {
+	return func(cfg *loaderConfig) {
+		if wait >= 0 {
+			cfg.wait = wait
+		}
+	}
+}
+
+// WithMaxBatch caps the number of keys fetched together; a full batch is fetched without waiting.
+func WithMaxBatch(size int) LoaderOption {
+	return func(cfg *loaderConfig) {
+		if size > 0 {
+			cfg.maxBatch = size
+		}
+	}
+}
+
+// KeyErrors reports failures of individual keys. A fetch returning KeyErrors fails only the listed
+// keys; the others resolve from the returned values.
+type KeyErrors[K comparable] map[K]error
+
+func (e KeyErrors[K]) Error() string {
+	for key, err := range e {
+		return fmt.Sprintf("load %v: %v (and %d more)", key, err, len(e)-1)
+	}
+	return "no key errors"
+}
+
+// EntityLoader batches and caches entity lookups for a single request. Loads issued within the
+// batch wait window are fetched together, and concurrent loads of the same key share one fetch.
 type EntityLoader[K comparable, V any] struct {
 	name      string
 	fetch     func(context.Context, []K) (map[K]V, error)
 	collector metrics.Collector
+	config    loaderConfig
 
-	mu    sync.RWMutex
-	cache map[K]V
+	mu      sync.Mutex
+	cache   map[K]V
+	pending map[K]*loadCall[V]
+	batch   *loadBatch[K, V]
 }
 
-func newEntityLoader[K comparable, V any](name string, collector metrics.Collector, fetch func(context.Context, []K) (map[K]V, error)) *EntityLoader[K, V] {
+// loadCall is the shared result of one in-flight key.
+type loadCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type loadBatch[K comparable, V any] struct {
+	keys  []K
+	calls []*loadCall[V]
+	full  chan struct{}
+}
+
+func newEntityLoader[K comparable, V any](name string, collector metrics.Collector, fetch func(context.Context, []K) (map[K]V, error), opts ...LoaderOption) *EntityLoader[K, V] {
 	if collector == nil {
 		collector = metrics.NoopCollector{}
 	}
+	config := loaderConfig{wait: DefaultBatchWait, maxBatch: DefaultMaxBatch}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&config)
+		}
+	}
 	return &EntityLoader[K, V]{
 		name:      name,
 		fetch:     fetch,
 		collector: collector,
+		config:    config,
 		cache:     make(map[K]V),
+		pending:   make(map[K]*loadCall[V]),
 	}
 }
 
-// Load resolves an entity by key, caching successful lookups.
+// Load resolves an entity by key, caching successful lookups. Missing entities resolve to the zero
+// value without an error.
 func (l *EntityLoader[K, V]) Load(ctx context.Context, key K) (V, error) {
-	l.mu.RLock()
-	if val, ok := l.cache[key]; ok {
-		l.mu.RUnlock()
-		return val, nil
+	value, call := l.enqueue(ctx, key)
+	if call == nil {
+		return value, nil
 	}
-	l.mu.RUnlock()
+	return call.wait(ctx)
+}
 
-	start := time.Now()
-	values, err := l.fetch(ctx, []K{key})
-	duration := time.Since(start)
-	if err != nil {
-		var zero V
-		return zero, err
+// LoadMany resolves several keys in as few fetches as possible. values and errs follow the order of
+// keys; errs is nil when every key loaded.
+func (l *EntityLoader[K, V]) LoadMany(ctx context.Context, keys []K) ([]V, []error) {
+	values := make([]V, len(keys))
+	calls := make([]*loadCall[V], len(keys))
+	for idx, key := range keys {
+		values[idx], calls[idx] = l.enqueue(ctx, key)
+	}
+	var errs []error
+	for idx, call := range calls {
+		if call == nil {
+			continue
+		}
+		value, err := call.wait(ctx)
+		if err != nil {
+			if errs == nil {
+				errs = make([]error, len(keys))
+			}
+			errs[idx] = err
+			continue
+		}
+		values[idx] = value
 	}
-	l.collector.RecordDataloaderBatch(l.name, len(values), duration)
+	return values, errs
+}
+
+// Prime seeds the cache with known results to avoid duplicate fetches.
+func (l *EntityLoader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	l.cache[key] = value
+	delete(l.pending, key)
+	l.mu.Unlock()
+}
+
+// Clear drops key from the cache so the next load fetches it again. Mutations call it for the rows
+// they change.
+func (l *EntityLoader[K, V]) Clear(key K) {
+	l.mu.Lock()
+	delete(l.cache, key)
+	delete(l.pending, key)
+	l.mu.Unlock()
+}
+
+// ClearAll empties the cache.
+func (l *EntityLoader[K, V]) ClearAll() {
+	l.mu.Lock()
+	l.cache = make(map[K]V)
+	l.pending = make(map[K]*loadCall[V])
+	l.mu.Unlock()
+}
 
+// enqueue returns the cached value of key or the call that resolves it, adding key to the open
+// batch unless a load of it is already in flight.
+func (l *EntityLoader[K, V]) enqueue(ctx context.Context, key K) (V, *loadCall[V]) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if value, ok := l.cache[key]; ok {
+		return value, nil
+	}
 	var zero V
-	val, ok := values[key]
-	if !ok {
-		return zero, nil
+	if call, ok := l.pending[key]; ok {
+		return zero, call
+	}
+	call := &loadCall[V]{done: make(chan struct{})}
+	l.pending[key] = call
+	batch := l.batch
+	if batch == nil {
+		batch = &loadBatch[K, V]{full: make(chan struct{})}
+		l.batch = batch
+		go l.dispatch(context.WithoutCancel(ctx), batch)
 	}
+	batch.keys = append(batch.keys, key)
+	batch.calls = append(batch.calls, call)
+	if len(batch.keys) >= l.config.maxBatch {
+		l.batch = nil
+		close(batch.full)
+	}
+	return zero, call
+}
 
+// dispatch fetches batch once the wait window closes or the batch fills up. The batch serves every
+// caller that joined it, so ctx keeps the values of the first caller but not its cancellation;
+// each caller stops waiting when its own context ends.
+func (l *EntityLoader[K, V]) dispatch(ctx context.Context, batch *loadBatch[K, V]) {
+	timer := time.NewTimer(l.config.wait)
+	select {
+	case <-timer.C:
+	case <-batch.full:
+		timer.Stop()
+	}
 	l.mu.Lock()
-	l.cache[key] = val
+	if l.batch == batch {
+		l.batch = nil
+	}
 	l.mu.Unlock()
 
-	return val, nil
-}
+	start := time.Now()
+	values, err := l.fetch(ctx, batch.keys)
+	l.collector.RecordDataloaderBatch(l.name, len(batch.keys), time.Since(start))
 
-// Prime seeds the cache with known results to avoid duplicate fetches.
-func (l *EntityLoader[K, V]) Prime(key K, value V) {
+	var keyErrs KeyErrors[K]
+	if errors.As(err, &keyErrs) {
+		err = nil
+	}
 	l.mu.Lock()
-	l.cache[key] = value
+	for idx, key := range batch.keys {
+		call := batch.calls[idx]
+		switch {
+		case err != nil:
+			call.err = err
+		case keyErrs[key] != nil:
+			call.err = keyErrs[key]
+		default:
+			call.value = values[key]
+		}
+		// A key primed or cleared while in flight keeps that state instead of this result.
+		if l.pending[key] == call {
+			delete(l.pending, key)
+			if _, ok := values[key]; ok && call.err == nil {
+				l.cache[key] = call.value
+			}
+		}
+		close(call.done)
+	}
 	l.mu.Unlock()
 }
 
+func (c *loadCall[V]) wait(ctx context.Context) (V, error) {
+	select {
+	case <-c.done:
+		return c.value, c.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
 // NewEntityLoader exposes loader construction for testing and advanced customization.
-func NewEntityLoader[K comparable, V any](name string, collector metrics.Collector, fetch func(context.Context, []K) (map[K]V, error)) *EntityLoader[K, V] {
-	return newEntityLoader(name, collector, fetch)
+func NewEntityLoader[K comparable, V any](name string, collector metrics.Collector, fetch func(context.Context, []K) (map[K]V, error), opts ...LoaderOption) *EntityLoader[K, V] {
+	return newEntityLoader(name, collector, fetch, opts...)
 }
 
 // contextKey isolates loader storage on context.
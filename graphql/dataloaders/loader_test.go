@@ -2,6 +2,9 @@ package dataloaders_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -77,3 +80,142 @@ func TestEntityLoaderPrime(t *testing.T) {
 		t.Fatalf("unexpected primed value: %q", value)
 	}
 }
+
+func TestEntityLoaderBatchesConcurrentLoads(t *testing.T) {
+	var fetches [][]string
+	var mu sync.Mutex
+	collector := &testCollector{}
+	loader := dataloaders.NewEntityLoader("test", collector, func(_ context.Context, keys []string) (map[string]string, error) {
+		mu.Lock()
+		fetches = append(fetches, append([]string(nil), keys...))
+		mu.Unlock()
+		out := make(map[string]string, len(keys))
+		for _, key := range keys {
+			out[key] = key + "-value"
+		}
+		return out, nil
+	}, dataloaders.WithBatchWait(20*time.Millisecond))
+
+	ctx := context.Background()
+	keys := []string{"a", "b", "c", "a"}
+	values := make([]string, len(keys))
+	var wg sync.WaitGroup
+	for idx, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := loader.Load(ctx, key)
+			if err != nil {
+				t.Errorf("load %s: %v", key, err)
+			}
+			values[idx] = value
+		}()
+	}
+	wg.Wait()
+
+	for idx, key := range keys {
+		if values[idx] != key+"-value" {
+			t.Fatalf("unexpected value for %s: %q", key, values[idx])
+		}
+	}
+	if len(fetches) != 1 || len(fetches[0]) != 3 {
+		t.Fatalf("expected one fetch of three distinct keys, got %v", fetches)
+	}
+	if total := atomic.LoadInt32(&collector.total); total != 3 {
+		t.Fatalf("expected metrics batch size 3, got %d", total)
+	}
+}
+
+func TestEntityLoaderMaxBatch(t *testing.T) {
+	var sizes []int
+	loader := dataloaders.NewEntityLoader("test", nil, func(_ context.Context, keys []string) (map[string]string, error) {
+		sizes = append(sizes, len(keys))
+		return map[string]string{}, nil
+	}, dataloaders.WithBatchWait(time.Hour), dataloaders.WithMaxBatch(2))
+
+	// A full batch is fetched without waiting for the window, so this returns promptly.
+	values, errs := loader.LoadMany(context.Background(), []string{"a", "b"})
+	if errs != nil {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(values) != 2 || values[0] != "" || values[1] != "" {
+		t.Fatalf("expected zero values for missing keys, got %q", values)
+	}
+	if len(sizes) != 1 || sizes[0] != 2 {
+		t.Fatalf("unexpected batch sizes: %v", sizes)
+	}
+}
+
+func TestEntityLoaderBatchOutlivesCanceledCaller(t *testing.T) {
+	loader := dataloaders.NewEntityLoader("test", nil, func(ctx context.Context, keys []string) (map[string]string, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		out := make(map[string]string, len(keys))
+		for _, key := range keys {
+			out[key] = key + "-value"
+		}
+		return out, nil
+	}, dataloaders.WithBatchWait(time.Hour), dataloaders.WithMaxBatch(2))
+
+	// The first caller starts the batch and gives up; the second fills it and still gets its value.
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := loader.Load(canceled, "a"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the canceled caller to stop waiting, got %v", err)
+	}
+	value, err := loader.Load(context.Background(), "b")
+	if err != nil || value != "b-value" {
+		t.Fatalf("expected the batch to be fetched for the second caller, got %q (err %v)", value, err)
+	}
+}
+
+func TestEntityLoaderLoadManyKeyErrors(t *testing.T) {
+	errBroken := errors.New("broken")
+	var calls int32
+	loader := dataloaders.NewEntityLoader("test", nil, func(_ context.Context, keys []string) (map[string]string, error) {
+		atomic.AddInt32(&calls, 1)
+		return map[string]string{"ok": "fine"}, dataloaders.KeyErrors[string]{"bad": errBroken}
+	})
+
+	ctx := context.Background()
+	values, errs := loader.LoadMany(ctx, []string{"ok", "bad"})
+	if values[0] != "fine" {
+		t.Fatalf("unexpected value: %q", values[0])
+	}
+	if errs == nil || errs[0] != nil || !errors.Is(errs[1], errBroken) {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	// Successful keys are cached, failed keys are fetched again.
+	if _, err := loader.Load(ctx, "ok"); err != nil {
+		t.Fatalf("load ok: %v", err)
+	}
+	if _, err := loader.Load(ctx, "bad"); !errors.Is(err, errBroken) {
+		t.Fatalf("expected key error, got %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("expected 2 fetches, got %d", got)
+	}
+}
+
+func TestEntityLoaderClear(t *testing.T) {
+	var calls int32
+	loader := dataloaders.NewEntityLoader("test", nil, func(_ context.Context, keys []string) (map[string]string, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return map[string]string{keys[0]: fmt.Sprintf("v%d", n)}, nil
+	})
+
+	ctx := context.Background()
+	if value, _ := loader.Load(ctx, "a"); value != "v1" {
+		t.Fatalf("unexpected value: %q", value)
+	}
+	loader.Clear("a")
+	if value, _ := loader.Load(ctx, "a"); value != "v2" {
+		t.Fatalf("expected refetch after Clear, got %q", value)
+	}
+	loader.ClearAll()
+	if value, _ := loader.Load(ctx, "a"); value != "v3" {
+		t.Fatalf("expected refetch after ClearAll, got %q", value)
+	}
+}
@@ -3,9 +3,13 @@ package directives
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/vektah/gqlparser/v2/gqlerror"
 
+	"github.com/deicod/erm/graphql/relay"
 	"github.com/deicod/erm/oidc"
 )
 
@@ -36,3 +40,170 @@ func RequireAuth() func(ctx context.Context, obj any, next func(ctx context.Cont
 		return next(ctx)
 	}
 }
+
+// Rule is the runtime form of a dsl.AuthRule. A viewer passes when every condition holds and
+// they hold all Roles or, when Owner is set, their subject equals the Owner field of the record.
+// Without Roles, only the owner passes.
+type Rule struct {
+	Roles      []string
+	Owner      string
+	Conditions []Condition
+}
+
+// Condition requires a claim of the viewer to equal Field of the record or, when Field is empty,
+// Value. The subject and email are read from their Claims fields, other claims from Claims.Raw.
+type Condition struct {
+	Claim string
+	Field string
+	Value string
+}
+
+// Match requires Field of a record to equal Value.
+type Match struct {
+	Field string
+	Value string
+}
+
+// Scope checks the viewer in ctx against the parts of rule that do not depend on a record and
+// returns the matches a record must satisfy for the viewer to reach it, e.g. to filter queries.
+// The owner match is left out for viewers holding the roles of the rule.
+func Scope(ctx context.Context, rule Rule) ([]Match, error) {
+	claims, ok := oidc.FromContext(ctx)
+	if !ok {
+		return nil, gqlerror.Errorf("unauthorized")
+	}
+	var matches []Match
+	for _, cond := range rule.Conditions {
+		value, ok := claimValue(claims, cond.Claim)
+		if !ok {
+			return nil, gqlerror.Errorf("forbidden: missing claim %s", cond.Claim)
+		}
+		if cond.Field != "" {
+			matches = append(matches, Match{Field: cond.Field, Value: value})
+			continue
+		}
+		if value != cond.Value {
+			return nil, gqlerror.Errorf("forbidden: claim %s does not match", cond.Claim)
+		}
+	}
+	missing := missingRole(claims, rule.Roles)
+	if rule.Owner == "" {
+		if missing != "" {
+			return nil, gqlerror.Errorf("forbidden: missing role %s", missing)
+		}
+		return matches, nil
+	}
+	if len(rule.Roles) > 0 && missing == "" {
+		return matches, nil
+	}
+	if claims.Subject == "" {
+		return nil, gqlerror.Errorf("forbidden: missing subject")
+	}
+	return append(matches, Match{Field: rule.Owner, Value: claims.Subject}), nil
+}
+
+// Authorize checks the viewer in ctx against rule for record, a struct or pointer to a struct
+// whose fields are matched to the fields of the rule by name, ignoring case and underscores.
+func Authorize(ctx context.Context, rule Rule, record any) error {
+	return authorize(ctx, rule, record)
+}
+
+// RequireOwner returns a directive handler admitting viewers holding roles or owning obj, the
+// object of the guarded field, through its owner field.
+func RequireOwner(roles []string, owner string) func(ctx context.Context, obj any, next func(ctx context.Context) (res any, err error)) (any, error) {
+	return requireRule(Rule{Roles: roles, Owner: owner})
+}
+
+// RequireClaim returns a directive handler requiring cond to hold for the viewer and obj, the
+// object of the guarded field.
+func RequireClaim(cond Condition) func(ctx context.Context, obj any, next func(ctx context.Context) (res any, err error)) (any, error) {
+	return requireRule(Rule{Conditions: []Condition{cond}})
+}
+
+func requireRule(rule Rule) func(ctx context.Context, obj any, next func(ctx context.Context) (res any, err error)) (any, error) {
+	return func(ctx context.Context, obj any, next func(ctx context.Context) (res any, err error)) (any, error) {
+		if err := authorize(ctx, rule, obj); err != nil {
+			return nil, err
+		}
+		return next(ctx)
+	}
+}
+
+func authorize(ctx context.Context, rule Rule, record any) error {
+	matches, err := Scope(ctx, rule)
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		if value, ok := recordValue(record, match.Field); ok && value == match.Value {
+			continue
+		}
+		if match.Field == rule.Owner {
+			return gqlerror.Errorf("forbidden: not the owner")
+		}
+		return gqlerror.Errorf("forbidden: %s does not match the viewer", match.Field)
+	}
+	return nil
+}
+
+func missingRole(claims oidc.Claims, roles []string) string {
+	for _, required := range roles {
+		if !slices.Contains(claims.Roles, required) {
+			return required
+		}
+	}
+	return ""
+}
+
+func claimValue(claims oidc.Claims, name string) (string, bool) {
+	switch {
+	case name == "sub" && claims.Subject != "":
+		return claims.Subject, true
+	case name == "email" && claims.Email != "":
+		return claims.Email, true
+	}
+	raw, ok := claims.Raw[name]
+	if !ok || raw == nil {
+		return "", false
+	}
+	if value, ok := raw.(string); ok {
+		return value, true
+	}
+	return fmt.Sprint(raw), true
+}
+
+// recordValue reads field of record as a string. Unset optional fields report false. GraphQL
+// objects carry relay global IDs, which are read as their native ID.
+func recordValue(record any, field string) (string, bool) {
+	value := reflect.ValueOf(record)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return "", false
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return "", false
+	}
+	name := strings.ReplaceAll(field, "_", "")
+	structField, ok := value.Type().FieldByNameFunc(func(candidate string) bool {
+		return strings.EqualFold(candidate, name)
+	})
+	if !ok || !structField.IsExported() {
+		return "", false
+	}
+	value = value.FieldByIndex(structField.Index)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return "", false
+		}
+		value = value.Elem()
+	}
+	str := fmt.Sprint(value.Interface())
+	if structField.Name == "ID" {
+		if _, id, err := relay.FromGlobalID(str); err == nil {
+			str = id
+		}
+	}
+	return str, true
+}
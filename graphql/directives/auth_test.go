@@ -0,0 +1,101 @@
+package directives
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/deicod/erm/graphql/relay"
+	"github.com/deicod/erm/oidc"
+)
+
+type post struct {
+	ID       string
+	AuthorID string
+	TenantID *string
+}
+
+func TestScope(t *testing.T) {
+	rule := Rule{
+		Roles:      []string{"admin"},
+		Owner:      "author_id",
+		Conditions: []Condition{{Claim: "tenant", Field: "tenant_id"}, {Claim: "tier", Value: "gold"}},
+	}
+	viewer := oidc.Claims{Subject: "user-1", Raw: map[string]any{"tenant": "acme", "tier": "gold"}}
+
+	matches, err := Scope(oidc.ToContext(context.Background(), viewer), rule)
+	if err != nil {
+		t.Fatalf("Scope: %v", err)
+	}
+	want := []Match{{Field: "tenant_id", Value: "acme"}, {Field: "author_id", Value: "user-1"}}
+	if !reflect.DeepEqual(matches, want) {
+		t.Fatalf("unexpected matches: %+v", matches)
+	}
+
+	admin := viewer
+	admin.Roles = []string{"admin"}
+	matches, err = Scope(oidc.ToContext(context.Background(), admin), rule)
+	if err != nil {
+		t.Fatalf("Scope: %v", err)
+	}
+	if !reflect.DeepEqual(matches, want[:1]) {
+		t.Fatalf("expected admins to skip the owner match, got %+v", matches)
+	}
+
+	cases := map[string]oidc.Claims{
+		"missing claim tenant":       {Subject: "user-1", Raw: map[string]any{"tier": "gold"}},
+		"claim tier does not match":  {Subject: "user-1", Raw: map[string]any{"tenant": "acme", "tier": "silver"}},
+		"forbidden: missing subject": {Raw: map[string]any{"tenant": "acme", "tier": "gold"}},
+	}
+	for want, claims := range cases {
+		if _, err := Scope(oidc.ToContext(context.Background(), claims), rule); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q, got %v", want, err)
+		}
+	}
+	if _, err := Scope(context.Background(), rule); err == nil || !strings.HasSuffix(err.Error(), "unauthorized") {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	tenant := "acme"
+	record := &post{ID: "post-1", AuthorID: "user-1", TenantID: &tenant}
+	rule := Rule{Owner: "author_id", Conditions: []Condition{{Claim: "tenant", Field: "tenant_id"}}}
+	ctx := func(subject, tenant string) context.Context {
+		return oidc.ToContext(context.Background(), oidc.Claims{Subject: subject, Raw: map[string]any{"tenant": tenant}})
+	}
+
+	if err := Authorize(ctx("user-1", "acme"), rule, record); err != nil {
+		t.Fatalf("expected the owner to pass, got %v", err)
+	}
+	if err := Authorize(ctx("user-2", "acme"), rule, record); err == nil || !strings.HasSuffix(err.Error(), "forbidden: not the owner") {
+		t.Fatalf("expected owner error, got %v", err)
+	}
+	if err := Authorize(ctx("user-1", "other"), rule, record); err == nil || !strings.HasSuffix(err.Error(), "forbidden: tenant_id does not match the viewer") {
+		t.Fatalf("expected tenant error, got %v", err)
+	}
+	record.TenantID = nil
+	if err := Authorize(ctx("user-1", "acme"), rule, record); err == nil {
+		t.Fatalf("expected unset tenant to fail")
+	}
+}
+
+func TestRequireOwnerMatchesGlobalIDs(t *testing.T) {
+	handler := RequireOwner([]string{"admin"}, "id")
+	obj := &post{ID: relay.ToGlobalID("User", "user-1")}
+	next := func(ctx context.Context) (any, error) { return "email", nil }
+
+	owner := oidc.ToContext(context.Background(), oidc.Claims{Subject: "user-1"})
+	if res, err := handler(owner, obj, next); err != nil || res != "email" {
+		t.Fatalf("expected the owner to resolve the field, got %v, %v", res, err)
+	}
+	other := oidc.ToContext(context.Background(), oidc.Claims{Subject: "user-2"})
+	if _, err := handler(other, obj, next); err == nil {
+		t.Fatalf("expected other viewers to be rejected")
+	}
+	admin := oidc.ToContext(context.Background(), oidc.Claims{Subject: "user-2", Roles: []string{"admin"}})
+	if _, err := handler(admin, obj, next); err != nil {
+		t.Fatalf("expected admins to resolve the field, got %v", err)
+	}
+}
@@ -3,6 +3,10 @@
 package graphql
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
 	"time"
 )
 
@@ -19,8 +23,9 @@ type CreateUserInput struct {
 }
 
 type CreateUserPayload struct {
-	ClientMutationID *string `json:"clientMutationId,omitempty"`
-	User             *User   `json:"user,omitempty"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+	User             *User        `json:"user,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
 }
 
 type DeleteUserInput struct {
@@ -57,8 +62,9 @@ type UpdateUserInput struct {
 }
 
 type UpdateUserPayload struct {
-	ClientMutationID *string `json:"clientMutationId,omitempty"`
-	User             *User   `json:"user,omitempty"`
+	ClientMutationID *string      `json:"clientMutationId,omitempty"`
+	User             *User        `json:"user,omitempty"`
+	UserErrors       []*UserError `json:"userErrors"`
 }
 
 type User struct {
@@ -80,3 +86,147 @@ type UserEdge struct {
 	Cursor string `json:"cursor"`
 	Node   *User  `json:"node,omitempty"`
 }
+
+type UserError struct {
+	Field   []string `json:"field,omitempty"`
+	Code    string   `json:"code"`
+	Message string   `json:"message"`
+}
+
+type UserOrder struct {
+	Field     UserOrderField `json:"field"`
+	Direction OrderDirection `json:"direction"`
+}
+
+type UserWhereInput struct {
+	Not          *UserWhereInput   `json:"not,omitempty"`
+	And          []*UserWhereInput `json:"and,omitempty"`
+	Or           []*UserWhereInput `json:"or,omitempty"`
+	ID           *string           `json:"id,omitempty"`
+	IDNeq        *string           `json:"idNeq,omitempty"`
+	IDIn         []string          `json:"idIn,omitempty"`
+	CreatedAt    *time.Time        `json:"createdAt,omitempty"`
+	CreatedAtNeq *time.Time        `json:"createdAtNeq,omitempty"`
+	CreatedAtIn  []*time.Time      `json:"createdAtIn,omitempty"`
+	CreatedAtGt  *time.Time        `json:"createdAtGt,omitempty"`
+	CreatedAtGte *time.Time        `json:"createdAtGte,omitempty"`
+	CreatedAtLt  *time.Time        `json:"createdAtLt,omitempty"`
+	CreatedAtLte *time.Time        `json:"createdAtLte,omitempty"`
+	UpdatedAt    *time.Time        `json:"updatedAt,omitempty"`
+	UpdatedAtNeq *time.Time        `json:"updatedAtNeq,omitempty"`
+	UpdatedAtIn  []*time.Time      `json:"updatedAtIn,omitempty"`
+	UpdatedAtGt  *time.Time        `json:"updatedAtGt,omitempty"`
+	UpdatedAtGte *time.Time        `json:"updatedAtGte,omitempty"`
+	UpdatedAtLt  *time.Time        `json:"updatedAtLt,omitempty"`
+	UpdatedAtLte *time.Time        `json:"updatedAtLte,omitempty"`
+}
+
+type OrderDirection string
+
+const (
+	OrderDirectionAsc  OrderDirection = "ASC"
+	OrderDirectionDesc OrderDirection = "DESC"
+)
+
+var AllOrderDirection = []OrderDirection{
+	OrderDirectionAsc,
+	OrderDirectionDesc,
+}
+
+func (e OrderDirection) IsValid() bool {
+	switch e {
+	case OrderDirectionAsc, OrderDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e OrderDirection) String() string {
+	return string(e)
+}
+
+func (e *OrderDirection) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OrderDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OrderDirection", str)
+	}
+	return nil
+}
+
+func (e OrderDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *OrderDirection) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e OrderDirection) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type UserOrderField string
+
+const (
+	UserOrderFieldID        UserOrderField = "ID"
+	UserOrderFieldCreatedAt UserOrderField = "CREATED_AT"
+)
+
+var AllUserOrderField = []UserOrderField{
+	UserOrderFieldID,
+	UserOrderFieldCreatedAt,
+}
+
+func (e UserOrderField) IsValid() bool {
+	switch e {
+	case UserOrderFieldID, UserOrderFieldCreatedAt:
+		return true
+	}
+	return false
+}
+
+func (e UserOrderField) String() string {
+	return string(e)
+}
+
+func (e *UserOrderField) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = UserOrderField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid UserOrderField", str)
+	}
+	return nil
+}
+
+func (e UserOrderField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *UserOrderField) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e UserOrderField) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
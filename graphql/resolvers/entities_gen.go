@@ -3,11 +3,16 @@ package resolvers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	gql "github.com/99designs/gqlgen/graphql"
 	"github.com/deicod/erm/graphql"
 	"github.com/deicod/erm/graphql/dataloaders"
 	"github.com/deicod/erm/graphql/relay"
 	"github.com/deicod/erm/orm/gen"
+	"github.com/deicod/erm/orm/gen/user"
+	"reflect"
+	"time"
 )
 
 type entityHooks struct {
@@ -92,6 +97,86 @@ func (r *queryResolver) Node(ctx context.Context, id string) (graphql.Node, erro
 	}
 }
 
+// pageWindow is the validated form of the Relay connection arguments first, after, last and before.
+type pageWindow struct {
+	limit  int
+	after  string
+	before string
+	last   bool
+}
+
+func newPageWindow(first *int, after *string, last *int, before *string) (pageWindow, error) {
+	if first != nil && last != nil {
+		return pageWindow{}, fmt.Errorf("first and last cannot be combined")
+	}
+	window := pageWindow{limit: defaultPageSize}
+	if first != nil {
+		if *first < 0 {
+			return pageWindow{}, fmt.Errorf("first must not be negative")
+		}
+		window.limit = *first
+	}
+	if last != nil {
+		if *last < 0 {
+			return pageWindow{}, fmt.Errorf("last must not be negative")
+		}
+		window.limit, window.last = *last, true
+	}
+	if after != nil {
+		window.after = *after
+	}
+	if before != nil {
+		window.before = *before
+	}
+	return window, nil
+}
+
+// trimPage drops the extra row fetched beyond the window's limit, which only tells whether more
+// rows exist in the paging direction.
+func trimPage[T any](records []T, window pageWindow) ([]T, bool) {
+	if len(records) <= window.limit {
+		return records, false
+	}
+	if window.last {
+		return records[len(records)-window.limit:], true
+	}
+	return records[:window.limit], true
+}
+
+func (w pageWindow) pageInfo(more bool, startCursor, endCursor *string) *graphql.PageInfo {
+	info := &graphql.PageInfo{StartCursor: startCursor, EndCursor: endCursor}
+	if w.last {
+		info.HasPreviousPage, info.HasNextPage = more, w.before != ""
+	} else {
+		info.HasNextPage, info.HasPreviousPage = more, w.after != ""
+	}
+	return info
+}
+
+// pageKey identifies a set of connection arguments, so that parents paged alike share an edge
+// dataloader.
+func pageKey(args ...any) string {
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Sprint(args...)
+	}
+	return string(raw)
+}
+
+// fieldSelected reports whether the current field's selection set requests name. Outside a
+// GraphQL operation every field counts as selected.
+func fieldSelected(ctx context.Context, name string) bool {
+	if !gql.HasOperationContext(ctx) || gql.GetFieldContext(ctx) == nil {
+		return true
+	}
+	for _, field := range gql.CollectAllFields(ctx) {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *Resolver) loadUser(ctx context.Context, id string) (*gen.User, error) {
 	if r == nil || r.ORM == nil {
 		return nil, nil
@@ -101,7 +186,7 @@ func (r *Resolver) loadUser(ctx context.Context, id string) (*gen.User, error) {
 			return loader.Load(ctx, id)
 		}
 	}
-	return r.ORM.Users().ByID(ctx, id)
+	return nilIfNotFound(r.ORM.Users().ByID(ctx, id))
 }
 
 func (r *Resolver) primeUser(ctx context.Context, record *gen.User) {
@@ -115,6 +200,14 @@ func (r *Resolver) primeUser(ctx context.Context, record *gen.User) {
 	}
 }
 
+func (r *Resolver) clearUser(ctx context.Context, id string) {
+	if loaders := dataloaders.FromContext(ctx); loaders != nil {
+		if loader := loaders.User(); loader != nil {
+			loader.Clear(id)
+		}
+	}
+}
+
 func toGraphQLUser(record *gen.User) *graphql.User {
 	if record == nil {
 		return nil
@@ -140,6 +233,140 @@ func decodeUserID(id string) (string, error) {
 	return nativeID, nil
 }
 
+// userWherePredicates converts where into predicates for UserQuery.Where.
+func userWherePredicates(ctx context.Context, where *graphql.UserWhereInput) ([]user.Predicate, error) {
+	if where == nil {
+		return nil, nil
+	}
+	var preds []user.Predicate
+	if where.Not != nil {
+		not, err := userWherePredicates(ctx, where.Not)
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, user.Not(user.And(not...)))
+	}
+	for _, item := range where.And {
+		and, err := userWherePredicates(ctx, item)
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, and...)
+	}
+	if where.Or != nil {
+		or := make([]user.Predicate, 0, len(where.Or))
+		for _, item := range where.Or {
+			and, err := userWherePredicates(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+			or = append(or, user.And(and...))
+		}
+		preds = append(preds, user.Or(or...))
+	}
+	if where.ID != nil {
+		nativeID, err := decodeUserID(*where.ID)
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, user.IDEq(nativeID))
+	}
+	if where.IDNeq != nil {
+		nativeID, err := decodeUserID(*where.IDNeq)
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, user.IDNotEq(nativeID))
+	}
+	if where.IDIn != nil {
+		values := make([]string, len(where.IDIn))
+		for idx, value := range where.IDIn {
+			nativeID, err := decodeUserID(value)
+			if err != nil {
+				return nil, err
+			}
+			values[idx] = nativeID
+		}
+		preds = append(preds, user.IDIn(values...))
+	}
+	if where.CreatedAt != nil {
+		preds = append(preds, user.CreatedAtEq(*where.CreatedAt))
+	}
+	if where.CreatedAtNeq != nil {
+		preds = append(preds, user.CreatedAtNotEq(*where.CreatedAtNeq))
+	}
+	if where.CreatedAtIn != nil {
+		values := make([]time.Time, len(where.CreatedAtIn))
+		for idx, value := range where.CreatedAtIn {
+			values[idx] = *value
+		}
+		preds = append(preds, user.CreatedAtIn(values...))
+	}
+	if where.CreatedAtGt != nil {
+		preds = append(preds, user.CreatedAtGT(*where.CreatedAtGt))
+	}
+	if where.CreatedAtGte != nil {
+		preds = append(preds, user.CreatedAtGTE(*where.CreatedAtGte))
+	}
+	if where.CreatedAtLt != nil {
+		preds = append(preds, user.CreatedAtLT(*where.CreatedAtLt))
+	}
+	if where.CreatedAtLte != nil {
+		preds = append(preds, user.CreatedAtLTE(*where.CreatedAtLte))
+	}
+	if where.UpdatedAt != nil {
+		preds = append(preds, user.UpdatedAtEq(*where.UpdatedAt))
+	}
+	if where.UpdatedAtNeq != nil {
+		preds = append(preds, user.UpdatedAtNotEq(*where.UpdatedAtNeq))
+	}
+	if where.UpdatedAtIn != nil {
+		values := make([]time.Time, len(where.UpdatedAtIn))
+		for idx, value := range where.UpdatedAtIn {
+			values[idx] = *value
+		}
+		preds = append(preds, user.UpdatedAtIn(values...))
+	}
+	if where.UpdatedAtGt != nil {
+		preds = append(preds, user.UpdatedAtGT(*where.UpdatedAtGt))
+	}
+	if where.UpdatedAtGte != nil {
+		preds = append(preds, user.UpdatedAtGTE(*where.UpdatedAtGte))
+	}
+	if where.UpdatedAtLt != nil {
+		preds = append(preds, user.UpdatedAtLT(*where.UpdatedAtLt))
+	}
+	if where.UpdatedAtLte != nil {
+		preds = append(preds, user.UpdatedAtLTE(*where.UpdatedAtLte))
+	}
+	return preds, nil
+}
+
+// userOrders converts orderBy into sort keys for UserQuery.Order.
+func userOrders(orderBy []*graphql.UserOrder) ([]user.Order, error) {
+	if len(orderBy) == 0 {
+		return []user.Order{user.Asc(user.FieldID)}, nil
+	}
+	orders := make([]user.Order, len(orderBy))
+	for idx, order := range orderBy {
+		var column string
+		switch order.Field {
+		case "ID":
+			column = user.FieldID
+		case "CREATED_AT":
+			column = user.FieldCreatedAt
+		default:
+			return nil, fmt.Errorf("unsupported UserOrderField %q", order.Field)
+		}
+		if order.Direction == graphql.OrderDirectionDesc {
+			orders[idx] = user.Desc(column)
+		} else {
+			orders[idx] = user.Asc(column)
+		}
+	}
+	return orders, nil
+}
+
 func (r *queryResolver) User(ctx context.Context, id string) (*graphql.User, error) {
 	nativeID, err := decodeUserID(id)
 	if err != nil {
@@ -155,34 +382,75 @@ func (r *queryResolver) User(ctx context.Context, id string) (*graphql.User, err
 	return toGraphQLUser(record), nil
 }
 
-func (r *queryResolver) Users(ctx context.Context, first *int, after *string, last *int, before *string) (*graphql.UserConnection, error) {
+func (r *queryResolver) Users(ctx context.Context, first *int, after *string, last *int, before *string, where *graphql.UserWhereInput, orderBy []*graphql.UserOrder) (*graphql.UserConnection, error) {
 	if r.ORM == nil {
 		return nil, fmt.Errorf("orm client is not configured")
 	}
-	if last != nil || before != nil {
-		return nil, fmt.Errorf("backward pagination is not supported")
+	return r.paginateUsers(ctx, r.ORM.Users().Query, first, after, last, before, where, orderBy)
+}
+
+// paginateUsers pages through the rows of newQuery, which is called once for the page and once
+// more when totalCount is selected.
+func (r *Resolver) paginateUsers(ctx context.Context, newQuery func() *gen.UserQuery, first *int, after *string, last *int, before *string, where *graphql.UserWhereInput, orderBy []*graphql.UserOrder) (*graphql.UserConnection, error) {
+	window, preds, query, err := userPage(ctx, newQuery(), first, after, last, before, where, orderBy)
+	if err != nil {
+		return nil, err
 	}
-	limit := defaultPageSize
-	if first != nil && *first > 0 {
-		limit = *first
+	records, err := query.All(ctx)
+	if err != nil {
+		return nil, err
 	}
-	offset := 0
-	if after != nil && *after != "" {
-		if decoded, err := decodeCursor(*after); err == nil {
-			offset = decoded + 1
+	connection, err := r.userConnection(ctx, query, window, records)
+	if err != nil {
+		return nil, err
+	}
+	if fieldSelected(ctx, "totalCount") {
+		total, err := newQuery().Where(preds...).Count(ctx)
+		if err != nil {
+			return nil, err
 		}
+		connection.TotalCount = total
 	}
-	total, err := r.ORM.Users().Count(ctx)
+	return connection, nil
+}
+
+// userPage narrows query to the page selected by the connection arguments, reading one row
+// beyond it. The where predicates are returned for counting the connection.
+func userPage(ctx context.Context, query *gen.UserQuery, first *int, after *string, last *int, before *string, where *graphql.UserWhereInput, orderBy []*graphql.UserOrder) (pageWindow, []user.Predicate, *gen.UserQuery, error) {
+	window, err := newPageWindow(first, after, last, before)
 	if err != nil {
-		return nil, err
+		return window, nil, nil, err
 	}
-	records, err := r.ORM.Users().List(ctx, limit, offset)
+	preds, err := userWherePredicates(ctx, where)
 	if err != nil {
-		return nil, err
+		return window, nil, nil, err
 	}
+	orders, err := userOrders(orderBy)
+	if err != nil {
+		return window, nil, nil, err
+	}
+	query.
+		Where(preds...).
+		Order(orders...).
+		After(window.after).
+		Before(window.before)
+	if window.last {
+		query.Last(window.limit + 1)
+	} else {
+		query.Limit(window.limit + 1)
+	}
+	return window, preds, query, nil
+}
+
+// userConnection builds the connection of records, the rows read for window by query.
+func (r *Resolver) userConnection(ctx context.Context, query *gen.UserQuery, window pageWindow, records []*gen.User) (*graphql.UserConnection, error) {
+	records, more := trimPage(records, window)
 	edges := make([]*graphql.UserEdge, len(records))
 	for idx, record := range records {
-		cursor := encodeCursor(offset + idx)
+		cursor, err := query.Cursor(record)
+		if err != nil {
+			return nil, err
+		}
 		if err := r.applyBeforeReturnUser(ctx, record); err != nil {
 			return nil, err
 		}
@@ -194,28 +462,52 @@ func (r *queryResolver) Users(ctx context.Context, first *int, after *string, la
 	}
 	var startCursor, endCursor *string
 	if len(edges) > 0 {
-		sc := edges[0].Cursor
-		ec := edges[len(edges)-1].Cursor
-		startCursor = &sc
-		endCursor = &ec
-	}
-	pageInfo := &graphql.PageInfo{
-		HasNextPage:     offset+len(edges) < total,
-		HasPreviousPage: offset > 0,
-		StartCursor:     startCursor,
-		EndCursor:       endCursor,
+		startCursor = &edges[0].Cursor
+		endCursor = &edges[len(edges)-1].Cursor
 	}
 	return &graphql.UserConnection{
-		Edges:      edges,
-		PageInfo:   pageInfo,
-		TotalCount: total,
+		Edges:    edges,
+		PageInfo: window.pageInfo(more, startCursor, endCursor),
 	}, nil
 }
 
+var userInputFields = map[string]string{
+	"ID":         "id",
+	"id":         "id",
+	"CreatedAt":  "createdAt",
+	"created_at": "createdAt",
+	"UpdatedAt":  "updatedAt",
+	"updated_at": "updatedAt",
+}
+
 func (r *mutationResolver) CreateUser(ctx context.Context, input graphql.CreateUserInput) (*graphql.CreateUserPayload, error) {
 	if r.ORM == nil {
 		return nil, fmt.Errorf("orm client is not configured")
 	}
+	record, err := r.createUser(ctx, r.ORM, input)
+	if err != nil {
+		userErrors, err := r.mutationFailure(err, userInputFields)
+		if err != nil {
+			return nil, err
+		}
+		return &graphql.CreateUserPayload{ClientMutationID: input.ClientMutationID, UserErrors: userErrors}, nil
+	}
+	if err := r.applyBeforeReturnUser(ctx, record); err != nil {
+		return nil, err
+	}
+	gqlRecord := toGraphQLUser(record)
+	r.primeUser(ctx, record)
+	publishSubscriptionEvent(ctx, r.subscriptionBroker(), "User", SubscriptionTriggerCreated, gqlRecord)
+	return &graphql.CreateUserPayload{
+		ClientMutationID: input.ClientMutationID,
+		User:             gqlRecord,
+		UserErrors:       []*graphql.UserError{},
+	}, nil
+}
+
+// createUser creates the User described by input through client, followed by the edges the
+// input links and creates.
+func (r *Resolver) createUser(ctx context.Context, client *gen.Client, input graphql.CreateUserInput) (*gen.User, error) {
 	model := new(gen.User)
 	if input.ID != nil {
 		model.ID = *input.ID
@@ -229,29 +521,44 @@ func (r *mutationResolver) CreateUser(ctx context.Context, input graphql.CreateU
 	if err := r.applyBeforeCreateUser(ctx, input, model); err != nil {
 		return nil, err
 	}
-	record, err := r.ORM.Users().Create(ctx, model)
+	record, err := client.Users().Create(ctx, model)
 	if err != nil {
 		return nil, err
 	}
 	if err := r.applyAfterCreateUser(ctx, record); err != nil {
 		return nil, err
 	}
+	return record, nil
+}
+
+func (r *mutationResolver) UpdateUser(ctx context.Context, input graphql.UpdateUserInput) (*graphql.UpdateUserPayload, error) {
+	if r.ORM == nil {
+		return nil, fmt.Errorf("orm client is not configured")
+	}
+	record, err := r.updateUser(ctx, r.ORM, input)
+	if err != nil {
+		userErrors, err := r.mutationFailure(err, userInputFields)
+		if err != nil {
+			return nil, err
+		}
+		return &graphql.UpdateUserPayload{ClientMutationID: input.ClientMutationID, UserErrors: userErrors}, nil
+	}
 	if err := r.applyBeforeReturnUser(ctx, record); err != nil {
 		return nil, err
 	}
 	gqlRecord := toGraphQLUser(record)
 	r.primeUser(ctx, record)
-	publishSubscriptionEvent(ctx, r.subscriptionBroker(), "User", SubscriptionTriggerCreated, gqlRecord)
-	return &graphql.CreateUserPayload{
+	publishSubscriptionEvent(ctx, r.subscriptionBroker(), "User", SubscriptionTriggerUpdated, gqlRecord)
+	return &graphql.UpdateUserPayload{
 		ClientMutationID: input.ClientMutationID,
 		User:             gqlRecord,
+		UserErrors:       []*graphql.UserError{},
 	}, nil
 }
 
-func (r *mutationResolver) UpdateUser(ctx context.Context, input graphql.UpdateUserInput) (*graphql.UpdateUserPayload, error) {
-	if r.ORM == nil {
-		return nil, fmt.Errorf("orm client is not configured")
-	}
+// updateUser updates the User identified by input through client, followed by the edges the
+// input changes.
+func (r *Resolver) updateUser(ctx context.Context, client *gen.Client, input graphql.UpdateUserInput) (*gen.User, error) {
 	nativeID, err := decodeUserID(input.ID)
 	if err != nil {
 		return nil, err
@@ -263,26 +570,22 @@ func (r *mutationResolver) UpdateUser(ctx context.Context, input graphql.UpdateU
 	if input.UpdatedAt != nil {
 		model.UpdatedAt = *input.UpdatedAt
 	}
+	before := *model
 	if err := r.applyBeforeUpdateUser(ctx, input, model); err != nil {
 		return nil, err
 	}
-	record, err := r.ORM.Users().Update(ctx, model)
+	fields := make([]string, 0, 1)
+	if input.UpdatedAt != nil || !reflect.DeepEqual(before.UpdatedAt, model.UpdatedAt) {
+		fields = append(fields, "updated_at")
+	}
+	record, err := client.Users().UpdateOneID(nativeID).SetFrom(model, fields...).Save(ctx)
 	if err != nil {
 		return nil, err
 	}
 	if err := r.applyAfterUpdateUser(ctx, record); err != nil {
 		return nil, err
 	}
-	if err := r.applyBeforeReturnUser(ctx, record); err != nil {
-		return nil, err
-	}
-	gqlRecord := toGraphQLUser(record)
-	r.primeUser(ctx, record)
-	publishSubscriptionEvent(ctx, r.subscriptionBroker(), "User", SubscriptionTriggerUpdated, gqlRecord)
-	return &graphql.UpdateUserPayload{
-		ClientMutationID: input.ClientMutationID,
-		User:             gqlRecord,
-	}, nil
+	return record, nil
 }
 
 func (r *mutationResolver) DeleteUser(ctx context.Context, input graphql.DeleteUserInput) (*graphql.DeleteUserPayload, error) {
@@ -299,6 +602,7 @@ func (r *mutationResolver) DeleteUser(ctx context.Context, input graphql.DeleteU
 	if err := r.ORM.Users().Delete(ctx, nativeID); err != nil {
 		return nil, err
 	}
+	r.clearUser(ctx, nativeID)
 	if err := r.applyAfterDeleteUser(ctx, input, nativeID); err != nil {
 		return nil, err
 	}
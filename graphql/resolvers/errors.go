@@ -0,0 +1,164 @@
+package resolvers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/deicod/erm/graphql"
+	"github.com/deicod/erm/orm/runtime"
+	"github.com/deicod/erm/orm/runtime/validation"
+)
+
+// MutationErrorMode selects how create and update mutations report errors caused by their input.
+type MutationErrorMode string
+
+const (
+	// MutationErrorsPayload lists input errors in the userErrors field of the mutation payload.
+	MutationErrorsPayload MutationErrorMode = "payload"
+	// MutationErrorsTopLevel reports input errors as top-level GraphQL errors carrying the code
+	// and field in their extensions.
+	MutationErrorsTopLevel MutationErrorMode = "top_level"
+)
+
+// Codes of the user errors reported by create and update mutations.
+const (
+	UserErrorValidation = "VALIDATION_FAILED"
+	UserErrorUnique     = "UNIQUE_VIOLATION"
+	UserErrorForeignKey = "FOREIGN_KEY_VIOLATION"
+	UserErrorCheck      = "CHECK_VIOLATION"
+	UserErrorNotNull    = "NOT_NULL_VIOLATION"
+)
+
+var constraintErrorCodes = map[runtime.ConstraintKind]string{
+	runtime.ConstraintUnique:     UserErrorUnique,
+	runtime.ConstraintForeignKey: UserErrorForeignKey,
+	runtime.ConstraintCheck:      UserErrorCheck,
+	runtime.ConstraintNotNull:    UserErrorNotNull,
+}
+
+var constraintErrorMessages = map[string]string{
+	UserErrorUnique:     "is already taken",
+	UserErrorForeignKey: "references a record that does not exist",
+	UserErrorCheck:      "violates a check constraint",
+	UserErrorNotNull:    "is required",
+}
+
+// mutationFailure reports err, returned while writing an entity, according to the resolver's
+// mutation error mode. Validation and constraint errors become user errors for the payload, or a
+// top-level error list in MutationErrorsTopLevel mode; any other error is returned unchanged.
+// fields maps the entity's Go field names and columns to their input field names.
+func (r *Resolver) mutationFailure(err error, fields map[string]string) ([]*graphql.UserError, error) {
+	userErrors, ok := toUserErrors(err, fields)
+	if !ok {
+		return nil, err
+	}
+	if r.mutationErrors != MutationErrorsTopLevel {
+		return userErrors, nil
+	}
+	list := make(gqlerror.List, len(userErrors))
+	for i, userErr := range userErrors {
+		extensions := map[string]any{"code": userErr.Code}
+		if len(userErr.Field) > 0 {
+			extensions["field"] = userErr.Field
+		}
+		list[i] = &gqlerror.Error{Message: userErr.Message, Extensions: extensions}
+	}
+	return nil, list
+}
+
+func toUserErrors(err error, fields map[string]string) ([]*graphql.UserError, bool) {
+	var nested *nestedInputError
+	if errors.As(err, &nested) {
+		userErrors, ok := toUserErrors(nested.err, nested.fields)
+		for _, userErr := range userErrors {
+			field := append([]string{"input"}, nested.path...)
+			if len(userErr.Field) > 0 {
+				field = append(field, userErr.Field[1:]...)
+			}
+			userErr.Field = field
+		}
+		return userErrors, ok
+	}
+	var fieldErrs validation.Errors
+	if errors.As(err, &fieldErrs) {
+		userErrors := make([]*graphql.UserError, 0, len(fieldErrs))
+		for _, fieldErr := range fieldErrs {
+			userErrors = append(userErrors, newUserError(UserErrorValidation, fieldErr.Message, fields, fieldErr.Field))
+		}
+		return userErrors, len(userErrors) > 0
+	}
+	var fieldErr validation.FieldError
+	if errors.As(err, &fieldErr) {
+		return []*graphql.UserError{newUserError(UserErrorValidation, fieldErr.Message, fields, fieldErr.Field)}, true
+	}
+	// Writes through the ORM already report violations as *runtime.ConstraintError; raw database
+	// errors, e.g. from hooks running their own statements, are converted here.
+	var constraintErr *runtime.ConstraintError
+	if !errors.As(runtime.WrapConstraintError(err, nil), &constraintErr) {
+		return nil, false
+	}
+	code, ok := constraintErrorCodes[constraintErr.Kind]
+	if !ok {
+		return nil, false
+	}
+	keys := constraintErr.Fields
+	if len(keys) == 0 {
+		keys = constraintErr.Columns
+	}
+	if len(keys) == 0 {
+		return []*graphql.UserError{newUserError(code, constraintErrorMessages[code], fields, "")}, true
+	}
+	// A violated key spanning several columns is reported on each of its fields.
+	userErrors := make([]*graphql.UserError, len(keys))
+	for i, key := range keys {
+		userErrors[i] = newUserError(code, constraintErrorMessages[code], fields, key)
+	}
+	return userErrors, true
+}
+
+// nestedInputError is an error writing a record given by an element of a list input field, such
+// as a child created inline through an edge input. Its user errors name the fields of that record
+// under the element's path.
+type nestedInputError struct {
+	err    error
+	fields map[string]string
+	path   []string
+}
+
+// wrapNestedInputError wraps err, returned while writing the record given at index of the input
+// field named field. fields maps the record's Go field names and columns to its input fields.
+func wrapNestedInputError(err error, fields map[string]string, field string, index int) error {
+	return &nestedInputError{err: err, fields: fields, path: []string{field, strconv.Itoa(index)}}
+}
+
+func (e *nestedInputError) Error() string {
+	return e.err.Error()
+}
+
+func (e *nestedInputError) Unwrap() error {
+	return e.err
+}
+
+// newUserError builds a user error for the input field named by key, a Go field name or column.
+// Keys without an input field are reported as given, and an empty key reports no field.
+func newUserError(code, message string, fields map[string]string, key string) *graphql.UserError {
+	userErr := &graphql.UserError{Code: code, Message: message}
+	if key != "" {
+		if name, ok := fields[key]; ok {
+			key = name
+		}
+		userErr.Field = []string{"input", key}
+	}
+	return userErr
+}
+
+// nilIfNotFound drops the *runtime.NotFoundError of a lookup, as GraphQL reports a missing record
+// as null.
+func nilIfNotFound[T any](record *T, err error) (*T, error) {
+	if runtime.IsNotFound(err) {
+		return nil, nil
+	}
+	return record, err
+}
@@ -0,0 +1,149 @@
+package resolvers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/deicod/erm/graphql"
+	"github.com/deicod/erm/orm/gen"
+	"github.com/deicod/erm/orm/runtime"
+	"github.com/deicod/erm/orm/runtime/validation"
+)
+
+func TestCreateReportsHookValidationErrorsAsUserErrors(t *testing.T) {
+	resolver := NewWithOptions(Options{ORM: gen.NewClient(nil)})
+	resolver.hooks.BeforeCreateUser = func(ctx context.Context, r *Resolver, input graphql.CreateUserInput, model *gen.User) error {
+		return validation.Errors{{Field: "CreatedAt", Message: "must be in the past"}}
+	}
+
+	payload, err := resolver.Mutation().CreateUser(context.Background(), graphql.CreateUserInput{})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if payload.User != nil {
+		t.Fatalf("expected no user, got %+v", payload.User)
+	}
+	want := []*graphql.UserError{{Field: []string{"input", "createdAt"}, Code: UserErrorValidation, Message: "must be in the past"}}
+	if !reflect.DeepEqual(payload.UserErrors, want) {
+		t.Fatalf("unexpected user errors: %+v", payload.UserErrors)
+	}
+}
+
+func TestMutationFailureMapsConstraintErrors(t *testing.T) {
+	resolver := NewWithOptions(Options{})
+	fields := map[string]string{"Email": "email", "email": "email", "tenant_id": "tenantID"}
+	cases := []struct {
+		name string
+		err  error
+		want []*graphql.UserError
+	}{
+		{
+			name: "unique",
+			err:  fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505", Detail: "Key (tenant_id, email)=(1, a@example.com) already exists."}),
+			want: []*graphql.UserError{
+				{Field: []string{"input", "tenantID"}, Code: UserErrorUnique, Message: "is already taken"},
+				{Field: []string{"input", "email"}, Code: UserErrorUnique, Message: "is already taken"},
+			},
+		},
+		{
+			name: "orm constraint",
+			err:  &runtime.ConstraintError{Kind: runtime.ConstraintUnique, Constraint: "users_email_key", Columns: []string{"email"}, Fields: []string{"Email"}},
+			want: []*graphql.UserError{{Field: []string{"input", "email"}, Code: UserErrorUnique, Message: "is already taken"}},
+		},
+		{
+			name: "not null",
+			err:  &pgconn.PgError{Code: "23502", ColumnName: "email"},
+			want: []*graphql.UserError{{Field: []string{"input", "email"}, Code: UserErrorNotNull, Message: "is required"}},
+		},
+		{
+			name: "check without columns",
+			err:  &pgconn.PgError{Code: "23514", ConstraintName: "users_age_check"},
+			want: []*graphql.UserError{{Code: UserErrorCheck, Message: "violates a check constraint"}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			userErrors, err := resolver.mutationFailure(tc.err, fields)
+			if err != nil {
+				t.Fatalf("mutationFailure: %v", err)
+			}
+			if !reflect.DeepEqual(userErrors, tc.want) {
+				t.Fatalf("unexpected user errors: %+v", userErrors)
+			}
+		})
+	}
+}
+
+func TestMutationFailureNamesNestedInputFields(t *testing.T) {
+	resolver := NewWithOptions(Options{})
+	commentFields := map[string]string{"Body": "body"}
+	postFields := map[string]string{"Title": "title"}
+	cases := []struct {
+		name string
+		err  error
+		want []*graphql.UserError
+	}{
+		{
+			name: "validation",
+			err:  wrapNestedInputError(validation.FieldError{Field: "Body", Message: "is required"}, commentFields, "createComments", 1),
+			want: []*graphql.UserError{{Field: []string{"input", "createComments", "1", "body"}, Code: UserErrorValidation, Message: "is required"}},
+		},
+		{
+			name: "grandchild",
+			err:  wrapNestedInputError(fmt.Errorf("create: %w", wrapNestedInputError(validation.FieldError{Field: "Body", Message: "is required"}, commentFields, "createComments", 0)), postFields, "createPosts", 2),
+			want: []*graphql.UserError{{Field: []string{"input", "createPosts", "2", "createComments", "0", "body"}, Code: UserErrorValidation, Message: "is required"}},
+		},
+		{
+			name: "check without columns",
+			err:  wrapNestedInputError(&pgconn.PgError{Code: "23514", ConstraintName: "comments_body_check"}, commentFields, "createComments", 0),
+			want: []*graphql.UserError{{Field: []string{"input", "createComments", "0"}, Code: UserErrorCheck, Message: "violates a check constraint"}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			userErrors, err := resolver.mutationFailure(tc.err, postFields)
+			if err != nil {
+				t.Fatalf("mutationFailure: %v", err)
+			}
+			if !reflect.DeepEqual(userErrors, tc.want) {
+				t.Fatalf("unexpected user errors: %+v", userErrors)
+			}
+		})
+	}
+}
+
+func TestMutationFailureReturnsOtherErrors(t *testing.T) {
+	resolver := NewWithOptions(Options{})
+	boom := errors.New("connection reset")
+	if _, err := resolver.mutationFailure(boom, nil); !errors.Is(err, boom) {
+		t.Fatalf("expected %v, got %v", boom, err)
+	}
+	deadlock := &pgconn.PgError{Code: "40P01"}
+	if _, err := resolver.mutationFailure(deadlock, nil); !errors.Is(err, deadlock) {
+		t.Fatalf("expected %v, got %v", deadlock, err)
+	}
+}
+
+func TestMutationFailureTopLevelMode(t *testing.T) {
+	resolver := NewWithOptions(Options{MutationErrors: MutationErrorsTopLevel})
+	userErrors, err := resolver.mutationFailure(validation.FieldError{Field: "Email", Message: "is invalid"}, map[string]string{"Email": "email"})
+	if userErrors != nil {
+		t.Fatalf("expected no user errors, got %+v", userErrors)
+	}
+	var list gqlerror.List
+	if !errors.As(err, &list) || len(list) != 1 {
+		t.Fatalf("expected one graphql error, got %v", err)
+	}
+	if list[0].Message != "is invalid" || list[0].Extensions["code"] != UserErrorValidation {
+		t.Fatalf("unexpected error: %+v", list[0])
+	}
+	if field := list[0].Extensions["field"]; !reflect.DeepEqual(field, []string{"input", "email"}) {
+		t.Fatalf("unexpected field extension: %v", field)
+	}
+}
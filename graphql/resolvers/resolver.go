@@ -15,14 +15,18 @@ type Options struct {
 	ORM           *gen.Client
 	Collector     metrics.Collector
 	Subscriptions subscriptions.Broker
+	// MutationErrors selects how create and update mutations report input errors, defaulting to
+	// MutationErrorsPayload.
+	MutationErrors MutationErrorMode
 }
 
 // Resolver wires GraphQL resolvers into the executable schema.
 type Resolver struct {
-	ORM           *gen.Client
-	collector     metrics.Collector
-	subscriptions subscriptions.Broker
-	hooks         entityHooks
+	ORM            *gen.Client
+	collector      metrics.Collector
+	subscriptions  subscriptions.Broker
+	hooks          entityHooks
+	mutationErrors MutationErrorMode
 }
 
 // New creates a resolver root bound to the provided ORM client.
@@ -36,7 +40,7 @@ func NewWithOptions(opts Options) *Resolver {
 	if collector == nil {
 		collector = metrics.NoopCollector{}
 	}
-	resolver := &Resolver{ORM: opts.ORM, collector: collector, subscriptions: opts.Subscriptions}
+	resolver := &Resolver{ORM: opts.ORM, collector: collector, subscriptions: opts.Subscriptions, mutationErrors: opts.MutationErrors}
 	resolver.hooks = newEntityHooks()
 	return resolver
 }
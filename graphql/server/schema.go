@@ -19,6 +19,8 @@ type Options struct {
 	ORM           *gen.Client
 	Collector     metrics.Collector
 	Subscriptions SubscriptionOptions
+	// MutationErrors selects how create and update mutations report input errors.
+	MutationErrors resolvers.MutationErrorMode
 }
 
 type SubscriptionOptions struct {
@@ -36,19 +38,39 @@ type SubscriptionTransports struct {
 func NewExecutableSchema(opts Options) gql.ExecutableSchema {
 	opts = normaliseOptions(opts)
 	collector := metrics.WithCollector(opts.Collector)
-	resolver := resolvers.NewWithOptions(resolvers.Options{ORM: opts.ORM, Collector: collector, Subscriptions: opts.Subscriptions.Broker})
+	resolver := resolvers.NewWithOptions(resolvers.Options{
+		ORM:            opts.ORM,
+		Collector:      collector,
+		Subscriptions:  opts.Subscriptions.Broker,
+		MutationErrors: opts.MutationErrors,
+	})
 	cfg := graphql.Config{
 		Resolvers: resolver,
 		Directives: graphql.DirectiveRoot{
-			Auth: func(ctx context.Context, obj any, next gql.Resolver, roles []string) (any, error) {
+			Auth: func(ctx context.Context, obj any, next gql.Resolver, roles []string, owner *string) (any, error) {
 				handler := directives.RequireAuth()
-				if len(roles) > 0 {
+				switch {
+				case owner != nil:
+					handler = directives.RequireOwner(roles, *owner)
+				case len(roles) > 0:
 					handler = directives.RequireRoles(roles)
 				}
 				return handler(ctx, obj, func(ctx context.Context) (any, error) {
 					return next(ctx)
 				})
 			},
+			AuthClaim: func(ctx context.Context, obj any, next gql.Resolver, claim string, field *string, value *string) (any, error) {
+				cond := directives.Condition{Claim: claim}
+				if field != nil {
+					cond.Field = *field
+				}
+				if value != nil {
+					cond.Value = *value
+				}
+				return directives.RequireClaim(cond)(ctx, obj, func(ctx context.Context) (any, error) {
+					return next(ctx)
+				})
+			},
 		},
 	}
 	return graphql.NewExecutableSchema(cfg)
@@ -18,8 +18,18 @@ type Annotation struct {
 const (
 	AnnotationGraphQL       = "graphql"
 	AnnotationAuthorization = "authorization"
+	AnnotationSoftDelete    = "soft_delete"
 )
 
+// SoftDeleteColumn is the timestamp column added to entities annotated with SoftDelete.
+const SoftDeleteColumn = "deleted_at"
+
+// SoftDelete switches the entity to soft deletes: Delete stamps deleted_at instead of removing the
+// row, reads skip deleted rows unless asked otherwise, and unique constraints only apply to live rows.
+func SoftDelete() Annotation {
+	return Annotation{Name: AnnotationSoftDelete, Payload: map[string]any{"column": SoftDeleteColumn}}
+}
+
 type AuthRequirement string
 
 const (
@@ -31,19 +41,71 @@ const (
 type AuthRule struct {
 	Requirement AuthRequirement
 	Roles       []string
+	// Owner names the field holding the subject of the record's owner. The owner passes the rule
+	// without holding Roles; without Roles, only the owner does.
+	Owner string
+	// Conditions must all hold, for owners and role holders alike.
+	Conditions []AuthCondition
+}
+
+// AuthCondition requires a claim of the viewer to equal Field of the record or, when Field is
+// empty, Value.
+type AuthCondition struct {
+	Claim string
+	Field string
+	Value string
 }
 
 func (r *AuthRule) Clone() *AuthRule {
 	if r == nil {
 		return nil
 	}
-	cloned := &AuthRule{Requirement: r.Requirement}
+	cloned := &AuthRule{Requirement: r.Requirement, Owner: r.Owner}
 	if len(r.Roles) > 0 {
 		cloned.Roles = append([]string(nil), r.Roles...)
 	}
+	if len(r.Conditions) > 0 {
+		cloned.Conditions = append([]AuthCondition(nil), r.Conditions...)
+	}
 	return cloned
 }
 
+// OrOwner also lets the viewer whose subject equals field of the record pass the rule, e.g.
+// dsl.AdminOnly().OrOwner("author_id"). Generated resolvers enforce ownership for reads, updates
+// and deletes.
+func (r *AuthRule) OrOwner(field string) *AuthRule {
+	rule := r.authenticated()
+	rule.Owner = field
+	return rule
+}
+
+// WhereClaim requires the claim of the viewer to equal field of the record, e.g.
+// WhereClaim("tenant", "tenant_id").
+func (r *AuthRule) WhereClaim(claim, field string) *AuthRule {
+	rule := r.authenticated()
+	rule.Conditions = append(rule.Conditions, AuthCondition{Claim: claim, Field: field})
+	return rule
+}
+
+// WhereClaimValue requires the claim of the viewer to equal value.
+func (r *AuthRule) WhereClaimValue(claim, value string) *AuthRule {
+	rule := r.authenticated()
+	rule.Conditions = append(rule.Conditions, AuthCondition{Claim: claim, Value: value})
+	return rule
+}
+
+// authenticated clones the rule, turning public rules into ones requiring a viewer.
+func (r *AuthRule) authenticated() *AuthRule {
+	rule := r.Clone()
+	if rule == nil {
+		rule = &AuthRule{}
+	}
+	if rule.Requirement == "" || rule.Requirement == AuthRequirementPublic {
+		rule.Requirement = AuthRequirementAuthenticated
+	}
+	return rule
+}
+
 type AuthRules struct {
 	Create *AuthRule
 	Read   *AuthRule
@@ -107,6 +169,11 @@ func AdminOnly() *AuthRule {
 	return RequireRole("admin")
 }
 
+// OwnerOnly allows only the viewer whose subject equals field of the record.
+func OwnerOnly(field string) *AuthRule {
+	return RequireAuth().OrOwner(field)
+}
+
 func ContentAuth() AuthRules {
 	return AuthRules{
 		Create: RequireRole("user"),
@@ -134,6 +201,99 @@ func ReadOnlyAuth() AuthRules {
 	}
 }
 
+type PolicyRuleKind string
+
+const (
+	PolicyAlwaysAllow          PolicyRuleKind = "always_allow"
+	PolicyAlwaysDeny           PolicyRuleKind = "always_deny"
+	PolicyAllowIfAuthenticated PolicyRuleKind = "allow_if_authenticated"
+	PolicyDenyIfAnonymous      PolicyRuleKind = "deny_if_anonymous"
+	PolicyAllowIfRole          PolicyRuleKind = "allow_if_role"
+	PolicyDenyUnlessRole       PolicyRuleKind = "deny_unless_role"
+	PolicyFilterOwner          PolicyRuleKind = "filter_owner"
+)
+
+type MutationOp string
+
+const (
+	MutationCreate MutationOp = "create"
+	MutationUpdate MutationOp = "update"
+	MutationDelete MutationOp = "delete"
+)
+
+// PolicyRule is a single privacy rule. Each rule allows, denies, or skips to the next rule;
+// FilterOwner rules narrow queries to the viewer's rows instead of deciding.
+type PolicyRule struct {
+	Kind  PolicyRuleKind
+	Roles []string
+	Field string
+	Ops   []MutationOp
+}
+
+// On limits a mutation rule to the given operations. Other operations skip the rule.
+func (r PolicyRule) On(ops ...MutationOp) PolicyRule {
+	r.Ops = append(append([]MutationOp(nil), r.Ops...), ops...)
+	return r
+}
+
+// Policy lists the privacy rules the generated ORM clients evaluate against the request viewer.
+// Rules run in order and the first allow or deny wins; when every rule skips, the operation is
+// allowed.
+type Policy struct {
+	QueryRules    []PolicyRule
+	MutationRules []PolicyRule
+}
+
+func NewPolicy() Policy {
+	return Policy{}
+}
+
+// Query appends rules evaluated for reads (queries, ByID, List, Count and edge loaders).
+func (p Policy) Query(rules ...PolicyRule) Policy {
+	p.QueryRules = append(append([]PolicyRule(nil), p.QueryRules...), rules...)
+	return p
+}
+
+// Mutation appends rules evaluated for creates, updates and deletes.
+func (p Policy) Mutation(rules ...PolicyRule) Policy {
+	p.MutationRules = append(append([]PolicyRule(nil), p.MutationRules...), rules...)
+	return p
+}
+
+func (p Policy) IsZero() bool {
+	return len(p.QueryRules) == 0 && len(p.MutationRules) == 0
+}
+
+func AlwaysAllow() PolicyRule {
+	return PolicyRule{Kind: PolicyAlwaysAllow}
+}
+
+func AlwaysDeny() PolicyRule {
+	return PolicyRule{Kind: PolicyAlwaysDeny}
+}
+
+func AllowIfAuthenticated() PolicyRule {
+	return PolicyRule{Kind: PolicyAllowIfAuthenticated}
+}
+
+func DenyIfAnonymous() PolicyRule {
+	return PolicyRule{Kind: PolicyDenyIfAnonymous}
+}
+
+func AllowIfRole(roles ...string) PolicyRule {
+	return PolicyRule{Kind: PolicyAllowIfRole, Roles: append([]string(nil), roles...)}
+}
+
+func DenyUnlessRole(roles ...string) PolicyRule {
+	return PolicyRule{Kind: PolicyDenyUnlessRole, Roles: append([]string(nil), roles...)}
+}
+
+// FilterOwner restricts rows to those whose field equals the viewer subject. Queries receive an
+// extra predicate; mutations are denied unless every targeted row belongs to the viewer.
+func FilterOwner(field string) PolicyRule {
+	return PolicyRule{Kind: PolicyFilterOwner, Field: field}
+}
+
 type GraphQLOption struct {
 	Key   string
 	Value any
@@ -175,6 +335,46 @@ func GraphQLSubscriptions(events ...SubscriptionEvent) GraphQLOption {
 	return GraphQLOption{Key: "subscriptions", Value: values}
 }
 
+// GraphQLFilterable restricts the generated XxxWhereInput to the named fields and edges. Without
+// it every field with a supported type and every joinable edge can be filtered on.
+func GraphQLFilterable(names ...string) GraphQLOption {
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		values = append(values, name)
+	}
+	return GraphQLOption{Key: "filterable", Value: values}
+}
+
+// GraphQLOrderable opts the named fields into the generated XxxOrderField enum, in addition to
+// the primary key and the fields sorted by the entity's dsl.Query orders.
+func GraphQLOrderable(names ...string) GraphQLOption {
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		values = append(values, name)
+	}
+	return GraphQLOption{Key: "orderable", Value: values}
+}
+
+// GraphQLAggregate generates the xxxAggregate query field, which groups the rows matched by a where
+// filter by the named fields and returns counts, sums, averages and extremes per group. Without
+// names every field with a supported type can be grouped and aggregated.
+func GraphQLAggregate(names ...string) GraphQLOption {
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		values = append(values, name)
+	}
+	return GraphQLOption{Key: "aggregate", Value: values}
+}
+
 type ComparisonOperator string
 
 const (
@@ -185,6 +385,15 @@ const (
 	OpGTE         ComparisonOperator = "gte"
 	OpLTE         ComparisonOperator = "lte"
 	OpILike       ComparisonOperator = "ilike"
+	OpLike        ComparisonOperator = "like"
+	OpIn          ComparisonOperator = "in"
+	OpNotIn       ComparisonOperator = "not_in"
+	OpIsNull      ComparisonOperator = "is_null"
+	OpNotNull     ComparisonOperator = "not_null"
+	OpBetween     ComparisonOperator = "between"
+	OpHasPrefix   ComparisonOperator = "has_prefix"
+	OpHasSuffix   ComparisonOperator = "has_suffix"
+	OpContains    ComparisonOperator = "contains"
 )
 
 type SortDirection string
@@ -318,6 +527,7 @@ func (f Field) ColumnName(name string) Field  { f.Column = name; return f }
 func (f Field) UniqueConstraint() Field       { f.IsUnique = true; return f }
 func (f Field) Unique() Field                 { return f.UniqueConstraint() }
 func (f Field) NotEmpty() Field               { return f.annotate("notEmpty", true) }
+func (f Field) Immutable() Field              { return f.annotate("immutable", true) }
 func (f Field) DefaultNow() Field             { f.HasDefaultNow = true; return f }
 func (f Field) UpdateNow() Field              { f.HasUpdateNow = true; return f }
 func (f Field) WithDefault(expr string) Field { f.DefaultExpr = expr; return f }
@@ -333,6 +543,22 @@ func (f Field) Default(value any) Field {
 	f.HasDefaultNow = false
 	return f.WithDefault(expr)
 }
+
+// Version marks an integer field as the optimistic lock of its entity. Updates only match rows
+// whose stored version equals the expected one and increment it; the column defaults to 1.
+func (f Field) Version() Field {
+	if f.DefaultExpr == "" {
+		f.DefaultExpr = "1"
+	}
+	return f.annotate("version", true)
+}
+
+// Authorize guards reads of the field in GraphQL with rule, e.g. dsl.AdminOnly().OrOwner("id").
+// Viewers failing the rule read null and an error for the field, which becomes nullable.
+func (f Field) Authorize(rule *AuthRule) Field {
+	return f.annotate("auth", rule.Clone())
+}
+
 func (f Field) SRID(srid int) Field { return f.annotate("srid", srid) }
 func (f Field) TimeSeries() Field   { return f.annotate("timeseries", true) }
 func (f Field) Identity(mode IdentityMode) Field {
@@ -851,3 +1077,88 @@ func (q QuerySpec) WithMaxLimit(limit int) QuerySpec {
 	}
 	return q
 }
+
+type HookKind string
+
+const (
+	HookViewerSubject HookKind = "viewer_subject"
+)
+
+// Hook is a declarative mutation hook contributed by a mixin. The generated client runs it before
+// privacy policies and validation.
+type Hook struct {
+	Kind  HookKind
+	Field string
+	Ops   []MutationOp
+}
+
+// ViewerSubjectHook stores the request viewer's subject in field for the given operations.
+func ViewerSubjectHook(field string, ops ...MutationOp) Hook {
+	return Hook{Kind: HookViewerSubject, Field: field, Ops: append([]MutationOp(nil), ops...)}
+}
+
+// Mixin bundles reusable fields, edges, indexes, annotations and hooks. Schemas list mixins in a
+// Mixin() method; primary-key fields from mixins come first, all other mixin fields follow the
+// schema's own fields, and definitions in the schema win over mixin ones with the same name.
+type Mixin struct {
+	Name        string
+	Fields      []Field
+	Edges       []Edge
+	Indexes     []Index
+	Annotations []Annotation
+	Hooks       []Hook
+}
+
+func NewMixin(name string) Mixin { return Mixin{Name: name} }
+
+func (m Mixin) WithFields(fields ...Field) Mixin {
+	m.Fields = append(append([]Field(nil), m.Fields...), fields...)
+	return m
+}
+
+func (m Mixin) WithEdges(edges ...Edge) Mixin {
+	m.Edges = append(append([]Edge(nil), m.Edges...), edges...)
+	return m
+}
+
+func (m Mixin) WithIndexes(indexes ...Index) Mixin {
+	m.Indexes = append(append([]Index(nil), m.Indexes...), indexes...)
+	return m
+}
+
+func (m Mixin) WithAnnotations(annotations ...Annotation) Mixin {
+	m.Annotations = append(append([]Annotation(nil), m.Annotations...), annotations...)
+	return m
+}
+
+func (m Mixin) WithHooks(hooks ...Hook) Mixin {
+	m.Hooks = append(append([]Hook(nil), m.Hooks...), hooks...)
+	return m
+}
+
+// UUIDv7IDMixin contributes a UUIDv7 primary key named id.
+func UUIDv7IDMixin() Mixin {
+	return NewMixin("uuidv7_id").WithFields(UUIDv7("id").Primary())
+}
+
+// TimeMixin contributes created_at and updated_at timestamps maintained by the ORM.
+func TimeMixin() Mixin {
+	return NewMixin("time").WithFields(
+		TimestampTZ("created_at").DefaultNow(),
+		TimestampTZ("updated_at").UpdateNow(),
+	)
+}
+
+// AuditMixin contributes created_by and updated_by columns filled from the viewer subject (the
+// OIDC sub claim). Writes without a viewer leave the values supplied by the caller.
+func AuditMixin() Mixin {
+	return NewMixin("audit").
+		WithFields(
+			String("created_by").Optional().Immutable(),
+			String("updated_by").Optional(),
+		).
+		WithHooks(
+			ViewerSubjectHook("created_by", MutationCreate),
+			ViewerSubjectHook("updated_by", MutationCreate, MutationUpdate),
+		)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/deicod/erm/orm/runtime/cache"
 	"github.com/deicod/erm/orm/runtime/validation"
 	"github.com/jackc/pgx/v5"
+	"iter"
 	"time"
 )
 
@@ -19,10 +20,11 @@ var ValidationRegistry = validation.NewRegistry()
 type Client struct {
 	db    *pg.DB
 	cache cache.Store
+	hooks *runtime.HookRegistry
 }
 
 func NewClient(db *pg.DB) *Client {
-	return &Client{db: db, cache: cache.Nop()}
+	return &Client{db: db, cache: cache.Nop(), hooks: runtime.NewHookRegistry()}
 }
 
 func (c *Client) UseCache(store cache.Store) {
@@ -46,8 +48,97 @@ func makeCacheKey(entity string, id any) string {
 	return "orm:" + entity + ":" + fmt.Sprint(id)
 }
 
+// OnConflict builds the conflict clause for Upsert and BulkUpsert. Targets for unique fields and
+// indexes are generated in the entity packages (for example user.ConflictEmail). Without an
+// action option the upsert overwrites every updatable column with the proposed values.
+func OnConflict(target runtime.ConflictTarget, opts ...runtime.ConflictOption) runtime.OnConflict {
+	return runtime.NewOnConflict(target, opts...)
+}
+
+// Use registers mutation hooks that run for every entity.
+func (c *Client) Use(hooks ...runtime.Hook) {
+	if c == nil {
+		return
+	}
+	if c.hooks == nil {
+		c.hooks = runtime.NewHookRegistry()
+	}
+	c.hooks.Use("", hooks...)
+}
+
+// Intercept registers query interceptors that run for every entity.
+func (c *Client) Intercept(interceptors ...runtime.Interceptor) {
+	if c == nil {
+		return
+	}
+	if c.hooks == nil {
+		c.hooks = runtime.NewHookRegistry()
+	}
+	c.hooks.Intercept("", interceptors...)
+}
+
 func (c *Client) Users() *UserClient {
-	return &UserClient{db: c.db, cache: c.cacheStore()}
+	return &UserClient{db: c.db, cache: c.cacheStore(), hooks: c.hooks}
+}
+
+// Tx is a transactional client. Entity clients obtained from it share one database
+// transaction and buffer cache writes until Commit.
+type Tx struct {
+	*Client
+	tx    *pg.Tx
+	cache *cache.Deferred
+}
+
+// BeginTx starts a transaction. Calling it on a transactional client creates a savepoint.
+func (c *Client) BeginTx(ctx context.Context, opts pgx.TxOptions) (*Tx, error) {
+	if c == nil || c.db == nil {
+		return nil, errors.New("database client is unavailable")
+	}
+	raw, err := c.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	deferred := cache.NewDeferred(c.cacheStore())
+	client := *c
+	client.db = raw.DB()
+	client.cache = deferred
+	return &Tx{Client: &client, tx: raw, cache: deferred}, nil
+}
+
+// Tx runs fn inside a transaction, committing when it returns nil and rolling back otherwise.
+func (c *Client) Tx(ctx context.Context, fn func(tx *Tx) error) error {
+	tx, err := c.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback(ctx)
+			panic(r)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Commit commits the transaction and publishes buffered cache writes.
+func (tx *Tx) Commit(ctx context.Context) error {
+	if err := tx.tx.Commit(ctx); err != nil {
+		tx.cache.Discard()
+		return err
+	}
+	return tx.cache.Flush(ctx)
+}
+
+// Rollback aborts the transaction and drops buffered cache writes.
+func (tx *Tx) Rollback(ctx context.Context) error {
+	tx.cache.Discard()
+	return tx.tx.Rollback(ctx)
 }
 
 const userInsertQuery = `INSERT INTO users (id, created_at, updated_at) VALUES ($1, $2, $3) RETURNING id, slug, created_at, updated_at`
@@ -60,20 +151,233 @@ const userDeleteQuery = `DELETE FROM users WHERE id = $1`
 type UserClient struct {
 	db    *pg.DB
 	cache cache.Store
+	hooks *runtime.HookRegistry
+}
+
+// Use registers mutation hooks that only run for User writes.
+func (c *UserClient) Use(hooks ...runtime.Hook) {
+	c.hooks.Use("User", hooks...)
+}
+
+// Intercept registers query interceptors that only run for User queries.
+func (c *UserClient) Intercept(interceptors ...runtime.Interceptor) {
+	c.hooks.Intercept("User", interceptors...)
+}
+
+func (c *UserClient) mutate(ctx context.Context, m *UserMutation, exec func(context.Context, *UserMutation) (any, error)) (any, error) {
+	return c.hooks.Mutate(ctx, "User", m, runtime.MutateFunc(func(ctx context.Context, mutation runtime.Mutation) (any, error) {
+		typed, ok := mutation.(*UserMutation)
+		if !ok {
+			return nil, fmt.Errorf("unexpected mutation type %T", mutation)
+		}
+		out, err := exec(ctx, typed)
+		return out, runtime.WrapConstraintError(err, userConstraints)
+	}))
+}
+
+// UserMutation describes a pending User write. Hooks may modify Input, Inputs, or IDs before
+// the statement runs, or return an error to veto it.
+type UserMutation struct {
+	op   runtime.MutationOp
+	bulk bool
+	db   *pg.DB
+	// Input is the record passed to Create or Update, or the values set by an UpdateWhere.
+	Input *User
+	// Inputs holds the records passed to BulkCreate or BulkUpdate.
+	Inputs []*User
+	// IDs lists the primary keys targeted by Delete or BulkDelete.
+	IDs []string
+	// Predicates selects the rows of UpdateWhere and DeleteWhere. Hooks may narrow it.
+	Predicates []runtime.Predicate
+	where      bool
+	conflict   *runtime.OnConflict
+	copy       bool
+	// fields lists the fields a partial update writes; nil when every field is written.
+	fields    []string
+	old       *User
+	oldErr    error
+	oldLoaded bool
+}
+
+func (m *UserMutation) Entity() string {
+	return "User"
+}
+
+func (m *UserMutation) Op() runtime.MutationOp {
+	return m.op
+}
+
+func (m *UserMutation) Bulk() bool {
+	return m.bulk
+}
+
+// IsWhere reports whether the mutation is an UpdateWhere or DeleteWhere, whose rows are selected
+// by Predicates rather than by primary key.
+func (m *UserMutation) IsWhere() bool {
+	return m.where
+}
+
+// IsCopy reports whether the mutation is a CopyCreate, whose rows are streamed and not
+// available as Inputs.
+func (m *UserMutation) IsCopy() bool {
+	return m.copy
+}
+
+// OnConflict returns the conflict clause of an Upsert or BulkUpsert, or nil for plain creates.
+// Hooks may modify it.
+func (m *UserMutation) OnConflict() *runtime.OnConflict {
+	return m.conflict
+}
+
+// ChangedFields lists the schema fields the statement writes. For creates only fields with a
+// non-zero value are reported.
+func (m *UserMutation) ChangedFields() []string {
+	switch m.op {
+	case runtime.MutationCreate:
+		names := []string{"id", "created_at", "updated_at"}
+		seen := make([]bool, len(names))
+		for _, input := range m.records() {
+			values := []any{input.ID, input.CreatedAt, input.UpdatedAt}
+			for i, value := range values {
+				if !runtime.IsZeroValue(value) {
+					seen[i] = true
+				}
+			}
+		}
+		fields := make([]string, 0, len(names))
+		for i, name := range names {
+			if seen[i] {
+				fields = append(fields, name)
+			}
+		}
+		return fields
+	case runtime.MutationUpdate:
+		if m.fields != nil {
+			return append([]string{}, m.fields...)
+		}
+		return []string{"updated_at"}
+	}
+	return nil
+}
+
+// Old loads the stored row targeted by a single-row Update or Delete, bypassing the cache.
+func (m *UserMutation) Old(ctx context.Context) (*User, error) {
+	if m.bulk || m.op == runtime.MutationCreate {
+		return nil, errors.New("old values are only available for single-row update and delete mutations")
+	}
+	if !m.oldLoaded {
+		var id string
+		if len(m.IDs) > 0 {
+			id = m.IDs[0]
+		} else if m.Input != nil {
+			id = m.Input.ID
+		}
+		m.old, m.oldErr = (&UserClient{db: m.db}).ByID(ctx, id)
+		m.oldLoaded = true
+	}
+	return m.old, m.oldErr
+}
+
+// touches reports whether the mutation writes field.
+func (m *UserMutation) touches(field string) bool {
+	if m.fields == nil {
+		return true
+	}
+	for _, name := range m.fields {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
+
+// touch adds field to the fields of a partial update.
+func (m *UserMutation) touch(field string) {
+	if !m.touches(field) {
+		m.fields = append(m.fields, field)
+	}
+}
+
+func (m *UserMutation) records() []*User {
+	if m.Input != nil {
+		return []*User{m.Input}
+	}
+	return m.Inputs
 }
 
 func (c *UserClient) Create(ctx context.Context, input *User) (*User, error) {
 	if input == nil {
 		return nil, errors.New("input cannot be nil")
 	}
+	m := &UserMutation{op: runtime.MutationCreate, db: c.db, Input: input}
+	return runtime.CastResult[*User](c.mutate(ctx, m, func(ctx context.Context, m *UserMutation) (any, error) {
+		return c.create(ctx, m.Input)
+	}))
+}
+
+func (c *UserClient) BulkCreate(ctx context.Context, inputs []*User) ([]*User, error) {
+	if len(inputs) == 0 {
+		return []*User{}, nil
+	}
+	m := &UserMutation{op: runtime.MutationCreate, bulk: true, db: c.db, Inputs: inputs}
+	return runtime.CastResult[[]*User](c.mutate(ctx, m, func(ctx context.Context, m *UserMutation) (any, error) {
+		return c.bulkCreate(ctx, m.Inputs)
+	}))
+}
+
+func (c *UserClient) Update(ctx context.Context, input *User) (*User, error) {
+	if input == nil {
+		return nil, errors.New("input cannot be nil")
+	}
+	m := &UserMutation{op: runtime.MutationUpdate, db: c.db, Input: input}
+	return runtime.CastResult[*User](c.mutate(ctx, m, func(ctx context.Context, m *UserMutation) (any, error) {
+		return c.update(ctx, m.Input)
+	}))
+}
+
+func (c *UserClient) BulkUpdate(ctx context.Context, inputs []*User) ([]*User, error) {
+	if len(inputs) == 0 {
+		return []*User{}, nil
+	}
+	m := &UserMutation{op: runtime.MutationUpdate, bulk: true, db: c.db, Inputs: inputs}
+	return runtime.CastResult[[]*User](c.mutate(ctx, m, func(ctx context.Context, m *UserMutation) (any, error) {
+		return c.bulkUpdate(ctx, m.Inputs)
+	}))
+}
+
+func (c *UserClient) Delete(ctx context.Context, id string) error {
+	m := &UserMutation{op: runtime.MutationDelete, db: c.db, IDs: []string{id}}
+	_, err := c.mutate(ctx, m, func(ctx context.Context, m *UserMutation) (any, error) {
+		if len(m.IDs) != 1 {
+			return nil, errors.New("delete expects exactly one id")
+		}
+		return nil, c.delete(ctx, m.IDs[0])
+	})
+	return err
+}
+
+func (c *UserClient) BulkDelete(ctx context.Context, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	m := &UserMutation{op: runtime.MutationDelete, bulk: true, db: c.db, IDs: ids}
+	return runtime.CastResult[int64](c.mutate(ctx, m, func(ctx context.Context, m *UserMutation) (any, error) {
+		return c.bulkDelete(ctx, m.IDs)
+	}))
+}
+
+func (c *UserClient) prepareCreate(ctx context.Context, input *User) error {
+	if input == nil {
+		return errors.New("input cannot be nil")
+	}
 	if !runtime.IsZeroValue(input.Slug) {
-		return nil, fmt.Errorf("User.Slug is computed and cannot be set")
+		return fmt.Errorf("User.Slug is computed and cannot be set")
 	}
 	now := time.Now().UTC()
 	if input.ID == "" {
 		v, err := id.NewV7()
 		if err != nil {
-			return nil, err
+			return err
 		}
 		input.ID = v
 	}
@@ -81,7 +385,11 @@ func (c *UserClient) Create(ctx context.Context, input *User) (*User, error) {
 		input.CreatedAt = now
 	}
 	input.UpdatedAt = now
-	if err := ValidationRegistry.Validate(ctx, "User", validation.OpCreate, userValidationRecord(input), input); err != nil {
+	return ValidationRegistry.Validate(ctx, "User", validation.OpCreate, userValidationRecord(input), input)
+}
+
+func (c *UserClient) create(ctx context.Context, input *User) (*User, error) {
+	if err := c.prepareCreate(ctx, input); err != nil {
 		return nil, err
 	}
 	writer := c.db.Writer()
@@ -99,47 +407,70 @@ func (c *UserClient) Create(ctx context.Context, input *User) (*User, error) {
 	return out, nil
 }
 
-func (c *UserClient) BulkCreate(ctx context.Context, inputs []*User) ([]*User, error) {
+func (c *UserClient) bulkCreate(ctx context.Context, inputs []*User) ([]*User, error) {
+	return c.insertRows(ctx, inputs, nil)
+}
+
+// insertRows inserts inputs, applying conflict when it is non-nil. Inserts that would exceed the
+// bind parameter limit run in chunks inside one transaction. Rows skipped by the conflict clause
+// are missing from the result.
+func (c *UserClient) insertRows(ctx context.Context, inputs []*User, conflict *runtime.OnConflict) ([]*User, error) {
 	if len(inputs) == 0 {
 		return []*User{}, nil
 	}
 	rowsSpec := make([][]any, 0, len(inputs))
 	for _, input := range inputs {
-		if input == nil {
-			return nil, errors.New("input cannot be nil")
-		}
-		if !runtime.IsZeroValue(input.Slug) {
-			return nil, fmt.Errorf("User.Slug is computed and cannot be set")
-		}
-		now := time.Now().UTC()
-		if input.ID == "" {
-			v, err := id.NewV7()
-			if err != nil {
-				return nil, err
-			}
-			input.ID = v
-		}
-		if input.CreatedAt.IsZero() {
-			input.CreatedAt = now
-		}
-		input.UpdatedAt = now
-		if err := ValidationRegistry.Validate(ctx, "User", validation.OpCreate, userValidationRecord(input), input); err != nil {
+		if err := c.prepareCreate(ctx, input); err != nil {
 			return nil, err
 		}
 		row := []any{input.ID, input.CreatedAt, input.UpdatedAt}
 		rowsSpec = append(rowsSpec, row)
 	}
 	spec := runtime.BulkInsertSpec{
-		Table:     "users",
-		Columns:   []string{"id", "created_at", "updated_at"},
-		Returning: []string{"id", "slug", "created_at", "updated_at"},
-		Rows:      rowsSpec,
+		Table:      "users",
+		Columns:    []string{"id", "created_at", "updated_at"},
+		Returning:  []string{"id", "slug", "created_at", "updated_at"},
+		Rows:       rowsSpec,
+		OnConflict: conflict,
+	}
+	chunks := runtime.ChunkBulkInsert(spec)
+	db := c.db
+	var tx *pg.Tx
+	if len(chunks) > 1 && !db.InTx() {
+		raw, err := db.BeginTx(ctx, pgx.TxOptions{})
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = raw.Rollback(ctx) }()
+		tx, db = raw, raw.DB()
+	}
+	created := make([]*User, 0, len(inputs))
+	for _, chunk := range chunks {
+		items, err := c.insertChunk(ctx, db, chunk)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, items...)
+	}
+	if tx != nil {
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
 	}
+	if c.cache != nil {
+		for _, item := range created {
+			_ = c.cache.Set(ctx, makeCacheKey("User", item.ID), item)
+		}
+	}
+	return created, nil
+}
+
+func (c *UserClient) insertChunk(ctx context.Context, db *pg.DB, spec runtime.BulkInsertSpec) ([]*User, error) {
 	sql, args, err := runtime.BuildBulkInsertSQL(spec)
 	if err != nil {
 		return nil, err
 	}
-	writer := c.db.Writer()
+	writer := db.Writer()
 	if writer == nil {
 		return nil, errors.New("database writer pool is unavailable")
 	}
@@ -148,23 +479,69 @@ func (c *UserClient) BulkCreate(ctx context.Context, inputs []*User) ([]*User, e
 		return nil, err
 	}
 	defer rows.Close()
-	var created []*User
+	items := make([]*User, 0, len(spec.Rows))
 	for rows.Next() {
 		item := new(User)
 		if err := rows.Scan(&item.ID, &item.Slug, &item.CreatedAt, &item.UpdatedAt); err != nil {
 			return nil, err
 		}
-		created = append(created, item)
-		if c.cache != nil {
-			_ = c.cache.Set(ctx, makeCacheKey("User", item.ID), item)
-		}
+		items = append(items, item)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return items, rows.Err()
+}
+
+// CopyCreate streams inputs into users with the COPY protocol and returns the number of rows
+// copied. IDs, defaults and validation apply per row as in BulkCreate, and a failing row aborts
+// the whole copy. Rows are not read back, so the cache is left untouched. Hooks and policies see
+// a bulk create mutation without Inputs; ownership rules reject it.
+func (c *UserClient) CopyCreate(ctx context.Context, inputs iter.Seq[*User]) (int64, error) {
+	m := &UserMutation{op: runtime.MutationCreate, bulk: true, copy: true, db: c.db}
+	return runtime.CastResult[int64](c.mutate(ctx, m, func(ctx context.Context, m *UserMutation) (any, error) {
+		src, stop := runtime.CopySource(inputs, func(input *User) ([]any, error) {
+			if err := c.prepareCreate(ctx, input); err != nil {
+				return nil, err
+			}
+			return []any{input.ID, input.CreatedAt, input.UpdatedAt}, nil
+		})
+		defer stop()
+		return c.db.CopyFrom(ctx, "users", []string{"id", "created_at", "updated_at"}, src)
+	}))
+}
+
+// Upsert inserts input or, when it conflicts with a stored row on conflict.Target, applies the
+// conflict action. It returns the inserted or updated row, or nil when the row was skipped by DO
+// NOTHING or the update WHERE clause. Hooks and policies see a create mutation.
+func (c *UserClient) Upsert(ctx context.Context, input *User, conflict runtime.OnConflict) (*User, error) {
+	if input == nil {
+		return nil, errors.New("input cannot be nil")
 	}
-	return created, nil
+	conflict = conflict.WithDefaultUpdates([]string{"updated_at"}...)
+	m := &UserMutation{op: runtime.MutationCreate, db: c.db, Input: input, conflict: &conflict}
+	return runtime.CastResult[*User](c.mutate(ctx, m, func(ctx context.Context, m *UserMutation) (any, error) {
+		rows, err := c.insertRows(ctx, []*User{m.Input}, m.conflict)
+		if err != nil || len(rows) == 0 {
+			return (*User)(nil), err
+		}
+		return rows[0], nil
+	}))
+}
+
+// BulkUpsert upserts inputs in one statement. Rows skipped by the conflict clause are missing
+// from the result. Two inputs must not conflict with each other.
+func (c *UserClient) BulkUpsert(ctx context.Context, inputs []*User, conflict runtime.OnConflict) ([]*User, error) {
+	if len(inputs) == 0 {
+		return []*User{}, nil
+	}
+	conflict = conflict.WithDefaultUpdates([]string{"updated_at"}...)
+	m := &UserMutation{op: runtime.MutationCreate, bulk: true, db: c.db, Inputs: inputs, conflict: &conflict}
+	return runtime.CastResult[[]*User](c.mutate(ctx, m, func(ctx context.Context, m *UserMutation) (any, error) {
+		return c.insertRows(ctx, m.Inputs, m.conflict)
+	}))
 }
 
+// ByID loads the User with id, returning a *runtime.NotFoundError when no row visible to the
+// caller has it. It reads from the primary, or the transaction of the client, so records written
+// just before are found regardless of replica lag.
 func (c *UserClient) ByID(ctx context.Context, id string) (*User, error) {
 	var cachedKey string
 	if c.cache != nil {
@@ -177,11 +554,11 @@ func (c *UserClient) ByID(ctx context.Context, id string) (*User, error) {
 			}
 		}
 	}
-	row := c.db.QueryRow(ctx, "users", userSelectQuery, id)
+	row := c.db.Pool.QueryRow(ctx, userSelectQuery, id)
 	out := new(User)
 	if err := row.Scan(&out.ID, &out.Slug, &out.CreatedAt, &out.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
+			return nil, &runtime.NotFoundError{Entity: "User", ID: id}
 		}
 		return nil, err
 	}
@@ -199,7 +576,7 @@ func (c *UserClient) List(ctx context.Context, limit, offset int) ([]*User, erro
 	if offset < 0 {
 		offset = 0
 	}
-	rows, err := c.db.Query(ctx, "users", userListQuery, limit, offset)
+	rows, err := c.db.Pool.Query(ctx, userListQuery, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -219,7 +596,7 @@ func (c *UserClient) List(ctx context.Context, limit, offset int) ([]*User, erro
 }
 
 func (c *UserClient) Count(ctx context.Context) (int, error) {
-	row := c.db.QueryRow(ctx, "users", userCountQuery)
+	row := c.db.Pool.QueryRow(ctx, userCountQuery)
 	var total int
 	if err := row.Scan(&total); err != nil {
 		return 0, err
@@ -227,7 +604,7 @@ func (c *UserClient) Count(ctx context.Context) (int, error) {
 	return total, nil
 }
 
-func (c *UserClient) Update(ctx context.Context, input *User) (*User, error) {
+func (c *UserClient) update(ctx context.Context, input *User) (*User, error) {
 	if input == nil {
 		return nil, errors.New("input cannot be nil")
 	}
@@ -246,6 +623,9 @@ func (c *UserClient) Update(ctx context.Context, input *User) (*User, error) {
 	row := writer.QueryRow(ctx, userUpdateQuery, input.UpdatedAt, input.ID)
 	out := new(User)
 	if err := row.Scan(&out.ID, &out.Slug, &out.CreatedAt, &out.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &runtime.NotFoundError{Entity: "User", ID: input.ID}
+		}
 		return nil, err
 	}
 	if c.cache != nil {
@@ -254,7 +634,7 @@ func (c *UserClient) Update(ctx context.Context, input *User) (*User, error) {
 	return out, nil
 }
 
-func (c *UserClient) BulkUpdate(ctx context.Context, inputs []*User) ([]*User, error) {
+func (c *UserClient) bulkUpdate(ctx context.Context, inputs []*User) ([]*User, error) {
 	if len(inputs) == 0 {
 		return []*User{}, nil
 	}
@@ -314,7 +694,239 @@ func (c *UserClient) BulkUpdate(ctx context.Context, inputs []*User) ([]*User, e
 	return updated, nil
 }
 
-func (c *UserClient) Delete(ctx context.Context, id string) error {
+// UserUpdateOne updates the columns of one User that its setters touch; every other column keeps its
+// stored value. Hooks see the touched fields through UserMutation.ChangedFields.
+type UserUpdateOne struct {
+	client *UserClient
+	input  *User
+	fields []string
+	ops    map[string]runtime.AssignOp
+	err    error
+}
+
+// UpdateOneID starts a partial update of the User with the given id.
+func (c *UserClient) UpdateOneID(id string) *UserUpdateOne {
+	return &UserUpdateOne{client: c, input: &User{ID: id}, ops: make(map[string]runtime.AssignOp)}
+}
+
+func (u *UserUpdateOne) touch(field string, op runtime.AssignOp) {
+	if _, ok := u.ops[field]; !ok {
+		u.fields = append(u.fields, field)
+	}
+	u.ops[field] = op
+}
+
+func (u *UserUpdateOne) SetUpdatedAt(v time.Time) *UserUpdateOne {
+	u.input.UpdatedAt = v
+	u.touch("updated_at", runtime.AssignSet)
+	return u
+}
+
+// SetFrom copies the named fields from model, clearing nullable fields that are nil on model.
+// Naming a field that cannot be updated fails Save.
+func (u *UserUpdateOne) SetFrom(model *User, fields ...string) *UserUpdateOne {
+	if model == nil {
+		return u
+	}
+	for _, field := range fields {
+		switch field {
+		case "updated_at":
+			u.input.UpdatedAt = model.UpdatedAt
+			u.touch("updated_at", runtime.AssignSet)
+		default:
+			u.err = fmt.Errorf("User field %q cannot be updated", field)
+		}
+	}
+	return u
+}
+
+// Save writes the touched columns and returns the updated row.
+func (u *UserUpdateOne) Save(ctx context.Context) (*User, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+	if _, ok := u.ops["updated_at"]; !ok {
+		u.input.UpdatedAt = time.Now().UTC()
+		u.touch("updated_at", runtime.AssignSet)
+	}
+	m := &UserMutation{op: runtime.MutationUpdate, db: u.client.db, Input: u.input, fields: append([]string{}, u.fields...)}
+	return runtime.CastResult[*User](u.client.mutate(ctx, m, func(ctx context.Context, m *UserMutation) (any, error) {
+		return u.client.updateFields(ctx, m.Input, m.fields, u.ops)
+	}))
+}
+
+// Exec is Save without the returned row.
+func (u *UserUpdateOne) Exec(ctx context.Context) error {
+	_, err := u.Save(ctx)
+	return err
+}
+
+// updateAssignments returns the assignments writing fields of input, and the validation keys
+// of those fields. For fields updated with AssignAdd input holds the delta.
+func (c *UserClient) updateAssignments(input *User, fields []string, ops map[string]runtime.AssignOp) ([]runtime.Assignment, []string, error) {
+	assignments := make([]runtime.Assignment, 0, len(fields))
+	keys := make([]string, 0, len(fields))
+	for _, field := range fields {
+		assignment := runtime.Assignment{Op: ops[field]}
+		switch field {
+		case "updated_at":
+			assignment.Column, assignment.Value = "updated_at", input.UpdatedAt
+			keys = append(keys, "UpdatedAt")
+		default:
+			return nil, nil, fmt.Errorf("User field %q cannot be updated", field)
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments, keys, nil
+}
+
+func (c *UserClient) updateFields(ctx context.Context, input *User, fields []string, ops map[string]runtime.AssignOp) (*User, error) {
+	if input == nil {
+		return nil, errors.New("input cannot be nil")
+	}
+	if input.ID == "" {
+		return nil, errors.New("id is required")
+	}
+	assignments, keys, err := c.updateAssignments(input, fields, ops)
+	if err != nil {
+		return nil, err
+	}
+	if len(assignments) == 0 {
+		return c.ByID(ctx, input.ID)
+	}
+	if err := ValidationRegistry.ValidateFields(ctx, "User", validation.OpUpdate, userValidationRecord(input), input, keys); err != nil {
+		return nil, err
+	}
+	preds := []runtime.Predicate{runtime.Compare("id", runtime.OpEqual, input.ID)}
+	sql, args, err := runtime.BuildUpdateSQL(runtime.UpdateSpec{
+		Table:       "users",
+		Assignments: assignments,
+		Predicates:  preds,
+		Returning:   []string{"id", "slug", "created_at", "updated_at"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	writer := c.db.Writer()
+	if writer == nil {
+		return nil, errors.New("database writer pool is unavailable")
+	}
+	out := new(User)
+	if err := writer.QueryRow(ctx, sql, args...).Scan(&out.ID, &out.Slug, &out.CreatedAt, &out.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &runtime.NotFoundError{Entity: "User", ID: input.ID}
+		}
+		return nil, err
+	}
+	if c.cache != nil {
+		_ = c.cache.Set(ctx, makeCacheKey("User", out.ID), out)
+	}
+	return out, nil
+}
+
+// UserUpdate updates every User matching its predicates in a single UPDATE statement. Only the
+// columns its setters touch are written.
+type UserUpdate struct {
+	client     *UserClient
+	predicates []runtime.Predicate
+	input      *User
+	fields     []string
+	ops        map[string]runtime.AssignOp
+}
+
+// UpdateWhere starts an update of the User rows matching preds. Without predicates every row
+// is updated.
+func (c *UserClient) UpdateWhere(preds ...runtime.Predicate) *UserUpdate {
+	return &UserUpdate{client: c, predicates: preds, input: new(User), ops: make(map[string]runtime.AssignOp)}
+}
+
+// Where narrows the rows to update.
+func (u *UserUpdate) Where(preds ...runtime.Predicate) *UserUpdate {
+	u.predicates = append(u.predicates, preds...)
+	return u
+}
+
+func (u *UserUpdate) touch(field string, op runtime.AssignOp) {
+	if _, ok := u.ops[field]; !ok {
+		u.fields = append(u.fields, field)
+	}
+	u.ops[field] = op
+}
+
+func (u *UserUpdate) SetUpdatedAt(v time.Time) *UserUpdate {
+	u.input.UpdatedAt = v
+	u.touch("updated_at", runtime.AssignSet)
+	return u
+}
+
+// Save writes the touched columns of every matching row and returns the updated rows.
+func (u *UserUpdate) Save(ctx context.Context) ([]*User, error) {
+	if _, ok := u.ops["updated_at"]; !ok {
+		u.input.UpdatedAt = time.Now().UTC()
+		u.touch("updated_at", runtime.AssignSet)
+	}
+	m := &UserMutation{op: runtime.MutationUpdate, bulk: true, where: true, db: u.client.db, Input: u.input, fields: append([]string{}, u.fields...), Predicates: append([]runtime.Predicate(nil), u.predicates...)}
+	return runtime.CastResult[[]*User](u.client.mutate(ctx, m, func(ctx context.Context, m *UserMutation) (any, error) {
+		return u.client.updateWhere(ctx, m.Input, m.fields, u.ops, m.Predicates)
+	}))
+}
+
+// Exec is Save returning the number of updated rows.
+func (u *UserUpdate) Exec(ctx context.Context) (int64, error) {
+	rows, err := u.Save(ctx)
+	return int64(len(rows)), err
+}
+
+func (c *UserClient) updateWhere(ctx context.Context, input *User, fields []string, ops map[string]runtime.AssignOp, preds []runtime.Predicate) ([]*User, error) {
+	if input == nil {
+		return nil, errors.New("input cannot be nil")
+	}
+	assignments, keys, err := c.updateAssignments(input, fields, ops)
+	if err != nil {
+		return nil, err
+	}
+	if len(assignments) == 0 {
+		return []*User{}, nil
+	}
+	if err := ValidationRegistry.ValidateFields(ctx, "User", validation.OpUpdate, userValidationRecord(input), input, keys); err != nil {
+		return nil, err
+	}
+	sql, args, err := runtime.BuildUpdateSQL(runtime.UpdateSpec{
+		Table:       "users",
+		Assignments: assignments,
+		Predicates:  preds,
+		Returning:   []string{"id", "slug", "created_at", "updated_at"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	writer := c.db.Writer()
+	if writer == nil {
+		return nil, errors.New("database writer pool is unavailable")
+	}
+	rows, err := writer.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	updated := []*User{}
+	for rows.Next() {
+		item := new(User)
+		if err := rows.Scan(&item.ID, &item.Slug, &item.CreatedAt, &item.UpdatedAt); err != nil {
+			return nil, err
+		}
+		updated = append(updated, item)
+		if c.cache != nil {
+			_ = c.cache.Set(ctx, makeCacheKey("User", item.ID), item)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (c *UserClient) delete(ctx context.Context, id string) error {
 	writer := c.db.Writer()
 	if writer == nil {
 		return errors.New("database writer pool is unavailable")
@@ -328,7 +940,7 @@ func (c *UserClient) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (c *UserClient) BulkDelete(ctx context.Context, ids []string) (int64, error) {
+func (c *UserClient) bulkDelete(ctx context.Context, ids []string) (int64, error) {
 	if len(ids) == 0 {
 		return 0, nil
 	}
@@ -360,21 +972,89 @@ func (c *UserClient) BulkDelete(ctx context.Context, ids []string) (int64, error
 	return int64(tag.RowsAffected()), nil
 }
 
+// UserDelete deletes every User matching its predicates in a single DELETE statement.
+type UserDelete struct {
+	client     *UserClient
+	predicates []runtime.Predicate
+}
+
+// DeleteWhere starts a delete of the User rows matching preds. Without predicates every row is
+// deleted.
+func (c *UserClient) DeleteWhere(preds ...runtime.Predicate) *UserDelete {
+	return &UserDelete{client: c, predicates: preds}
+}
+
+// Where narrows the rows to delete.
+func (d *UserDelete) Where(preds ...runtime.Predicate) *UserDelete {
+	d.predicates = append(d.predicates, preds...)
+	return d
+}
+
+// Exec runs the delete and returns the number of affected rows.
+func (d *UserDelete) Exec(ctx context.Context) (int64, error) {
+	m := &UserMutation{op: runtime.MutationDelete, bulk: true, where: true, db: d.client.db, Predicates: append([]runtime.Predicate(nil), d.predicates...)}
+	return runtime.CastResult[int64](d.client.mutate(ctx, m, func(ctx context.Context, m *UserMutation) (any, error) {
+		return d.client.deleteWhere(ctx, m.Predicates)
+	}))
+}
+
+func (c *UserClient) deleteWhere(ctx context.Context, preds []runtime.Predicate) (int64, error) {
+	spec := runtime.DeleteSpec{
+		Table:      "users",
+		Predicates: preds,
+		Returning:  []string{"id"},
+	}
+	sql, args, err := runtime.BuildDeleteSQL(spec)
+	if err != nil {
+		return 0, err
+	}
+	writer := c.db.Writer()
+	if writer == nil {
+		return 0, errors.New("database writer pool is unavailable")
+	}
+	rows, err := writer.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var affected int64
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return 0, err
+		}
+		affected++
+		if c.cache != nil {
+			_ = c.cache.Delete(ctx, makeCacheKey("User", id))
+		}
+	}
+	return affected, rows.Err()
+}
+
 type UserQuery struct {
 	db           *pg.DB
+	hooks        *runtime.HookRegistry
+	op           runtime.QueryOp
 	predicates   []runtime.Predicate
 	orders       []runtime.Order
 	limit        *int
 	offset       int
+	after        string
+	before       string
+	fromEnd      bool
 	defaultLimit int
 	maxLimit     int
+	partition    string
+	through      *runtime.EdgeJoin
+	throughKeys  any
 }
 
 func (c *UserClient) Query() *UserQuery {
-	return &UserQuery{db: c.db, defaultLimit: 20, maxLimit: 0}
+	return &UserQuery{db: c.db, hooks: c.hooks, defaultLimit: 20, maxLimit: 0}
 }
 
 func (q *UserQuery) Limit(n int) *UserQuery {
+	q.fromEnd = false
 	if n <= 0 {
 		q.limit = nil
 		return q
@@ -391,6 +1071,12 @@ func (q *UserQuery) Offset(n int) *UserQuery {
 	return q
 }
 
+// Where adds predicates, such as those in the user package, joined with AND.
+func (q *UserQuery) Where(preds ...runtime.Predicate) *UserQuery {
+	q.predicates = append(q.predicates, preds...)
+	return q
+}
+
 func (q *UserQuery) WhereIDEq(value string) *UserQuery {
 	q.predicates = append(q.predicates, runtime.Predicate{Column: "id", Operator: runtime.OpEqual, Value: value})
 	return q
@@ -401,14 +1087,145 @@ func (q *UserQuery) OrderByCreatedAtAsc() *UserQuery {
 	return q
 }
 
+// After keeps the rows sorting after the row cursor was created for. Cursors come from Cursor on
+// a query with the same order; an empty cursor clears the bound.
+func (q *UserQuery) After(cursor string) *UserQuery {
+	q.after = cursor
+	return q
+}
+
+// Before keeps the rows sorting before the row cursor was created for. Combine it with Last to
+// page backwards.
+func (q *UserQuery) Before(cursor string) *UserQuery {
+	q.before = cursor
+	return q
+}
+
+// Last limits the query to its last n rows, which are still returned in the query's order.
+func (q *UserQuery) Last(n int) *UserQuery {
+	q.Limit(n)
+	q.fromEnd = q.limit != nil
+	return q
+}
+
+// Order appends sort keys after those added by the OrderBy methods.
+func (q *UserQuery) Order(orders ...runtime.Order) *UserQuery {
+	q.orders = append(q.orders, orders...)
+	return q
+}
+
+// Cursor returns the opaque cursor of item for the query's order, for use with After and Before.
+func (q *UserQuery) Cursor(item *User) (string, error) {
+	if item == nil {
+		return "", errors.New("item cannot be nil")
+	}
+	orders, fields, err := q.keysetFields(item)
+	if err != nil {
+		return "", err
+	}
+	return runtime.EncodeCursor(orders, fields...)
+}
+
+func (q *UserQuery) keyset(cursor string) ([]any, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	orders, fields, err := q.keysetFields(new(User))
+	if err != nil {
+		return nil, err
+	}
+	return runtime.DecodeCursor(cursor, orders, fields...)
+}
+
+// keysetFields returns the keyset order of the query and pointers to the matching fields of item.
+func (q *UserQuery) keysetFields(item *User) ([]runtime.Order, []any, error) {
+	orders := runtime.KeysetOrders(q.orders, "id")
+	fields := make([]any, len(orders))
+	for i, order := range orders {
+		switch order.Column {
+		case "id":
+			fields[i] = &item.ID
+		case "slug":
+			fields[i] = &item.Slug
+		case "created_at":
+			fields[i] = &item.CreatedAt
+		case "updated_at":
+			fields[i] = &item.UpdatedAt
+		default:
+			return nil, nil, fmt.Errorf("User cannot be paginated by %q", order.Column)
+		}
+	}
+	return orders, fields, nil
+}
+
+func (q *UserQuery) Entity() string {
+	return "User"
+}
+
+// Op reports the terminal being executed while the query passes through interceptors.
+func (q *UserQuery) Op() runtime.QueryOp {
+	return q.op
+}
+
 func (q *UserQuery) All(ctx context.Context) ([]*User, error) {
+	return runtime.CastResult[[]*User](q.intercept(ctx, runtime.QueryAll, func(ctx context.Context, q *UserQuery) (any, error) {
+		return q.all(ctx)
+	}))
+}
+
+func (q *UserQuery) Stream(ctx context.Context) (*runtime.Stream[*User], error) {
+	return runtime.CastResult[*runtime.Stream[*User]](q.intercept(ctx, runtime.QueryStream, func(ctx context.Context, q *UserQuery) (any, error) {
+		return q.stream(ctx)
+	}))
+}
+
+func (q *UserQuery) First(ctx context.Context) (*User, error) {
+	return runtime.CastResult[*User](q.intercept(ctx, runtime.QueryFirst, func(ctx context.Context, q *UserQuery) (any, error) {
+		return q.first(ctx)
+	}))
+}
+
+func (q *UserQuery) intercept(ctx context.Context, op runtime.QueryOp, exec func(context.Context, *UserQuery) (any, error)) (any, error) {
+	cp := q.clone()
+	cp.op = op
+	return q.hooks.Query(ctx, "User", cp, runtime.QuerierFunc(func(ctx context.Context, query runtime.Query) (any, error) {
+		typed, ok := query.(*UserQuery)
+		if !ok {
+			return nil, fmt.Errorf("unexpected query type %T", query)
+		}
+		return exec(ctx, typed)
+	}))
+}
+
+func (q *UserQuery) all(ctx context.Context) ([]*User, error) {
+	return q.scan(ctx, nil)
+}
+
+// scan runs the select. When the query joins a link table for eager loading, owner supplies the
+// destination for each row's link key.
+func (q *UserQuery) scan(ctx context.Context, owner func() any) ([]*User, error) {
+	after, err := q.keyset(q.after)
+	if err != nil {
+		return nil, err
+	}
+	before, err := q.keyset(q.before)
+	if err != nil {
+		return nil, err
+	}
 	spec := runtime.SelectSpec{
-		Table:      "users",
-		Columns:    []string{"id", "slug", "created_at", "updated_at"},
-		Predicates: q.predicates,
-		Orders:     q.orders,
-		Limit:      q.effectiveLimit(),
-		Offset:     q.offset,
+		Table:       "users",
+		Columns:     []string{"id", "slug", "created_at", "updated_at"},
+		Predicates:  q.predicates,
+		Orders:      q.orders,
+		Limit:       q.effectiveLimit(),
+		Offset:      q.offset,
+		PartitionBy: q.partition,
+		Through:     q.through,
+		ThroughKeys: q.throughKeys,
+		KeyColumn:   "id",
+		After:       after,
+		Before:      before,
+		FromEnd:     q.fromEnd,
 	}
 	rows, err := q.db.Select(ctx, spec)
 	if err != nil {
@@ -418,7 +1235,11 @@ func (q *UserQuery) All(ctx context.Context) ([]*User, error) {
 	var result []*User
 	for rows.Next() {
 		item := new(User)
-		if err := rows.Scan(&item.ID, &item.Slug, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		dest := []any{&item.ID, &item.Slug, &item.CreatedAt, &item.UpdatedAt}
+		if owner != nil {
+			dest = append(dest, owner())
+		}
+		if err := rows.Scan(dest...); err != nil {
 			return nil, err
 		}
 		result = append(result, item)
@@ -429,7 +1250,15 @@ func (q *UserQuery) All(ctx context.Context) ([]*User, error) {
 	return result, nil
 }
 
-func (q *UserQuery) Stream(ctx context.Context) (*runtime.Stream[*User], error) {
+func (q *UserQuery) stream(ctx context.Context) (*runtime.Stream[*User], error) {
+	after, err := q.keyset(q.after)
+	if err != nil {
+		return nil, err
+	}
+	before, err := q.keyset(q.before)
+	if err != nil {
+		return nil, err
+	}
 	spec := runtime.SelectSpec{
 		Table:      "users",
 		Columns:    []string{"id", "slug", "created_at", "updated_at"},
@@ -437,6 +1266,10 @@ func (q *UserQuery) Stream(ctx context.Context) (*runtime.Stream[*User], error)
 		Orders:     q.orders,
 		Limit:      q.effectiveLimit(),
 		Offset:     q.offset,
+		KeyColumn:  "id",
+		After:      after,
+		Before:     before,
+		FromEnd:    q.fromEnd,
 	}
 	rows, err := q.db.Select(ctx, spec)
 	if err != nil {
@@ -452,11 +1285,11 @@ func (q *UserQuery) Stream(ctx context.Context) (*runtime.Stream[*User], error)
 	return stream, nil
 }
 
-func (q *UserQuery) First(ctx context.Context) (*User, error) {
+func (q *UserQuery) first(ctx context.Context) (*User, error) {
 	clone := q.clone()
 	one := 1
 	clone.limit = &one
-	items, err := clone.All(ctx)
+	items, err := clone.all(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -467,6 +1300,12 @@ func (q *UserQuery) First(ctx context.Context) (*User, error) {
 }
 
 func (q *UserQuery) Count(ctx context.Context) (int, error) {
+	return runtime.CastResult[int](q.intercept(ctx, runtime.QueryCount, func(ctx context.Context, q *UserQuery) (any, error) {
+		return q.count(ctx)
+	}))
+}
+
+func (q *UserQuery) count(ctx context.Context) (int, error) {
 	spec := runtime.AggregateSpec{
 		Table:      "users",
 		Predicates: q.predicates,
@@ -480,6 +1319,78 @@ func (q *UserQuery) Count(ctx context.Context) (int, error) {
 	return out, nil
 }
 
+// GroupBy groups the rows matched by the query by columns, such as the Field constants of the
+// user package, to compute aggregates per group. Without columns the aggregates cover every
+// matched row in a single group.
+func (q *UserQuery) GroupBy(columns ...string) *UserGroupBy {
+	return &UserGroupBy{query: q.clone(), columns: append([]string(nil), columns...)}
+}
+
+// UserGroupBy computes aggregates of User rows per group.
+type UserGroupBy struct {
+	query      *UserQuery
+	columns    []string
+	aggregates []runtime.Aggregate
+	having     []runtime.Predicate
+	orders     []runtime.Order
+	limit      int
+}
+
+// Aggregate adds aggregates such as runtime.Count() and runtime.Sum(column), selected after the
+// grouped columns under their aliases.
+func (g *UserGroupBy) Aggregate(aggs ...runtime.Aggregate) *UserGroupBy {
+	g.aggregates = append(g.aggregates, aggs...)
+	return g
+}
+
+// Having keeps the groups matching every predicate. Aggregates are compared through their Expr,
+// e.g. runtime.Compare(runtime.Count().Expr(), runtime.OpGreaterThan, 1).
+func (g *UserGroupBy) Having(preds ...runtime.Predicate) *UserGroupBy {
+	g.having = append(g.having, preds...)
+	return g
+}
+
+// Order sorts the groups by grouped columns or aggregate aliases.
+func (g *UserGroupBy) Order(orders ...runtime.Order) *UserGroupBy {
+	g.orders = append(g.orders, orders...)
+	return g
+}
+
+// Limit caps the number of groups returned.
+func (g *UserGroupBy) Limit(n int) *UserGroupBy {
+	if n < 0 {
+		n = 0
+	}
+	g.limit = n
+	return g
+}
+
+// Scan reads a row per group into dest, a pointer to a slice of structs, matching columns and
+// aliases to fields as runtime.ScanGroups does. The query runs through interceptors as
+// runtime.QueryAggregate.
+func (g *UserGroupBy) Scan(ctx context.Context, dest any) error {
+	_, err := g.query.intercept(ctx, runtime.QueryAggregate, func(ctx context.Context, q *UserQuery) (any, error) {
+		spec := runtime.AggregateSpec{
+			Table:      "users",
+			Predicates: q.predicates,
+			Aggregates: g.aggregates,
+			GroupBy:    g.columns,
+			Having:     g.having,
+			Orders:     g.orders,
+			Limit:      g.limit,
+		}
+		if err := spec.Validate(); err != nil {
+			return nil, err
+		}
+		rows, err := q.db.AggregateGroups(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+		return nil, runtime.ScanGroups(rows, dest)
+	})
+	return err
+}
+
 func (q *UserQuery) clone() *UserQuery {
 	cp := *q
 	if len(q.predicates) > 0 {
@@ -522,3 +1433,16 @@ func userValidationRecord(input *User) validation.Record {
 		"UpdatedAt": input.UpdatedAt,
 	}
 }
+
+var userConstraints = &runtime.ConstraintSchema{
+	Entity: "User",
+	Constraints: map[string]runtime.Constraint{
+		"users_pkey": {Kind: runtime.ConstraintUnique, Columns: []string{"id"}, Fields: []string{"ID"}},
+	},
+	Fields: map[string]string{
+		"id":         "ID",
+		"slug":       "Slug",
+		"created_at": "CreatedAt",
+		"updated_at": "UpdatedAt",
+	},
+}
@@ -0,0 +1,274 @@
+// Code generated by erm. DO NOT EDIT.
+
+// Package user holds typed predicates for User queries, for use with UserQuery.Where, sort orders for
+// UserQuery.Order, aggregates for UserGroupBy.Aggregate, and the conflict targets of its unique
+// fields and indexes, for use with UserClient.Upsert.
+package user
+
+import (
+	"time"
+
+	"github.com/deicod/erm/orm/runtime"
+)
+
+// Table is the table backing User.
+const Table = "users"
+
+// Predicate is a condition on User rows.
+type Predicate = runtime.Predicate
+
+// Order is a sort key of User rows.
+type Order = runtime.Order
+
+// Aggregate is an aggregate of grouped User rows.
+type Aggregate = runtime.Aggregate
+
+const (
+	// FieldID is the column of the id field.
+	FieldID = "id"
+	// FieldSlug is the column of the slug field.
+	FieldSlug = "slug"
+	// FieldCreatedAt is the column of the created_at field.
+	FieldCreatedAt = "created_at"
+	// FieldUpdatedAt is the column of the updated_at field.
+	FieldUpdatedAt = "updated_at"
+)
+
+// Conflict targets for UserClient.Upsert and BulkUpsert.
+var (
+	ConflictID = runtime.ConflictColumns("id")
+)
+
+// And matches User rows that satisfy every predicate.
+func And(preds ...runtime.Predicate) runtime.Predicate {
+	return runtime.And(preds...)
+}
+
+// Or matches User rows that satisfy at least one predicate.
+func Or(preds ...runtime.Predicate) runtime.Predicate {
+	return runtime.Or(preds...)
+}
+
+// Not negates pred.
+func Not(pred runtime.Predicate) runtime.Predicate {
+	return runtime.Not(pred)
+}
+
+// Asc sorts User rows by column in ascending order, for use with UserQuery.Order.
+func Asc(column string) runtime.Order {
+	return runtime.Order{Column: column, Direction: runtime.SortAsc}
+}
+
+// Desc sorts User rows by column in descending order, for use with UserQuery.Order.
+func Desc(column string) runtime.Order {
+	return runtime.Order{Column: column, Direction: runtime.SortDesc}
+}
+
+// Count counts the User rows of each group, for use with UserGroupBy.Aggregate.
+func Count() runtime.Aggregate {
+	return runtime.Count()
+}
+
+// Sum adds up column per group, for use with UserGroupBy.Aggregate.
+func Sum(column string) runtime.Aggregate {
+	return runtime.Sum(column)
+}
+
+// Avg averages column per group, for use with UserGroupBy.Aggregate.
+func Avg(column string) runtime.Aggregate {
+	return runtime.Avg(column)
+}
+
+// Min selects the smallest value of column per group, for use with UserGroupBy.Aggregate.
+func Min(column string) runtime.Aggregate {
+	return runtime.Min(column)
+}
+
+// Max selects the largest value of column per group, for use with UserGroupBy.Aggregate.
+func Max(column string) runtime.Aggregate {
+	return runtime.Max(column)
+}
+
+func IDEq(value string) runtime.Predicate {
+	return runtime.Predicate{Column: FieldID, Operator: runtime.OpEqual, Value: value}
+}
+
+func IDNotEq(value string) runtime.Predicate {
+	return runtime.Predicate{Column: FieldID, Operator: runtime.OpNotEqual, Value: value}
+}
+
+func IDIn(values ...string) runtime.Predicate {
+	return runtime.In(FieldID, values)
+}
+
+func IDNotIn(values ...string) runtime.Predicate {
+	return runtime.NotIn(FieldID, values)
+}
+
+func IDGT(value string) runtime.Predicate {
+	return runtime.Predicate{Column: FieldID, Operator: runtime.OpGreaterThan, Value: value}
+}
+
+func IDGTE(value string) runtime.Predicate {
+	return runtime.Predicate{Column: FieldID, Operator: runtime.OpGTE, Value: value}
+}
+
+func IDLT(value string) runtime.Predicate {
+	return runtime.Predicate{Column: FieldID, Operator: runtime.OpLessThan, Value: value}
+}
+
+func IDLTE(value string) runtime.Predicate {
+	return runtime.Predicate{Column: FieldID, Operator: runtime.OpLTE, Value: value}
+}
+
+func IDBetween(low, high string) runtime.Predicate {
+	return runtime.Between(FieldID, low, high)
+}
+
+func IDLike(value string) runtime.Predicate {
+	return runtime.Like(FieldID, value)
+}
+
+func IDILike(value string) runtime.Predicate {
+	return runtime.Predicate{Column: FieldID, Operator: runtime.OpILike, Value: value}
+}
+
+func IDHasPrefix(value string) runtime.Predicate {
+	return runtime.HasPrefix(FieldID, value)
+}
+
+func IDHasSuffix(value string) runtime.Predicate {
+	return runtime.HasSuffix(FieldID, value)
+}
+
+func IDContains(value string) runtime.Predicate {
+	return runtime.Contains(FieldID, value)
+}
+
+func SlugEq(value string) runtime.Predicate {
+	return runtime.Predicate{Column: FieldSlug, Operator: runtime.OpEqual, Value: value}
+}
+
+func SlugNotEq(value string) runtime.Predicate {
+	return runtime.Predicate{Column: FieldSlug, Operator: runtime.OpNotEqual, Value: value}
+}
+
+func SlugIn(values ...string) runtime.Predicate {
+	return runtime.In(FieldSlug, values)
+}
+
+func SlugNotIn(values ...string) runtime.Predicate {
+	return runtime.NotIn(FieldSlug, values)
+}
+
+func SlugGT(value string) runtime.Predicate {
+	return runtime.Predicate{Column: FieldSlug, Operator: runtime.OpGreaterThan, Value: value}
+}
+
+func SlugGTE(value string) runtime.Predicate {
+	return runtime.Predicate{Column: FieldSlug, Operator: runtime.OpGTE, Value: value}
+}
+
+func SlugLT(value string) runtime.Predicate {
+	return runtime.Predicate{Column: FieldSlug, Operator: runtime.OpLessThan, Value: value}
+}
+
+func SlugLTE(value string) runtime.Predicate {
+	return runtime.Predicate{Column: FieldSlug, Operator: runtime.OpLTE, Value: value}
+}
+
+func SlugBetween(low, high string) runtime.Predicate {
+	return runtime.Between(FieldSlug, low, high)
+}
+
+func SlugLike(value string) runtime.Predicate {
+	return runtime.Like(FieldSlug, value)
+}
+
+func SlugILike(value string) runtime.Predicate {
+	return runtime.Predicate{Column: FieldSlug, Operator: runtime.OpILike, Value: value}
+}
+
+func SlugHasPrefix(value string) runtime.Predicate {
+	return runtime.HasPrefix(FieldSlug, value)
+}
+
+func SlugHasSuffix(value string) runtime.Predicate {
+	return runtime.HasSuffix(FieldSlug, value)
+}
+
+func SlugContains(value string) runtime.Predicate {
+	return runtime.Contains(FieldSlug, value)
+}
+
+func CreatedAtEq(value time.Time) runtime.Predicate {
+	return runtime.Predicate{Column: FieldCreatedAt, Operator: runtime.OpEqual, Value: value}
+}
+
+func CreatedAtNotEq(value time.Time) runtime.Predicate {
+	return runtime.Predicate{Column: FieldCreatedAt, Operator: runtime.OpNotEqual, Value: value}
+}
+
+func CreatedAtIn(values ...time.Time) runtime.Predicate {
+	return runtime.In(FieldCreatedAt, values)
+}
+
+func CreatedAtNotIn(values ...time.Time) runtime.Predicate {
+	return runtime.NotIn(FieldCreatedAt, values)
+}
+
+func CreatedAtGT(value time.Time) runtime.Predicate {
+	return runtime.Predicate{Column: FieldCreatedAt, Operator: runtime.OpGreaterThan, Value: value}
+}
+
+func CreatedAtGTE(value time.Time) runtime.Predicate {
+	return runtime.Predicate{Column: FieldCreatedAt, Operator: runtime.OpGTE, Value: value}
+}
+
+func CreatedAtLT(value time.Time) runtime.Predicate {
+	return runtime.Predicate{Column: FieldCreatedAt, Operator: runtime.OpLessThan, Value: value}
+}
+
+func CreatedAtLTE(value time.Time) runtime.Predicate {
+	return runtime.Predicate{Column: FieldCreatedAt, Operator: runtime.OpLTE, Value: value}
+}
+
+func CreatedAtBetween(low, high time.Time) runtime.Predicate {
+	return runtime.Between(FieldCreatedAt, low, high)
+}
+
+func UpdatedAtEq(value time.Time) runtime.Predicate {
+	return runtime.Predicate{Column: FieldUpdatedAt, Operator: runtime.OpEqual, Value: value}
+}
+
+func UpdatedAtNotEq(value time.Time) runtime.Predicate {
+	return runtime.Predicate{Column: FieldUpdatedAt, Operator: runtime.OpNotEqual, Value: value}
+}
+
+func UpdatedAtIn(values ...time.Time) runtime.Predicate {
+	return runtime.In(FieldUpdatedAt, values)
+}
+
+func UpdatedAtNotIn(values ...time.Time) runtime.Predicate {
+	return runtime.NotIn(FieldUpdatedAt, values)
+}
+
+func UpdatedAtGT(value time.Time) runtime.Predicate {
+	return runtime.Predicate{Column: FieldUpdatedAt, Operator: runtime.OpGreaterThan, Value: value}
+}
+
+func UpdatedAtGTE(value time.Time) runtime.Predicate {
+	return runtime.Predicate{Column: FieldUpdatedAt, Operator: runtime.OpGTE, Value: value}
+}
+
+func UpdatedAtLT(value time.Time) runtime.Predicate {
+	return runtime.Predicate{Column: FieldUpdatedAt, Operator: runtime.OpLessThan, Value: value}
+}
+
+func UpdatedAtLTE(value time.Time) runtime.Predicate {
+	return runtime.Predicate{Column: FieldUpdatedAt, Operator: runtime.OpLTE, Value: value}
+}
+
+func UpdatedAtBetween(low, high time.Time) runtime.Predicate {
+	return runtime.Between(FieldUpdatedAt, low, high)
+}
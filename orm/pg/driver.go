@@ -3,6 +3,7 @@ package pg
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -91,6 +92,8 @@ type DB struct {
 	healthInterval time.Duration
 
 	routing replicaRouting
+
+	tx *Tx
 }
 
 type replicaRouting struct {
@@ -163,15 +166,10 @@ func ConnectCluster(ctx context.Context, url string, replicas []ReplicaConfig, o
 			writer.Close()
 			return nil, err
 		}
-		name := cfg.Name
-		if name == "" {
-			name = fmt.Sprintf("replica-%d", i+1)
+		if cfg.Name == "" {
+			cfg.Name = fmt.Sprintf("replica-%d", i+1)
 		}
-		db.replicas = append(db.replicas, &replicaPool{
-			name:   name,
-			pool:   pool,
-			config: cfg,
-		})
+		db.AddReplica(cfg, pool)
 	}
 
 	return db, nil
@@ -235,12 +233,43 @@ func (db *DB) Select(ctx context.Context, spec runtime.SelectSpec) (pgx.Rows, er
 	return db.queryWithOperation(ctx, runtime.OperationSelect, spec.Table, sql, args...)
 }
 
+// CopyFromer is implemented by pools that support the COPY protocol (pgxpool.Pool, pgx.Conn, pgx.Tx).
+type CopyFromer interface {
+	CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, src pgx.CopyFromSource) (int64, error)
+}
+
+// CopyFrom streams src into table on the writer pool with COPY FROM STDIN and returns the number
+// of rows copied. Schema-qualified tables such as "app.users" are split into their parts.
+func (db *DB) CopyFrom(ctx context.Context, table string, columns []string, src pgx.CopyFromSource) (int64, error) {
+	writer := db.writerPool()
+	if writer == nil {
+		return 0, fmt.Errorf("pg: writer pool is unavailable")
+	}
+	copier, ok := writer.(CopyFromer)
+	if !ok {
+		return 0, fmt.Errorf("pg: writer pool %T does not support COPY", writer)
+	}
+	sql := fmt.Sprintf("COPY %s (%s) FROM STDIN", table, strings.Join(columns, ", "))
+	attrs := buildObservationAttrs("primary", false, observationFlags{})
+	obs := db.Observer.Observe(ctx, runtime.OperationCopy, table, sql, nil, runtime.WithObservationAttributes(attrs...))
+	count, err := copier.CopyFrom(obs.Context(), pgx.Identifier(strings.Split(table, ".")), columns, src)
+	obs.End(err)
+	return count, err
+}
+
 // Aggregate issues an aggregate query generated from runtime specs against the appropriate pool.
 func (db *DB) Aggregate(ctx context.Context, spec runtime.AggregateSpec) pgx.Row {
 	sql, args := runtime.BuildAggregateSQL(spec)
 	return db.queryRowWithOperation(ctx, runtime.OperationAggregate, spec.Table, sql, args...)
 }
 
+// AggregateGroups issues a grouped aggregate query, returning one row per group with the GroupBy
+// columns followed by the aggregate.
+func (db *DB) AggregateGroups(ctx context.Context, spec runtime.AggregateSpec) (pgx.Rows, error) {
+	sql, args := runtime.BuildAggregateSQL(spec)
+	return db.queryWithOperation(ctx, runtime.OperationAggregate, spec.Table, sql, args...)
+}
+
 type observationFlags struct {
 	failover    bool
 	reason      error
@@ -454,6 +483,19 @@ func (db *DB) UseObserver(observer runtime.QueryObserver) {
 	db.Observer = observer
 }
 
+// AddReplica registers pool as a read replica described by cfg, for pools opened by the caller
+// rather than through ConnectCluster. Unnamed replicas are named after their position.
+func (db *DB) AddReplica(cfg ReplicaConfig, pool Pool) {
+	if db == nil || pool == nil {
+		return
+	}
+	name := cfg.Name
+	if name == "" {
+		name = fmt.Sprintf("replica-%d", len(db.replicas)+1)
+	}
+	db.replicas = append(db.replicas, &replicaPool{name: name, pool: pool, config: cfg})
+}
+
 // UseReplicaHealthCheck overrides the health probe used for replicas.
 func (db *DB) UseReplicaHealthCheck(check ReplicaHealthCheck) {
 	if db == nil {
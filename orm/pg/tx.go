@@ -0,0 +1,157 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrTxDone is returned when committing or rolling back a transaction that already finished.
+var ErrTxDone = errors.New("pg: transaction has already been committed or rolled back")
+
+// TxBeginner is implemented by pools capable of starting transactions (pgxpool.Pool, pgx.Conn).
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error)
+}
+
+// Tx represents a database transaction or a savepoint nested inside one. All statements issued
+// through DB() share the same underlying pgx.Tx.
+type Tx struct {
+	db        *DB
+	tx        pgx.Tx
+	parent    *Tx
+	savepoint string
+	seq       int
+	done      bool
+}
+
+// BeginTx starts a transaction on the writer pool. When the handle is already bound to a
+// transaction a SAVEPOINT is created instead so callers can nest units of work.
+func (db *DB) BeginTx(ctx context.Context, opts pgx.TxOptions) (*Tx, error) {
+	if db == nil {
+		return nil, errors.New("pg: database handle is nil")
+	}
+	if db.tx != nil {
+		return db.tx.begin(ctx)
+	}
+	writer := db.writerPool()
+	if writer == nil {
+		return nil, errors.New("pg: writer pool is unavailable")
+	}
+	beginner, ok := writer.(TxBeginner)
+	if !ok {
+		return nil, fmt.Errorf("pg: writer pool %T does not support transactions", writer)
+	}
+	raw, err := beginner.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	tx := &Tx{tx: raw}
+	tx.db = db.bind(tx)
+	return tx, nil
+}
+
+// InTx reports whether the handle is bound to a transaction.
+func (db *DB) InTx() bool { return db != nil && db.tx != nil }
+
+func (db *DB) bind(tx *Tx) *DB {
+	pool := txPool{tx: tx.tx}
+	return &DB{
+		Pool:     pool,
+		writer:   pool,
+		Observer: db.Observer,
+		tx:       tx,
+	}
+}
+
+func (tx *Tx) begin(ctx context.Context) (*Tx, error) {
+	if tx.done {
+		return nil, ErrTxDone
+	}
+	root := tx
+	for root.parent != nil {
+		root = root.parent
+	}
+	root.seq++
+	name := fmt.Sprintf("sp_%d", root.seq)
+	if _, err := tx.tx.Exec(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+	child := &Tx{tx: tx.tx, parent: tx, savepoint: name}
+	child.db = tx.db.bind(child)
+	return child, nil
+}
+
+// DB returns a handle whose reads and writes execute inside the transaction.
+func (tx *Tx) DB() *DB {
+	if tx == nil {
+		return nil
+	}
+	return tx.db
+}
+
+// Raw exposes the underlying pgx transaction.
+func (tx *Tx) Raw() pgx.Tx {
+	if tx == nil {
+		return nil
+	}
+	return tx.tx
+}
+
+// Nested reports whether the transaction is a savepoint inside an outer transaction.
+func (tx *Tx) Nested() bool { return tx != nil && tx.parent != nil }
+
+// Commit commits the transaction, or releases the savepoint when nested.
+func (tx *Tx) Commit(ctx context.Context) error {
+	if tx == nil || tx.done {
+		return ErrTxDone
+	}
+	tx.done = true
+	if tx.parent != nil {
+		_, err := tx.tx.Exec(ctx, "RELEASE SAVEPOINT "+tx.savepoint)
+		return err
+	}
+	return tx.tx.Commit(ctx)
+}
+
+// Rollback aborts the transaction, or rolls back to the savepoint when nested. Rolling back a
+// finished transaction is a no-op so callers can defer it unconditionally.
+func (tx *Tx) Rollback(ctx context.Context) error {
+	if tx == nil || tx.done {
+		return nil
+	}
+	tx.done = true
+	if tx.parent != nil {
+		_, err := tx.tx.Exec(ctx, "ROLLBACK TO SAVEPOINT "+tx.savepoint)
+		return err
+	}
+	return tx.tx.Rollback(ctx)
+}
+
+// txPool adapts a pgx.Tx to the Pool interface so transaction-bound handles reuse the regular
+// query paths.
+type txPool struct {
+	tx pgx.Tx
+}
+
+func (p txPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return p.tx.Query(ctx, sql, args...)
+}
+
+func (p txPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return p.tx.QueryRow(ctx, sql, args...)
+}
+
+func (p txPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return p.tx.Exec(ctx, sql, args...)
+}
+
+func (p txPool) CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, src pgx.CopyFromSource) (int64, error) {
+	return p.tx.CopyFrom(ctx, table, columns, src)
+}
+
+// Close is a no-op; the transaction owner is responsible for Commit/Rollback.
+func (txPool) Close() {}
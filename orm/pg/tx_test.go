@@ -0,0 +1,155 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v4"
+)
+
+type mockConnPool struct {
+	pgxmock.PgxConnIface
+}
+
+func (m mockConnPool) Close() { _ = m.PgxConnIface.Close(context.Background()) }
+
+func newMockDB(t *testing.T) (*DB, pgxmock.PgxConnIface) {
+	t.Helper()
+	mock, err := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("pgxmock.NewConn: %v", err)
+	}
+	t.Cleanup(func() { _ = mock.Close(context.Background()) })
+	return &DB{Pool: mockConnPool{PgxConnIface: mock}}, mock
+}
+
+func TestBeginTxRoutesStatementsThroughTransaction(t *testing.T) {
+	ctx := context.Background()
+	db, mock := newMockDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET name = $1").WithArgs("x").WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectQuery("SELECT id FROM users").WillReturnRows(mock.NewRows([]string{"id"}).AddRow("u1"))
+	mock.ExpectCommit()
+
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if !tx.DB().InTx() || db.InTx() {
+		t.Fatalf("expected only the transaction handle to report InTx")
+	}
+	if _, err := tx.DB().Writer().Exec(ctx, "UPDATE users SET name = $1", "x"); err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	rows, err := tx.DB().Query(ctx, "users", "SELECT id FROM users")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	rows.Close()
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if err := tx.Commit(ctx); !errors.Is(err, ErrTxDone) {
+		t.Fatalf("expected ErrTxDone on second commit, got %v", err)
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("rollback after commit should be a no-op, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestNestedTxUsesSavepoints(t *testing.T) {
+	ctx := context.Background()
+	db, mock := newMockDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(pgxmock.NewResult("SAVEPOINT", 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_1").WillReturnResult(pgxmock.NewResult("ROLLBACK", 0))
+	mock.ExpectExec("SAVEPOINT sp_2").WillReturnResult(pgxmock.NewResult("SAVEPOINT", 0))
+	mock.ExpectExec("RELEASE SAVEPOINT sp_2").WillReturnResult(pgxmock.NewResult("RELEASE", 0))
+	mock.ExpectRollback()
+
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	first, err := tx.DB().BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("savepoint: %v", err)
+	}
+	if !first.Nested() {
+		t.Fatalf("expected nested transaction")
+	}
+	if err := first.Rollback(ctx); err != nil {
+		t.Fatalf("rollback savepoint: %v", err)
+	}
+	second, err := tx.DB().BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("second savepoint: %v", err)
+	}
+	if err := second.Commit(ctx); err != nil {
+		t.Fatalf("release savepoint: %v", err)
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestBeginTxRequiresTransactionalPool(t *testing.T) {
+	db := &DB{Pool: &fakePool{}}
+	if _, err := db.BeginTx(context.Background(), pgx.TxOptions{}); err == nil {
+		t.Fatalf("expected error for pool without BeginTx support")
+	}
+}
+
+func TestCopyFromUsesTransaction(t *testing.T) {
+	ctx := context.Background()
+	db, mock := newMockDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectCopyFrom(pgx.Identifier{"users"}, []string{"id", "name"}).WillReturnResult(2)
+	mock.ExpectCommit()
+
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	count, err := tx.DB().CopyFrom(ctx, "users", []string{"id", "name"}, pgx.CopyFromRows([][]any{{"u1", "ada"}, {"u2", "bob"}}))
+	if err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 copied rows, got %d", count)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+	if _, err := (&DB{Pool: &fakePool{}}).CopyFrom(ctx, "users", []string{"id"}, pgx.CopyFromRows(nil)); err == nil {
+		t.Fatalf("expected error for pool without CopyFrom support")
+	}
+}
+
+func TestCopyFromSplitsSchemaQualifiedTables(t *testing.T) {
+	ctx := context.Background()
+	db, mock := newMockDB(t)
+
+	mock.ExpectCopyFrom(pgx.Identifier{"app", "users"}, []string{"id"}).WillReturnResult(1)
+	count, err := db.CopyFrom(ctx, "app.users", []string{"id"}, pgx.CopyFromRows([][]any{{"u1"}}))
+	if err != nil || count != 1 {
+		t.Fatalf("expected one row copied into app.users, got %d (err %v)", count, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package runtime
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Count counts the rows of each group, selected as count.
+func Count() Aggregate {
+	return Aggregate{Func: AggCount, Column: "*", Alias: "count"}
+}
+
+// Sum adds up column, selected as sum_<column>.
+func Sum(column string) Aggregate {
+	return newAggregate(AggSum, column)
+}
+
+// Avg averages column, selected as avg_<column>.
+func Avg(column string) Aggregate {
+	return newAggregate(AggAvg, column)
+}
+
+// Min selects the smallest value of column as min_<column>.
+func Min(column string) Aggregate {
+	return newAggregate(AggMin, column)
+}
+
+// Max selects the largest value of column as max_<column>.
+func Max(column string) Aggregate {
+	return newAggregate(AggMax, column)
+}
+
+func newAggregate(fn AggregateFunc, column string) Aggregate {
+	return Aggregate{Func: fn, Column: column, Alias: strings.ToLower(string(fn)) + "_" + column}
+}
+
+// As selects the aggregate under alias instead of its default name.
+func (a Aggregate) As(alias string) Aggregate {
+	a.Alias = alias
+	return a
+}
+
+// Expr renders the aggregate call, e.g. SUM(amount), for use as the column of a Having predicate.
+func (a Aggregate) Expr() string {
+	return a.expr("")
+}
+
+func (a Aggregate) expr(qualifier string) string {
+	column := a.Column
+	switch {
+	case column == "" || column == "*":
+		column = "*"
+	case qualifier != "":
+		column = qualifier + "." + column
+	}
+	return string(a.Func) + "(" + column + ")"
+}
+
+// ScanGroups reads every row of rows into dest, a pointer to a slice of structs or struct pointers,
+// and closes rows. Columns are matched to fields by their db tag, or else by name ignoring case and
+// underscores, so the column sum_amount fills the field SumAmount. A column without a matching
+// field is an error.
+func ScanGroups(rows pgx.Rows, dest any) error {
+	defer rows.Close()
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Pointer || slice.IsNil() || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("scan groups: destination must be a pointer to a slice, got %T", dest)
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	structType, pointers := elemType, false
+	if structType.Kind() == reflect.Pointer {
+		structType, pointers = structType.Elem(), true
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("scan groups: slice elements must be structs, got %s", elemType)
+	}
+	descriptions := rows.FieldDescriptions()
+	fields := make([]int, len(descriptions))
+	for i, desc := range descriptions {
+		idx, ok := groupFieldIndex(structType, desc.Name)
+		if !ok {
+			return fmt.Errorf("scan groups: %s has no field for column %q", structType, desc.Name)
+		}
+		fields[i] = idx
+	}
+	targets := make([]any, len(fields))
+	for rows.Next() {
+		item := reflect.New(structType)
+		for i, idx := range fields {
+			targets[i] = item.Elem().Field(idx).Addr().Interface()
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return err
+		}
+		if pointers {
+			slice.Set(reflect.Append(slice, item))
+		} else {
+			slice.Set(reflect.Append(slice, item.Elem()))
+		}
+	}
+	return rows.Err()
+}
+
+// groupFieldIndex finds the exported field of typ receiving column.
+func groupFieldIndex(typ reflect.Type, column string) (int, bool) {
+	normalized := strings.ReplaceAll(column, "_", "")
+	match := -1
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if tag, ok := field.Tag.Lookup("db"); ok {
+			if name, _, _ := strings.Cut(tag, ","); name == column {
+				return i, true
+			}
+			continue
+		}
+		if match < 0 && strings.EqualFold(field.Name, normalized) {
+			match = i
+		}
+	}
+	return match, match >= 0
+}
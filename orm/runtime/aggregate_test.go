@@ -0,0 +1,43 @@
+package runtime
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanGroups(t *testing.T) {
+	type bucket struct {
+		Currency  string
+		Count     int
+		Total     int `db:"sum_amount"`
+		Remaining int
+	}
+	rows := newSliceRows([]any{"EUR", 2, 30}, []any{"USD", 1, 5})
+	rows.columns = []string{"currency", "count", "sum_amount"}
+
+	var buckets []*bucket
+	if err := ScanGroups(rows, &buckets); err != nil {
+		t.Fatalf("ScanGroups: %v", err)
+	}
+	want := []*bucket{{Currency: "EUR", Count: 2, Total: 30}, {Currency: "USD", Count: 1, Total: 5}}
+	if !reflect.DeepEqual(buckets, want) {
+		t.Fatalf("unexpected buckets: %+v", buckets)
+	}
+	if !rows.closed {
+		t.Fatalf("expected rows to be closed")
+	}
+}
+
+func TestScanGroupsErrors(t *testing.T) {
+	type bucket struct{ Count int }
+	rows := newSliceRows([]any{1, 2})
+	rows.columns = []string{"count", "max_amount"}
+	var buckets []bucket
+	if err := ScanGroups(rows, &buckets); err == nil || !strings.Contains(err.Error(), `"max_amount"`) {
+		t.Fatalf("expected unmatched column error, got %v", err)
+	}
+	if err := ScanGroups(newSliceRows(), buckets); err == nil {
+		t.Fatalf("expected error for non-pointer destination")
+	}
+}
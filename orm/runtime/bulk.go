@@ -10,6 +10,9 @@ type BulkInsertSpec struct {
 	Columns   []string
 	Returning []string
 	Rows      [][]any
+	// OnConflict, when set, turns the insert into an upsert. RETURNING then only yields rows
+	// that were inserted or updated.
+	OnConflict *OnConflict
 }
 
 func BuildBulkInsertSQL(spec BulkInsertSpec) (string, []any, error) {
@@ -37,16 +40,57 @@ func BuildBulkInsertSQL(spec BulkInsertSpec) (string, []any, error) {
 		}
 		values[i] = fmt.Sprintf("(%s)", strings.Join(placeholders, ", "))
 	}
-	sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", spec.Table, strings.Join(spec.Columns, ", "), strings.Join(values, ", "))
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "INSERT INTO %s (%s) VALUES %s", spec.Table, strings.Join(spec.Columns, ", "), strings.Join(values, ", "))
+	if spec.OnConflict != nil {
+		var err error
+		if args, err = writeOnConflict(&sb, spec.Table, *spec.OnConflict, args); err != nil {
+			return "", nil, err
+		}
+	}
 	if len(spec.Returning) > 0 {
-		sql += " RETURNING " + strings.Join(spec.Returning, ", ")
+		sb.WriteString(" RETURNING ")
+		sb.WriteString(strings.Join(spec.Returning, ", "))
 	}
-	return sql, args, nil
+	return sb.String(), args, nil
+}
+
+// MaxBindParams is the most bind parameters PostgreSQL accepts in one statement.
+const MaxBindParams = 65535
+
+// ChunkBulkInsert splits spec into consecutive specs whose statements each stay within
+// MaxBindParams, counting the parameters of the conflict clause once per chunk.
+func ChunkBulkInsert(spec BulkInsertSpec) []BulkInsertSpec {
+	budget := MaxBindParams
+	if spec.OnConflict != nil {
+		var sb strings.Builder
+		args, _ := writeOnConflict(&sb, spec.Table, *spec.OnConflict, nil)
+		budget -= len(args)
+	}
+	perChunk := len(spec.Rows)
+	if len(spec.Columns) > 0 {
+		perChunk = max(budget/len(spec.Columns), 1)
+	}
+	if len(spec.Rows) <= perChunk {
+		return []BulkInsertSpec{spec}
+	}
+	chunks := make([]BulkInsertSpec, 0, (len(spec.Rows)+perChunk-1)/perChunk)
+	for start := 0; start < len(spec.Rows); start += perChunk {
+		chunk := spec
+		chunk.Rows = spec.Rows[start:min(start+perChunk, len(spec.Rows))]
+		chunks = append(chunks, chunk)
+	}
+	return chunks
 }
 
+// expectedVersionColumn names the data column holding the expected row versions, so it never
+// shadows a table column in RETURNING.
+const expectedVersionColumn = "erm_version"
+
 type BulkUpdateRow struct {
 	Primary any
 	Values  []any
+	Version any
 }
 
 type BulkUpdateSpec struct {
@@ -55,6 +99,11 @@ type BulkUpdateSpec struct {
 	Columns       []string
 	Returning     []string
 	Rows          []BulkUpdateRow
+	// VersionColumn, when set, only updates rows whose version equals BulkUpdateRow.Version and
+	// increments it.
+	VersionColumn string
+	// SoftDeleteColumn, when set, leaves rows whose column is not NULL untouched.
+	SoftDeleteColumn string
 }
 
 func BuildBulkUpdateSQL(spec BulkUpdateSpec) (string, []any, error) {
@@ -75,7 +124,11 @@ func BuildBulkUpdateSQL(spec BulkUpdateSpec) (string, []any, error) {
 	for i, col := range spec.Columns {
 		assignments[i] = fmt.Sprintf("%s = data.%s", col, col)
 	}
-	args := make([]any, 0, len(spec.Rows)*(len(spec.Columns)+1))
+	if spec.VersionColumn != "" {
+		cols = append(cols, expectedVersionColumn)
+		assignments = append(assignments, fmt.Sprintf("%s = t.%s + 1", spec.VersionColumn, spec.VersionColumn))
+	}
+	args := make([]any, 0, len(spec.Rows)*len(cols))
 	values := make([]string, len(spec.Rows))
 	param := 1
 	for i, row := range spec.Rows {
@@ -91,6 +144,11 @@ func BuildBulkUpdateSQL(spec BulkUpdateSpec) (string, []any, error) {
 			args = append(args, value)
 			param++
 		}
+		if spec.VersionColumn != "" {
+			placeholders[len(cols)-1] = fmt.Sprintf("$%d", param)
+			args = append(args, row.Version)
+			param++
+		}
 		values[i] = fmt.Sprintf("(%s)", strings.Join(placeholders, ", "))
 	}
 	sql := fmt.Sprintf("WITH data(%s) AS (VALUES %s) UPDATE %s AS t SET %s FROM data WHERE t.%s = data.%s",
@@ -101,6 +159,12 @@ func BuildBulkUpdateSQL(spec BulkUpdateSpec) (string, []any, error) {
 		spec.PrimaryColumn,
 		spec.PrimaryColumn,
 	)
+	if spec.VersionColumn != "" {
+		sql += fmt.Sprintf(" AND t.%s = data.%s", spec.VersionColumn, expectedVersionColumn)
+	}
+	if spec.SoftDeleteColumn != "" {
+		sql += fmt.Sprintf(" AND t.%s IS NULL", spec.SoftDeleteColumn)
+	}
 	if len(spec.Returning) > 0 {
 		sql += " RETURNING " + strings.Join(spec.Returning, ", ")
 	}
@@ -111,6 +175,8 @@ type BulkDeleteSpec struct {
 	Table         string
 	PrimaryColumn string
 	IDs           []any
+	// SoftDeleteColumn, when set, stamps the column with now() on live rows instead of deleting them.
+	SoftDeleteColumn string
 }
 
 func BuildBulkDeleteSQL(spec BulkDeleteSpec) (string, []any, error) {
@@ -129,6 +195,76 @@ func BuildBulkDeleteSQL(spec BulkDeleteSpec) (string, []any, error) {
 		placeholders[i] = fmt.Sprintf("$%d", i+1)
 		args[i] = id
 	}
+	if spec.SoftDeleteColumn != "" {
+		sql := fmt.Sprintf("UPDATE %s SET %s = now() WHERE %s IN (%s) AND %s IS NULL", spec.Table, spec.SoftDeleteColumn, spec.PrimaryColumn, strings.Join(placeholders, ", "), spec.SoftDeleteColumn)
+		return sql, args, nil
+	}
 	sql := fmt.Sprintf("DELETE FROM %s WHERE %s IN (%s)", spec.Table, spec.PrimaryColumn, strings.Join(placeholders, ", "))
 	return sql, args, nil
 }
+
+// EdgeLinkOp selects how BuildEdgeLinkSQL changes the edge of a source row.
+type EdgeLinkOp int
+
+const (
+	// EdgeLinkAdd links the targets to the source row.
+	EdgeLinkAdd EdgeLinkOp = iota
+	// EdgeLinkRemove unlinks the targets from the source row.
+	EdgeLinkRemove
+	// EdgeLinkClear unlinks every target of the source row.
+	EdgeLinkClear
+)
+
+// EdgeLinkSpec describes linking targets to, or unlinking them from, one source row. Edges stored
+// in a link table (Join.Through) insert and delete link rows; other edges set or clear the
+// foreign key column Join.TargetColumn of the target rows, which are identified by TargetKey.
+type EdgeLinkSpec struct {
+	Join    EdgeJoin
+	Op      EdgeLinkOp
+	Source  any
+	Targets []any
+	// TargetKey is the primary column of the target table. Foreign key edges return it for every
+	// updated row, so callers can invalidate what they cached of those rows.
+	TargetKey string
+}
+
+func BuildEdgeLinkSQL(spec EdgeLinkSpec) (string, []any, error) {
+	join := spec.Join
+	if join.TargetTable == "" || join.TargetColumn == "" {
+		return "", nil, fmt.Errorf("edge target is required")
+	}
+	if spec.Op != EdgeLinkClear && len(spec.Targets) == 0 {
+		return "", nil, fmt.Errorf("at least one target is required")
+	}
+	args := []any{spec.Source}
+	placeholders := make([]string, len(spec.Targets))
+	for i, target := range spec.Targets {
+		placeholders[i] = fmt.Sprintf("$%d", i+2)
+		args = append(args, target)
+	}
+	if join.Through != "" {
+		switch spec.Op {
+		case EdgeLinkAdd:
+			rows := make([]string, len(placeholders))
+			for i, placeholder := range placeholders {
+				rows[i] = fmt.Sprintf("($1, %s)", placeholder)
+			}
+			return fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES %s ON CONFLICT DO NOTHING", join.Through, join.ThroughSourceColumn, join.ThroughTargetColumn, strings.Join(rows, ", ")), args, nil
+		case EdgeLinkRemove:
+			return fmt.Sprintf("DELETE FROM %s WHERE %s = $1 AND %s IN (%s)", join.Through, join.ThroughSourceColumn, join.ThroughTargetColumn, strings.Join(placeholders, ", ")), args, nil
+		default:
+			return fmt.Sprintf("DELETE FROM %s WHERE %s = $1", join.Through, join.ThroughSourceColumn), args[:1], nil
+		}
+	}
+	if spec.TargetKey == "" {
+		return "", nil, fmt.Errorf("target key is required")
+	}
+	switch spec.Op {
+	case EdgeLinkAdd:
+		return fmt.Sprintf("UPDATE %s SET %s = $1 WHERE %s IN (%s) RETURNING %s", join.TargetTable, join.TargetColumn, spec.TargetKey, strings.Join(placeholders, ", "), spec.TargetKey), args, nil
+	case EdgeLinkRemove:
+		return fmt.Sprintf("UPDATE %s SET %s = NULL WHERE %s = $1 AND %s IN (%s) RETURNING %s", join.TargetTable, join.TargetColumn, join.TargetColumn, spec.TargetKey, strings.Join(placeholders, ", "), spec.TargetKey), args, nil
+	default:
+		return fmt.Sprintf("UPDATE %s SET %s = NULL WHERE %s = $1 RETURNING %s", join.TargetTable, join.TargetColumn, join.TargetColumn, spec.TargetKey), args[:1], nil
+	}
+}
@@ -47,6 +47,48 @@ func TestBuildBulkUpdateSQL(t *testing.T) {
 	}
 }
 
+func TestBuildBulkUpdateSQLSoftDelete(t *testing.T) {
+	sql, _, err := BuildBulkUpdateSQL(BulkUpdateSpec{
+		Table:            "users",
+		PrimaryColumn:    "id",
+		Columns:          []string{"name"},
+		Returning:        []string{"id", "name"},
+		SoftDeleteColumn: "deleted_at",
+		Rows:             []BulkUpdateRow{{Primary: 1, Values: []any{"alice"}}},
+	})
+	if err != nil {
+		t.Fatalf("build update: %v", err)
+	}
+	wantSQL := "WITH data(id, name) AS (VALUES ($1, $2)) UPDATE users AS t SET name = data.name FROM data WHERE t.id = data.id AND t.deleted_at IS NULL RETURNING id, name"
+	if sql != wantSQL {
+		t.Fatalf("sql = %q, want %q", sql, wantSQL)
+	}
+}
+
+func TestBuildBulkUpdateSQLVersion(t *testing.T) {
+	sql, args, err := BuildBulkUpdateSQL(BulkUpdateSpec{
+		Table:         "users",
+		PrimaryColumn: "id",
+		Columns:       []string{"name"},
+		Returning:     []string{"id", "name", "version"},
+		VersionColumn: "version",
+		Rows: []BulkUpdateRow{
+			{Primary: 1, Values: []any{"alice"}, Version: 3},
+			{Primary: 2, Values: []any{"bob"}, Version: 7},
+		},
+	})
+	if err != nil {
+		t.Fatalf("build update: %v", err)
+	}
+	wantSQL := "WITH data(id, name, erm_version) AS (VALUES ($1, $2, $3), ($4, $5, $6)) UPDATE users AS t SET name = data.name, version = t.version + 1 FROM data WHERE t.id = data.id AND t.version = data.erm_version RETURNING id, name, version"
+	if sql != wantSQL {
+		t.Fatalf("sql = %q, want %q", sql, wantSQL)
+	}
+	if len(args) != 6 || args[2] != 3 || args[5] != 7 {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
 func TestBuildBulkDeleteSQL(t *testing.T) {
 	sql, args, err := BuildBulkDeleteSQL(BulkDeleteSpec{
 		Table:         "users",
@@ -64,3 +106,150 @@ func TestBuildBulkDeleteSQL(t *testing.T) {
 		t.Fatalf("unexpected args: %#v", args)
 	}
 }
+
+func TestBuildBulkDeleteSQLSoftDelete(t *testing.T) {
+	sql, args, err := BuildBulkDeleteSQL(BulkDeleteSpec{
+		Table:            "users",
+		PrimaryColumn:    "id",
+		IDs:              []any{1, 2},
+		SoftDeleteColumn: "deleted_at",
+	})
+	if err != nil {
+		t.Fatalf("build delete: %v", err)
+	}
+	wantSQL := "UPDATE users SET deleted_at = now() WHERE id IN ($1, $2) AND deleted_at IS NULL"
+	if sql != wantSQL {
+		t.Fatalf("sql = %q, want %q", sql, wantSQL)
+	}
+	if len(args) != 2 {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestBuildBulkInsertSQLOnConflict(t *testing.T) {
+	conflict := NewOnConflict(
+		ConflictTarget{Columns: []string{"email"}, Where: "deleted_at IS NULL"},
+		UpdateExcluded("name"),
+		UpdateCoalesce("bio"),
+		UpdateIncrement("logins"),
+		UpdateValue("source", "import"),
+		UpdateWhere(Compare("locked", OpEqual, false)),
+	)
+	sql, args, err := BuildBulkInsertSQL(BulkInsertSpec{
+		Table:      "users",
+		Columns:    []string{"email", "name", "bio", "logins", "source"},
+		Returning:  []string{"id"},
+		Rows:       [][]any{{"a@example.com", "A", nil, 1, "api"}},
+		OnConflict: &conflict,
+	})
+	if err != nil {
+		t.Fatalf("build upsert: %v", err)
+	}
+	wantSQL := "INSERT INTO users (email, name, bio, logins, source) VALUES ($1, $2, $3, $4, $5)" +
+		" ON CONFLICT (email) WHERE deleted_at IS NULL DO UPDATE SET name = EXCLUDED.name, bio = COALESCE(users.bio, EXCLUDED.bio)," +
+		" logins = users.logins + EXCLUDED.logins, source = $6 WHERE users.locked = $7 RETURNING id"
+	if sql != wantSQL {
+		t.Fatalf("sql = %q, want %q", sql, wantSQL)
+	}
+	if len(args) != 7 || args[5] != "import" || args[6] != false {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestBuildBulkInsertSQLOnConflictVersion(t *testing.T) {
+	conflict := NewOnConflict(ConflictColumns("email"), UpdateExcluded("name"))
+	conflict.VersionColumn = "version"
+	sql, _, err := BuildBulkInsertSQL(BulkInsertSpec{
+		Table:      "users",
+		Columns:    []string{"email", "name", "version"},
+		Rows:       [][]any{{"a@example.com", "A", 1}},
+		OnConflict: &conflict,
+	})
+	if err != nil {
+		t.Fatalf("build upsert: %v", err)
+	}
+	wantSQL := "INSERT INTO users (email, name, version) VALUES ($1, $2, $3) ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name, version = users.version + 1"
+	if sql != wantSQL {
+		t.Fatalf("sql = %q, want %q", sql, wantSQL)
+	}
+}
+
+func TestOnConflictDefaultsAndValidation(t *testing.T) {
+	conflict := NewOnConflict(ConflictColumns("email")).WithDefaultUpdates("email", "name")
+	if len(conflict.Updates) != 1 || conflict.Updates[0].Column != "name" || conflict.Updates[0].Policy != SetExcluded {
+		t.Fatalf("unexpected default updates: %#v", conflict.Updates)
+	}
+	if !NewOnConflict(ConflictColumns("email")).WithDefaultUpdates("email").DoNothing {
+		t.Fatalf("expected DO NOTHING when only target columns remain")
+	}
+
+	nothing := NewOnConflict(ConflictConstraint("users_email_key"), DoNothing())
+	sql, _, err := BuildBulkInsertSQL(BulkInsertSpec{Table: "users", Columns: []string{"email"}, Rows: [][]any{{"a"}}, OnConflict: &nothing})
+	if err != nil || sql != "INSERT INTO users (email) VALUES ($1) ON CONFLICT ON CONSTRAINT users_email_key DO NOTHING" {
+		t.Fatalf("unexpected do nothing sql %q (%v)", sql, err)
+	}
+
+	untargeted := NewOnConflict(ConflictTarget{}, UpdateExcluded("email"))
+	if _, _, err := BuildBulkInsertSQL(BulkInsertSpec{Table: "users", Columns: []string{"email"}, Rows: [][]any{{"a"}}, OnConflict: &untargeted}); err == nil {
+		t.Fatalf("expected error for DO UPDATE without target")
+	}
+}
+
+func TestChunkBulkInsert(t *testing.T) {
+	rows := make([][]any, 40000)
+	for i := range rows {
+		rows[i] = []any{i, "name"}
+	}
+	spec := BulkInsertSpec{Table: "users", Columns: []string{"id", "name"}, Rows: rows}
+	chunks := ChunkBulkInsert(spec)
+	if len(chunks) != 2 || len(chunks[0].Rows) != 32767 || len(chunks[1].Rows) != 40000-32767 {
+		t.Fatalf("unexpected chunks: %d", len(chunks))
+	}
+	for _, chunk := range chunks {
+		if _, args, err := BuildBulkInsertSQL(chunk); err != nil || len(args) > MaxBindParams {
+			t.Fatalf("chunk exceeds parameter limit: %d args, err %v", len(args), err)
+		}
+	}
+
+	conflict := NewOnConflict(ConflictColumns("id"), UpdateValue("name", "x"), UpdateWhere(Compare("name", OpNotEqual, "admin")))
+	spec.Rows = rows[:32767]
+	spec.OnConflict = &conflict
+	if chunks := ChunkBulkInsert(spec); len(chunks) != 2 || len(chunks[0].Rows) != 32766 {
+		t.Fatalf("expected the conflict parameters to force a second chunk, got %d", len(chunks))
+	}
+	if chunks := ChunkBulkInsert(BulkInsertSpec{Table: "users", Columns: []string{"id"}, Rows: rows[:3]}); len(chunks) != 1 {
+		t.Fatalf("expected one chunk for small inserts, got %d", len(chunks))
+	}
+}
+
+func TestBuildEdgeLinkSQL(t *testing.T) {
+	tags := EdgeJoin{SourceTable: "posts", SourceColumn: "id", TargetTable: "tags", TargetColumn: "id", Through: "posts_tags", ThroughSourceColumn: "post_id", ThroughTargetColumn: "tag_id"}
+	comments := EdgeJoin{SourceTable: "posts", SourceColumn: "id", TargetTable: "comments", TargetColumn: "post_id"}
+	cases := []struct {
+		spec     EdgeLinkSpec
+		wantSQL  string
+		wantArgs int
+	}{
+		{EdgeLinkSpec{Join: tags, Op: EdgeLinkAdd, Source: "p1", Targets: []any{"t1", "t2"}}, "INSERT INTO posts_tags (post_id, tag_id) VALUES ($1, $2), ($1, $3) ON CONFLICT DO NOTHING", 3},
+		{EdgeLinkSpec{Join: tags, Op: EdgeLinkRemove, Source: "p1", Targets: []any{"t1"}}, "DELETE FROM posts_tags WHERE post_id = $1 AND tag_id IN ($2)", 2},
+		{EdgeLinkSpec{Join: tags, Op: EdgeLinkClear, Source: "p1"}, "DELETE FROM posts_tags WHERE post_id = $1", 1},
+		{EdgeLinkSpec{Join: comments, Op: EdgeLinkAdd, Source: "p1", Targets: []any{"c1", "c2"}, TargetKey: "id"}, "UPDATE comments SET post_id = $1 WHERE id IN ($2, $3) RETURNING id", 3},
+		{EdgeLinkSpec{Join: comments, Op: EdgeLinkRemove, Source: "p1", Targets: []any{"c1"}, TargetKey: "id"}, "UPDATE comments SET post_id = NULL WHERE post_id = $1 AND id IN ($2) RETURNING id", 2},
+		{EdgeLinkSpec{Join: comments, Op: EdgeLinkClear, Source: "p1", TargetKey: "id"}, "UPDATE comments SET post_id = NULL WHERE post_id = $1 RETURNING id", 1},
+	}
+	for _, tc := range cases {
+		sql, args, err := BuildEdgeLinkSQL(tc.spec)
+		if err != nil {
+			t.Fatalf("build edge link: %v", err)
+		}
+		if sql != tc.wantSQL {
+			t.Fatalf("sql = %q, want %q", sql, tc.wantSQL)
+		}
+		if len(args) != tc.wantArgs || args[0] != "p1" {
+			t.Fatalf("unexpected args for %q: %#v", sql, args)
+		}
+	}
+	if _, _, err := BuildEdgeLinkSQL(EdgeLinkSpec{Join: tags, Op: EdgeLinkAdd, Source: "p1"}); err == nil {
+		t.Fatalf("expected error when adding no targets")
+	}
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+// Deferred buffers writes destined for another Store until Flush is called. Transactional
+// clients use it so that cache state only reflects committed data.
+type Deferred struct {
+	mu      sync.Mutex
+	target  Store
+	ops     []deferredOp
+	pending map[string]deferredOp
+}
+
+type deferredOp struct {
+	key    string
+	value  any
+	delete bool
+}
+
+// NewDeferred wraps target with a write buffer. A nil target behaves like Nop.
+func NewDeferred(target Store) *Deferred {
+	if target == nil {
+		target = Nop()
+	}
+	return &Deferred{target: target}
+}
+
+// Get returns buffered values first and falls back to the wrapped store.
+func (d *Deferred) Get(ctx context.Context, key string) (any, bool, error) {
+	d.mu.Lock()
+	op, ok := d.pending[key]
+	d.mu.Unlock()
+	if ok {
+		if op.delete {
+			return nil, false, nil
+		}
+		return op.value, true, nil
+	}
+	return d.target.Get(ctx, key)
+}
+
+// Set records a pending write.
+func (d *Deferred) Set(_ context.Context, key string, value any) error {
+	d.record(deferredOp{key: key, value: value})
+	return nil
+}
+
+// Delete records a pending invalidation.
+func (d *Deferred) Delete(_ context.Context, key string) error {
+	d.record(deferredOp{key: key, delete: true})
+	return nil
+}
+
+func (d *Deferred) record(op deferredOp) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pending == nil {
+		d.pending = make(map[string]deferredOp)
+	}
+	d.pending[op.key] = op
+	d.ops = append(d.ops, op)
+}
+
+// Flush applies buffered writes to the wrapped store in the order they were recorded. The
+// first error is returned after all operations have been attempted.
+func (d *Deferred) Flush(ctx context.Context) error {
+	d.mu.Lock()
+	ops := d.ops
+	d.ops = nil
+	d.pending = nil
+	d.mu.Unlock()
+
+	var firstErr error
+	for _, op := range ops {
+		var err error
+		if op.delete {
+			err = d.target.Delete(ctx, op.key)
+		} else {
+			err = d.target.Set(ctx, op.key, op.value)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Discard drops buffered writes without touching the wrapped store.
+func (d *Deferred) Discard() {
+	d.mu.Lock()
+	d.ops = nil
+	d.pending = nil
+	d.mu.Unlock()
+}
@@ -21,3 +21,66 @@ func TestNopStore(t *testing.T) {
 		t.Fatalf("delete: %v", err)
 	}
 }
+
+type mapStore map[string]any
+
+func (m mapStore) Get(_ context.Context, key string) (any, bool, error) {
+	value, ok := m[key]
+	return value, ok, nil
+}
+
+func (m mapStore) Set(_ context.Context, key string, value any) error {
+	m[key] = value
+	return nil
+}
+
+func (m mapStore) Delete(_ context.Context, key string) error {
+	delete(m, key)
+	return nil
+}
+
+func TestDeferredStoreBuffersUntilFlush(t *testing.T) {
+	ctx := context.Background()
+	target := mapStore{"stale": 1}
+	deferred := NewDeferred(target)
+
+	if err := deferred.Set(ctx, "fresh", 2); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if err := deferred.Delete(ctx, "stale"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, ok := target["fresh"]; ok {
+		t.Fatalf("expected target to be untouched before flush")
+	}
+	if value, ok, _ := deferred.Get(ctx, "fresh"); !ok || value != 2 {
+		t.Fatalf("expected buffered value, got %v (ok=%v)", value, ok)
+	}
+	if _, ok, _ := deferred.Get(ctx, "stale"); ok {
+		t.Fatalf("expected buffered delete to hide target value")
+	}
+
+	if err := deferred.Flush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if target["fresh"] != 2 {
+		t.Fatalf("expected flushed value in target")
+	}
+	if _, ok := target["stale"]; ok {
+		t.Fatalf("expected flushed delete in target")
+	}
+}
+
+func TestDeferredStoreDiscard(t *testing.T) {
+	ctx := context.Background()
+	target := mapStore{}
+	deferred := NewDeferred(target)
+	_ = deferred.Set(ctx, "key", 1)
+	deferred.Discard()
+	if err := deferred.Flush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if len(target) != 0 {
+		t.Fatalf("expected discarded writes to be dropped, got %v", target)
+	}
+}
@@ -0,0 +1,190 @@
+package runtime
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConflictTarget selects the unique index an ON CONFLICT clause arbitrates on: Columns plus the
+// index predicate for partial unique indexes, or a named Constraint.
+type ConflictTarget struct {
+	Columns    []string
+	Where      string
+	Constraint string
+}
+
+// ConflictColumns targets the unique index covering columns.
+func ConflictColumns(columns ...string) ConflictTarget {
+	return ConflictTarget{Columns: columns}
+}
+
+// ConflictConstraint targets a unique or exclusion constraint by name.
+func ConflictConstraint(name string) ConflictTarget {
+	return ConflictTarget{Constraint: name}
+}
+
+// UpdatePolicy decides the value a column takes when DO UPDATE hits an existing row.
+type UpdatePolicy string
+
+const (
+	// SetExcluded takes the value proposed for insertion (`col = EXCLUDED.col`).
+	SetExcluded UpdatePolicy = "excluded"
+	// SetValue assigns ConflictUpdate.Value.
+	SetValue UpdatePolicy = "value"
+	// SetCoalesce keeps the stored value unless it is NULL.
+	SetCoalesce UpdatePolicy = "coalesce"
+	// SetIncrement adds the proposed value to the stored one.
+	SetIncrement UpdatePolicy = "increment"
+)
+
+type ConflictUpdate struct {
+	Column string
+	Policy UpdatePolicy
+	Value  any
+}
+
+// OnConflict is the ON CONFLICT clause of an insert. Without DoNothing or Updates it is incomplete;
+// generated Upsert methods fill in WithDefaultUpdates before running it.
+type OnConflict struct {
+	Target    ConflictTarget
+	DoNothing bool
+	Updates   []ConflictUpdate
+	// Where restricts DO UPDATE to stored rows matching every predicate. Columns refer to the
+	// stored row.
+	Where []Predicate
+	// VersionColumn, when set, is incremented on every row DO UPDATE touches.
+	VersionColumn string
+}
+
+type ConflictOption func(*OnConflict)
+
+// NewOnConflict builds an ON CONFLICT clause for target.
+func NewOnConflict(target ConflictTarget, opts ...ConflictOption) OnConflict {
+	conflict := OnConflict{Target: target}
+	for _, opt := range opts {
+		opt(&conflict)
+	}
+	return conflict
+}
+
+// DoNothing skips conflicting rows. They are not returned.
+func DoNothing() ConflictOption {
+	return func(c *OnConflict) { c.DoNothing = true }
+}
+
+// UpdateExcluded overwrites columns with the values proposed for insertion.
+func UpdateExcluded(columns ...string) ConflictOption {
+	return updateColumns(SetExcluded, columns)
+}
+
+// UpdateCoalesce fills columns that are NULL on the stored row.
+func UpdateCoalesce(columns ...string) ConflictOption {
+	return updateColumns(SetCoalesce, columns)
+}
+
+// UpdateIncrement adds the proposed values to the stored ones, e.g. for counters.
+func UpdateIncrement(columns ...string) ConflictOption {
+	return updateColumns(SetIncrement, columns)
+}
+
+// UpdateValue sets column to value.
+func UpdateValue(column string, value any) ConflictOption {
+	return func(c *OnConflict) {
+		c.Updates = append(c.Updates, ConflictUpdate{Column: column, Policy: SetValue, Value: value})
+	}
+}
+
+// UpdateWhere only updates stored rows matching preds; other conflicting rows are skipped.
+func UpdateWhere(preds ...Predicate) ConflictOption {
+	return func(c *OnConflict) { c.Where = append(c.Where, preds...) }
+}
+
+func updateColumns(policy UpdatePolicy, columns []string) ConflictOption {
+	return func(c *OnConflict) {
+		for _, column := range columns {
+			c.Updates = append(c.Updates, ConflictUpdate{Column: column, Policy: policy})
+		}
+	}
+}
+
+// WithDefaultUpdates returns c unchanged when it names an action. Otherwise it updates every
+// column outside the conflict target from the proposed row, or does nothing when no such column
+// remains.
+func (c OnConflict) WithDefaultUpdates(columns ...string) OnConflict {
+	if c.DoNothing || len(c.Updates) > 0 {
+		return c
+	}
+	target := make(map[string]struct{}, len(c.Target.Columns))
+	for _, column := range c.Target.Columns {
+		target[column] = struct{}{}
+	}
+	for _, column := range columns {
+		if _, ok := target[column]; ok {
+			continue
+		}
+		c.Updates = append(c.Updates, ConflictUpdate{Column: column, Policy: SetExcluded})
+	}
+	c.DoNothing = len(c.Updates) == 0
+	return c
+}
+
+func writeOnConflict(sb *strings.Builder, table string, c OnConflict, args []any) ([]any, error) {
+	sb.WriteString(" ON CONFLICT")
+	switch {
+	case c.Target.Constraint != "":
+		sb.WriteString(" ON CONSTRAINT ")
+		sb.WriteString(c.Target.Constraint)
+	case len(c.Target.Columns) > 0:
+		sb.WriteString(" (")
+		sb.WriteString(strings.Join(c.Target.Columns, ", "))
+		sb.WriteByte(')')
+		if c.Target.Where != "" {
+			sb.WriteString(" WHERE ")
+			sb.WriteString(c.Target.Where)
+		}
+	case !c.DoNothing:
+		return nil, fmt.Errorf("on conflict do update requires a conflict target")
+	}
+	if c.DoNothing {
+		sb.WriteString(" DO NOTHING")
+		return args, nil
+	}
+	if len(c.Updates) == 0 {
+		return nil, fmt.Errorf("on conflict requires DoNothing or at least one update")
+	}
+	sb.WriteString(" DO UPDATE SET ")
+	for i, update := range c.Updates {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(update.Column)
+		sb.WriteString(" = ")
+		switch update.Policy {
+		case SetExcluded, "":
+			fmt.Fprintf(sb, "EXCLUDED.%s", update.Column)
+		case SetValue:
+			args = append(args, update.Value)
+			sb.WriteByte('$')
+			sb.WriteString(strconv.Itoa(len(args)))
+		case SetCoalesce:
+			fmt.Fprintf(sb, "COALESCE(%s.%s, EXCLUDED.%s)", table, update.Column, update.Column)
+		case SetIncrement:
+			fmt.Fprintf(sb, "%s.%s + EXCLUDED.%s", table, update.Column, update.Column)
+		default:
+			return nil, fmt.Errorf("unknown update policy %q for column %s", update.Policy, update.Column)
+		}
+	}
+	if c.VersionColumn != "" {
+		fmt.Fprintf(sb, ", %s = %s.%s + 1", c.VersionColumn, table, c.VersionColumn)
+	}
+	for i, pred := range c.Where {
+		if i == 0 {
+			sb.WriteString(" WHERE ")
+		} else {
+			sb.WriteString(" AND ")
+		}
+		args = WritePredicate(sb, pred, table, args)
+	}
+	return args, nil
+}
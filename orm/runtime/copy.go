@@ -0,0 +1,41 @@
+package runtime
+
+import (
+	"iter"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CopySource adapts seq to pgx.CopyFromSource, converting each item to its column values with
+// row. An error from row aborts the copy. Callers must invoke stop once the copy finished.
+func CopySource[T any](seq iter.Seq[T], row func(T) ([]any, error)) (src pgx.CopyFromSource, stop func()) {
+	next, stop := iter.Pull(seq)
+	return &copySource[T]{next: next, row: row}, stop
+}
+
+type copySource[T any] struct {
+	next   func() (T, bool)
+	row    func(T) ([]any, error)
+	values []any
+	err    error
+}
+
+func (s *copySource[T]) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	item, ok := s.next()
+	if !ok {
+		return false
+	}
+	s.values, s.err = s.row(item)
+	return s.err == nil
+}
+
+func (s *copySource[T]) Values() ([]any, error) {
+	return s.values, s.err
+}
+
+func (s *copySource[T]) Err() error {
+	return s.err
+}
@@ -0,0 +1,32 @@
+package runtime
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCopySource(t *testing.T) {
+	src, stop := CopySource(slices.Values([]string{"ada", "bob", "eve"}), func(name string) ([]any, error) {
+		if name == "eve" {
+			return nil, errors.New("invalid name")
+		}
+		return []any{name}, nil
+	})
+	defer stop()
+
+	var copied []any
+	for src.Next() {
+		values, err := src.Values()
+		if err != nil {
+			t.Fatalf("values: %v", err)
+		}
+		copied = append(copied, values...)
+	}
+	if len(copied) != 2 || copied[1] != "bob" {
+		t.Fatalf("unexpected values: %v", copied)
+	}
+	if err := src.Err(); err == nil || err.Error() != "invalid name" {
+		t.Fatalf("expected row error, got %v", err)
+	}
+}
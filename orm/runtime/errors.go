@@ -0,0 +1,189 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrStaleObject is matched by every error returned when an optimistic lock check fails.
+var ErrStaleObject = errors.New("runtime: stale object")
+
+// StaleObjectError reports that an update expected a version the stored row no longer has, or
+// that the row no longer exists.
+type StaleObjectError struct {
+	Entity  string
+	ID      string
+	Version int64
+}
+
+// Error implements the error interface.
+func (e *StaleObjectError) Error() string {
+	return fmt.Sprintf("runtime: %s %s was modified concurrently (expected version %d)", e.Entity, e.ID, e.Version)
+}
+
+// Is reports whether target is ErrStaleObject.
+func (e *StaleObjectError) Is(target error) bool {
+	return target == ErrStaleObject
+}
+
+// ErrInvalidCursor is matched by errors for pagination cursors that are malformed or were created
+// for a different ordering.
+var ErrInvalidCursor = errors.New("runtime: invalid cursor")
+
+// ErrNotFound is matched by every NotFoundError.
+var ErrNotFound = errors.New("runtime: not found")
+
+// NotFoundError reports that no row of an entity has the requested primary key, or that the
+// viewer's privacy policy hides it.
+type NotFoundError struct {
+	Entity string
+	ID     string
+}
+
+// Error implements the error interface.
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("runtime: %s %s not found", e.Entity, e.ID)
+}
+
+// Is reports whether target is ErrNotFound.
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// IsNotFound reports whether err reports a missing row.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// ConstraintKind classifies the integrity constraint a write violated.
+type ConstraintKind string
+
+const (
+	ConstraintUnique     ConstraintKind = "unique"
+	ConstraintForeignKey ConstraintKind = "foreign_key"
+	ConstraintCheck      ConstraintKind = "check"
+	ConstraintNotNull    ConstraintKind = "not_null"
+)
+
+// constraintKinds maps the PostgreSQL SQLSTATE codes of integrity violations to their kind.
+var constraintKinds = map[string]ConstraintKind{
+	"23505": ConstraintUnique,
+	"23503": ConstraintForeignKey,
+	"23514": ConstraintCheck,
+	"23502": ConstraintNotNull,
+}
+
+// ConstraintError reports a write rejected by an integrity constraint. Columns lists the columns
+// the constraint covers and Fields the entity fields holding them, when known.
+type ConstraintError struct {
+	Kind       ConstraintKind
+	Constraint string
+	Columns    []string
+	Fields     []string
+	Entity     string
+	Err        error
+}
+
+// Error implements the error interface.
+func (e *ConstraintError) Error() string {
+	target := e.Entity
+	if target == "" {
+		target = "row"
+	}
+	name := e.Constraint
+	if name == "" && len(e.Columns) > 0 {
+		name = strings.Join(e.Columns, ", ")
+	}
+	return fmt.Sprintf("runtime: %s violates %s constraint %s", target, strings.ReplaceAll(string(e.Kind), "_", " "), name)
+}
+
+// Unwrap returns the database error.
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// IsConstraintError reports whether err is a ConstraintError of one of kinds, or of any kind when
+// none are given.
+func IsConstraintError(err error, kinds ...ConstraintKind) bool {
+	var constraintErr *ConstraintError
+	if !errors.As(err, &constraintErr) {
+		return false
+	}
+	return len(kinds) == 0 || slices.Contains(kinds, constraintErr.Kind)
+}
+
+// Constraint describes a named constraint of an entity's table.
+type Constraint struct {
+	Kind    ConstraintKind
+	Columns []string
+	Fields  []string
+}
+
+// ConstraintSchema maps the constraints of an entity's table to the columns and fields they
+// cover. Generated clients declare one per entity from the schema snapshot.
+type ConstraintSchema struct {
+	Entity      string
+	Constraints map[string]Constraint
+	// Fields maps the table's columns to the entity's Go field names.
+	Fields map[string]string
+}
+
+// WrapConstraintError converts a PostgreSQL integrity violation in err into a *ConstraintError,
+// resolving its columns and fields through schema, which may be nil. Constraints missing from
+// schema fall back to the column reported by the database or listed in the error detail. Other
+// errors, including those already converted, are returned unchanged.
+func WrapConstraintError(err error, schema *ConstraintSchema) error {
+	var pgErr *pgconn.PgError
+	if err == nil || IsConstraintError(err) || !errors.As(err, &pgErr) {
+		return err
+	}
+	kind, ok := constraintKinds[pgErr.Code]
+	if !ok {
+		return err
+	}
+	out := &ConstraintError{Kind: kind, Constraint: pgErr.ConstraintName, Err: err}
+	if schema != nil {
+		out.Entity = schema.Entity
+		if constraint, ok := schema.Constraints[pgErr.ConstraintName]; ok && pgErr.ConstraintName != "" {
+			out.Kind = constraint.Kind
+			out.Columns = append([]string(nil), constraint.Columns...)
+			out.Fields = append([]string(nil), constraint.Fields...)
+			return out
+		}
+	}
+	out.Columns = violatedColumns(pgErr)
+	if schema != nil {
+		for _, column := range out.Columns {
+			if field, ok := schema.Fields[column]; ok {
+				out.Fields = append(out.Fields, field)
+			}
+		}
+	}
+	return out
+}
+
+// violatedColumns returns the columns named by a violation. PostgreSQL reports the column of NOT
+// NULL violations directly and lists the key columns of unique and foreign key violations in the
+// detail, e.g. "Key (email)=(a@example.com) already exists.".
+func violatedColumns(pgErr *pgconn.PgError) []string {
+	if pgErr.ColumnName != "" {
+		return []string{pgErr.ColumnName}
+	}
+	detail, ok := strings.CutPrefix(pgErr.Detail, "Key (")
+	if !ok {
+		return nil
+	}
+	list, _, ok := strings.Cut(detail, ")=")
+	if !ok {
+		return nil
+	}
+	columns := strings.Split(list, ",")
+	for i, column := range columns {
+		columns[i] = strings.TrimSpace(column)
+	}
+	return columns
+}
@@ -0,0 +1,81 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestNotFoundError(t *testing.T) {
+	err := fmt.Errorf("load: %w", &NotFoundError{Entity: "User", ID: "u1"})
+	if !IsNotFound(err) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected %v to be a not found error", err)
+	}
+	if IsNotFound(errors.New("boom")) || IsNotFound(nil) {
+		t.Fatal("unexpected not found match")
+	}
+}
+
+func TestWrapConstraintError(t *testing.T) {
+	schema := &ConstraintSchema{
+		Entity: "User",
+		Constraints: map[string]Constraint{
+			"users_email_key": {Kind: ConstraintUnique, Columns: []string{"email"}, Fields: []string{"Email"}},
+		},
+		Fields: map[string]string{"email": "Email", "org_id": "OrgID"},
+	}
+	cases := []struct {
+		name string
+		err  error
+		want *ConstraintError
+	}{
+		{
+			name: "known constraint",
+			err:  &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key", Detail: "Key (email)=(a@example.com) already exists."},
+			want: &ConstraintError{Kind: ConstraintUnique, Constraint: "users_email_key", Columns: []string{"email"}, Fields: []string{"Email"}, Entity: "User"},
+		},
+		{
+			name: "detail columns",
+			err:  &pgconn.PgError{Code: "23503", ConstraintName: "fk_users_org_id", Detail: `Key (org_id)=(o1) is not present in table "orgs".`},
+			want: &ConstraintError{Kind: ConstraintForeignKey, Constraint: "fk_users_org_id", Columns: []string{"org_id"}, Fields: []string{"OrgID"}, Entity: "User"},
+		},
+		{
+			name: "not null column",
+			err:  &pgconn.PgError{Code: "23502", ColumnName: "email"},
+			want: &ConstraintError{Kind: ConstraintNotNull, Columns: []string{"email"}, Fields: []string{"Email"}, Entity: "User"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := WrapConstraintError(fmt.Errorf("insert: %w", tc.err), schema)
+			var got *ConstraintError
+			if !errors.As(err, &got) {
+				t.Fatalf("expected a constraint error, got %v", err)
+			}
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v to unwrap to the database error", err)
+			}
+			got.Err = nil
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("unexpected constraint error: %+v", got)
+			}
+		})
+	}
+
+	if !IsConstraintError(WrapConstraintError(&pgconn.PgError{Code: "23514"}, nil), ConstraintCheck) {
+		t.Fatal("expected a check violation without a schema")
+	}
+	if IsConstraintError(WrapConstraintError(&pgconn.PgError{Code: "23514"}, nil), ConstraintUnique) {
+		t.Fatal("unexpected kind match")
+	}
+	deadlock := &pgconn.PgError{Code: "40P01"}
+	if err := WrapConstraintError(deadlock, schema); err != deadlock {
+		t.Fatalf("expected other errors unchanged, got %v", err)
+	}
+	if err := WrapConstraintError(nil, schema); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MutationOp identifies the kind of write flowing through a hook chain.
+type MutationOp string
+
+const (
+	MutationCreate MutationOp = "create"
+	MutationUpdate MutationOp = "update"
+	MutationDelete MutationOp = "delete"
+)
+
+// Mutation is implemented by the generated <Entity>Mutation types. Hooks usually type-assert to
+// the concrete mutation (or use MutationHook) to read and modify the pending values.
+type Mutation interface {
+	Entity() string
+	Op() MutationOp
+	Bulk() bool
+	ChangedFields() []string
+}
+
+// Mutator executes a mutation and returns the generated client's result.
+type Mutator interface {
+	Mutate(ctx context.Context, m Mutation) (any, error)
+}
+
+// MutateFunc adapts a function to the Mutator interface.
+type MutateFunc func(ctx context.Context, m Mutation) (any, error)
+
+// Mutate implements Mutator.
+func (f MutateFunc) Mutate(ctx context.Context, m Mutation) (any, error) { return f(ctx, m) }
+
+// Hook wraps a Mutator. Returning an error without calling next vetoes the mutation.
+type Hook func(next Mutator) Mutator
+
+// MutationHook builds a Hook that only runs for mutations of type M (for example
+// *gen.UserMutation). Other mutations are forwarded to next untouched.
+func MutationHook[M Mutation](fn func(ctx context.Context, m M, next Mutator) (any, error)) Hook {
+	return func(next Mutator) Mutator {
+		return MutateFunc(func(ctx context.Context, m Mutation) (any, error) {
+			typed, ok := m.(M)
+			if !ok {
+				return next.Mutate(ctx, m)
+			}
+			return fn(ctx, typed, next)
+		})
+	}
+}
+
+// ChainHooks wraps final with hooks. The first hook is the outermost one.
+func ChainHooks(final Mutator, hooks ...Hook) Mutator {
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if hooks[i] == nil {
+			continue
+		}
+		final = hooks[i](final)
+	}
+	return final
+}
+
+// QueryOp identifies the query terminal flowing through an interceptor chain.
+type QueryOp string
+
+const (
+	QueryAll       QueryOp = "all"
+	QueryFirst     QueryOp = "first"
+	QueryCount     QueryOp = "count"
+	QueryStream    QueryOp = "stream"
+	QueryAggregate QueryOp = "aggregate"
+)
+
+// Query is implemented by the generated <Entity>Query builders. Interceptors receive a private
+// copy of the builder and may add predicates, orders, or limits before calling next.
+type Query interface {
+	Entity() string
+	Op() QueryOp
+}
+
+// Querier executes a query and returns the generated builder's result.
+type Querier interface {
+	Query(ctx context.Context, q Query) (any, error)
+}
+
+// QuerierFunc adapts a function to the Querier interface.
+type QuerierFunc func(ctx context.Context, q Query) (any, error)
+
+// Query implements Querier.
+func (f QuerierFunc) Query(ctx context.Context, q Query) (any, error) { return f(ctx, q) }
+
+// Interceptor wraps a Querier.
+type Interceptor func(next Querier) Querier
+
+// QueryInterceptor builds an Interceptor that only runs for queries of type Q (for example
+// *gen.UserQuery). Other queries are forwarded to next untouched.
+func QueryInterceptor[Q Query](fn func(ctx context.Context, q Q, next Querier) (any, error)) Interceptor {
+	return func(next Querier) Querier {
+		return QuerierFunc(func(ctx context.Context, q Query) (any, error) {
+			typed, ok := q.(Q)
+			if !ok {
+				return next.Query(ctx, q)
+			}
+			return fn(ctx, typed, next)
+		})
+	}
+}
+
+// ChainInterceptors wraps final with interceptors. The first interceptor is the outermost one.
+func ChainInterceptors(final Querier, interceptors ...Interceptor) Querier {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		if interceptors[i] == nil {
+			continue
+		}
+		final = interceptors[i](final)
+	}
+	return final
+}
+
+// CastResult converts the untyped value returned by a hook or interceptor chain back into the
+// type expected by the generated API.
+func CastResult[T any](v any, err error) (T, error) {
+	var zero T
+	if err != nil {
+		return zero, err
+	}
+	if v == nil {
+		return zero, nil
+	}
+	out, ok := v.(T)
+	if !ok {
+		return zero, fmt.Errorf("unexpected result type %T, want %T", v, zero)
+	}
+	return out, nil
+}
+
+// HookRegistry stores hooks and interceptors keyed by entity name. Entries registered for the
+// empty entity name apply to every entity and run before entity-specific ones.
+type HookRegistry struct {
+	mu           sync.RWMutex
+	hooks        map[string][]Hook
+	interceptors map[string][]Interceptor
+}
+
+// NewHookRegistry constructs an empty registry.
+func NewHookRegistry() *HookRegistry {
+	return &HookRegistry{
+		hooks:        make(map[string][]Hook),
+		interceptors: make(map[string][]Interceptor),
+	}
+}
+
+// Use registers mutation hooks for entity, or for every entity when entity is empty.
+func (r *HookRegistry) Use(entity string, hooks ...Hook) {
+	if r == nil || len(hooks) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.hooks == nil {
+		r.hooks = make(map[string][]Hook)
+	}
+	r.hooks[entity] = append(r.hooks[entity], hooks...)
+}
+
+// Intercept registers query interceptors for entity, or for every entity when entity is empty.
+func (r *HookRegistry) Intercept(entity string, interceptors ...Interceptor) {
+	if r == nil || len(interceptors) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.interceptors == nil {
+		r.interceptors = make(map[string][]Interceptor)
+	}
+	r.interceptors[entity] = append(r.interceptors[entity], interceptors...)
+}
+
+// Hooks returns the global hooks followed by those registered for entity.
+func (r *HookRegistry) Hooks(entity string) []Hook {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := append([]Hook(nil), r.hooks[""]...)
+	if entity != "" {
+		out = append(out, r.hooks[entity]...)
+	}
+	return out
+}
+
+// Interceptors returns the global interceptors followed by those registered for entity.
+func (r *HookRegistry) Interceptors(entity string) []Interceptor {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := append([]Interceptor(nil), r.interceptors[""]...)
+	if entity != "" {
+		out = append(out, r.interceptors[entity]...)
+	}
+	return out
+}
+
+// Mutate runs m through the hooks registered for entity before handing it to final.
+func (r *HookRegistry) Mutate(ctx context.Context, entity string, m Mutation, final Mutator) (any, error) {
+	return ChainHooks(final, r.Hooks(entity)...).Mutate(ctx, m)
+}
+
+// Query runs q through the interceptors registered for entity before handing it to final.
+func (r *HookRegistry) Query(ctx context.Context, entity string, q Query, final Querier) (any, error) {
+	return ChainInterceptors(final, r.Interceptors(entity)...).Query(ctx, q)
+}
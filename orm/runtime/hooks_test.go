@@ -0,0 +1,132 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testMutation struct {
+	entity string
+	op     MutationOp
+	value  string
+}
+
+func (m *testMutation) Entity() string          { return m.entity }
+func (m *testMutation) Op() MutationOp          { return m.op }
+func (m *testMutation) Bulk() bool              { return false }
+func (m *testMutation) ChangedFields() []string { return []string{"value"} }
+
+type otherMutation struct{ testMutation }
+
+type testQuery struct {
+	entity string
+	limit  int
+}
+
+func (q *testQuery) Entity() string { return q.entity }
+func (q *testQuery) Op() QueryOp    { return QueryAll }
+
+func TestHookRegistryOrdersGlobalBeforeEntityHooks(t *testing.T) {
+	registry := NewHookRegistry()
+	var calls []string
+	record := func(name string) Hook {
+		return func(next Mutator) Mutator {
+			return MutateFunc(func(ctx context.Context, m Mutation) (any, error) {
+				calls = append(calls, name)
+				return next.Mutate(ctx, m)
+			})
+		}
+	}
+	registry.Use("User", record("user"))
+	registry.Use("", record("global-1"), record("global-2"))
+	registry.Use("Post", record("post"))
+
+	final := MutateFunc(func(ctx context.Context, m Mutation) (any, error) {
+		calls = append(calls, "final")
+		return m.(*testMutation).value, nil
+	})
+	out, err := registry.Mutate(context.Background(), "User", &testMutation{entity: "User", value: "ok"}, final)
+	if err != nil {
+		t.Fatalf("mutate: %v", err)
+	}
+	if out != "ok" {
+		t.Fatalf("unexpected result: %v", out)
+	}
+	want := []string{"global-1", "global-2", "user", "final"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("unexpected call order: %v", calls)
+	}
+}
+
+func TestMutationHookModifiesAndVetoes(t *testing.T) {
+	errVeto := errors.New("veto")
+	hook := MutationHook(func(ctx context.Context, m *testMutation, next Mutator) (any, error) {
+		if m.value == "" {
+			return nil, errVeto
+		}
+		m.value = m.value + "!"
+		return next.Mutate(ctx, m)
+	})
+	final := MutateFunc(func(ctx context.Context, m Mutation) (any, error) {
+		switch typed := m.(type) {
+		case *testMutation:
+			return typed.value, nil
+		case *otherMutation:
+			return typed.value, nil
+		}
+		return nil, nil
+	})
+	chain := ChainHooks(final, hook)
+
+	out, err := chain.Mutate(context.Background(), &testMutation{value: "hi"})
+	if err != nil || out != "hi!" {
+		t.Fatalf("expected modified value, got %v (err %v)", out, err)
+	}
+	if _, err := chain.Mutate(context.Background(), &testMutation{}); !errors.Is(err, errVeto) {
+		t.Fatalf("expected veto error, got %v", err)
+	}
+	out, err = chain.Mutate(context.Background(), &otherMutation{testMutation{value: "skip"}})
+	if err != nil || out != "skip" {
+		t.Fatalf("expected other mutation to bypass hook, got %v (err %v)", out, err)
+	}
+}
+
+func TestQueryInterceptorModifiesQuery(t *testing.T) {
+	registry := NewHookRegistry()
+	registry.Intercept("User", QueryInterceptor(func(ctx context.Context, q *testQuery, next Querier) (any, error) {
+		q.limit = 5
+		return next.Query(ctx, q)
+	}))
+	final := QuerierFunc(func(ctx context.Context, q Query) (any, error) {
+		return q.(*testQuery).limit, nil
+	})
+	limit, err := CastResult[int](registry.Query(context.Background(), "User", &testQuery{entity: "User"}, final))
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if limit != 5 {
+		t.Fatalf("expected interceptor to set limit, got %d", limit)
+	}
+	limit, err = CastResult[int](registry.Query(context.Background(), "Post", &testQuery{entity: "Post"}, final))
+	if err != nil || limit != 0 {
+		t.Fatalf("expected Post query to skip User interceptor, got %d (err %v)", limit, err)
+	}
+}
+
+func TestCastResult(t *testing.T) {
+	if _, err := CastResult[string](42, nil); err == nil {
+		t.Fatalf("expected type mismatch error")
+	}
+	if out, err := CastResult[*testQuery](nil, nil); err != nil || out != nil {
+		t.Fatalf("expected nil result, got %v (err %v)", out, err)
+	}
+	var nilRegistry *HookRegistry
+	out, err := nilRegistry.Mutate(context.Background(), "User", &testMutation{value: "direct"}, MutateFunc(func(ctx context.Context, m Mutation) (any, error) {
+		return m.(*testMutation).value, nil
+	}))
+	if err != nil || out != "direct" {
+		t.Fatalf("expected nil registry to call final directly, got %v (err %v)", out, err)
+	}
+}
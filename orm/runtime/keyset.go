@@ -0,0 +1,164 @@
+package runtime
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// KeysetOrders returns orders followed by key, sorted in the direction of the last order, unless
+// orders already sort by key. The result gives every row a distinct position, which keyset
+// pagination needs to neither skip nor repeat rows sharing a sort value.
+func KeysetOrders(orders []Order, key string) []Order {
+	direction := SortAsc
+	for _, order := range orders {
+		if order.Column == key {
+			return orders
+		}
+		direction = order.Direction
+	}
+	out := make([]Order, len(orders), len(orders)+1)
+	copy(out, orders)
+	return append(out, Order{Column: key, Direction: direction})
+}
+
+func reverseOrders(orders []Order) []Order {
+	out := make([]Order, len(orders))
+	for i, order := range orders {
+		out[i] = order
+		if order.Direction == SortDesc {
+			out[i].Direction = SortAsc
+		} else {
+			out[i].Direction = SortDesc
+		}
+	}
+	return out
+}
+
+// writeKeyset renders the condition keeping rows that sort strictly after values in orders, or
+// strictly before them. Orders sharing one direction compare as a row value, which PostgreSQL can
+// answer from a matching index; mixed directions expand into one OR branch per column.
+func writeKeyset(sb *strings.Builder, orders []Order, values []any, before bool, qualifier string, args []any) []any {
+	if len(values) < len(orders) {
+		orders = orders[:len(values)]
+	}
+	values = values[:len(orders)]
+	base := len(args)
+	column := func(order Order) string {
+		if qualifier != "" {
+			return qualifier + "." + order.Column
+		}
+		return order.Column
+	}
+	compare := func(order Order) string {
+		if (order.Direction == SortDesc) != before {
+			return " < "
+		}
+		return " > "
+	}
+	placeholder := func(i int) string {
+		return "$" + strconv.Itoa(base+i+1)
+	}
+
+	uniform := true
+	for _, order := range orders {
+		if order.Direction != orders[0].Direction {
+			uniform = false
+			break
+		}
+	}
+	switch {
+	case len(orders) == 1:
+		sb.WriteString(column(orders[0]) + compare(orders[0]) + placeholder(0))
+	case uniform:
+		columns := make([]string, len(orders))
+		params := make([]string, len(orders))
+		for i, order := range orders {
+			columns[i], params[i] = column(order), placeholder(i)
+		}
+		sb.WriteString("(" + strings.Join(columns, ", ") + ")" + compare(orders[0]) + "(" + strings.Join(params, ", ") + ")")
+	default:
+		sb.WriteByte('(')
+		for i, order := range orders {
+			if i > 0 {
+				sb.WriteString(" OR (")
+				for j := 0; j < i; j++ {
+					sb.WriteString(column(orders[j]) + " = " + placeholder(j) + " AND ")
+				}
+			}
+			sb.WriteString(column(order) + compare(order) + placeholder(i))
+			if i > 0 {
+				sb.WriteByte(')')
+			}
+		}
+		sb.WriteByte(')')
+	}
+	return append(args, values...)
+}
+
+type cursorPayload struct {
+	Keys   []string          `json:"k"`
+	Values []json.RawMessage `json:"v"`
+}
+
+func cursorKeys(orders []Order) []string {
+	keys := make([]string, len(orders))
+	for i, order := range orders {
+		keys[i] = order.Column + " " + string(order.Direction)
+	}
+	return keys
+}
+
+// EncodeCursor returns an opaque pagination cursor holding the sort key of a row: one value per
+// entry of orders, usually the result of KeysetOrders. The cursor records orders, so it cannot be
+// replayed against a query sorted differently.
+func EncodeCursor(orders []Order, values ...any) (string, error) {
+	if len(values) != len(orders) {
+		return "", fmt.Errorf("runtime: cursor needs %d values, got %d", len(orders), len(values))
+	}
+	payload := cursorPayload{Keys: cursorKeys(orders), Values: make([]json.RawMessage, len(values))}
+	for i, value := range values {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("runtime: encode cursor value for %s: %w", orders[i].Column, err)
+		}
+		payload.Values[i] = raw
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor reverses EncodeCursor for a query sorted by orders. Each value is unmarshalled into
+// the matching pointer of dest, and the pointed-to values are returned ready to use as
+// SelectSpec.After or SelectSpec.Before. Malformed cursors and cursors created for other orders
+// fail with ErrInvalidCursor.
+func DecodeCursor(cursor string, orders []Order, dest ...any) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if keys := cursorKeys(orders); strings.Join(payload.Keys, ",") != strings.Join(keys, ",") {
+		return nil, fmt.Errorf("%w: cursor was created for order %q, query uses %q", ErrInvalidCursor, strings.Join(payload.Keys, ", "), strings.Join(keys, ", "))
+	}
+	if len(payload.Values) != len(dest) {
+		return nil, fmt.Errorf("%w: cursor holds %d values, want %d", ErrInvalidCursor, len(payload.Values), len(dest))
+	}
+	values := make([]any, len(dest))
+	for i, raw := range payload.Values {
+		if err := json.Unmarshal(raw, dest[i]); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidCursor, orders[i].Column, err)
+		}
+		values[i] = reflect.ValueOf(dest[i]).Elem().Interface()
+	}
+	return values, nil
+}
@@ -0,0 +1,100 @@
+package runtime
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKeysetOrders(t *testing.T) {
+	orders := KeysetOrders([]Order{{Column: "created_at", Direction: SortDesc}}, "id")
+	want := []Order{{Column: "created_at", Direction: SortDesc}, {Column: "id", Direction: SortDesc}}
+	if !reflect.DeepEqual(orders, want) {
+		t.Fatalf("orders = %v, want %v", orders, want)
+	}
+	if orders := KeysetOrders(nil, "id"); !reflect.DeepEqual(orders, []Order{{Column: "id", Direction: SortAsc}}) {
+		t.Fatalf("unexpected default orders: %v", orders)
+	}
+	sorted := []Order{{Column: "id", Direction: SortDesc}, {Column: "name", Direction: SortAsc}}
+	if orders := KeysetOrders(sorted, "id"); !reflect.DeepEqual(orders, sorted) {
+		t.Fatalf("orders already sorted by key should not change: %v", orders)
+	}
+}
+
+func TestBuildSelectSQLKeyset(t *testing.T) {
+	spec := SelectSpec{
+		Table:      "posts",
+		Columns:    []string{"id", "title"},
+		Predicates: []Predicate{Compare("author_id", OpEqual, "u1")},
+		Orders:     []Order{{Column: "created_at", Direction: SortDesc}},
+		KeyColumn:  "id",
+		After:      []any{"2024-01-01", "p1"},
+		Limit:      10,
+	}
+
+	sql, args := BuildSelectSQL(spec)
+	expected := "SELECT id, title FROM posts WHERE author_id = $1 AND (created_at, id) < ($2, $3) ORDER BY created_at DESC, id DESC LIMIT $4"
+	if sql != expected {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+	if !reflect.DeepEqual(args, []any{"u1", "2024-01-01", "p1", 10}) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+
+	spec.After, spec.Before = nil, []any{"2024-01-01", "p1"}
+	sql, _ = BuildSelectSQL(spec)
+	expected = "SELECT id, title FROM posts WHERE author_id = $1 AND (created_at, id) > ($2, $3) ORDER BY created_at DESC, id DESC LIMIT $4"
+	if sql != expected {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+
+	spec.FromEnd = true
+	sql, _ = BuildSelectSQL(spec)
+	expected = "SELECT * FROM (SELECT id, title FROM posts WHERE author_id = $1 AND (created_at, id) > ($2, $3) ORDER BY created_at ASC, id ASC LIMIT $4) AS erm_page ORDER BY created_at DESC, id DESC"
+	if sql != expected {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+
+	spec.Predicates, spec.Before, spec.Limit, spec.FromEnd = nil, nil, 0, false
+	spec.Orders = []Order{{Column: "title", Direction: SortAsc}, {Column: "created_at", Direction: SortDesc}}
+	spec.After = []any{"b", "2024-01-01", "p1"}
+	sql, args = BuildSelectSQL(spec)
+	expected = "SELECT id, title FROM posts WHERE (title > $1 OR (title = $1 AND created_at < $2) OR (title = $1 AND created_at = $2 AND id < $3)) ORDER BY title ASC, created_at DESC, id DESC"
+	if sql != expected {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 3 {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+
+	spec.Orders, spec.After = nil, nil
+	if sql, _ = BuildSelectSQL(spec); sql != "SELECT id, title FROM posts" {
+		t.Fatalf("unordered selects without keys should not sort: %s", sql)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	orders := KeysetOrders([]Order{{Column: "created_at", Direction: SortDesc}}, "id")
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cursor, err := EncodeCursor(orders, created, "p1")
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	values, err := DecodeCursor(cursor, orders, new(time.Time), new(string))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !reflect.DeepEqual(values, []any{created, "p1"}) {
+		t.Fatalf("unexpected values: %#v", values)
+	}
+
+	other := KeysetOrders([]Order{{Column: "created_at", Direction: SortAsc}}, "id")
+	if _, err := DecodeCursor(cursor, other, new(time.Time), new(string)); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor for a different order, got %v", err)
+	}
+	if _, err := DecodeCursor("not a cursor", orders, new(time.Time), new(string)); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor for garbage, got %v", err)
+	}
+}
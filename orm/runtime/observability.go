@@ -17,6 +17,8 @@ const (
 	OperationSelect QueryOperation = "select"
 	// OperationAggregate captures aggregate queries constructed from AggregateSpec.
 	OperationAggregate QueryOperation = "aggregate"
+	// OperationCopy captures COPY FROM STDIN bulk loads.
+	OperationCopy QueryOperation = "copy"
 )
 
 // QueryLog describes the structured payload emitted for each ORM query.
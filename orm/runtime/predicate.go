@@ -0,0 +1,235 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// And matches rows that satisfy every predicate. An empty And matches all rows.
+func And(preds ...Predicate) Predicate {
+	return Predicate{Operator: OpAnd, Children: preds}
+}
+
+// Or matches rows that satisfy at least one predicate. An empty Or matches no rows.
+func Or(preds ...Predicate) Predicate {
+	return Predicate{Operator: OpOr, Children: preds}
+}
+
+// Not negates pred.
+func Not(pred Predicate) Predicate {
+	return Predicate{Operator: OpNot, Children: []Predicate{pred}}
+}
+
+// Compare builds a binary column predicate such as `column >= $1`.
+func Compare(column string, op Operator, value any) Predicate {
+	return Predicate{Column: column, Operator: op, Value: value}
+}
+
+// In matches rows whose column equals one of values. values must be a slice; it is bound as a
+// single array parameter (`column = ANY($1)`), so the statement text does not depend on its length.
+func In(column string, values any) Predicate {
+	return Predicate{Column: column, Operator: OpIn, Value: values}
+}
+
+// NotIn matches rows whose column differs from every element of values (`column <> ALL($1)`).
+func NotIn(column string, values any) Predicate {
+	return Predicate{Column: column, Operator: OpNotIn, Value: values}
+}
+
+func IsNull(column string) Predicate {
+	return Predicate{Column: column, Operator: OpIsNull}
+}
+
+func NotNull(column string) Predicate {
+	return Predicate{Column: column, Operator: OpNotNull}
+}
+
+// Between matches rows whose column lies in the inclusive range [low, high].
+func Between(column string, low, high any) Predicate {
+	return Predicate{Column: column, Operator: OpBetween, Value: []any{low, high}}
+}
+
+// Like matches column against a LIKE pattern. The pattern is passed through unchanged.
+func Like(column, pattern string) Predicate {
+	return Predicate{Column: column, Operator: OpLike, Value: pattern}
+}
+
+// HasPrefix matches values starting with prefix. LIKE wildcards in prefix are escaped.
+func HasPrefix(column, prefix string) Predicate {
+	return Like(column, EscapeLike(prefix)+"%")
+}
+
+// HasSuffix matches values ending with suffix. LIKE wildcards in suffix are escaped.
+func HasSuffix(column, suffix string) Predicate {
+	return Like(column, "%"+EscapeLike(suffix))
+}
+
+// Contains matches values containing substr. LIKE wildcards in substr are escaped.
+func Contains(column, substr string) Predicate {
+	return Like(column, "%"+EscapeLike(substr)+"%")
+}
+
+// ContainsFold is the case-insensitive variant of Contains.
+func ContainsFold(column, substr string) Predicate {
+	return Predicate{Column: column, Operator: OpILike, Value: "%" + EscapeLike(substr) + "%"}
+}
+
+// EdgeJoin describes how rows of SourceTable reach the rows of an edge target: directly when
+// TargetTable.TargetColumn = SourceTable.SourceColumn, or through a join table whose
+// ThroughSourceColumn and ThroughTargetColumn reference the two sides. Target names the target
+// entity so ScopeEdges can add its query filters.
+type EdgeJoin struct {
+	Name                string
+	Target              string
+	SourceTable         string
+	SourceColumn        string
+	TargetTable         string
+	TargetColumn        string
+	Through             string
+	ThroughSourceColumn string
+	ThroughTargetColumn string
+}
+
+// HasEdge matches rows with at least one edge target satisfying preds, rendered as a correlated
+// EXISTS subquery. preds may themselves contain HasEdge predicates on the target's edges.
+func HasEdge(join EdgeJoin, preds ...Predicate) Predicate {
+	return Predicate{Operator: OpExists, Value: join, Children: preds}
+}
+
+// EdgeScope returns the predicates every row of entity must satisfy to be visible to the caller.
+type EdgeScope func(ctx context.Context, entity string) ([]Predicate, error)
+
+// ScopeEdges returns preds with scope(ctx, join.Target) added to every HasEdge subquery,
+// including nested ones, so edge predicates only match targets the caller may read. preds is
+// not modified.
+func ScopeEdges(ctx context.Context, preds []Predicate, scope EdgeScope) ([]Predicate, error) {
+	if len(preds) == 0 {
+		return nil, nil
+	}
+	out := make([]Predicate, len(preds))
+	for i, pred := range preds {
+		if len(pred.Children) > 0 || pred.Operator == OpExists {
+			children, err := ScopeEdges(ctx, pred.Children, scope)
+			if err != nil {
+				return nil, err
+			}
+			if pred.Operator == OpExists {
+				join, _ := pred.Value.(EdgeJoin)
+				filters, err := scope(ctx, join.Target)
+				if err != nil {
+					return nil, err
+				}
+				children = append(children, filters...)
+			}
+			pred.Children = children
+		}
+		out[i] = pred
+	}
+	return out, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike escapes the LIKE wildcards in s using PostgreSQL's default escape character.
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// WritePredicate renders pred into sb, qualifying columns with qualifier when it is non-empty.
+// Placeholders continue from len(args); the returned slice holds args plus the bound values.
+func WritePredicate(sb *strings.Builder, pred Predicate, qualifier string, args []any) []any {
+	switch pred.Operator {
+	case OpAnd, OpOr:
+		if len(pred.Children) == 0 {
+			if pred.Operator == OpAnd {
+				sb.WriteString("TRUE")
+			} else {
+				sb.WriteString("FALSE")
+			}
+			return args
+		}
+		sb.WriteByte('(')
+		for i, child := range pred.Children {
+			if i > 0 {
+				sb.WriteByte(' ')
+				sb.WriteString(string(pred.Operator))
+				sb.WriteByte(' ')
+			}
+			args = WritePredicate(sb, child, qualifier, args)
+		}
+		sb.WriteByte(')')
+		return args
+	case OpExists:
+		join, _ := pred.Value.(EdgeJoin)
+		return writeExists(sb, join, pred.Children, qualifier, args)
+	case OpNot:
+		sb.WriteString("NOT ")
+		if len(pred.Children) == 1 {
+			sb.WriteByte('(')
+			args = WritePredicate(sb, pred.Children[0], qualifier, args)
+			sb.WriteByte(')')
+			return args
+		}
+		return WritePredicate(sb, And(pred.Children...), qualifier, args)
+	}
+
+	if qualifier != "" {
+		sb.WriteString(qualifier)
+		sb.WriteByte('.')
+	}
+	sb.WriteString(pred.Column)
+	sb.WriteByte(' ')
+	sb.WriteString(string(pred.Operator))
+	switch {
+	case pred.Operator.Unary():
+		return args
+	case pred.Operator == OpIn || pred.Operator == OpNotIn:
+		sb.WriteString("($")
+		sb.WriteString(strconv.Itoa(len(args) + 1))
+		sb.WriteByte(')')
+		return append(args, pred.Value)
+	case pred.Operator == OpBetween:
+		bounds, _ := pred.Value.([]any)
+		var low, high any
+		if len(bounds) == 2 {
+			low, high = bounds[0], bounds[1]
+		}
+		sb.WriteString(" $")
+		sb.WriteString(strconv.Itoa(len(args) + 1))
+		sb.WriteString(" AND $")
+		sb.WriteString(strconv.Itoa(len(args) + 2))
+		return append(args, low, high)
+	}
+	sb.WriteString(" $")
+	sb.WriteString(strconv.Itoa(len(args) + 1))
+	return append(args, pred.Value)
+}
+
+// writeExists renders an EdgeJoin subquery. The target is aliased by edge name, prefixed with the
+// enclosing alias, so nested and self-referencing edges never shadow the outer row.
+func writeExists(sb *strings.Builder, join EdgeJoin, preds []Predicate, qualifier string, args []any) []any {
+	outer := qualifier
+	alias := "e_" + join.Name
+	if outer == "" {
+		outer = join.SourceTable
+	} else {
+		alias = qualifier + "_" + join.Name
+	}
+	sb.WriteString("EXISTS (SELECT 1 FROM ")
+	if join.Through != "" {
+		through := alias + "_j"
+		fmt.Fprintf(sb, "%s AS %s JOIN %s AS %s ON %s.%s = %s.%s WHERE %s.%s = %s.%s",
+			join.Through, through, join.TargetTable, alias, alias, join.TargetColumn, through, join.ThroughTargetColumn,
+			through, join.ThroughSourceColumn, outer, join.SourceColumn)
+	} else {
+		fmt.Fprintf(sb, "%s AS %s WHERE %s.%s = %s.%s", join.TargetTable, alias, alias, join.TargetColumn, outer, join.SourceColumn)
+	}
+	for _, pred := range preds {
+		sb.WriteString(" AND ")
+		args = WritePredicate(sb, pred, alias, args)
+	}
+	sb.WriteByte(')')
+	return args
+}
@@ -0,0 +1,100 @@
+package runtime
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildSelectSQLPredicateTree(t *testing.T) {
+	spec := SelectSpec{
+		Table:   "posts",
+		Columns: []string{"id"},
+		Predicates: []Predicate{
+			Or(
+				HasPrefix("title", "50%_off"),
+				And(In("status", []string{"draft", "review"}), Not(IsNull("published_at"))),
+			),
+			Between("views", 10, 20),
+			NotIn("id", []string{"p1"}),
+		},
+		Limit: 5,
+	}
+
+	sql, args := BuildSelectSQL(spec)
+	want := "SELECT id FROM posts WHERE (title LIKE $1 OR (status = ANY($2) AND NOT (published_at IS NULL))) AND views BETWEEN $3 AND $4 AND id <> ALL($5) LIMIT $6"
+	if sql != want {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", sql, want)
+	}
+	wantArgs := []any{`50\%\_off%`, []string{"draft", "review"}, 10, 20, []string{"p1"}, 5}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestWritePredicateEmptyGroupsAndQualifier(t *testing.T) {
+	var sb strings.Builder
+	args := WritePredicate(&sb, And(Or(), And(), Contains("name", "x")), "t", []any{"existing"})
+	if got := sb.String(); got != "(FALSE AND TRUE AND t.name LIKE $2)" {
+		t.Fatalf("unexpected SQL: %s", got)
+	}
+	if !reflect.DeepEqual(args, []any{"existing", "%x%"}) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestWritePredicateHasEdge(t *testing.T) {
+	author := EdgeJoin{Name: "author", SourceTable: "posts", SourceColumn: "author_id", TargetTable: "users", TargetColumn: "id"}
+	comments := EdgeJoin{Name: "comments", SourceTable: "users", SourceColumn: "id", TargetTable: "comments", TargetColumn: "user_id"}
+	tags := EdgeJoin{Name: "tags", SourceTable: "posts", SourceColumn: "id", TargetTable: "tags", TargetColumn: "id", Through: "post_tags", ThroughSourceColumn: "post_id", ThroughTargetColumn: "tag_id"}
+
+	spec := SelectSpec{
+		Table:   "posts",
+		Columns: []string{"id"},
+		Predicates: []Predicate{
+			HasEdge(author, HasSuffix("email", "@corp"), HasEdge(comments)),
+			Not(HasEdge(tags, Compare("name", OpEqual, "draft"))),
+		},
+	}
+
+	sql, args := BuildSelectSQL(spec)
+	want := "SELECT id FROM posts WHERE EXISTS (SELECT 1 FROM users AS e_author WHERE e_author.id = posts.author_id AND e_author.email LIKE $1" +
+		" AND EXISTS (SELECT 1 FROM comments AS e_author_comments WHERE e_author_comments.user_id = e_author.id))" +
+		" AND NOT (EXISTS (SELECT 1 FROM post_tags AS e_tags_j JOIN tags AS e_tags ON e_tags.id = e_tags_j.tag_id WHERE e_tags_j.post_id = posts.id AND e_tags.name = $2))"
+	if sql != want {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", sql, want)
+	}
+	if !reflect.DeepEqual(args, []any{"%@corp", "draft"}) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestScopeEdgesFiltersNestedTargets(t *testing.T) {
+	author := EdgeJoin{Name: "author", Target: "User", SourceTable: "posts", SourceColumn: "author_id", TargetTable: "users", TargetColumn: "id"}
+	comments := EdgeJoin{Name: "comments", Target: "Comment", SourceTable: "users", SourceColumn: "id", TargetTable: "comments", TargetColumn: "user_id"}
+	preds := []Predicate{Not(HasEdge(author, HasEdge(comments)))}
+	scope := func(_ context.Context, entity string) ([]Predicate, error) {
+		if entity != "Comment" {
+			return nil, nil
+		}
+		return []Predicate{Compare("owner_id", OpEqual, "u1")}, nil
+	}
+
+	scoped, err := ScopeEdges(context.Background(), preds, scope)
+	if err != nil {
+		t.Fatalf("ScopeEdges: %v", err)
+	}
+	sql, args := BuildSelectSQL(SelectSpec{Table: "posts", Columns: []string{"id"}, Predicates: scoped})
+	want := "SELECT id FROM posts WHERE NOT (EXISTS (SELECT 1 FROM users AS e_author WHERE e_author.id = posts.author_id" +
+		" AND EXISTS (SELECT 1 FROM comments AS e_author_comments WHERE e_author_comments.user_id = e_author.id AND e_author_comments.owner_id = $1)))"
+	if sql != want {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", sql, want)
+	}
+	if !reflect.DeepEqual(args, []any{"u1"}) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+	if len(preds[0].Children[0].Children[0].Children) != 0 {
+		t.Fatalf("ScopeEdges modified its input: %+v", preds)
+	}
+}
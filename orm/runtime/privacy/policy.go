@@ -0,0 +1,231 @@
+package privacy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/deicod/erm/orm/runtime"
+)
+
+// Decision is the outcome of evaluating a single rule.
+type Decision int
+
+const (
+	// Skip defers to the next rule.
+	Skip Decision = iota
+	// Allow stops evaluation and permits the operation.
+	Allow
+	// Deny stops evaluation and rejects the operation.
+	Deny
+)
+
+// RuleKind identifies a built-in privacy rule.
+type RuleKind string
+
+const (
+	RuleAlwaysAllow          RuleKind = "always_allow"
+	RuleAlwaysDeny           RuleKind = "always_deny"
+	RuleAllowIfAuthenticated RuleKind = "allow_if_authenticated"
+	RuleDenyIfAnonymous      RuleKind = "deny_if_anonymous"
+	RuleAllowIfRole          RuleKind = "allow_if_role"
+	RuleDenyUnlessRole       RuleKind = "deny_unless_role"
+	RuleFilterOwner          RuleKind = "filter_owner"
+)
+
+// Rule is a single entry in a Policy. Generated clients build rules from the schema's Policy().
+type Rule struct {
+	Kind   RuleKind
+	Roles  []string
+	Column string
+	Ops    []runtime.MutationOp
+}
+
+func AlwaysAllow() Rule          { return Rule{Kind: RuleAlwaysAllow} }
+func AlwaysDeny() Rule           { return Rule{Kind: RuleAlwaysDeny} }
+func AllowIfAuthenticated() Rule { return Rule{Kind: RuleAllowIfAuthenticated} }
+func DenyIfAnonymous() Rule      { return Rule{Kind: RuleDenyIfAnonymous} }
+
+func AllowIfRole(roles ...string) Rule {
+	return Rule{Kind: RuleAllowIfRole, Roles: roles}
+}
+
+func DenyUnlessRole(roles ...string) Rule {
+	return Rule{Kind: RuleDenyUnlessRole, Roles: roles}
+}
+
+// FilterOwner narrows queries to rows whose column equals the viewer subject and denies
+// mutations that touch rows owned by someone else.
+func FilterOwner(column string) Rule {
+	return Rule{Kind: RuleFilterOwner, Column: column}
+}
+
+// On limits a mutation rule to ops. Other operations skip the rule.
+func (r Rule) On(ops ...runtime.MutationOp) Rule {
+	r.Ops = append(append([]runtime.MutationOp(nil), r.Ops...), ops...)
+	return r
+}
+
+func (r Rule) appliesTo(op runtime.MutationOp) bool {
+	return len(r.Ops) == 0 || slices.Contains(r.Ops, op)
+}
+
+func (r Rule) decide(viewer Viewer, ok bool) Decision {
+	authenticated := ok && viewer.Subject != ""
+	switch r.Kind {
+	case RuleAlwaysAllow:
+		return Allow
+	case RuleAlwaysDeny:
+		return Deny
+	case RuleAllowIfAuthenticated:
+		if authenticated {
+			return Allow
+		}
+	case RuleDenyIfAnonymous:
+		if !authenticated {
+			return Deny
+		}
+	case RuleAllowIfRole:
+		if ok && viewer.HasRole(r.Roles...) {
+			return Allow
+		}
+	case RuleDenyUnlessRole:
+		if !ok || !viewer.HasRole(r.Roles...) {
+			return Deny
+		}
+	}
+	return Skip
+}
+
+// ErrDenied is matched by every error returned when a policy rejects an operation.
+var ErrDenied = errors.New("privacy: operation denied")
+
+// DeniedError reports which rule rejected an operation.
+type DeniedError struct {
+	Entity string
+	Op     string
+	Rule   RuleKind
+}
+
+// Error implements the error interface.
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("privacy: %s on %s denied by %s rule", e.Op, e.Entity, e.Rule)
+}
+
+// Is reports whether target is ErrDenied.
+func (e *DeniedError) Is(target error) bool {
+	return target == ErrDenied
+}
+
+// OwnershipChecker is implemented by generated mutations so FilterOwner rules can verify that
+// every row touched by the mutation belongs to subject.
+type OwnershipChecker interface {
+	OwnedBy(ctx context.Context, column, subject string) (bool, error)
+}
+
+// Policy holds the query and mutation rules of one entity. Rules run in order and the first
+// Allow or Deny wins; when every rule skips, the operation is allowed.
+type Policy struct {
+	Query    []Rule
+	Mutation []Rule
+}
+
+// EvalQuery evaluates the query rules for entity. The returned predicates come from filter
+// rules and must be added to the query before it runs.
+func (p Policy) EvalQuery(ctx context.Context, entity string) ([]runtime.Predicate, error) {
+	if len(p.Query) == 0 || IsBypassed(ctx) {
+		return nil, nil
+	}
+	viewer, ok := FromContext(ctx)
+	var filters []runtime.Predicate
+	for _, rule := range p.Query {
+		if rule.Kind == RuleFilterOwner {
+			if !ok || viewer.Subject == "" {
+				return nil, &DeniedError{Entity: entity, Op: "query", Rule: rule.Kind}
+			}
+			filters = append(filters, runtime.Predicate{Column: rule.Column, Operator: runtime.OpEqual, Value: viewer.Subject})
+			continue
+		}
+		switch rule.decide(viewer, ok) {
+		case Allow:
+			return filters, nil
+		case Deny:
+			return nil, &DeniedError{Entity: entity, Op: "query", Rule: rule.Kind}
+		}
+	}
+	return filters, nil
+}
+
+// EvalMutation evaluates the mutation rules against m.
+func (p Policy) EvalMutation(ctx context.Context, m runtime.Mutation) error {
+	if len(p.Mutation) == 0 || IsBypassed(ctx) {
+		return nil
+	}
+	viewer, ok := FromContext(ctx)
+	for _, rule := range p.Mutation {
+		if !rule.appliesTo(m.Op()) {
+			continue
+		}
+		if rule.Kind == RuleFilterOwner {
+			if !ok || viewer.Subject == "" {
+				return &DeniedError{Entity: m.Entity(), Op: string(m.Op()), Rule: rule.Kind}
+			}
+			checker, isChecker := m.(OwnershipChecker)
+			if !isChecker {
+				return fmt.Errorf("privacy: %s mutation cannot verify ownership", m.Entity())
+			}
+			owned, err := checker.OwnedBy(ctx, rule.Column, viewer.Subject)
+			if err != nil {
+				return err
+			}
+			if !owned {
+				return &DeniedError{Entity: m.Entity(), Op: string(m.Op()), Rule: rule.Kind}
+			}
+			continue
+		}
+		switch rule.decide(viewer, ok) {
+		case Allow:
+			return nil
+		case Deny:
+			return &DeniedError{Entity: m.Entity(), Op: string(m.Op()), Rule: rule.Kind}
+		}
+	}
+	return nil
+}
+
+// AppendFilters ANDs filters onto a statement that already ends in a WHERE clause, numbering
+// placeholders after args. Qualifier, when set, prefixes each column (for example "t").
+func AppendFilters(query string, args []any, qualifier string, filters []runtime.Predicate) (string, []any) {
+	if len(filters) == 0 {
+		return query, args
+	}
+	var sb strings.Builder
+	sb.WriteString(query)
+	for _, pred := range filters {
+		sb.WriteString(" AND ")
+		args = runtime.WritePredicate(&sb, pred, qualifier, args)
+	}
+	return sb.String(), args
+}
+
+// MatchesSubject reports whether an owner field value equals subject. Nil and invalid values
+// never match.
+func MatchesSubject(value any, subject string) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case string:
+		return v == subject
+	case *string:
+		return v != nil && *v == subject
+	case sql.NullString:
+		return v.Valid && v.String == subject
+	case fmt.Stringer:
+		return v.String() == subject
+	default:
+		return fmt.Sprint(v) == subject
+	}
+}
@@ -0,0 +1,108 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/deicod/erm/oidc"
+	"github.com/deicod/erm/orm/runtime"
+)
+
+type testMutation struct {
+	op     runtime.MutationOp
+	owned  bool
+	checks int
+}
+
+func (m *testMutation) Entity() string          { return "Post" }
+func (m *testMutation) Op() runtime.MutationOp  { return m.op }
+func (m *testMutation) Bulk() bool              { return false }
+func (m *testMutation) ChangedFields() []string { return nil }
+func (m *testMutation) OwnedBy(context.Context, string, string) (bool, error) {
+	m.checks++
+	return m.owned, nil
+}
+
+func TestFromContextPrefersExplicitViewer(t *testing.T) {
+	ctx := oidc.ToContext(context.Background(), oidc.Claims{Subject: "claims", Roles: []string{"user"}})
+	viewer, ok := FromContext(ctx)
+	if !ok || viewer.Subject != "claims" || !viewer.HasRole("user") {
+		t.Fatalf("expected viewer from claims, got %+v (ok %v)", viewer, ok)
+	}
+	viewer, ok = FromContext(WithViewer(ctx, Viewer{Subject: "explicit"}))
+	if !ok || viewer.Subject != "explicit" {
+		t.Fatalf("expected explicit viewer, got %+v (ok %v)", viewer, ok)
+	}
+	if _, ok := FromContext(context.Background()); ok {
+		t.Fatalf("expected no viewer on empty context")
+	}
+}
+
+func TestEvalQueryFiltersAndShortCircuits(t *testing.T) {
+	policy := Policy{Query: []Rule{AllowIfRole("admin"), FilterOwner("author_id")}}
+
+	if _, err := policy.EvalQuery(context.Background(), "Post"); !errors.Is(err, ErrDenied) {
+		t.Fatalf("expected anonymous query to be denied, got %v", err)
+	}
+
+	alice := WithViewer(context.Background(), Viewer{Subject: "alice"})
+	filters, err := policy.EvalQuery(alice, "Post")
+	if err != nil {
+		t.Fatalf("eval: %v", err)
+	}
+	want := []runtime.Predicate{{Column: "author_id", Operator: runtime.OpEqual, Value: "alice"}}
+	if !reflect.DeepEqual(filters, want) {
+		t.Fatalf("unexpected filters: %+v", filters)
+	}
+
+	admin := WithViewer(context.Background(), Viewer{Subject: "root", Roles: []string{"admin"}})
+	if filters, err := policy.EvalQuery(admin, "Post"); err != nil || len(filters) != 0 {
+		t.Fatalf("expected admin to skip filters, got %+v (err %v)", filters, err)
+	}
+	if filters, err := policy.EvalQuery(Bypass(context.Background()), "Post"); err != nil || filters != nil {
+		t.Fatalf("expected bypass to skip policy, got %+v (err %v)", filters, err)
+	}
+}
+
+func TestEvalMutationHonoursOpsAndOwnership(t *testing.T) {
+	policy := Policy{Mutation: []Rule{
+		DenyIfAnonymous(),
+		AlwaysDeny().On(runtime.MutationDelete),
+		FilterOwner("author_id"),
+	}}
+	alice := WithViewer(context.Background(), Viewer{Subject: "alice"})
+
+	owned := &testMutation{op: runtime.MutationUpdate, owned: true}
+	if err := policy.EvalMutation(alice, owned); err != nil || owned.checks != 1 {
+		t.Fatalf("expected owned update to pass after one check, got %v (checks %d)", err, owned.checks)
+	}
+
+	foreign := &testMutation{op: runtime.MutationUpdate}
+	var denied *DeniedError
+	if err := policy.EvalMutation(alice, foreign); !errors.As(err, &denied) || denied.Rule != RuleFilterOwner {
+		t.Fatalf("expected ownership denial, got %v", err)
+	}
+
+	deletion := &testMutation{op: runtime.MutationDelete, owned: true}
+	if err := policy.EvalMutation(alice, deletion); !errors.As(err, &denied) || denied.Rule != RuleAlwaysDeny || deletion.checks != 0 {
+		t.Fatalf("expected delete rule to deny before ownership check, got %v", err)
+	}
+
+	if err := policy.EvalMutation(context.Background(), owned); !errors.Is(err, ErrDenied) {
+		t.Fatalf("expected anonymous mutation to be denied, got %v", err)
+	}
+}
+
+func TestAppendFilters(t *testing.T) {
+	query, args := AppendFilters("SELECT id FROM posts AS t WHERE t.author_id IN ($1, $2)", []any{"a", "b"}, "t", []runtime.Predicate{
+		{Column: "author_id", Operator: runtime.OpEqual, Value: "alice"},
+	})
+	if query != "SELECT id FROM posts AS t WHERE t.author_id IN ($1, $2) AND t.author_id = $3" {
+		t.Fatalf("unexpected query: %s", query)
+	}
+	if !reflect.DeepEqual(args, []any{"a", "b", "alice"}) {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
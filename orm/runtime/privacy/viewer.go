@@ -0,0 +1,79 @@
+package privacy
+
+import (
+	"context"
+	"slices"
+
+	"github.com/deicod/erm/oidc"
+)
+
+// Viewer identifies the caller on whose behalf ORM operations run.
+type Viewer struct {
+	Subject string
+	Roles   []string
+	Claims  map[string]any
+}
+
+// HasRole reports whether the viewer holds any of roles.
+func (v Viewer) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if slices.Contains(v.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// ViewerFromClaims converts verified OIDC claims into a viewer.
+func ViewerFromClaims(claims oidc.Claims) Viewer {
+	return Viewer{
+		Subject: claims.Subject,
+		Roles:   append([]string(nil), claims.Roles...),
+		Claims:  claims.Raw,
+	}
+}
+
+type viewerKey struct{}
+
+type bypassKey struct{}
+
+// WithViewer attaches an explicit viewer to the context, taking precedence over OIDC claims.
+func WithViewer(ctx context.Context, viewer Viewer) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, viewerKey{}, viewer)
+}
+
+// FromContext returns the viewer set with WithViewer or, failing that, the one derived from the
+// OIDC claims placed in the context by the oidc middleware.
+func FromContext(ctx context.Context) (Viewer, bool) {
+	if ctx == nil {
+		return Viewer{}, false
+	}
+	if viewer, ok := ctx.Value(viewerKey{}).(Viewer); ok {
+		return viewer, true
+	}
+	if claims, ok := oidc.FromContext(ctx); ok {
+		return ViewerFromClaims(claims), true
+	}
+	return Viewer{}, false
+}
+
+// Bypass returns a context in which generated clients skip policy evaluation. Use it for
+// migrations, background jobs, and other trusted system code.
+func Bypass(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+// IsBypassed reports whether ctx was created with Bypass.
+func IsBypassed(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	bypassed, _ := ctx.Value(bypassKey{}).(bool)
+	return bypassed
+}
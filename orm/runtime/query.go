@@ -15,9 +15,30 @@ const (
 	OpLessThan    Operator = "<"
 	OpGTE         Operator = ">="
 	OpLTE         Operator = "<="
+	OpLike        Operator = "LIKE"
 	OpILike       Operator = "ILIKE"
+	OpIsNull      Operator = "IS NULL"
+	OpNotNull     Operator = "IS NOT NULL"
+	OpIn          Operator = "= ANY"
+	OpNotIn       Operator = "<> ALL"
+	OpBetween     Operator = "BETWEEN"
+
+	OpAnd    Operator = "AND"
+	OpOr     Operator = "OR"
+	OpNot    Operator = "NOT"
+	OpExists Operator = "EXISTS"
 )
 
+// Unary reports whether the operator takes no value, in which case Predicate.Value is ignored.
+func (op Operator) Unary() bool {
+	return op == OpIsNull || op == OpNotNull
+}
+
+// Logical reports whether the operator combines Predicate.Children instead of testing a column.
+func (op Operator) Logical() bool {
+	return op == OpAnd || op == OpOr || op == OpNot || op == OpExists
+}
+
 type SortDirection string
 
 const (
@@ -25,10 +46,14 @@ const (
 	SortDesc SortDirection = "DESC"
 )
 
+// Predicate is a node in a WHERE clause tree. Leaf predicates compare Column using Operator and
+// Value; logical predicates (OpAnd, OpOr, OpNot) combine Children. A slice of predicates is
+// joined with AND.
 type Predicate struct {
 	Column   string
 	Operator Operator
 	Value    any
+	Children []Predicate
 }
 
 type Order struct {
@@ -43,6 +68,27 @@ type SelectSpec struct {
 	Orders     []Order
 	Limit      int
 	Offset     int
+	// PartitionBy applies Limit and Offset to every group of rows sharing the column's value
+	// instead of to the whole result, ranking rows with ROW_NUMBER() in Orders. Eager loading uses
+	// it to limit children per parent.
+	PartitionBy string
+	// Through joins the link table of a many-to-many edge targeting Table, keeps rows linked to
+	// one of ThroughKeys (bound as a single array parameter) and selects the link's source column
+	// after Columns. Results are partitioned by that column.
+	Through     *EdgeJoin
+	ThroughKeys any
+	// KeyColumn breaks ties between rows with equal Orders values, usually the primary key. Once
+	// the select is ordered or positioned by After or Before, rows are sorted by
+	// KeysetOrders(Orders, KeyColumn).
+	KeyColumn string
+	// After and Before hold the sort key of a row, one value per column of
+	// KeysetOrders(Orders, KeyColumn), and keep only rows sorting strictly after or before it.
+	After  []any
+	Before []any
+	// FromEnd applies Limit and Offset from the end of the ordering, so a limited select returns
+	// the last rows, such as those closest to Before. Rows are still returned in Orders order.
+	// Partitioned selects rank each partition from its end.
+	FromEnd bool
 }
 
 type AggregateFunc string
@@ -55,15 +101,33 @@ const (
 	AggMax   AggregateFunc = "MAX"
 )
 
+// Aggregate applies Func to Column, or to every row when Column is empty or "*". A non-empty Alias
+// names the selected value.
 type Aggregate struct {
 	Func   AggregateFunc
 	Column string
+	Alias  string
 }
 
 type AggregateSpec struct {
 	Table      string
 	Predicates []Predicate
 	Aggregate  Aggregate
+	// Aggregates selects several aggregates at once and takes precedence over Aggregate.
+	Aggregates []Aggregate
+	// GroupBy computes the aggregates for every distinct combination of the columns, which are
+	// selected before them.
+	GroupBy []string
+	// Having keeps the groups matching every predicate. Predicates may compare aggregates through
+	// columns such as Count().Expr().
+	Having []Predicate
+	// Orders sorts the groups by grouped columns or aggregate aliases, and Limit caps their number.
+	Orders []Order
+	Limit  int
+	// Through joins the link table of a many-to-many edge targeting Table like SelectSpec, keeps
+	// rows linked to one of ThroughKeys and groups by the link's source column instead of GroupBy.
+	Through     *EdgeJoin
+	ThroughKeys any
 }
 
 func BuildSelectSQL(spec SelectSpec) (string, []any) {
@@ -71,104 +135,232 @@ func BuildSelectSQL(spec SelectSpec) (string, []any) {
 	if len(columns) == 0 {
 		columns = []string{"*"}
 	}
+	qualifier, partition := "", spec.PartitionBy
+	if spec.Through != nil {
+		qualifier, partition = spec.Table, throughAlias+"."+spec.Through.ThroughSourceColumn
+		qualified := make([]string, len(columns), len(columns)+1)
+		for i, column := range columns {
+			qualified[i] = qualifier + "." + column
+		}
+		columns = append(qualified, partition)
+	}
+	partitioned := partition != "" && (spec.Limit > 0 || spec.Offset > 0)
+	orders := spec.Orders
+	if spec.KeyColumn != "" && (len(orders) > 0 || len(spec.After) > 0 || len(spec.Before) > 0 || spec.FromEnd) {
+		orders = KeysetOrders(orders, spec.KeyColumn)
+	}
+	// Rows counted from the end are read in reverse, then put back in order by an outer select.
+	reversed := spec.FromEnd && (spec.Limit > 0 || spec.Offset > 0) && !partitioned
 
 	var sb strings.Builder
+	if reversed {
+		sb.WriteString("SELECT * FROM (")
+	}
 	sb.WriteString("SELECT ")
-	sb.WriteString(strings.Join(columns, ", "))
+	if partitioned {
+		outer := append([]string(nil), spec.Columns...)
+		if len(outer) == 0 {
+			outer = []string{"*"}
+		}
+		if spec.Through != nil {
+			columns[len(columns)-1] += " AS " + throughOwnerColumn
+			outer = append(outer, throughOwnerColumn)
+		}
+		sb.WriteString(strings.Join(outer, ", "))
+		sb.WriteString(" FROM (SELECT ")
+		sb.WriteString(strings.Join(columns, ", "))
+		sb.WriteString(", ROW_NUMBER() OVER (PARTITION BY ")
+		sb.WriteString(partition)
+		if spec.FromEnd {
+			writeOrders(&sb, reverseOrders(orders), qualifier)
+		} else {
+			writeOrders(&sb, orders, qualifier)
+		}
+		sb.WriteString(") AS erm_rank")
+	} else {
+		sb.WriteString(strings.Join(columns, ", "))
+	}
 	sb.WriteString(" FROM ")
 	sb.WriteString(spec.Table)
-
-	args := make([]any, 0, len(spec.Predicates)+2)
-	param := 1
-
-	if len(spec.Predicates) > 0 {
+	args := make([]any, 0, len(spec.Predicates)+3)
+	preds := spec.Predicates
+	filtered := len(preds) > 0 || len(spec.After) > 0 || len(spec.Before) > 0
+	if spec.Through != nil {
+		fmt.Fprintf(&sb, " JOIN %s AS %s ON %s.%s = %s.%s", spec.Through.Through, throughAlias, throughAlias, spec.Through.ThroughTargetColumn, spec.Table, spec.Through.TargetColumn)
 		sb.WriteString(" WHERE ")
-		for i, pred := range spec.Predicates {
-			if i > 0 {
+		sb.WriteString(partition)
+		sb.WriteString(" = ANY($1)")
+		args = append(args, spec.ThroughKeys)
+		if filtered {
+			sb.WriteString(" AND ")
+		}
+	} else if filtered {
+		sb.WriteString(" WHERE ")
+	}
+	for i, pred := range preds {
+		if i > 0 {
+			sb.WriteString(" AND ")
+		}
+		args = WritePredicate(&sb, pred, qualifier, args)
+	}
+	conditions := len(preds)
+	for _, key := range []struct {
+		values []any
+		before bool
+	}{{spec.After, false}, {spec.Before, true}} {
+		if len(key.values) == 0 {
+			continue
+		}
+		if conditions > 0 {
+			sb.WriteString(" AND ")
+		}
+		args = writeKeyset(&sb, orders, key.values, key.before, qualifier, args)
+		conditions++
+	}
+	if partitioned {
+		sb.WriteString(") AS erm_ranked WHERE ")
+		if spec.Offset > 0 {
+			sb.WriteString("erm_rank > $")
+			sb.WriteString(strconv.Itoa(len(args) + 1))
+			args = append(args, spec.Offset)
+		}
+		if spec.Limit > 0 {
+			if spec.Offset > 0 {
 				sb.WriteString(" AND ")
 			}
-			sb.WriteString(pred.Column)
-			sb.WriteByte(' ')
-			sb.WriteString(string(pred.Operator))
-			sb.WriteByte(' ')
-			sb.WriteString("$")
-			sb.WriteString(strconv.Itoa(param))
-			args = append(args, pred.Value)
-			param++
-		}
-	}
-
-	if len(spec.Orders) > 0 {
-		sb.WriteString(" ORDER BY ")
-		for i, order := range spec.Orders {
-			if i > 0 {
-				sb.WriteString(", ")
-			}
-			sb.WriteString(order.Column)
-			sb.WriteByte(' ')
-			sb.WriteString(string(order.Direction))
+			sb.WriteString("erm_rank <= $")
+			sb.WriteString(strconv.Itoa(len(args) + 1))
+			args = append(args, spec.Offset+spec.Limit)
 		}
+		writeOrders(&sb, orders, "")
+		return sb.String(), args
+	}
+	if reversed {
+		writeOrders(&sb, reverseOrders(orders), qualifier)
+	} else {
+		writeOrders(&sb, orders, qualifier)
 	}
-
 	if spec.Limit > 0 {
 		sb.WriteString(" LIMIT $")
-		sb.WriteString(strconv.Itoa(param))
+		sb.WriteString(strconv.Itoa(len(args) + 1))
 		args = append(args, spec.Limit)
-		param++
 	}
-
 	if spec.Offset > 0 {
 		sb.WriteString(" OFFSET $")
-		sb.WriteString(strconv.Itoa(param))
+		sb.WriteString(strconv.Itoa(len(args) + 1))
 		args = append(args, spec.Offset)
 	}
-
+	if reversed {
+		sb.WriteString(") AS erm_page")
+		writeOrders(&sb, orders, "")
+	}
 	return sb.String(), args
 }
 
+const (
+	throughAlias       = "erm_j"
+	throughOwnerColumn = "erm_owner"
+)
+
+func writeOrders(sb *strings.Builder, orders []Order, qualifier string) {
+	if len(orders) == 0 {
+		return
+	}
+	sb.WriteString(" ORDER BY ")
+	for i, order := range orders {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if qualifier != "" {
+			sb.WriteString(qualifier)
+			sb.WriteByte('.')
+		}
+		sb.WriteString(order.Column)
+		sb.WriteByte(' ')
+		sb.WriteString(string(order.Direction))
+	}
+}
+
 func BuildAggregateSQL(spec AggregateSpec) (string, []any) {
-	column := spec.Aggregate.Column
-	if column == "" {
-		column = "*"
+	qualifier, groups := "", spec.GroupBy
+	if spec.Through != nil {
+		qualifier, groups = spec.Table, []string{throughAlias + "." + spec.Through.ThroughSourceColumn}
+	}
+	selects := append([]string(nil), groups...)
+	for _, agg := range spec.aggregates() {
+		expr := agg.expr(qualifier)
+		if agg.Alias != "" {
+			expr += " AS " + agg.Alias
+		}
+		selects = append(selects, expr)
 	}
 
 	var sb strings.Builder
 	sb.WriteString("SELECT ")
-	sb.WriteString(string(spec.Aggregate.Func))
-	sb.WriteByte('(')
-	sb.WriteString(column)
-	sb.WriteString(") FROM ")
+	sb.WriteString(strings.Join(selects, ", "))
+	sb.WriteString(" FROM ")
 	sb.WriteString(spec.Table)
 
-	args := make([]any, 0, len(spec.Predicates))
-	param := 1
-
-	if len(spec.Predicates) > 0 {
-		sb.WriteString(" WHERE ")
-		for i, pred := range spec.Predicates {
-			if i > 0 {
-				sb.WriteString(" AND ")
-			}
-			sb.WriteString(pred.Column)
-			sb.WriteByte(' ')
-			sb.WriteString(string(pred.Operator))
-			sb.WriteByte(' ')
-			sb.WriteString("$")
-			sb.WriteString(strconv.Itoa(param))
-			args = append(args, pred.Value)
-			param++
+	args := make([]any, 0, len(spec.Predicates)+len(spec.Having)+2)
+	conditions := 0
+	if spec.Through != nil {
+		fmt.Fprintf(&sb, " JOIN %s AS %s ON %s.%s = %s.%s WHERE %s = ANY($1)", spec.Through.Through, throughAlias, throughAlias, spec.Through.ThroughTargetColumn, spec.Table, spec.Through.TargetColumn, groups[0])
+		args = append(args, spec.ThroughKeys)
+		conditions++
+	}
+	for _, pred := range spec.Predicates {
+		if conditions > 0 {
+			sb.WriteString(" AND ")
+		} else {
+			sb.WriteString(" WHERE ")
+		}
+		args = WritePredicate(&sb, pred, qualifier, args)
+		conditions++
+	}
+	if len(groups) > 0 {
+		sb.WriteString(" GROUP BY ")
+		sb.WriteString(strings.Join(groups, ", "))
+	}
+	for i, pred := range spec.Having {
+		if i > 0 {
+			sb.WriteString(" AND ")
+		} else {
+			sb.WriteString(" HAVING ")
 		}
+		args = WritePredicate(&sb, pred, "", args)
+	}
+	writeOrders(&sb, spec.Orders, "")
+	if spec.Limit > 0 {
+		sb.WriteString(" LIMIT $")
+		sb.WriteString(strconv.Itoa(len(args) + 1))
+		args = append(args, spec.Limit)
 	}
 
 	return sb.String(), args
 }
 
+func (spec AggregateSpec) aggregates() []Aggregate {
+	if len(spec.Aggregates) > 0 {
+		return spec.Aggregates
+	}
+	if spec.Aggregate.Func != "" {
+		return []Aggregate{spec.Aggregate}
+	}
+	return nil
+}
+
 func (spec AggregateSpec) Validate() error {
 	if spec.Table == "" {
 		return fmt.Errorf("table name is required")
 	}
-	if spec.Aggregate.Func == "" {
+	aggregates := spec.aggregates()
+	if len(aggregates) == 0 && len(spec.GroupBy) == 0 {
 		return fmt.Errorf("aggregate function is required")
 	}
+	for _, agg := range aggregates {
+		if agg.Func == "" {
+			return fmt.Errorf("aggregate function is required")
+		}
+	}
 	return nil
 }
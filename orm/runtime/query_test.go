@@ -3,6 +3,7 @@ package runtime
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 
@@ -48,6 +49,92 @@ func TestBuildSelectSQLDefaults(t *testing.T) {
 	}
 }
 
+func TestBuildSelectSQLUnaryPredicates(t *testing.T) {
+	spec := SelectSpec{
+		Table:   "posts",
+		Columns: []string{"id"},
+		Predicates: []Predicate{
+			{Column: "deleted_at", Operator: OpIsNull},
+			{Column: "title", Operator: OpEqual, Value: "hello"},
+			{Column: "published_at", Operator: OpNotNull, Value: "ignored"},
+		},
+		Limit: 5,
+	}
+
+	sql, args := BuildSelectSQL(spec)
+	expected := "SELECT id FROM posts WHERE deleted_at IS NULL AND title = $1 AND published_at IS NOT NULL LIMIT $2"
+	if sql != expected {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 2 || args[0] != "hello" || args[1] != 5 {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestBuildSelectSQLPartitioned(t *testing.T) {
+	spec := SelectSpec{
+		Table:       "comments",
+		Columns:     []string{"id", "post_id"},
+		Predicates:  []Predicate{In("post_id", []string{"p1", "p2"})},
+		Orders:      []Order{{Column: "created_at", Direction: SortDesc}},
+		Limit:       5,
+		Offset:      1,
+		PartitionBy: "post_id",
+	}
+
+	sql, args := BuildSelectSQL(spec)
+	expected := "SELECT id, post_id FROM (SELECT id, post_id, ROW_NUMBER() OVER (PARTITION BY post_id ORDER BY created_at DESC) AS erm_rank FROM comments WHERE post_id = ANY($1)) AS erm_ranked WHERE erm_rank > $2 AND erm_rank <= $3 ORDER BY created_at DESC"
+	if sql != expected {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 3 || args[1] != 1 || args[2] != 6 {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+
+	spec.Offset, spec.FromEnd = 0, true
+	sql, _ = BuildSelectSQL(spec)
+	expected = "SELECT id, post_id FROM (SELECT id, post_id, ROW_NUMBER() OVER (PARTITION BY post_id ORDER BY created_at ASC) AS erm_rank FROM comments WHERE post_id = ANY($1)) AS erm_ranked WHERE erm_rank <= $2 ORDER BY created_at DESC"
+	if sql != expected {
+		t.Fatalf("partitions read from the end should rank in reverse:\n got: %s\nwant: %s", sql, expected)
+	}
+
+	spec.Limit, spec.FromEnd = 0, false
+	sql, _ = BuildSelectSQL(spec)
+	if sql != "SELECT id, post_id FROM comments WHERE post_id = ANY($1) ORDER BY created_at DESC" {
+		t.Fatalf("unlimited partitions should not rank rows: %s", sql)
+	}
+}
+
+func TestBuildSelectSQLThrough(t *testing.T) {
+	spec := SelectSpec{
+		Table:       "tags",
+		Columns:     []string{"id", "name"},
+		Predicates:  []Predicate{HasPrefix("name", "go")},
+		Orders:      []Order{{Column: "name", Direction: SortAsc}},
+		Through:     &EdgeJoin{Name: "tags", SourceTable: "posts", SourceColumn: "id", TargetTable: "tags", TargetColumn: "id", Through: "posts_tags", ThroughSourceColumn: "post_id", ThroughTargetColumn: "tag_id"},
+		ThroughKeys: []string{"p1"},
+	}
+
+	sql, args := BuildSelectSQL(spec)
+	expected := "SELECT tags.id, tags.name, erm_j.post_id FROM tags JOIN posts_tags AS erm_j ON erm_j.tag_id = tags.id WHERE erm_j.post_id = ANY($1) AND tags.name LIKE $2 ORDER BY tags.name ASC"
+	if sql != expected {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 2 || args[1] != "go%" {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+
+	spec.Limit = 3
+	sql, args = BuildSelectSQL(spec)
+	expected = "SELECT id, name, erm_owner FROM (SELECT tags.id, tags.name, erm_j.post_id AS erm_owner, ROW_NUMBER() OVER (PARTITION BY erm_j.post_id ORDER BY tags.name ASC) AS erm_rank FROM tags JOIN posts_tags AS erm_j ON erm_j.tag_id = tags.id WHERE erm_j.post_id = ANY($1) AND tags.name LIKE $2) AS erm_ranked WHERE erm_rank <= $3 ORDER BY name ASC"
+	if sql != expected {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 3 || args[2] != 3 {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
 func TestBuildAggregateSQL(t *testing.T) {
 	spec := AggregateSpec{
 		Table: "users",
@@ -82,6 +169,67 @@ func TestBuildAggregateSQLDefaultColumn(t *testing.T) {
 	}
 }
 
+func TestBuildAggregateSQLGrouped(t *testing.T) {
+	spec := AggregateSpec{
+		Table:      "comments",
+		Predicates: []Predicate{In("post_id", []string{"p1", "p2"}), IsNull("deleted_at")},
+		Aggregate:  Aggregate{Func: AggCount},
+		GroupBy:    []string{"post_id"},
+	}
+
+	sql, args := BuildAggregateSQL(spec)
+	expected := "SELECT post_id, COUNT(*) FROM comments WHERE post_id = ANY($1) AND deleted_at IS NULL GROUP BY post_id"
+	if sql != expected {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 1 {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+
+	spec = AggregateSpec{
+		Table:       "tags",
+		Predicates:  []Predicate{HasPrefix("name", "go")},
+		Aggregate:   Aggregate{Func: AggCount},
+		Through:     &EdgeJoin{Name: "tags", SourceTable: "posts", SourceColumn: "id", TargetTable: "tags", TargetColumn: "id", Through: "posts_tags", ThroughSourceColumn: "post_id", ThroughTargetColumn: "tag_id"},
+		ThroughKeys: []string{"p1"},
+	}
+	sql, args = BuildAggregateSQL(spec)
+	expected = "SELECT erm_j.post_id, COUNT(*) FROM tags JOIN posts_tags AS erm_j ON erm_j.tag_id = tags.id WHERE erm_j.post_id = ANY($1) AND tags.name LIKE $2 GROUP BY erm_j.post_id"
+	if sql != expected {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+	if len(args) != 2 || args[1] != "go%" {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestBuildAggregateSQLMultipleAggregates(t *testing.T) {
+	spec := AggregateSpec{
+		Table:      "orders",
+		Predicates: []Predicate{{Column: "status", Operator: OpEqual, Value: "paid"}},
+		GroupBy:    []string{"customer_id", "currency"},
+		Aggregates: []Aggregate{Count(), Sum("amount"), Max("created_at").As("latest")},
+		Having:     []Predicate{{Column: Sum("amount").Expr(), Operator: OpGreaterThan, Value: 100}},
+		Orders:     []Order{{Column: "sum_amount", Direction: SortDesc}},
+		Limit:      10,
+	}
+
+	sql, args := BuildAggregateSQL(spec)
+	expected := "SELECT customer_id, currency, COUNT(*) AS count, SUM(amount) AS sum_amount, MAX(created_at) AS latest FROM orders WHERE status = $1 GROUP BY customer_id, currency HAVING SUM(amount) > $2 ORDER BY sum_amount DESC LIMIT $3"
+	if sql != expected {
+		t.Fatalf("unexpected SQL:\n got: %s\nwant: %s", sql, expected)
+	}
+	if !reflect.DeepEqual(args, []any{"paid", 100, 10}) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if err := (AggregateSpec{Table: "orders"}).Validate(); err == nil {
+		t.Fatalf("expected an aggregate or group to be required")
+	}
+}
+
 func TestQueryObserverEmitsTelemetry(t *testing.T) {
 	ctx := context.WithValue(context.Background(), ctxKey{}, "request-42")
 
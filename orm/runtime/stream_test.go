@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/jackc/pgx/v5"
@@ -44,10 +45,11 @@ func TestStreamScanError(t *testing.T) {
 }
 
 type sliceRows struct {
-	data   [][]any
-	idx    int
-	closed bool
-	err    error
+	columns []string
+	data    [][]any
+	idx     int
+	closed  bool
+	err     error
 }
 
 func newSliceRows(rows ...[]any) *sliceRows {
@@ -60,7 +62,13 @@ func (r *sliceRows) Err() error { return r.err }
 
 func (r *sliceRows) CommandTag() pgconn.CommandTag { return pgconn.CommandTag{} }
 
-func (r *sliceRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *sliceRows) FieldDescriptions() []pgconn.FieldDescription {
+	descriptions := make([]pgconn.FieldDescription, len(r.columns))
+	for i, column := range r.columns {
+		descriptions[i].Name = column
+	}
+	return descriptions
+}
 
 func (r *sliceRows) Next() bool {
 	if r.closed || r.idx >= len(r.data) {
@@ -88,7 +96,12 @@ func (r *sliceRows) Scan(dest ...any) error {
 				return errors.New("unexpected type")
 			}
 		default:
-			return errors.New("unsupported destination type")
+			target := reflect.ValueOf(d).Elem()
+			value := reflect.ValueOf(row[i])
+			if !value.Type().AssignableTo(target.Type()) {
+				return errors.New("unsupported destination type")
+			}
+			target.Set(value)
 		}
 	}
 	return nil
@@ -0,0 +1,124 @@
+package runtime
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AssignOp selects how an UPDATE assignment derives the new column value.
+type AssignOp string
+
+const (
+	// AssignSet writes Value.
+	AssignSet AssignOp = "set"
+	// AssignAdd adds Value to the stored value.
+	AssignAdd AssignOp = "add"
+	// AssignClear writes NULL.
+	AssignClear AssignOp = "clear"
+)
+
+// Assignment is one `column = ...` entry of an UPDATE.
+type Assignment struct {
+	Column string
+	Op     AssignOp
+	Value  any
+}
+
+// UpdateSpec describes an UPDATE of the rows matching every predicate.
+type UpdateSpec struct {
+	Table       string
+	Assignments []Assignment
+	Predicates  []Predicate
+	Returning   []string
+}
+
+// BuildUpdateSQL renders spec. It refuses updates without assignments.
+func BuildUpdateSQL(spec UpdateSpec) (string, []any, error) {
+	if spec.Table == "" {
+		return "", nil, fmt.Errorf("table is required")
+	}
+	if len(spec.Assignments) == 0 {
+		return "", nil, fmt.Errorf("at least one assignment is required")
+	}
+	var (
+		sb   strings.Builder
+		args []any
+	)
+	sb.WriteString("UPDATE ")
+	sb.WriteString(spec.Table)
+	sb.WriteString(" SET ")
+	for i, assignment := range spec.Assignments {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(assignment.Column)
+		sb.WriteString(" = ")
+		switch assignment.Op {
+		case AssignSet, "":
+			args = append(args, assignment.Value)
+			sb.WriteByte('$')
+			sb.WriteString(strconv.Itoa(len(args)))
+		case AssignAdd:
+			args = append(args, assignment.Value)
+			fmt.Fprintf(&sb, "%s + $%d", assignment.Column, len(args))
+		case AssignClear:
+			sb.WriteString("NULL")
+		default:
+			return "", nil, fmt.Errorf("unknown assignment %q for column %s", assignment.Op, assignment.Column)
+		}
+	}
+	args = writeWhere(&sb, spec.Predicates, args)
+	writeReturning(&sb, spec.Returning)
+	return sb.String(), args, nil
+}
+
+// DeleteSpec describes a DELETE of the rows matching every predicate.
+type DeleteSpec struct {
+	Table      string
+	Predicates []Predicate
+	// SoftDeleteColumn, when set, stamps the column with now() on live rows instead of deleting them.
+	SoftDeleteColumn string
+	Returning        []string
+}
+
+// BuildDeleteSQL renders spec. Without predicates every row of the table is deleted.
+func BuildDeleteSQL(spec DeleteSpec) (string, []any, error) {
+	if spec.Table == "" {
+		return "", nil, fmt.Errorf("table is required")
+	}
+	var (
+		sb   strings.Builder
+		args []any
+	)
+	preds := spec.Predicates
+	if spec.SoftDeleteColumn != "" {
+		fmt.Fprintf(&sb, "UPDATE %s SET %s = now()", spec.Table, spec.SoftDeleteColumn)
+		preds = append(append([]Predicate(nil), preds...), IsNull(spec.SoftDeleteColumn))
+	} else {
+		sb.WriteString("DELETE FROM ")
+		sb.WriteString(spec.Table)
+	}
+	args = writeWhere(&sb, preds, args)
+	writeReturning(&sb, spec.Returning)
+	return sb.String(), args, nil
+}
+
+func writeWhere(sb *strings.Builder, preds []Predicate, args []any) []any {
+	for i, pred := range preds {
+		if i == 0 {
+			sb.WriteString(" WHERE ")
+		} else {
+			sb.WriteString(" AND ")
+		}
+		args = WritePredicate(sb, pred, "", args)
+	}
+	return args
+}
+
+func writeReturning(sb *strings.Builder, columns []string) {
+	if len(columns) > 0 {
+		sb.WriteString(" RETURNING ")
+		sb.WriteString(strings.Join(columns, ", "))
+	}
+}
@@ -0,0 +1,66 @@
+package runtime
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildUpdateSQL(t *testing.T) {
+	sql, args, err := BuildUpdateSQL(UpdateSpec{
+		Table: "posts",
+		Assignments: []Assignment{
+			{Column: "title", Op: AssignSet, Value: "hello"},
+			{Column: "body", Op: AssignClear},
+			{Column: "view_count", Op: AssignAdd, Value: 1},
+		},
+		Predicates: []Predicate{Compare("id", OpEqual, "p1"), IsNull("deleted_at")},
+		Returning:  []string{"id", "title"},
+	})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	want := "UPDATE posts SET title = $1, body = NULL, view_count = view_count + $2 WHERE id = $3 AND deleted_at IS NULL RETURNING id, title"
+	if sql != want {
+		t.Fatalf("unexpected sql:\n got %s\nwant %s", sql, want)
+	}
+	if !reflect.DeepEqual(args, []any{"hello", 1, "p1"}) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+	if _, _, err := BuildUpdateSQL(UpdateSpec{Table: "posts"}); err == nil {
+		t.Fatalf("expected error without assignments")
+	}
+}
+
+func TestBuildDeleteSQL(t *testing.T) {
+	cutoff := "2024-01-01"
+	sql, args, err := BuildDeleteSQL(DeleteSpec{
+		Table:      "posts",
+		Predicates: []Predicate{Compare("workspace_id", OpEqual, "w1"), Compare("created_at", OpLessThan, cutoff)},
+		Returning:  []string{"id"},
+	})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if want := "DELETE FROM posts WHERE workspace_id = $1 AND created_at < $2 RETURNING id"; sql != want {
+		t.Fatalf("unexpected sql:\n got %s\nwant %s", sql, want)
+	}
+	if !reflect.DeepEqual(args, []any{"w1", cutoff}) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+
+	sql, args, err = BuildDeleteSQL(DeleteSpec{
+		Table:            "posts",
+		Predicates:       []Predicate{Compare("workspace_id", OpEqual, "w1")},
+		SoftDeleteColumn: "deleted_at",
+		Returning:        []string{"id"},
+	})
+	if err != nil {
+		t.Fatalf("build soft: %v", err)
+	}
+	if want := "UPDATE posts SET deleted_at = now() WHERE workspace_id = $1 AND deleted_at IS NULL RETURNING id"; sql != want {
+		t.Fatalf("unexpected soft sql:\n got %s\nwant %s", sql, want)
+	}
+	if !reflect.DeepEqual(args, []any{"w1"}) {
+		t.Fatalf("unexpected soft args: %#v", args)
+	}
+}
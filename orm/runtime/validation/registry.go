@@ -24,6 +24,22 @@ type Subject struct {
 	Operation Operation
 	Record    Record
 	Input     any
+	// Fields lists the record fields a partial update writes. It is nil when the mutation writes
+	// every field; untouched fields then hold zero values in Record.
+	Fields []string
+}
+
+// Touches reports whether the mutation writes field.
+func (s Subject) Touches(field string) bool {
+	if s.Fields == nil {
+		return true
+	}
+	for _, name := range s.Fields {
+		if name == field {
+			return true
+		}
+	}
+	return false
 }
 
 // Rule represents a validation constraint applied to an entity mutation.
@@ -71,6 +87,12 @@ func (r *Registry) Entity(name string) *EntityRules {
 // Validate executes all rules registered for the entity/operation pair.
 // Multiple rule violations are aggregated into a single error value.
 func (r *Registry) Validate(ctx context.Context, entity string, op Operation, record Record, input any) error {
+	return r.ValidateFields(ctx, entity, op, record, input, nil)
+}
+
+// ValidateFields is Validate for partial updates that only write fields. Rules can skip
+// untouched fields with Subject.Touches.
+func (r *Registry) ValidateFields(ctx context.Context, entity string, op Operation, record Record, input any, fields []string) error {
 	if entity == "" {
 		return nil
 	}
@@ -80,7 +102,7 @@ func (r *Registry) Validate(ctx context.Context, entity string, op Operation, re
 	if rules == nil {
 		return nil
 	}
-	subject := Subject{Entity: entity, Operation: op, Record: record, Input: input}
+	subject := Subject{Entity: entity, Operation: op, Record: record, Input: input, Fields: fields}
 	entries := rules.snapshot(op)
 	if len(entries) == 0 {
 		return nil
@@ -77,7 +77,7 @@ func (b *StringRuleBuilder) Rule() Rule {
 	pattern := b.pattern
 	allowEmpty := b.allowEmpty
 	return RuleFunc(func(_ context.Context, subject Subject) error {
-		if field == "" {
+		if field == "" || !subject.Touches(field) {
 			return nil
 		}
 		raw, ok := subject.Record.Get(field)
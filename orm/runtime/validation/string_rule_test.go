@@ -37,3 +37,15 @@ func TestStringRuleOptional(t *testing.T) {
 		t.Fatalf("expected min length error")
 	}
 }
+
+func TestStringRuleSkipsUntouchedFields(t *testing.T) {
+	rule := String("Email").Required().Rule()
+	subj := Subject{Record: Record{"Email": ""}, Fields: []string{"Name"}}
+	if err := rule.Validate(context.Background(), subj); err != nil {
+		t.Fatalf("expected untouched field to pass: %v", err)
+	}
+	subj.Fields = append(subj.Fields, "Email")
+	if err := rule.Validate(context.Background(), subj); err == nil {
+		t.Fatalf("expected required error for touched field")
+	}
+}
@@ -5,12 +5,13 @@ import "github.com/deicod/erm/orm/dsl"
 // User models the User domain entity used in generator examples.
 type User struct{ dsl.Schema }
 
+func (User) Mixin() []dsl.Mixin {
+	return []dsl.Mixin{dsl.UUIDv7IDMixin(), dsl.TimeMixin()}
+}
+
 func (User) Fields() []dsl.Field {
 	return []dsl.Field{
-		dsl.UUIDv7("id").Primary(),
 		dsl.Text("slug").Computed(dsl.Computed(dsl.Expression("id::text"))),
-		dsl.TimestampTZ("created_at").DefaultNow(),
-		dsl.TimestampTZ("updated_at").UpdateNow(),
 	}
 }
 
@@ -15,7 +15,8 @@ type QueryResolver any
 type SubscriptionResolver any
 
 type DirectiveRoot struct {
-	Auth func(ctx context.Context, obj any, next gql.Resolver, roles []string) (any, error)
+	Auth      func(ctx context.Context, obj any, next gql.Resolver, roles []string, owner *string) (any, error)
+	AuthClaim func(ctx context.Context, obj any, next gql.Resolver, claim string, field *string, value *string) (any, error)
 }
 
 type Config struct {
@@ -27,6 +27,7 @@ var runtimeTemplates = []runtimeTemplate{
 	{source: "graphql/resolvers/resolver.go.tmpl", target: "graphql/resolvers/resolver.go", isTemplate: true},
 	{source: "graphql/resolvers/entities_gen.go", target: "graphql/resolvers/entities_gen.go"},
 	{source: "graphql/resolvers/entities_hooks.go.tmpl", target: "graphql/resolvers/entities_hooks.go", isTemplate: true},
+	{source: "graphql/resolvers/errors.go.tmpl", target: "graphql/resolvers/errors.go", isTemplate: true},
 	{source: "graphql/server/schema.go.tmpl", target: "graphql/server/schema.go", isTemplate: true},
 	{source: "graphql/server/server.go", target: "graphql/server/server.go"},
 	{source: "graphql/subscriptions/bus.go", target: "graphql/subscriptions/bus.go"},
@@ -32,6 +32,10 @@ func ScaffoldGraphQLRuntime(tb stdtesting.TB, root, modulePath string) {
 		}
 	}
 
+	// The ORM client stub only stands in for an ORM that has not been generated yet.
+	if _, err := os.Stat(filepath.Join(root, "orm", "gen", "client_gen.go")); err == nil {
+		return
+	}
 	stubs := map[string]string{
 		filepath.Join(root, "orm", "gen", "client.go"): `package gen
 